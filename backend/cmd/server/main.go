@@ -9,6 +9,12 @@ import (
 	"time"
 
 	"monman-backend/internal/api"
+	"monman-backend/internal/config"
+	"monman-backend/internal/db"
+	"monman-backend/internal/notify"
+	"monman-backend/internal/repository"
+	"monman-backend/internal/scheduler"
+	"monman-backend/internal/service"
 )
 
 func main() {
@@ -17,13 +23,61 @@ func main() {
 		addr = ":" + p
 	}
 
-	h := api.NewHandler()
+	cfg := config.Load()
+	database, err := db.Connect(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer database.Close()
+
+	h := api.NewHandler(cfg, database)
 
 	srv := &http.Server{
 		Addr:    addr,
 		Handler: h,
 	}
 
+	// Background jobs: recurring transactions, budget period rollover, budget alerts
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	if cfg.Scheduler.IntervalMinutes > 0 {
+		txRepo := repository.NewTransactionRepository(database.DB)
+		accRepo := repository.NewAccountRepository(database.DB)
+		catRepo := repository.NewCategoryRepository(database.DB)
+		budRepo := repository.NewBudgetRepository(database.DB)
+		recurringService := service.NewRecurringService(
+			repository.NewRecurringRepository(database.DB), txRepo, accRepo, catRepo)
+		rateRepo := repository.NewExchangeRateRepository(database.DB)
+		alertService := service.NewAlertService(
+			repository.NewAlertRepository(database.DB), budRepo, rateRepo, notify.FromConfig(cfg.Alerts))
+		financeService := service.NewFinanceService(txRepo, accRepo, catRepo, budRepo,
+			rateRepo, repository.NewCategorizationRuleRepository(database.DB), alertService)
+
+		scheduler.Start(jobsCtx, time.Duration(cfg.Scheduler.IntervalMinutes)*time.Minute,
+			scheduler.Job{Name: "recurring transactions", Run: func(now time.Time) error {
+				n, err := recurringService.ProcessDue(now)
+				if n > 0 {
+					log.Printf("recurring transactions: posted %d occurrence(s)", n)
+				}
+				return err
+			}},
+			scheduler.Job{Name: "budget rollover", Run: func(now time.Time) error {
+				n, err := financeService.RolloverDue(now)
+				if n > 0 {
+					log.Printf("budget rollover: closed %d period(s)", n)
+				}
+				return err
+			}},
+			scheduler.Job{Name: "budget alerts", Run: func(now time.Time) error {
+				n, err := alertService.EvaluateAll(now)
+				if n > 0 {
+					log.Printf("budget alerts: recorded %d alert(s)", n)
+				}
+				return err
+			}},
+		)
+	}
+
 	go func() {
 		log.Printf("Starting server on %s\n", addr)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -36,6 +90,7 @@ func main() {
 	signal.Notify(quit, os.Interrupt)
 	<-quit
 	log.Println("Shutting down server...")
+	stopJobs()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
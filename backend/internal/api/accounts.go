@@ -0,0 +1,83 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"monman-backend/internal/middleware"
+	"monman-backend/internal/models"
+	"monman-backend/internal/service"
+	"monman-backend/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) handleUpdateAccount(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "accountID"))
+	if err != nil {
+		utils.WriteErrorResponse(w, "Invalid account id", http.StatusBadRequest)
+		return
+	}
+	var req models.UpdateAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	a, err := h.financeService.UpdateAccount(userID, id, &req, time.Now())
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("update account: %v", err)
+		utils.WriteErrorResponse(w, "Failed to update account", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   a,
+	}, http.StatusOK)
+}
+
+func (h *Handler) handleArchiveAccount(w http.ResponseWriter, r *http.Request) {
+	h.setAccountActive(w, r, false)
+}
+
+func (h *Handler) handleRestoreAccount(w http.ResponseWriter, r *http.Request) {
+	h.setAccountActive(w, r, true)
+}
+
+func (h *Handler) setAccountActive(w http.ResponseWriter, r *http.Request, active bool) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "accountID"))
+	if err != nil {
+		utils.WriteErrorResponse(w, "Invalid account id", http.StatusBadRequest)
+		return
+	}
+	a, err := h.financeService.SetAccountActive(userID, id, active)
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("set account active=%v: %v", active, err)
+		utils.WriteErrorResponse(w, "Failed to update account", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   a,
+	}, http.StatusOK)
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"monman-backend/internal/middleware"
+	"monman-backend/internal/models"
+	"monman-backend/internal/service"
+	"monman-backend/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// handleAlerts lists budget alerts, newest first; ?unread=1 limits to unread ones.
+func (h *Handler) handleAlerts(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	limit := 50
+	offset := 0
+	if q := r.URL.Query().Get("limit"); q != "" {
+		if n, err := strconv.Atoi(q); err == nil && n > 0 && n <= 200 {
+			limit = n
+		}
+	}
+	if q := r.URL.Query().Get("offset"); q != "" {
+		if n, err := strconv.Atoi(q); err == nil && n >= 0 {
+			offset = n
+		}
+	}
+	unreadOnly := r.URL.Query().Get("unread") == "1" || r.URL.Query().Get("unread") == "true"
+
+	payload, err := h.alertService.List(userID, unreadOnly, limit, offset)
+	if err != nil {
+		log.Printf("alerts: %v", err)
+		utils.WriteErrorResponse(w, "Failed to load alerts", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   payload,
+	}, http.StatusOK)
+}
+
+func (h *Handler) handleUpdateAlert(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "alertID"))
+	if err != nil {
+		utils.WriteErrorResponse(w, "Invalid alert id", http.StatusBadRequest)
+		return
+	}
+	var req models.UpdateAlertRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	if err := h.alertService.SetRead(userID, id, req.IsRead); err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("update alert: %v", err)
+		utils.WriteErrorResponse(w, "Failed to update alert", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   map[string]string{"id": id.String()},
+	}, http.StatusOK)
+}
+
+func (h *Handler) handleMarkAllAlertsRead(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	n, err := h.alertService.MarkAllRead(userID)
+	if err != nil {
+		log.Printf("mark alerts read: %v", err)
+		utils.WriteErrorResponse(w, "Failed to update alerts", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   map[string]int64{"updated": n},
+	}, http.StatusOK)
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"monman-backend/internal/middleware"
+	"monman-backend/internal/models"
+	"monman-backend/internal/service"
+	"monman-backend/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// handleRolloverBudget closes the budget's ended period(s) into history and opens the current window.
+func (h *Handler) handleRolloverBudget(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "budgetID"))
+	if err != nil {
+		utils.WriteErrorResponse(w, "Invalid budget id", http.StatusBadRequest)
+		return
+	}
+	payload, err := h.financeService.RolloverBudget(userID, id, time.Now())
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("rollover budget: %v", err)
+		utils.WriteErrorResponse(w, "Failed to roll over budget", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   payload,
+	}, http.StatusOK)
+}
+
+func (h *Handler) handleUpdateBudget(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "budgetID"))
+	if err != nil {
+		utils.WriteErrorResponse(w, "Invalid budget id", http.StatusBadRequest)
+		return
+	}
+	var req models.UpdateBudgetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	b, err := h.financeService.UpdateBudget(userID, id, &req)
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("update budget: %v", err)
+		utils.WriteErrorResponse(w, "Failed to update budget", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   b,
+	}, http.StatusOK)
+}
+
+func (h *Handler) handleArchiveBudget(w http.ResponseWriter, r *http.Request) {
+	h.setBudgetActive(w, r, false)
+}
+
+func (h *Handler) handleRestoreBudget(w http.ResponseWriter, r *http.Request) {
+	h.setBudgetActive(w, r, true)
+}
+
+func (h *Handler) setBudgetActive(w http.ResponseWriter, r *http.Request, active bool) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "budgetID"))
+	if err != nil {
+		utils.WriteErrorResponse(w, "Invalid budget id", http.StatusBadRequest)
+		return
+	}
+	b, err := h.financeService.SetBudgetActive(userID, id, active)
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("set budget active=%v: %v", active, err)
+		utils.WriteErrorResponse(w, "Failed to update budget", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   b,
+	}, http.StatusOK)
+}
+
+func (h *Handler) handleReorderBudgets(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req models.ReorderBudgetsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	if err := h.financeService.ReorderBudgets(userID, &req); err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("reorder budgets: %v", err)
+		utils.WriteErrorResponse(w, "Failed to reorder budgets", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{"status": "success"}, http.StatusOK)
+}
@@ -0,0 +1,190 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"monman-backend/internal/middleware"
+	"monman-backend/internal/models"
+	"monman-backend/internal/service"
+	"monman-backend/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) handleCreateCategory(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req models.CreateCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	c, err := h.financeService.CreateCategory(userID, &req)
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("create category: %v", err)
+		utils.WriteErrorResponse(w, "Failed to create category", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   c,
+	}, http.StatusCreated)
+}
+
+func (h *Handler) handleUpdateCategory(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "categoryID"))
+	if err != nil {
+		utils.WriteErrorResponse(w, "Invalid category id", http.StatusBadRequest)
+		return
+	}
+	var req models.UpdateCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	c, err := h.financeService.UpdateCategory(userID, id, &req)
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("update category: %v", err)
+		utils.WriteErrorResponse(w, "Failed to update category", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   c,
+	}, http.StatusOK)
+}
+
+func (h *Handler) handleDeleteCategory(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "categoryID"))
+	if err != nil {
+		utils.WriteErrorResponse(w, "Invalid category id", http.StatusBadRequest)
+		return
+	}
+	if err := h.financeService.DeleteCategory(userID, id); err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("delete category: %v", err)
+		utils.WriteErrorResponse(w, "Failed to delete category", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{"status": "success"}, http.StatusOK)
+}
+
+// handleArchiveCategory archives a user category or hides a system one for this user.
+func (h *Handler) handleArchiveCategory(w http.ResponseWriter, r *http.Request) {
+	h.setCategoryActive(w, r, false)
+}
+
+func (h *Handler) handleRestoreCategory(w http.ResponseWriter, r *http.Request) {
+	h.setCategoryActive(w, r, true)
+}
+
+func (h *Handler) setCategoryActive(w http.ResponseWriter, r *http.Request, active bool) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "categoryID"))
+	if err != nil {
+		utils.WriteErrorResponse(w, "Invalid category id", http.StatusBadRequest)
+		return
+	}
+	c, err := h.financeService.SetCategoryActive(userID, id, active)
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("set category active=%v: %v", active, err)
+		utils.WriteErrorResponse(w, "Failed to update category", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   c,
+	}, http.StatusOK)
+}
+
+// handleMergeCategory moves everything from the category in the path into target_category_id.
+func (h *Handler) handleMergeCategory(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "categoryID"))
+	if err != nil {
+		utils.WriteErrorResponse(w, "Invalid category id", http.StatusBadRequest)
+		return
+	}
+	var req models.MergeCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	payload, err := h.financeService.MergeCategory(userID, id, &req)
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("merge category: %v", err)
+		utils.WriteErrorResponse(w, "Failed to merge category", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   payload,
+	}, http.StatusOK)
+}
+
+func (h *Handler) handleCategoryReport(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	q := r.URL.Query()
+	payload, err := h.financeService.CategoryReport(userID, q.Get("type"), q.Get("from"), q.Get("to"), time.Now())
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("category report: %v", err)
+		utils.WriteErrorResponse(w, "Failed to build category report", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   payload,
+	}, http.StatusOK)
+}
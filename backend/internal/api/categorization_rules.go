@@ -0,0 +1,171 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"monman-backend/internal/middleware"
+	"monman-backend/internal/models"
+	"monman-backend/internal/service"
+	"monman-backend/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) handleCategorizationRules(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	payload, err := h.categorizationService.List(userID)
+	if err != nil {
+		log.Printf("list categorization rules: %v", err)
+		utils.WriteErrorResponse(w, "Failed to load categorization rules", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   payload,
+	}, http.StatusOK)
+}
+
+func (h *Handler) handleCreateCategorizationRule(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req models.CategorizationRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	rule, err := h.categorizationService.Create(userID, &req)
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("create categorization rule: %v", err)
+		utils.WriteErrorResponse(w, "Failed to create categorization rule", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   rule,
+	}, http.StatusCreated)
+}
+
+func (h *Handler) handleUpdateCategorizationRule(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "ruleID"))
+	if err != nil {
+		utils.WriteErrorResponse(w, "Invalid rule id", http.StatusBadRequest)
+		return
+	}
+	var req models.CategorizationRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	rule, err := h.categorizationService.Update(userID, id, &req)
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("update categorization rule: %v", err)
+		utils.WriteErrorResponse(w, "Failed to update categorization rule", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   rule,
+	}, http.StatusOK)
+}
+
+func (h *Handler) handleDeleteCategorizationRule(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "ruleID"))
+	if err != nil {
+		utils.WriteErrorResponse(w, "Invalid rule id", http.StatusBadRequest)
+		return
+	}
+	if err := h.categorizationService.Delete(userID, id); err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("delete categorization rule: %v", err)
+		utils.WriteErrorResponse(w, "Failed to delete categorization rule", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{"status": "success"}, http.StatusOK)
+}
+
+// handleTestCategorizationRules shows which rule would categorize a sample transaction.
+func (h *Handler) handleTestCategorizationRules(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req models.CategorizationRuleTestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	payload, err := h.categorizationService.Test(userID, &req)
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("test categorization rules: %v", err)
+		utils.WriteErrorResponse(w, "Failed to test categorization rules", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   payload,
+	}, http.StatusOK)
+}
+
+// handleApplyCategorizationRules re-applies the rules to past transactions (dry_run reports only).
+func (h *Handler) handleApplyCategorizationRules(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req models.ApplyCategorizationRulesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	payload, err := h.categorizationService.Apply(userID, &req)
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("apply categorization rules: %v", err)
+		utils.WriteErrorResponse(w, "Failed to apply categorization rules", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   payload,
+	}, http.StatusOK)
+}
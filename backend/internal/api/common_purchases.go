@@ -0,0 +1,210 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"monman-backend/internal/middleware"
+	"monman-backend/internal/models"
+	"monman-backend/internal/service"
+	"monman-backend/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// commonPurchaseIDs reads the budget and (optionally) preset ids from the path, writing a 400 on failure.
+func commonPurchaseIDs(w http.ResponseWriter, r *http.Request, withPurchase bool) (budgetID, purchaseID uuid.UUID, ok bool) {
+	budgetID, err := uuid.Parse(chi.URLParam(r, "budgetID"))
+	if err != nil {
+		utils.WriteErrorResponse(w, "Invalid budget id", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	if withPurchase {
+		purchaseID, err = uuid.Parse(chi.URLParam(r, "purchaseID"))
+		if err != nil {
+			utils.WriteErrorResponse(w, "Invalid common purchase id", http.StatusBadRequest)
+			return uuid.Nil, uuid.Nil, false
+		}
+	}
+	return budgetID, purchaseID, true
+}
+
+// handleBudgetCommonPurchases lists a budget's presets; ?sort=usage orders them most-logged first.
+func (h *Handler) handleBudgetCommonPurchases(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	budgetID, _, ok := commonPurchaseIDs(w, r, false)
+	if !ok {
+		return
+	}
+	byUsage := r.URL.Query().Get("sort") == "usage"
+	payload, err := h.financeService.ListCommonPurchases(userID, budgetID, byUsage)
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("common purchases: %v", err)
+		utils.WriteErrorResponse(w, "Failed to load common purchases", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   payload,
+	}, http.StatusOK)
+}
+
+// handleReplaceBudgetCommonPurchases replaces the budget's whole preset list.
+func (h *Handler) handleReplaceBudgetCommonPurchases(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	budgetID, _, ok := commonPurchaseIDs(w, r, false)
+	if !ok {
+		return
+	}
+	var req models.AppendCommonPurchasesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	payload, err := h.financeService.ReplaceCommonPurchases(userID, budgetID, &req)
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("replace common purchases: %v", err)
+		utils.WriteErrorResponse(w, "Failed to replace common purchases", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   payload,
+	}, http.StatusOK)
+}
+
+func (h *Handler) handleUpdateBudgetCommonPurchase(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	budgetID, purchaseID, ok := commonPurchaseIDs(w, r, true)
+	if !ok {
+		return
+	}
+	var req models.UpdateCommonPurchaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	cp, err := h.financeService.UpdateCommonPurchase(userID, budgetID, purchaseID, &req)
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("update common purchase: %v", err)
+		utils.WriteErrorResponse(w, "Failed to update common purchase", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   cp,
+	}, http.StatusOK)
+}
+
+func (h *Handler) handleDeleteBudgetCommonPurchase(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	budgetID, purchaseID, ok := commonPurchaseIDs(w, r, true)
+	if !ok {
+		return
+	}
+	if err := h.financeService.DeleteCommonPurchase(userID, budgetID, purchaseID); err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("delete common purchase: %v", err)
+		utils.WriteErrorResponse(w, "Failed to delete common purchase", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{"status": "success"}, http.StatusOK)
+}
+
+func (h *Handler) handleReorderBudgetCommonPurchases(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	budgetID, _, ok := commonPurchaseIDs(w, r, false)
+	if !ok {
+		return
+	}
+	var req models.ReorderCommonPurchasesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	if err := h.financeService.ReorderCommonPurchases(userID, budgetID, &req); err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("reorder common purchases: %v", err)
+		utils.WriteErrorResponse(w, "Failed to reorder common purchases", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{"status": "success"}, http.StatusOK)
+}
+
+// handleLogBudgetCommonPurchase records one purchase of a preset as a budget expense.
+func (h *Handler) handleLogBudgetCommonPurchase(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	budgetID, purchaseID, ok := commonPurchaseIDs(w, r, true)
+	if !ok {
+		return
+	}
+	var req models.LogCommonPurchaseRequest
+	// An empty body logs the preset as-is.
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		utils.WriteErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	payload, err := h.financeService.LogCommonPurchase(userID, budgetID, purchaseID, &req, time.Now())
+	if err != nil {
+		if writeDuplicateConflict(w, err) {
+			return
+		}
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("log common purchase: %v", err)
+		utils.WriteErrorResponse(w, "Failed to log common purchase", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   payload,
+	}, http.StatusCreated)
+}
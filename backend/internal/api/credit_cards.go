@@ -0,0 +1,94 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"monman-backend/internal/middleware"
+	"monman-backend/internal/models"
+	"monman-backend/internal/service"
+	"monman-backend/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) handleCreditCards(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	payload, err := h.financeService.ListCreditCards(userID, time.Now())
+	if err != nil {
+		log.Printf("credit cards: %v", err)
+		utils.WriteErrorResponse(w, "Failed to load credit cards", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   payload,
+	}, http.StatusOK)
+}
+
+// handleCreditCardStatement returns the statement closing on or before ?on=YYYY-MM-DD (default today).
+func (h *Handler) handleCreditCardStatement(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "accountID"))
+	if err != nil {
+		utils.WriteErrorResponse(w, "Invalid account id", http.StatusBadRequest)
+		return
+	}
+	payload, err := h.financeService.CreditCardStatement(userID, id, r.URL.Query().Get("on"), time.Now())
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("credit card statement: %v", err)
+		utils.WriteErrorResponse(w, "Failed to load statement", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   payload,
+	}, http.StatusOK)
+}
+
+func (h *Handler) handlePayCreditCard(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "accountID"))
+	if err != nil {
+		utils.WriteErrorResponse(w, "Invalid account id", http.StatusBadRequest)
+		return
+	}
+	var req models.PayCreditCardRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	payload, err := h.financeService.PayCreditCard(userID, id, &req, time.Now())
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("pay credit card: %v", err)
+		utils.WriteErrorResponse(w, "Failed to pay credit card", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   payload,
+	}, http.StatusCreated)
+}
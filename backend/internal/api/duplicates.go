@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"monman-backend/internal/middleware"
+	"monman-backend/internal/models"
+	"monman-backend/internal/service"
+	"monman-backend/internal/utils"
+)
+
+// writeDuplicateConflict answers 409 with the rows a new transaction appears to duplicate; ok is false
+// when err is not a duplicate warning.
+func writeDuplicateConflict(w http.ResponseWriter, err error) bool {
+	var dup *service.DuplicateTransactionError
+	if !errors.As(err, &dup) {
+		return false
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"success": false,
+		"error":   dup.Error(),
+		"data":    map[string]interface{}{"duplicates": dup.Matches},
+	}, http.StatusConflict)
+	return true
+}
+
+// handleDuplicateTransactions lists suspected duplicate pairs for review; from/to (YYYY-MM-DD) are optional.
+func (h *Handler) handleDuplicateTransactions(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	q := r.URL.Query()
+	payload, err := h.financeService.DuplicatePairs(userID, strings.TrimSpace(q.Get("from")), strings.TrimSpace(q.Get("to")))
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("duplicate transactions: %v", err)
+		utils.WriteErrorResponse(w, "Failed to load duplicate transactions", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   payload,
+	}, http.StatusOK)
+}
+
+// handleMergeDuplicateTransactions keeps keep_id and deletes remove_id.
+func (h *Handler) handleMergeDuplicateTransactions(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req models.MergeDuplicateTransactionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	if err := h.financeService.MergeDuplicates(userID, &req); err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("merge duplicate transactions: %v", err)
+		utils.WriteErrorResponse(w, "Failed to merge transactions", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   map[string]string{"id": req.KeepID.String()},
+	}, http.StatusOK)
+}
+
+// handleDismissDuplicateTransactions marks a suggested pair as two separate transactions.
+func (h *Handler) handleDismissDuplicateTransactions(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req models.DismissDuplicateTransactionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	if err := h.financeService.DismissDuplicates(userID, &req); err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("dismiss duplicate transactions: %v", err)
+		utils.WriteErrorResponse(w, "Failed to dismiss duplicate", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{"status": "success"}, http.StatusOK)
+}
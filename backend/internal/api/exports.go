@@ -0,0 +1,169 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"monman-backend/internal/exporter"
+	"monman-backend/internal/middleware"
+	"monman-backend/internal/service"
+	"monman-backend/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// handleExportStatement downloads one account's transactions as OFX or QIF:
+// ?account_id=...&from=YYYY-MM-DD&to=YYYY-MM-DD, plus date_format for QIF (default MM/DD/YYYY).
+func (h *Handler) handleExportStatement(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	q := r.URL.Query()
+	accountID, err := uuid.Parse(q.Get("account_id"))
+	if err != nil {
+		utils.WriteErrorResponse(w, "Invalid account id", http.StatusBadRequest)
+		return
+	}
+	format := chi.URLParam(r, "format")
+	dateFormat := strings.TrimSpace(q.Get("date_format"))
+	if format == "qif" {
+		if _, err := exporter.QIFDateLayout(dateFormat); err != nil {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	st, err := h.financeService.AccountStatement(userID, accountID, q.Get("from"), q.Get("to"), time.Now())
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("export %s: %v", format, err)
+		utils.WriteErrorResponse(w, "Failed to export transactions", http.StatusInternalServerError)
+		return
+	}
+
+	var buf bytes.Buffer
+	contentType := "application/x-ofx"
+	if format == "qif" {
+		contentType = "application/qif"
+		err = exporter.WriteQIF(&buf, *st, dateFormat)
+	} else {
+		err = exporter.WriteOFX(&buf, *st)
+	}
+	if err != nil {
+		log.Printf("export %s: %v", format, err)
+		utils.WriteErrorResponse(w, "Failed to export transactions", http.StatusInternalServerError)
+		return
+	}
+
+	name := strings.Trim(unsafeFilenameChars.ReplaceAllString(st.AccountName, "-"), "-")
+	if name == "" {
+		name = "account"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.%s"`, name, st.GeneratedAt.Format("20060102"), format))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// handleExportData downloads all of the user's data: ?format=zip (default; CSV and JSON per entity) or
+// xlsx (one sheet per entity), with optional from/to (YYYY-MM-DD) for the dated entities.
+func (h *Handler) handleExportData(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	q := r.URL.Query()
+	format := strings.ToLower(strings.TrimSpace(q.Get("format")))
+	if format == "" {
+		format = "zip"
+	}
+	if format != "zip" && format != "xlsx" {
+		utils.WriteErrorResponse(w, "format must be zip or xlsx", http.StatusBadRequest)
+		return
+	}
+
+	d, err := h.exportService.Export(userID, q.Get("from"), q.Get("to"), time.Now())
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("export data: %v", err)
+		utils.WriteErrorResponse(w, "Failed to export data", http.StatusInternalServerError)
+		return
+	}
+
+	var buf bytes.Buffer
+	contentType := "application/zip"
+	if format == "xlsx" {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+		err = exporter.WriteXLSX(&buf, *d)
+	} else {
+		err = exporter.WriteArchive(&buf, *d)
+	}
+	if err != nil {
+		log.Printf("export data %s: %v", format, err)
+		utils.WriteErrorResponse(w, "Failed to export data", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="monman-export-%s.%s"`, d.GeneratedAt.Format("20060102"), format))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// handleExportJournal downloads all accounts as a plain-text accounting journal: {format} is ledger
+// (also read by hledger) or beancount, with optional from/to (YYYY-MM-DD).
+func (h *Handler) handleExportJournal(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	format := chi.URLParam(r, "format")
+	q := r.URL.Query()
+	j, err := h.exportService.Journal(userID, q.Get("from"), q.Get("to"))
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("export %s: %v", format, err)
+		utils.WriteErrorResponse(w, "Failed to export journal", http.StatusInternalServerError)
+		return
+	}
+
+	var buf bytes.Buffer
+	ext := "journal"
+	if format == "beancount" {
+		ext = "beancount"
+		err = exporter.WriteBeancount(&buf, *j)
+	} else {
+		err = exporter.WriteLedger(&buf, *j)
+	}
+	if err != nil {
+		log.Printf("export %s: %v", format, err)
+		utils.WriteErrorResponse(w, "Failed to export journal", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="monman-%s.%s"`, time.Now().Format("20060102"), ext))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
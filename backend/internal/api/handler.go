@@ -7,11 +7,13 @@ import (
 	"monman-backend/internal/db"
 	"monman-backend/internal/middleware"
 	"monman-backend/internal/models"
+	"monman-backend/internal/notify"
 	"monman-backend/internal/repository"
 	"monman-backend/internal/service"
 	"monman-backend/internal/utils"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -19,39 +21,70 @@ import (
 
 // Handler holds dependencies for API handlers
 type Handler struct {
-	userService    *service.UserService
-	financeService *service.FinanceService
-	jwtUtil        *utils.JWTUtil
+	userService           *service.UserService
+	financeService        *service.FinanceService
+	recurringService      *service.RecurringService
+	incomeService         *service.IncomeService
+	alertService          *service.AlertService
+	importService         *service.ImportService
+	exportService         *service.DataExportService
+	reconciliationService *service.ReconciliationService
+	netWorthService       *service.NetWorthService
+	exchangeRateService   *service.ExchangeRateService
+	investmentService     *service.InvestmentService
+	categorizationService *service.CategorizationService
+	jwtUtil               *utils.JWTUtil
 }
 
 // NewHandler creates a new API handler with dependencies
-func NewHandler() http.Handler {
-	// Load configuration
-	cfg := config.Load()
-
-	// Initialize database connection
-	database, err := db.Connect(cfg)
-	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
-	}
-
+func NewHandler(cfg *config.Config, database *db.DB) http.Handler {
 	// Initialize repositories and services
 	userRepo := repository.NewUserRepository(database.DB)
 	txRepo := repository.NewTransactionRepository(database.DB)
 	accRepo := repository.NewAccountRepository(database.DB)
 	catRepo := repository.NewCategoryRepository(database.DB)
 	budRepo := repository.NewBudgetRepository(database.DB)
+	recRepo := repository.NewRecurringRepository(database.DB)
+	incRepo := repository.NewIncomeSourceRepository(database.DB)
+	alertRepo := repository.NewAlertRepository(database.DB)
+	impRepo := repository.NewImportRepository(database.DB)
+	expRepo := repository.NewExportRepository(database.DB)
+	reconRepo := repository.NewReconciliationRepository(database.DB)
+	histRepo := repository.NewBalanceHistoryRepository(database.DB)
+	rateRepo := repository.NewExchangeRateRepository(database.DB)
+	invRepo := repository.NewInvestmentRepository(database.DB)
+	ruleRepo := repository.NewCategorizationRuleRepository(database.DB)
 	userService := service.NewUserService(userRepo)
-	financeService := service.NewFinanceService(txRepo, accRepo, catRepo, budRepo)
+	alertService := service.NewAlertService(alertRepo, budRepo, rateRepo, notify.FromConfig(cfg.Alerts))
+	financeService := service.NewFinanceService(txRepo, accRepo, catRepo, budRepo, rateRepo, ruleRepo, alertService)
+	recurringService := service.NewRecurringService(recRepo, txRepo, accRepo, catRepo)
+	incomeService := service.NewIncomeService(incRepo, accRepo, catRepo, rateRepo)
+	importService := service.NewImportService(impRepo, accRepo, catRepo, ruleRepo, alertService)
+	exportService := service.NewDataExportService(expRepo)
+	reconciliationService := service.NewReconciliationService(reconRepo, accRepo)
+	netWorthService := service.NewNetWorthService(histRepo, invRepo, rateRepo)
+	exchangeRateService := service.NewExchangeRateService(rateRepo)
+	investmentService := service.NewInvestmentService(invRepo, accRepo, catRepo, rateRepo)
+	categorizationService := service.NewCategorizationService(ruleRepo, accRepo, catRepo, budRepo, alertService)
 
 	// Initialize JWT utility
 	jwtUtil := utils.NewJWTUtil(cfg.JWT.Secret, cfg.JWT.TTL)
 
 	// Create handler instance
 	h := &Handler{
-		userService:    userService,
-		financeService: financeService,
-		jwtUtil:        jwtUtil,
+		userService:           userService,
+		financeService:        financeService,
+		recurringService:      recurringService,
+		incomeService:         incomeService,
+		alertService:          alertService,
+		importService:         importService,
+		exportService:         exportService,
+		reconciliationService: reconciliationService,
+		netWorthService:       netWorthService,
+		exchangeRateService:   exchangeRateService,
+		investmentService:     investmentService,
+		categorizationService: categorizationService,
+		jwtUtil:               jwtUtil,
 	}
 
 	// Setup router
@@ -84,12 +117,95 @@ func NewHandler() http.Handler {
 		r.Get("/dashboard", h.handleDashboard)
 		r.Get("/transactions", h.handleTransactions)
 		r.Post("/transactions", h.handleCreateTransaction)
+		r.Get("/transactions/duplicates", h.handleDuplicateTransactions)
+		r.Post("/transactions/duplicates/merge", h.handleMergeDuplicateTransactions)
+		r.Post("/transactions/duplicates/dismiss", h.handleDismissDuplicateTransactions)
+		r.Patch("/transactions/{transactionID}", h.handleUpdateTransaction)
+		r.Delete("/transactions/{transactionID}", h.handleDeleteTransaction)
+		r.Get("/transfers", h.handleTransfers)
+		r.Post("/transfers", h.handleCreateTransfer)
+		r.Delete("/transfers/{transferID}", h.handleDeleteTransfer)
+		r.Get("/recurring-transactions", h.handleRecurringTransactions)
+		r.Post("/recurring-transactions", h.handleCreateRecurringTransaction)
+		r.Patch("/recurring-transactions/{recurringID}", h.handleUpdateRecurringTransaction)
+		r.Delete("/recurring-transactions/{recurringID}", h.handleDeleteRecurringTransaction)
+		r.Get("/income-sources", h.handleIncomeSources)
+		r.Post("/income-sources", h.handleCreateIncomeSource)
+		r.Get("/income-sources/report", h.handleIncomeReport)
+		r.Get("/reports/net-worth", h.handleNetWorthReport)
+		r.Patch("/income-sources/{incomeSourceID}", h.handleUpdateIncomeSource)
+		r.Delete("/income-sources/{incomeSourceID}", h.handleDeleteIncomeSource)
 		r.Post("/accounts", h.handleCreateAccount)
 		r.Get("/accounts", h.handleAccounts)
+		r.Patch("/accounts/{accountID}", h.handleUpdateAccount)
+		r.Post("/accounts/{accountID}/archive", h.handleArchiveAccount)
+		r.Post("/accounts/{accountID}/restore", h.handleRestoreAccount)
+		r.Get("/accounts/{accountID}/reconciliations", h.handleAccountReconciliations)
+		r.Post("/accounts/{accountID}/reconciliations", h.handleStartReconciliation)
+		r.Get("/reconciliations/{reconciliationID}", h.handleReconciliation)
+		r.Patch("/reconciliations/{reconciliationID}", h.handleUpdateReconciliation)
+		r.Post("/reconciliations/{reconciliationID}/cleared", h.handleClearReconciliationTransactions)
+		r.Post("/reconciliations/{reconciliationID}/complete", h.handleCompleteReconciliation)
+		r.Delete("/reconciliations/{reconciliationID}", h.handleDeleteReconciliation)
+		r.Get("/credit-cards", h.handleCreditCards)
+		r.Get("/credit-cards/{accountID}/statement", h.handleCreditCardStatement)
+		r.Post("/credit-cards/{accountID}/pay", h.handlePayCreditCard)
+		r.Get("/exchange-rates", h.handleExchangeRates)
+		r.Post("/exchange-rates", h.handleCreateExchangeRate)
+		r.Post("/exchange-rates/import", h.handleImportExchangeRates)
+		r.Put("/exchange-rates/base-currency", h.handleSetBaseCurrency)
+		r.Delete("/exchange-rates/{rateID}", h.handleDeleteExchangeRate)
+		r.Get("/instruments", h.handleInstruments)
+		r.Post("/instruments", h.handleCreateInstrument)
+		r.Delete("/instruments/{instrumentID}", h.handleDeleteInstrument)
+		r.Get("/instruments/{instrumentID}/prices", h.handleInstrumentPrices)
+		r.Post("/instruments/{instrumentID}/prices", h.handleSaveInstrumentPrice)
+		r.Delete("/instruments/{instrumentID}/prices/{priceID}", h.handleDeleteInstrumentPrice)
+		r.Get("/accounts/{accountID}/trades", h.handleInvestmentTrades)
+		r.Post("/accounts/{accountID}/trades", h.handleCreateInvestmentTrade)
+		r.Delete("/trades/{tradeID}", h.handleDeleteInvestmentTrade)
+		r.Get("/investments", h.handleInvestments)
+		r.Get("/reports/investment-gains", h.handleInvestmentGains)
 		r.Get("/categories", h.handleCategories)
+		r.Post("/categories", h.handleCreateCategory)
+		r.Patch("/categories/{categoryID}", h.handleUpdateCategory)
+		r.Delete("/categories/{categoryID}", h.handleDeleteCategory)
+		r.Post("/categories/{categoryID}/archive", h.handleArchiveCategory)
+		r.Post("/categories/{categoryID}/restore", h.handleRestoreCategory)
+		r.Post("/categories/{categoryID}/merge", h.handleMergeCategory)
+		r.Get("/reports/categories", h.handleCategoryReport)
+		r.Get("/categorization-rules", h.handleCategorizationRules)
+		r.Post("/categorization-rules", h.handleCreateCategorizationRule)
+		r.Post("/categorization-rules/test", h.handleTestCategorizationRules)
+		r.Post("/categorization-rules/apply", h.handleApplyCategorizationRules)
+		r.Patch("/categorization-rules/{ruleID}", h.handleUpdateCategorizationRule)
+		r.Delete("/categorization-rules/{ruleID}", h.handleDeleteCategorizationRule)
 		r.Get("/budgets", h.handleBudgets)
 		r.Post("/budgets", h.handleCreateBudget)
 		r.Post("/budgets/{budgetID}/common-purchases", h.handleAppendBudgetCommonPurchases)
+		r.Get("/budgets/{budgetID}/common-purchases", h.handleBudgetCommonPurchases)
+		r.Put("/budgets/{budgetID}/common-purchases", h.handleReplaceBudgetCommonPurchases)
+		r.Put("/budgets/{budgetID}/common-purchases/order", h.handleReorderBudgetCommonPurchases)
+		r.Patch("/budgets/{budgetID}/common-purchases/{purchaseID}", h.handleUpdateBudgetCommonPurchase)
+		r.Delete("/budgets/{budgetID}/common-purchases/{purchaseID}", h.handleDeleteBudgetCommonPurchase)
+		r.Post("/budgets/{budgetID}/common-purchases/{purchaseID}/log", h.handleLogBudgetCommonPurchase)
+		r.Put("/budgets/order", h.handleReorderBudgets)
+		r.Patch("/budgets/{budgetID}", h.handleUpdateBudget)
+		r.Post("/budgets/{budgetID}/archive", h.handleArchiveBudget)
+		r.Post("/budgets/{budgetID}/restore", h.handleRestoreBudget)
+		r.Post("/budgets/{budgetID}/rollover", h.handleRolloverBudget)
+		r.Get("/alerts", h.handleAlerts)
+		r.Post("/alerts/read-all", h.handleMarkAllAlertsRead)
+		r.Patch("/alerts/{alertID}", h.handleUpdateAlert)
+		r.Post("/import/{format:csv|ofx|qif}", h.handleImport)
+		r.Get("/import/profiles", h.handleImportProfiles)
+		r.Post("/import/profiles", h.handleCreateImportProfile)
+		r.Delete("/import/profiles/{profileID}", h.handleDeleteImportProfile)
+		r.Get("/import/batches", h.handleImportBatches)
+		r.Post("/import/batches/{batchID}/revert", h.handleRevertImportBatch)
+		r.Get("/export", h.handleExportData)
+		r.Get("/export/{format:ofx|qif}", h.handleExportStatement)
+		r.Get("/export/{format:ledger|beancount}", h.handleExportJournal)
 	})
 
 	return r
@@ -284,7 +400,7 @@ func (h *Handler) handleDashboard(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	payload, err := h.financeService.Dashboard(userID, recent)
+	payload, err := h.financeService.Dashboard(userID, recent, time.Now())
 	if err != nil {
 		log.Printf("dashboard: %v", err)
 		utils.WriteErrorResponse(w, "Failed to load dashboard", http.StatusInternalServerError)
@@ -297,6 +413,8 @@ func (h *Handler) handleDashboard(w http.ResponseWriter, r *http.Request) {
 	}, http.StatusOK)
 }
 
+// handleTransactions lists transactions, filtered and sorted by the query parameters described in
+// transactionFilterFromQuery.
 func (h *Handler) handleTransactions(w http.ResponseWriter, r *http.Request) {
 	userID, _, ok := middleware.GetUserFromContext(r)
 	if !ok {
@@ -316,9 +434,19 @@ func (h *Handler) handleTransactions(w http.ResponseWriter, r *http.Request) {
 			offset = n
 		}
 	}
+	filter, err := transactionFilterFromQuery(r.URL.Query())
+	if err != nil {
+		utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.Limit, filter.Offset = limit, offset
 
-	payload, err := h.financeService.ListTransactions(userID, limit, offset)
+	payload, err := h.financeService.ListTransactions(userID, filter)
 	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		log.Printf("transactions: %v", err)
 		utils.WriteErrorResponse(w, "Failed to load transactions", http.StatusInternalServerError)
 		return
@@ -341,7 +469,7 @@ func (h *Handler) handleCreateAccount(w http.ResponseWriter, r *http.Request) {
 		utils.WriteErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
 		return
 	}
-	id, err := h.financeService.CreateAccount(userID, &req)
+	id, err := h.financeService.CreateAccount(userID, &req, time.Now())
 	if err != nil {
 		if service.IsValidation(err) {
 			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
@@ -363,7 +491,15 @@ func (h *Handler) handleAccounts(w http.ResponseWriter, r *http.Request) {
 		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
-	list, err := h.financeService.ListAccounts(userID)
+	var (
+		list []models.AccountSummary
+		err  error
+	)
+	if archived := r.URL.Query().Get("archived"); archived == "1" || archived == "true" {
+		list, err = h.financeService.ListArchivedAccounts(userID)
+	} else {
+		list, err = h.financeService.ListAccounts(userID)
+	}
 	if err != nil {
 		log.Printf("accounts: %v", err)
 		utils.WriteErrorResponse(w, "Failed to load accounts", http.StatusInternalServerError)
@@ -381,8 +517,20 @@ func (h *Handler) handleCategories(w http.ResponseWriter, r *http.Request) {
 		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
-	tfilter := r.URL.Query().Get("type")
-	list, err := h.financeService.ListCategories(userID, tfilter)
+	q := r.URL.Query()
+	tfilter := q.Get("type")
+	var (
+		list interface{}
+		err  error
+	)
+	switch {
+	case q.Get("archived") == "1" || q.Get("archived") == "true":
+		list, err = h.financeService.ListArchivedCategories(userID)
+	case q.Get("tree") == "1" || q.Get("tree") == "true":
+		list, err = h.financeService.CategoryTree(userID, tfilter)
+	default:
+		list, err = h.financeService.ListCategories(userID, tfilter)
+	}
 	if err != nil {
 		log.Printf("categories: %v", err)
 		utils.WriteErrorResponse(w, "Failed to load categories", http.StatusInternalServerError)
@@ -400,7 +548,8 @@ func (h *Handler) handleBudgets(w http.ResponseWriter, r *http.Request) {
 		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
-	payload, err := h.financeService.ListBudgets(userID)
+	archived := r.URL.Query().Get("archived") == "1" || r.URL.Query().Get("archived") == "true"
+	payload, err := h.financeService.ListBudgets(userID, archived)
 	if err != nil {
 		log.Printf("budgets: %v", err)
 		utils.WriteErrorResponse(w, "Failed to load budgets", http.StatusInternalServerError)
@@ -425,6 +574,9 @@ func (h *Handler) handleCreateTransaction(w http.ResponseWriter, r *http.Request
 	}
 	id, err := h.financeService.CreateTransaction(userID, &req)
 	if err != nil {
+		if writeDuplicateConflict(w, err) {
+			return
+		}
 		if service.IsValidation(err) {
 			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
 			return
@@ -439,6 +591,60 @@ func (h *Handler) handleCreateTransaction(w http.ResponseWriter, r *http.Request
 	}, http.StatusCreated)
 }
 
+func (h *Handler) handleUpdateTransaction(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "transactionID"))
+	if err != nil {
+		utils.WriteErrorResponse(w, "Invalid transaction id", http.StatusBadRequest)
+		return
+	}
+	var req models.UpdateTransactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	if err := h.financeService.UpdateTransaction(userID, id, &req); err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("update transaction: %v", err)
+		utils.WriteErrorResponse(w, "Failed to update transaction", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   map[string]string{"id": id.String()},
+	}, http.StatusOK)
+}
+
+func (h *Handler) handleDeleteTransaction(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "transactionID"))
+	if err != nil {
+		utils.WriteErrorResponse(w, "Invalid transaction id", http.StatusBadRequest)
+		return
+	}
+	if err := h.financeService.DeleteTransaction(userID, id); err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("delete transaction: %v", err)
+		utils.WriteErrorResponse(w, "Failed to delete transaction", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{"status": "success"}, http.StatusOK)
+}
+
 func (h *Handler) handleCreateBudget(w http.ResponseWriter, r *http.Request) {
 	userID, _, ok := middleware.GetUserFromContext(r)
 	if !ok {
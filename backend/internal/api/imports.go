@@ -0,0 +1,243 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"monman-backend/internal/middleware"
+	"monman-backend/internal/models"
+	"monman-backend/internal/service"
+	"monman-backend/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// maxImportUploadBytes bounds the multipart body of POST /api/import/{format}.
+const maxImportUploadBytes = 5 << 20
+
+// formBool reads a checkbox-style form field ("1", "true", "on").
+func formBool(r *http.Request, key string) bool {
+	v, _ := strconv.ParseBool(r.FormValue(key))
+	return v || r.FormValue(key) == "on"
+}
+
+// formUUID reads an optional uuid form field; ok is false when it is set but malformed.
+func formUUID(r *http.Request, key string) (id *uuid.UUID, ok bool) {
+	v := strings.TrimSpace(r.FormValue(key))
+	if v == "" {
+		return nil, true
+	}
+	parsed, err := uuid.Parse(v)
+	if err != nil {
+		return nil, false
+	}
+	return &parsed, true
+}
+
+// handleImport parses an uploaded statement (multipart field "file") in the {format} from the path
+// (csv, ofx or qif) and previews it, or with commit=true posts the rows to account_id as one import batch.
+func (h *Handler) handleImport(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportUploadBytes)
+	if err := r.ParseMultipartForm(maxImportUploadBytes); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			utils.WriteErrorResponse(w, "File too large (max 5 MB)", http.StatusRequestEntityTooLarge)
+			return
+		}
+		utils.WriteErrorResponse(w, "Invalid multipart form", http.StatusBadRequest)
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		utils.WriteErrorResponse(w, "file is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	in := models.ImportInput{
+		Format:      chi.URLParam(r, "format"),
+		ProfileID:   strings.TrimSpace(r.FormValue("profile_id")),
+		DateFormat:  strings.TrimSpace(r.FormValue("date_format")),
+		Filename:    header.Filename,
+		Commit:      formBool(r, "commit"),
+		SkipInvalid: formBool(r, "skip_invalid"),
+	}
+	if in.AccountID, err = uuid.Parse(r.FormValue("account_id")); err != nil {
+		utils.WriteErrorResponse(w, "Invalid account id", http.StatusBadRequest)
+		return
+	}
+	if in.ExpenseCategoryID, ok = formUUID(r, "expense_category_id"); !ok {
+		utils.WriteErrorResponse(w, "Invalid expense category id", http.StatusBadRequest)
+		return
+	}
+	if in.IncomeCategoryID, ok = formUUID(r, "income_category_id"); !ok {
+		utils.WriteErrorResponse(w, "Invalid income category id", http.StatusBadRequest)
+		return
+	}
+	if m := r.FormValue("mapping"); m != "" {
+		in.Mapping = &models.ImportProfile{}
+		if err := json.Unmarshal([]byte(m), in.Mapping); err != nil {
+			utils.WriteErrorResponse(w, "Invalid mapping JSON", http.StatusBadRequest)
+			return
+		}
+	}
+
+	payload, err := h.importService.Import(userID, &in, file)
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("import %s: %v", in.Format, err)
+		utils.WriteErrorResponse(w, "Failed to import statement", http.StatusInternalServerError)
+		return
+	}
+	code := http.StatusOK
+	if payload.Batch != nil {
+		code = http.StatusCreated
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   payload,
+	}, code)
+}
+
+// handleImportProfiles lists the built-in bank profiles and the user's saved mappings.
+func (h *Handler) handleImportProfiles(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	payload, err := h.importService.ListProfiles(userID)
+	if err != nil {
+		log.Printf("import profiles: %v", err)
+		utils.WriteErrorResponse(w, "Failed to load import profiles", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   payload,
+	}, http.StatusOK)
+}
+
+func (h *Handler) handleCreateImportProfile(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req models.ImportProfile
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	p, err := h.importService.SaveProfile(userID, &req)
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("create import profile: %v", err)
+		utils.WriteErrorResponse(w, "Failed to save import profile", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   p,
+	}, http.StatusCreated)
+}
+
+func (h *Handler) handleDeleteImportProfile(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "profileID"))
+	if err != nil {
+		utils.WriteErrorResponse(w, "Invalid import profile id", http.StatusBadRequest)
+		return
+	}
+	if err := h.importService.DeleteProfile(userID, id); err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("delete import profile: %v", err)
+		utils.WriteErrorResponse(w, "Failed to delete import profile", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{"status": "success"}, http.StatusOK)
+}
+
+// handleImportBatches lists committed and reverted imports, newest first.
+func (h *Handler) handleImportBatches(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	limit := 50
+	offset := 0
+	if q := r.URL.Query().Get("limit"); q != "" {
+		if n, err := strconv.Atoi(q); err == nil && n > 0 && n <= 200 {
+			limit = n
+		}
+	}
+	if q := r.URL.Query().Get("offset"); q != "" {
+		if n, err := strconv.Atoi(q); err == nil && n >= 0 {
+			offset = n
+		}
+	}
+
+	payload, err := h.importService.ListBatches(userID, limit, offset)
+	if err != nil {
+		log.Printf("import batches: %v", err)
+		utils.WriteErrorResponse(w, "Failed to load import batches", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   payload,
+	}, http.StatusOK)
+}
+
+// handleRevertImportBatch deletes every transaction an import created.
+func (h *Handler) handleRevertImportBatch(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "batchID"))
+	if err != nil {
+		utils.WriteErrorResponse(w, "Invalid import batch id", http.StatusBadRequest)
+		return
+	}
+	batch, err := h.importService.RevertBatch(userID, id)
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("revert import batch: %v", err)
+		utils.WriteErrorResponse(w, "Failed to revert import", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   batch,
+	}, http.StatusOK)
+}
@@ -0,0 +1,302 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"monman-backend/internal/middleware"
+	"monman-backend/internal/models"
+	"monman-backend/internal/service"
+	"monman-backend/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) handleInstruments(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	payload, err := h.investmentService.ListInstruments(userID)
+	if err != nil {
+		log.Printf("list instruments: %v", err)
+		utils.WriteErrorResponse(w, "Failed to load instruments", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   payload,
+	}, http.StatusOK)
+}
+
+func (h *Handler) handleCreateInstrument(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var req models.CreateInstrumentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	payload, err := h.investmentService.CreateInstrument(userID, &req)
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("create instrument: %v", err)
+		utils.WriteErrorResponse(w, "Failed to create instrument", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   payload,
+	}, http.StatusCreated)
+}
+
+func (h *Handler) handleDeleteInstrument(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "instrumentID"))
+	if err != nil {
+		utils.WriteErrorResponse(w, "Invalid instrument id", http.StatusBadRequest)
+		return
+	}
+	if err := h.investmentService.DeleteInstrument(userID, id); err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("delete instrument: %v", err)
+		utils.WriteErrorResponse(w, "Failed to delete instrument", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{"status": "success"}, http.StatusOK)
+}
+
+func (h *Handler) handleInstrumentPrices(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "instrumentID"))
+	if err != nil {
+		utils.WriteErrorResponse(w, "Invalid instrument id", http.StatusBadRequest)
+		return
+	}
+	payload, err := h.investmentService.Prices(userID, id)
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("instrument prices: %v", err)
+		utils.WriteErrorResponse(w, "Failed to load prices", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   payload,
+	}, http.StatusOK)
+}
+
+func (h *Handler) handleSaveInstrumentPrice(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "instrumentID"))
+	if err != nil {
+		utils.WriteErrorResponse(w, "Invalid instrument id", http.StatusBadRequest)
+		return
+	}
+	var req models.SaveInstrumentPriceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	payload, err := h.investmentService.SavePrice(userID, id, &req, time.Now())
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("save instrument price: %v", err)
+		utils.WriteErrorResponse(w, "Failed to save price", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   payload,
+	}, http.StatusCreated)
+}
+
+func (h *Handler) handleDeleteInstrumentPrice(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "instrumentID"))
+	if err != nil {
+		utils.WriteErrorResponse(w, "Invalid instrument id", http.StatusBadRequest)
+		return
+	}
+	priceID, err := uuid.Parse(chi.URLParam(r, "priceID"))
+	if err != nil {
+		utils.WriteErrorResponse(w, "Invalid price id", http.StatusBadRequest)
+		return
+	}
+	if err := h.investmentService.DeletePrice(userID, id, priceID); err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("delete instrument price: %v", err)
+		utils.WriteErrorResponse(w, "Failed to delete price", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{"status": "success"}, http.StatusOK)
+}
+
+func (h *Handler) handleInvestmentTrades(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	accountID, err := uuid.Parse(chi.URLParam(r, "accountID"))
+	if err != nil {
+		utils.WriteErrorResponse(w, "Invalid account id", http.StatusBadRequest)
+		return
+	}
+	payload, err := h.investmentService.ListTrades(userID, accountID)
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("list trades: %v", err)
+		utils.WriteErrorResponse(w, "Failed to load trades", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   payload,
+	}, http.StatusOK)
+}
+
+// handleCreateInvestmentTrade records a buy, sell or dividend and posts its cash movement on the
+// account.
+func (h *Handler) handleCreateInvestmentTrade(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	accountID, err := uuid.Parse(chi.URLParam(r, "accountID"))
+	if err != nil {
+		utils.WriteErrorResponse(w, "Invalid account id", http.StatusBadRequest)
+		return
+	}
+	var req models.CreateInvestmentTradeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	id, err := h.investmentService.CreateTrade(userID, accountID, &req, time.Now())
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("create trade: %v", err)
+		utils.WriteErrorResponse(w, "Failed to record trade", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   map[string]string{"id": id.String()},
+	}, http.StatusCreated)
+}
+
+func (h *Handler) handleDeleteInvestmentTrade(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "tradeID"))
+	if err != nil {
+		utils.WriteErrorResponse(w, "Invalid trade id", http.StatusBadRequest)
+		return
+	}
+	if err := h.investmentService.DeleteTrade(userID, id); err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("delete trade: %v", err)
+		utils.WriteErrorResponse(w, "Failed to delete trade", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{"status": "success"}, http.StatusOK)
+}
+
+func (h *Handler) handleInvestments(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	payload, err := h.investmentService.Holdings(userID, time.Now())
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("investments: %v", err)
+		utils.WriteErrorResponse(w, "Failed to load investments", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   payload,
+	}, http.StatusOK)
+}
+
+// handleInvestmentGains reports realized gains and dividends between from and to and unrealized gains
+// at to.
+func (h *Handler) handleInvestmentGains(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	q := r.URL.Query()
+	payload, err := h.investmentService.Gains(userID, q.Get("from"), q.Get("to"), time.Now())
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("investment gains: %v", err)
+		utils.WriteErrorResponse(w, "Failed to build investment gains report", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   payload,
+	}, http.StatusOK)
+}
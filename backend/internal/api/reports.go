@@ -0,0 +1,34 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"monman-backend/internal/middleware"
+	"monman-backend/internal/service"
+	"monman-backend/internal/utils"
+)
+
+func (h *Handler) handleNetWorthReport(w http.ResponseWriter, r *http.Request) {
+	userID, _, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		utils.WriteErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	q := r.URL.Query()
+	payload, err := h.netWorthService.Report(userID, q.Get("from"), q.Get("to"), q.Get("interval"), time.Now())
+	if err != nil {
+		if service.IsValidation(err) {
+			utils.WriteErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("net worth report: %v", err)
+		utils.WriteErrorResponse(w, "Failed to load net worth report", http.StatusInternalServerError)
+		return
+	}
+	utils.WriteJSONResponse(w, map[string]interface{}{
+		"status": "success",
+		"data":   payload,
+	}, http.StatusOK)
+}
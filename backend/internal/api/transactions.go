@@ -0,0 +1,99 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"monman-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// transactionFilterFromQuery reads the GET /api/transactions filters:
+//
+//	from, to              YYYY-MM-DD, inclusive
+//	account_id            repeatable or comma-separated
+//	category_id           repeatable or comma-separated
+//	type                  income | expense | transfer; repeatable or comma-separated
+//	min_amount, max_amount absolute amount in cents
+//	budget_id             expenses linked to this budget
+//	store                 substring of the budget line store or the location
+//	tag                   transactions with this tag
+//	q                     free text over description, notes, location, item and store
+//	sort                  date_desc (default) | date_asc | amount_desc | amount_asc
+//	cursor                next_cursor or prev_cursor from an earlier page with the same sort
+//
+// Values are checked further by FinanceService.ListTransactions.
+func transactionFilterFromQuery(q url.Values) (models.TransactionFilter, error) {
+	f := models.TransactionFilter{
+		From:   strings.TrimSpace(q.Get("from")),
+		To:     strings.TrimSpace(q.Get("to")),
+		Types:  queryList(q, "type"),
+		Store:  q.Get("store"),
+		Tag:    strings.ToLower(strings.TrimSpace(q.Get("tag"))),
+		Query:  q.Get("q"),
+		Sort:   strings.TrimSpace(q.Get("sort")),
+		Cursor: strings.TrimSpace(q.Get("cursor")),
+	}
+	var err error
+	if f.AccountIDs, err = queryUUIDs(q, "account_id"); err != nil {
+		return f, err
+	}
+	if f.CategoryIDs, err = queryUUIDs(q, "category_id"); err != nil {
+		return f, err
+	}
+	if v := strings.TrimSpace(q.Get("budget_id")); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return f, errors.New("Invalid budget_id")
+		}
+		f.BudgetID = &id
+	}
+	if f.MinAmount, err = queryCents(q, "min_amount"); err != nil {
+		return f, err
+	}
+	if f.MaxAmount, err = queryCents(q, "max_amount"); err != nil {
+		return f, err
+	}
+	return f, nil
+}
+
+// queryList collects a parameter given repeatedly and/or as a comma-separated list.
+func queryList(q url.Values, name string) []string {
+	var out []string
+	for _, v := range q[name] {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+	}
+	return out
+}
+
+func queryUUIDs(q url.Values, name string) ([]uuid.UUID, error) {
+	var out []uuid.UUID
+	for _, v := range queryList(q, name) {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid %s", name)
+		}
+		out = append(out, id)
+	}
+	return out, nil
+}
+
+func queryCents(q url.Values, name string) (*int64, error) {
+	v := strings.TrimSpace(q.Get(name))
+	if v == "" {
+		return nil, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid %s", name)
+	}
+	return &n, nil
+}
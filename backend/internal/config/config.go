@@ -3,13 +3,16 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 )
 
 // Config holds all configuration for our application
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	JWT       JWTConfig
+	Scheduler SchedulerConfig
+	Alerts    AlertsConfig
 }
 
 // ServerConfig holds server configuration
@@ -29,6 +32,27 @@ type JWTConfig struct {
 	TTL    int // in hours
 }
 
+// SchedulerConfig holds background job configuration
+type SchedulerConfig struct {
+	IntervalMinutes int // 0 disables background jobs
+}
+
+// AlertsConfig holds budget alert delivery configuration
+type AlertsConfig struct {
+	Notifiers  []string // any of: log, webhook, smtp
+	WebhookURL string
+	SMTP       SMTPConfig
+}
+
+// SMTPConfig holds outgoing mail configuration for the smtp notifier
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
 // Load loads configuration from environment variables
 func Load() *Config {
 	return &Config{
@@ -43,6 +67,20 @@ func Load() *Config {
 			Secret: getEnv("JWT_SECRET", "your-secret-key-change-this"),
 			TTL:    getEnvAsInt("JWT_TTL_HOURS", 24),
 		},
+		Scheduler: SchedulerConfig{
+			IntervalMinutes: getEnvAsInt("SCHEDULER_INTERVAL_MINUTES", 15),
+		},
+		Alerts: AlertsConfig{
+			Notifiers:  getEnvAsList("ALERT_NOTIFIERS", "log"),
+			WebhookURL: getEnv("ALERT_WEBHOOK_URL", ""),
+			SMTP: SMTPConfig{
+				Host:     getEnv("SMTP_HOST", ""),
+				Port:     getEnvAsInt("SMTP_PORT", 587),
+				Username: getEnv("SMTP_USERNAME", ""),
+				Password: getEnv("SMTP_PASSWORD", ""),
+				From:     getEnv("SMTP_FROM", ""),
+			},
+		},
 	}
 }
 
@@ -61,3 +99,13 @@ func getEnvAsInt(name string, fallback int) int {
 	}
 	return fallback
 }
+
+func getEnvAsList(name, fallback string) []string {
+	var out []string
+	for _, v := range strings.Split(getEnv(name, fallback), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, strings.ToLower(v))
+		}
+	}
+	return out
+}
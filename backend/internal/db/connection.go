@@ -79,7 +79,8 @@ func BackendRoot(cwd string) string {
 }
 
 // ApplySQLiteMigrations runs SQL files from migrations/sqlite in lexical order (001..., 004...).
-// Set includeSample to apply 004_dev_sample.sql (extra rows for developer test user).
+// Schema files are recorded in schema_migrations and run once, so later files may ALTER existing tables.
+// Set includeSample to apply 004_dev_sample.sql (extra rows for developer test user); it re-runs on every call.
 func ApplySQLiteMigrations(db *sql.DB, backendRoot string, includeSample bool) error {
 	dir := os.Getenv("SQLITE_MIGRATIONS_DIR")
 	if dir == "" {
@@ -100,16 +101,55 @@ func ApplySQLiteMigrations(db *sql.DB, backendRoot string, includeSample bool) e
 		names = append(names, e.Name())
 	}
 
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			name TEXT PRIMARY KEY NOT NULL,
+			applied_at TEXT NOT NULL DEFAULT (datetime('now'))
+		)`); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
 	sort.Strings(names)
 	for _, name := range names {
+		sample := strings.HasPrefix(name, "004_")
+		if !sample {
+			var n int
+			if err := db.QueryRow(`SELECT COUNT(*) FROM schema_migrations WHERE name = ?`, name).Scan(&n); err != nil {
+				return fmt.Errorf("check migration %s: %w", name, err)
+			}
+			if n > 0 {
+				continue
+			}
+		}
 		path := filepath.Join(dir, name)
 		body, err := os.ReadFile(path)
 		if err != nil {
 			return fmt.Errorf("read %s: %w", path, err)
 		}
-		if _, err := db.Exec(string(body)); err != nil {
-			return fmt.Errorf("exec migration %s: %w", name, err)
+		if err := applyMigration(db, name, string(body), !sample); err != nil {
+			return err
 		}
 	}
 	return nil
 }
+
+func applyMigration(db *sql.DB, name, body string, record bool) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin migration %s: %w", name, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(body); err != nil {
+		return fmt.Errorf("exec migration %s: %w", name, err)
+	}
+	if record {
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (name) VALUES (?)`, name); err != nil {
+			return fmt.Errorf("record migration %s: %w", name, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit migration %s: %w", name, err)
+	}
+	return nil
+}
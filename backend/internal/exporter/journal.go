@@ -0,0 +1,179 @@
+package exporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// journalAmountColumn is the width postings' account names are padded to, so amounts line up.
+const journalAmountColumn = 52
+
+// Journal is a double-entry view of a user's books for plain-text accounting tools. It carries no
+// generation time, so exporting unchanged data twice gives identical files.
+type Journal struct {
+	From, To  string // YYYY-MM-DD, inclusive; empty means open-ended
+	Commodity string // the base currency, e.g. IDR
+	Accounts  []JournalAccount
+	Entries   []JournalEntry
+}
+
+// JournalAccount is one declared account, e.g. "Assets:Bank:Rekening-Utama".
+type JournalAccount struct {
+	Name      string
+	Opened    string // YYYY-MM-DD, on or before its first posting
+	ID        string // MonMan id, empty for derived accounts such as Equity:Opening-Balances
+	Commodity string // the only commodity posted to it; empty when it holds several
+}
+
+// JournalEntry is one balanced transaction; postings sum to zero.
+type JournalEntry struct {
+	ID          string // stable MonMan id, written as metadata
+	Date        string // YYYY-MM-DD
+	Description string
+	Notes       string
+	Postings    []JournalPosting
+}
+
+// JournalPosting moves Amount cents of Commodity (empty: the journal's) into Account (negative = out
+// of it).
+type JournalPosting struct {
+	Account   string
+	Amount    int64
+	Commodity string
+}
+
+// commodities lists the journal's commodity and every other one a posting uses, sorted.
+func (j Journal) commodities() []string {
+	seen := map[string]bool{j.Commodity: true}
+	out := []string{j.Commodity}
+	for _, e := range j.Entries {
+		for _, p := range e.Postings {
+			if p.Commodity != "" && !seen[p.Commodity] {
+				seen[p.Commodity] = true
+				out = append(out, p.Commodity)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (j Journal) commodity(p JournalPosting) string {
+	if p.Commodity == "" {
+		return j.Commodity
+	}
+	return p.Commodity
+}
+
+// AccountSegment turns a MonMan name into one account-name component both ledger and beancount
+// accept: words capitalized and joined by hyphens ("Makanan & Minuman" -> "Makanan-Minuman").
+func AccountSegment(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		r := []rune(w)
+		r[0] = unicode.ToUpper(r[0])
+		words[i] = string(r)
+	}
+	if len(words) == 0 {
+		return "Unnamed"
+	}
+	return strings.Join(words, "-")
+}
+
+// WriteLedger writes j in ledger-cli syntax, which hledger reads as well. Each entry's id is a
+// "; id: ..." tag.
+func WriteLedger(w io.Writer, j Journal) error {
+	bw := bufio.NewWriter(w)
+	journalHeader(bw, ";", j)
+	for _, c := range j.commodities() {
+		fmt.Fprintf(bw, "commodity %s\n", c)
+	}
+	bw.WriteString("\n")
+	for _, a := range j.Accounts {
+		fmt.Fprintf(bw, "account %s\n", a.Name)
+	}
+	for _, e := range j.Entries {
+		fmt.Fprintf(bw, "\n%s * %s\n", e.Date, ledgerText(e.Description))
+		fmt.Fprintf(bw, "    ; id: %s\n", e.ID)
+		if e.Notes != "" {
+			fmt.Fprintf(bw, "    ; %s\n", ledgerText(e.Notes))
+		}
+		for _, p := range e.Postings {
+			fmt.Fprintf(bw, "    %-*s  %s %s\n", journalAmountColumn, p.Account, decimal(p.Amount), j.commodity(p))
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteBeancount writes j as a beancount file: an open directive per account and id/note metadata
+// on each transaction.
+func WriteBeancount(w io.Writer, j Journal) error {
+	bw := bufio.NewWriter(w)
+	journalHeader(bw, ";;", j)
+	fmt.Fprintf(bw, "option \"operating_currency\" \"%s\"\n", j.Commodity)
+
+	first := ""
+	for _, a := range j.Accounts {
+		if first == "" || a.Opened < first {
+			first = a.Opened
+		}
+	}
+	if first != "" {
+		bw.WriteString("\n")
+		for _, c := range j.commodities() {
+			fmt.Fprintf(bw, "%s commodity %s\n", first, c)
+		}
+		bw.WriteString("\n")
+	}
+	for _, a := range j.Accounts {
+		if a.Commodity == "" {
+			fmt.Fprintf(bw, "%s open %s\n", a.Opened, a.Name)
+		} else {
+			fmt.Fprintf(bw, "%s open %s %s\n", a.Opened, a.Name, a.Commodity)
+		}
+		if a.ID != "" {
+			fmt.Fprintf(bw, "  id: %s\n", beancountString(a.ID))
+		}
+	}
+	for _, e := range j.Entries {
+		fmt.Fprintf(bw, "\n%s * %s\n", e.Date, beancountString(e.Description))
+		fmt.Fprintf(bw, "  id: %s\n", beancountString(e.ID))
+		if e.Notes != "" {
+			fmt.Fprintf(bw, "  note: %s\n", beancountString(e.Notes))
+		}
+		for _, p := range e.Postings {
+			fmt.Fprintf(bw, "  %-*s  %s %s\n", journalAmountColumn, p.Account, decimal(p.Amount), j.commodity(p))
+		}
+	}
+	return bw.Flush()
+}
+
+func journalHeader(bw *bufio.Writer, comment string, j Journal) {
+	fmt.Fprintf(bw, "%s MonMan export\n", comment)
+	if j.From != "" || j.To != "" {
+		from, to := j.From, j.To
+		if from == "" {
+			from = "start"
+		}
+		if to == "" {
+			to = "now"
+		}
+		fmt.Fprintf(bw, "%s Range: %s .. %s\n", comment, from, to)
+	}
+	bw.WriteString("\n")
+}
+
+// ledgerText keeps free text on one line; ledger reads two spaces before ";" as a comment.
+func ledgerText(s string) string {
+	return strings.ReplaceAll(singleLine(s), ";", ",")
+}
+
+func beancountString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(singleLine(s)) + `"`
+}
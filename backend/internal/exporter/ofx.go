@@ -0,0 +1,145 @@
+package exporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ofxEscape covers the only entities OFX 1.x SGML defines.
+var ofxEscape = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// WriteOFX writes s as an OFX 1.0.2 (SGML) bank or credit-card statement. FITID is the MonMan
+// transaction id, so importing the file back into the same account skips rows it already has.
+func WriteOFX(w io.Writer, s Statement) error {
+	bw := bufio.NewWriter(w)
+	now := s.GeneratedAt.UTC().Format("20060102150405")
+	esc := func(v string) string { return ofxEscape.Replace(singleLine(v)) }
+	line := func(format string, args ...any) { fmt.Fprintf(bw, format+"\r\n", args...) }
+
+	line("OFXHEADER:100")
+	line("DATA:OFXSGML")
+	line("VERSION:102")
+	line("SECURITY:NONE")
+	line("ENCODING:UTF-8")
+	line("CHARSET:NONE")
+	line("COMPRESSION:NONE")
+	line("OLDFILEUID:NONE")
+	line("NEWFILEUID:NONE")
+	line("")
+	line("<OFX>")
+	line("<SIGNONMSGSRSV1><SONRS>")
+	line("<STATUS><CODE>0<SEVERITY>INFO</STATUS>")
+	line("<DTSERVER>%s", now)
+	line("<LANGUAGE>IND")
+	line("</SONRS></SIGNONMSGSRSV1>")
+
+	creditCard := s.AccountType == "credit_card"
+	acctID := s.AccountNumber
+	if acctID == "" {
+		acctID = s.AccountID
+	}
+	if creditCard {
+		line("<CREDITCARDMSGSRSV1><CCSTMTTRNRS>")
+	} else {
+		line("<BANKMSGSRSV1><STMTTRNRS>")
+	}
+	line("<TRNUID>0")
+	line("<STATUS><CODE>0<SEVERITY>INFO</STATUS>")
+	if creditCard {
+		line("<CCSTMTRS>")
+	} else {
+		line("<STMTRS>")
+	}
+	line("<CURDEF>%s", s.Currency)
+	if creditCard {
+		line("<CCACCTFROM><ACCTID>%s</CCACCTFROM>", esc(acctID))
+	} else {
+		bankID := s.BankName
+		if bankID == "" {
+			bankID = "MONMAN"
+		}
+		line("<BANKACCTFROM><BANKID>%s<ACCTID>%s<ACCTTYPE>%s</BANKACCTFROM>", esc(bankID), esc(acctID), ofxAccountType(s.AccountType))
+	}
+
+	line("<BANKTRANLIST>")
+	line("<DTSTART>%s", ofxRangeDate(s.From, s.Transactions, true, now))
+	line("<DTEND>%s", ofxRangeDate(s.To, s.Transactions, false, now))
+	for _, t := range s.Transactions {
+		line("<STMTTRN>")
+		line("<TRNTYPE>%s", ofxTransactionType(t))
+		line("<DTPOSTED>%s", strings.ReplaceAll(t.Date, "-", ""))
+		line("<TRNAMT>%s", decimal(t.Amount))
+		line("<FITID>%s", esc(t.ID))
+		line("<NAME>%s", esc(truncateRunes(t.Description, 32)))
+		if memo := ofxMemo(t); memo != "" {
+			line("<MEMO>%s", esc(memo))
+		}
+		line("</STMTTRN>")
+	}
+	line("</BANKTRANLIST>")
+	line("<LEDGERBAL><BALAMT>%s<DTASOF>%s</LEDGERBAL>", decimal(s.Balance), now)
+	if creditCard {
+		line("</CCSTMTRS></CCSTMTTRNRS></CREDITCARDMSGSRSV1>")
+	} else {
+		line("</STMTRS></STMTTRNRS></BANKMSGSRSV1>")
+	}
+	line("</OFX>")
+	return bw.Flush()
+}
+
+func ofxAccountType(accountType string) string {
+	if accountType == "bank" {
+		return "CHECKING"
+	}
+	// Cash, e-wallets and investment cash have no OFX bank type of their own.
+	return "SAVINGS"
+}
+
+func ofxTransactionType(t Transaction) string {
+	switch {
+	case t.Type == "transfer":
+		return "XFER"
+	case t.Amount < 0:
+		return "DEBIT"
+	}
+	return "CREDIT"
+}
+
+// ofxMemo carries what NAME has no room for: the full description and the category.
+func ofxMemo(t Transaction) string {
+	var parts []string
+	if len([]rune(t.Description)) > 32 {
+		parts = append(parts, t.Description)
+	}
+	if t.Memo != "" {
+		parts = append(parts, t.Memo)
+	}
+	if t.Category != "" && t.Type != "transfer" {
+		parts = append(parts, t.Category)
+	}
+	return strings.Join(parts, " | ")
+}
+
+// ofxRangeDate is the requested bound, else the first/last transaction date, else now.
+func ofxRangeDate(bound string, txns []Transaction, first bool, now string) string {
+	if bound == "" && len(txns) > 0 {
+		bound = txns[len(txns)-1].Date
+		if first {
+			bound = txns[0].Date
+		}
+	}
+	if t, err := time.Parse("2006-01-02", bound); err == nil {
+		return t.Format("20060102")
+	}
+	return now
+}
+
+func truncateRunes(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
@@ -0,0 +1,74 @@
+package exporter
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// WriteQIF writes s as a QIF register. dateFormat uses DD, MM and YYYY tokens (the importer's
+// DefaultQIFDateFormat, MM/DD/YYYY, when empty). A transfer's category is the other account in
+// brackets, "[BCA]"; subcategories are written as "Parent:Child".
+func WriteQIF(w io.Writer, s Statement, dateFormat string) error {
+	layout, err := QIFDateLayout(dateFormat)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "!Type:%s\n", qifAccountType(s.AccountType))
+	for _, t := range s.Transactions {
+		d, err := time.Parse("2006-01-02", t.Date)
+		if err != nil {
+			return fmt.Errorf("transaction %s: invalid date %q", t.ID, t.Date)
+		}
+		fmt.Fprintf(bw, "D%s\n", d.Format(layout))
+		fmt.Fprintf(bw, "T%s\n", decimal(t.Amount))
+		fmt.Fprintf(bw, "P%s\n", singleLine(t.Description))
+		if t.Memo != "" {
+			fmt.Fprintf(bw, "M%s\n", singleLine(t.Memo))
+		}
+		switch {
+		case t.Type == "transfer":
+			if t.TransferAccount != "" {
+				fmt.Fprintf(bw, "L[%s]\n", qifName(t.TransferAccount))
+			}
+		case t.Category != "" && t.ParentCategory != "":
+			fmt.Fprintf(bw, "L%s:%s\n", qifName(t.ParentCategory), qifName(t.Category))
+		case t.Category != "":
+			fmt.Fprintf(bw, "L%s\n", qifName(t.Category))
+		}
+		fmt.Fprint(bw, "^\n")
+	}
+	return bw.Flush()
+}
+
+// QIFDateLayout converts a DD/MM/YYYY-style format into a Go layout, defaulting to MM/DD/YYYY.
+func QIFDateLayout(dateFormat string) (string, error) {
+	if dateFormat == "" {
+		dateFormat = "MM/DD/YYYY"
+	}
+	f := strings.ToUpper(dateFormat)
+	if strings.Count(f, "YYYY") != 1 || strings.Count(f, "MM") != 1 || strings.Count(f, "DD") != 1 {
+		return "", errors.New("date_format must contain DD, MM and YYYY once each")
+	}
+	return strings.NewReplacer("YYYY", "2006", "MM", "01", "DD", "02").Replace(f), nil
+}
+
+// qifName keeps a category or account name from being read as a subcategory path, class or transfer.
+func qifName(name string) string {
+	return strings.NewReplacer(":", "-", "/", "-", "[", "(", "]", ")").Replace(singleLine(name))
+}
+
+func qifAccountType(accountType string) string {
+	switch accountType {
+	case "credit_card":
+		return "CCard"
+	case "cash", "ewallet":
+		return "Cash"
+	}
+	return "Bank"
+}
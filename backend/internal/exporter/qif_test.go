@@ -0,0 +1,51 @@
+package exporter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteQIF(t *testing.T) {
+	s := Statement{
+		AccountName: "BCA",
+		AccountType: "bank",
+		Transactions: []Transaction{
+			{ID: "1", Date: "2026-09-01", Type: "transfer", Description: "Saldo awal", TransferAccount: "BCA", Amount: 1000000},
+			{ID: "2", Date: "2026-10-02", Type: "transfer", Description: "Transfer", TransferAccount: "Dompet [utama]", Amount: -100000},
+			{ID: "3", Date: "2026-10-02", Type: "transfer", Description: "Beli reksa dana", Amount: -50000},
+			{ID: "4", Date: "2026-10-03", Type: "expense", Description: "Aqua", Memo: "Galon\n2x", Category: "Galon", ParentCategory: "Rumah", Amount: -2000000},
+			{ID: "5", Date: "2026-10-04", Type: "expense", Description: "Listrik", Category: "Air/Listrik: PLN", Amount: -1234},
+		},
+	}
+	var b strings.Builder
+	if err := WriteQIF(&b, s, "DD/MM/YYYY"); err != nil {
+		t.Fatalf("WriteQIF: %v", err)
+	}
+	want := "!Type:Bank\n" +
+		"D01/09/2026\nT10000.00\nPSaldo awal\nL[BCA]\n^\n" +
+		"D02/10/2026\nT-1000.00\nPTransfer\nL[Dompet (utama)]\n^\n" +
+		"D02/10/2026\nT-500.00\nPBeli reksa dana\n^\n" +
+		"D03/10/2026\nT-20000.00\nPAqua\nMGalon 2x\nLRumah:Galon\n^\n" +
+		"D04/10/2026\nT-12.34\nPListrik\nLAir-Listrik- PLN\n^\n"
+	if got := b.String(); got != want {
+		t.Errorf("WriteQIF =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestQIFDateLayout(t *testing.T) {
+	tests := []struct {
+		format, want string
+		wantErr      bool
+	}{
+		{"", "01/02/2006", false},
+		{"dd.mm.yyyy", "02.01.2006", false},
+		{"YYYY-MM-DD", "2006-01-02", false},
+		{"DD/MM/YY", "", true},
+	}
+	for _, tt := range tests {
+		got, err := QIFDateLayout(tt.format)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("QIFDateLayout(%q) = %q, %v; want %q, err %v", tt.format, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+// Package exporter writes MonMan data in formats other finance software reads.
+package exporter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Statement is one account's transactions over a date range, in date order.
+type Statement struct {
+	AccountID     string
+	AccountName   string
+	AccountType   string // bank, credit_card, cash, investment, ewallet
+	BankName      string
+	AccountNumber string
+	Currency      string // ISO 4217, e.g. IDR
+	From, To      string // YYYY-MM-DD, inclusive; empty means open-ended
+	Balance       int64  // current balance in cents
+	GeneratedAt   time.Time
+	Transactions  []Transaction
+}
+
+// Transaction is one statement line; Amount is signed cents (negative = money out).
+type Transaction struct {
+	ID              string
+	Date            string // YYYY-MM-DD
+	Type            string // income, expense, transfer
+	Description     string
+	Memo            string
+	Category        string
+	ParentCategory  string // set for subcategories
+	TransferAccount string // the other account of a transfer (this one for an opening balance)
+	Amount          int64
+}
+
+// decimal formats cents as a plain decimal amount: -123456 -> "-1234.56".
+func decimal(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// singleLine collapses whitespace so free text cannot break line-oriented formats.
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
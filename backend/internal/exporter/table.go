@@ -0,0 +1,145 @@
+package exporter
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Table is one exported entity. Values are string, int64, bool or nil (empty).
+type Table struct {
+	Name    string // file and sheet name, e.g. "transactions"
+	Columns []string
+	Rows    [][]any
+}
+
+// DataExport is the whole-account dump written by WriteArchive and WriteXLSX.
+type DataExport struct {
+	From, To    string // YYYY-MM-DD, inclusive; empty means open-ended
+	Currency    string // the user's base currency
+	GeneratedAt time.Time
+	Tables      []Table
+}
+
+// WriteCSV writes t with a header row; nil is an empty field and bools are "true"/"false".
+func WriteCSV(w io.Writer, t Table) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(t.Columns); err != nil {
+		return err
+	}
+	record := make([]string, len(t.Columns))
+	for _, row := range t.Rows {
+		for i, v := range row {
+			record[i] = cellText(v)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes t as an array of objects keyed by column, in column order, one row per line.
+func WriteJSON(w io.Writer, t Table) error {
+	keys := make([][]byte, len(t.Columns))
+	for i, c := range t.Columns {
+		k, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+		keys[i] = k
+	}
+	bw := bufio.NewWriter(w)
+	bw.WriteString("[")
+	for r, row := range t.Rows {
+		if r > 0 {
+			bw.WriteString(",")
+		}
+		bw.WriteString("\n  {")
+		for i, v := range row {
+			if i > 0 {
+				bw.WriteString(",")
+			}
+			val, err := json.Marshal(v)
+			if err != nil {
+				return fmt.Errorf("%s row %d: %w", t.Name, r+1, err)
+			}
+			bw.Write(keys[i])
+			bw.WriteString(":")
+			bw.Write(val)
+		}
+		bw.WriteString("}")
+	}
+	if len(t.Rows) > 0 {
+		bw.WriteString("\n")
+	}
+	bw.WriteString("]\n")
+	return bw.Flush()
+}
+
+// WriteArchive writes a ZIP holding every table as <name>.csv and <name>.json, plus manifest.json
+// with the date range and row counts.
+func WriteArchive(w io.Writer, d DataExport) error {
+	zw := zip.NewWriter(w)
+	for _, t := range d.Tables {
+		for _, f := range []struct {
+			ext   string
+			write func(io.Writer, Table) error
+		}{{"csv", WriteCSV}, {"json", WriteJSON}} {
+			fw, err := zw.CreateHeader(&zip.FileHeader{Name: t.Name + "." + f.ext, Method: zip.Deflate, Modified: d.GeneratedAt})
+			if err != nil {
+				return err
+			}
+			if err := f.write(fw, t); err != nil {
+				return err
+			}
+		}
+	}
+
+	manifest := struct {
+		GeneratedAt string         `json:"generated_at"`
+		From        string         `json:"from,omitempty"`
+		To          string         `json:"to,omitempty"`
+		Currency    string         `json:"currency"`
+		Rows        map[string]int `json:"rows"`
+	}{
+		GeneratedAt: d.GeneratedAt.UTC().Format(time.RFC3339),
+		From:        d.From,
+		To:          d.To,
+		Currency:    d.Currency,
+		Rows:        make(map[string]int, len(d.Tables)),
+	}
+	for _, t := range d.Tables {
+		manifest.Rows[t.Name] = len(t.Rows)
+	}
+	fw, err := zw.CreateHeader(&zip.FileHeader{Name: "manifest.json", Method: zip.Deflate, Modified: d.GeneratedAt})
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(fw)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(manifest); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func cellText(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case bool:
+		return strconv.FormatBool(x)
+	}
+	return fmt.Sprint(v)
+}
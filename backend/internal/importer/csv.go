@@ -0,0 +1,455 @@
+// Package importer parses bank statement files into transaction rows.
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"monman-backend/internal/models"
+)
+
+// MaxRows bounds how many statement lines one import may contain.
+const MaxRows = 5000
+
+// maxDescriptionLen truncates long bank narratives.
+const maxDescriptionLen = 255
+
+// Row is one parsed statement line. Rows that could not be parsed carry Error and are never committed.
+type Row struct {
+	Line        int    // line number in the file
+	Date        string // YYYY-MM-DD
+	Description string
+	Amount      int64  // signed cents: negative = money out
+	ExternalID  string // bank-assigned id (OFX FITID), used to skip rows imported before
+	Category    string // category given by the file (QIF L field), "Parent:Child" for subcategories
+	Error       string
+}
+
+// ValidateProfile checks that p names the columns an import needs and fills in defaults.
+func ValidateProfile(p *models.ImportProfile) error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Delimiter == "" {
+		p.Delimiter = ","
+	}
+	if p.Delimiter == `\t` {
+		p.Delimiter = "\t"
+	}
+	if utf8.RuneCountInString(p.Delimiter) != 1 || p.Delimiter == `"` || p.Delimiter == "\n" {
+		return errors.New("delimiter must be a single character")
+	}
+	if p.DecimalSeparator == "" {
+		p.DecimalSeparator = "."
+	}
+	if p.DecimalSeparator != "." && p.DecimalSeparator != "," {
+		return errors.New(`decimal_separator must be "." or ","`)
+	}
+	if p.SkipRows < 0 || p.SkipRows > 100 {
+		return errors.New("skip_rows must be between 0 and 100")
+	}
+	if strings.TrimSpace(p.DateColumn) == "" {
+		return errors.New("date_column is required")
+	}
+	if _, err := dateLayout(p.DateFormat); err != nil {
+		return err
+	}
+	if len(p.DescriptionColumns) == 0 {
+		return errors.New("description_columns is required")
+	}
+	hasAmount := strings.TrimSpace(p.AmountColumn) != ""
+	hasSplit := strings.TrimSpace(p.DebitColumn) != "" || strings.TrimSpace(p.CreditColumn) != ""
+	switch {
+	case hasAmount && hasSplit:
+		return errors.New("use either amount_column or debit_column/credit_column, not both")
+	case !hasAmount && !hasSplit:
+		return errors.New("amount_column or debit_column and credit_column is required")
+	case hasSplit && (strings.TrimSpace(p.DebitColumn) == "" || strings.TrimSpace(p.CreditColumn) == ""):
+		return errors.New("debit_column and credit_column must both be set")
+	case hasSplit && strings.TrimSpace(p.IndicatorColumn) != "":
+		return errors.New("indicator_column only applies to amount_column")
+	}
+	return nil
+}
+
+// ParseCSV reads a statement export laid out as p describes. A structural problem (unreadable
+// file, missing column) is returned as an error; problems with single lines are reported on the Row.
+func ParseCSV(r io.Reader, p models.ImportProfile) ([]Row, error) {
+	if err := ValidateProfile(&p); err != nil {
+		return nil, err
+	}
+	layout, _ := dateLayout(p.DateFormat)
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.Comma, _ = utf8.DecodeRuneInString(p.Delimiter)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	cr.TrimLeadingSpace = true
+
+	var header []string
+	skip := p.SkipRows
+	var cols columns
+	var rows []Row
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := cr.FieldPos(0)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		if blank(rec) {
+			continue
+		}
+		if header == nil && !p.NoHeader {
+			header = rec
+			if cols, err = resolveColumns(p, header); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if cols.date == nil {
+			if cols, err = resolveColumns(p, nil); err != nil {
+				return nil, err
+			}
+		}
+		if len(rows) >= MaxRows {
+			return nil, fmt.Errorf("file has more than %d rows", MaxRows)
+		}
+		rows = append(rows, parseRecord(rec, line, cols, p, layout))
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("file has no data rows")
+	}
+	return rows, nil
+}
+
+// columns holds resolved 0-based column indexes; nil pointers mean "not mapped".
+type columns struct {
+	date, amount, debit, credit, indicator *int
+	description                            []int
+}
+
+func resolveColumns(p models.ImportProfile, header []string) (columns, error) {
+	var c columns
+	var err error
+	if c.date, err = columnIndex(p.DateColumn, header); err != nil {
+		return c, err
+	}
+	for _, ref := range p.DescriptionColumns {
+		i, err := columnIndex(ref, header)
+		if err != nil {
+			return c, err
+		}
+		c.description = append(c.description, *i)
+	}
+	if c.amount, err = columnIndex(p.AmountColumn, header); err != nil {
+		return c, err
+	}
+	if c.debit, err = columnIndex(p.DebitColumn, header); err != nil {
+		return c, err
+	}
+	if c.credit, err = columnIndex(p.CreditColumn, header); err != nil {
+		return c, err
+	}
+	if c.indicator, err = columnIndex(p.IndicatorColumn, header); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+// columnIndex resolves a header name or 1-based column number; an empty ref returns nil.
+func columnIndex(ref string, header []string) (*int, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, nil
+	}
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n < 1 {
+			return nil, fmt.Errorf("column %d is out of range", n)
+		}
+		i := n - 1
+		return &i, nil
+	}
+	if header == nil {
+		return nil, fmt.Errorf("column %q must be a column number when the file has no header", ref)
+	}
+	for i, h := range header {
+		if strings.EqualFold(strings.TrimSpace(h), ref) {
+			return &i, nil
+		}
+	}
+	return nil, fmt.Errorf("column %q not found in header", ref)
+}
+
+func parseRecord(rec []string, line int, c columns, p models.ImportProfile, layout string) Row {
+	row := Row{Line: line}
+	field := func(i *int) string {
+		if i == nil || *i >= len(rec) {
+			return ""
+		}
+		// BCA prefixes cells with an apostrophe so spreadsheets keep them as text.
+		return strings.TrimPrefix(strings.TrimSpace(rec[*i]), "'")
+	}
+
+	var parts []string
+	for _, i := range c.description {
+		if v := field(&i); v != "" {
+			parts = append(parts, v)
+		}
+	}
+	row.Description = truncate(strings.Join(strings.Fields(strings.Join(parts, " ")), " "))
+
+	date, err := parseDate(field(c.date), layout)
+	if err != nil {
+		row.Error = err.Error()
+		return row
+	}
+	row.Date = date
+
+	if c.amount != nil {
+		row.Amount, err = parseSignedAmount(field(c.amount), field(c.indicator), p.DecimalSeparator)
+	} else {
+		row.Amount, err = parseDebitCredit(field(c.debit), field(c.credit), p.DecimalSeparator)
+	}
+	if err != nil {
+		row.Error = err.Error()
+		return row
+	}
+	if row.Description == "" {
+		row.Error = "description is empty"
+	}
+	return row
+}
+
+func blank(rec []string) bool {
+	for _, f := range rec {
+		if strings.TrimSpace(f) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// truncate cuts long bank narratives to maxDescriptionLen characters.
+func truncate(s string) string {
+	if r := []rune(s); len(r) > maxDescriptionLen {
+		return string(r[:maxDescriptionLen])
+	}
+	return s
+}
+
+// dateLayout converts a DD/MM/YYYY-style format into a Go time layout.
+func dateLayout(format string) (string, error) {
+	format = strings.TrimSpace(format)
+	if format == "" {
+		return "", errors.New("date_format is required")
+	}
+	tokens := []struct{ tok, layout string }{
+		{"YYYY", "2006"}, {"YY", "06"},
+		{"MMM", "Jan"}, {"MM", "01"}, {"M", "1"},
+		{"DD", "02"}, {"D", "2"},
+	}
+	var b strings.Builder
+	var hasY, hasM, hasD bool
+	for i := 0; i < len(format); {
+		matched := false
+		for _, t := range tokens {
+			if strings.HasPrefix(format[i:], t.tok) {
+				b.WriteString(t.layout)
+				switch t.tok[0] {
+				case 'Y':
+					hasY = true
+				case 'M':
+					hasM = true
+				case 'D':
+					hasD = true
+				}
+				i += len(t.tok)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			b.WriteByte(format[i])
+			i++
+		}
+	}
+	if !hasY || !hasM || !hasD {
+		return "", errors.New("date_format needs day, month and year (e.g. DD/MM/YYYY)")
+	}
+	return b.String(), nil
+}
+
+// indonesianMonths maps Indonesian month abbreviations that differ from English ones.
+var indonesianMonths = strings.NewReplacer(
+	"Mei", "May", "MEI", "May", "mei", "May",
+	"Agu", "Aug", "AGU", "Aug", "agu", "Aug",
+	"Agt", "Aug", "AGT", "Aug", "agt", "Aug",
+	"Okt", "Oct", "OKT", "Oct", "okt", "Oct",
+	"Des", "Dec", "DES", "Dec", "des", "Dec",
+)
+
+func parseDate(v, layout string) (string, error) {
+	if v == "" {
+		return "", errors.New("date is empty")
+	}
+	// Drop a trailing time ("01/03/2026 10:15:00") unless the layout itself has a space.
+	if !strings.Contains(layout, " ") {
+		if i := strings.IndexAny(v, " \t"); i > 0 {
+			v = v[:i]
+		}
+	}
+	t, err := time.Parse(layout, indonesianMonths.Replace(v))
+	if err != nil {
+		return "", fmt.Errorf("invalid date %q", v)
+	}
+	return t.Format("2006-01-02"), nil
+}
+
+// parseSignedAmount reads an amount that is negative for money out, or whose direction is given by a
+// DB/CR marker in the indicator column or at the end of the value.
+func parseSignedAmount(v, indicator, decimalSep string) (int64, error) {
+	if v == "" {
+		return 0, errors.New("amount is empty")
+	}
+	v = strings.TrimSpace(v)
+	up := strings.ToUpper(v)
+	marker := strings.ToUpper(strings.TrimSpace(indicator))
+	for _, m := range []string{"DB", "DR", "CR", "D", "K", "C"} {
+		rest := strings.TrimSpace(strings.TrimSuffix(up, m))
+		if rest == up || rest == "" || !strings.ContainsAny(rest[len(rest)-1:], "0123456789)") {
+			continue
+		}
+		if marker == "" {
+			marker = m
+		}
+		v = strings.TrimSpace(v[:len(v)-len(m)])
+		break
+	}
+	cents, err := parseCents(v, decimalSep)
+	if err != nil {
+		return 0, err
+	}
+	switch marker {
+	case "":
+		return cents, checkNonZero(cents)
+	case "DB", "DR", "D":
+		return -abs(cents), checkNonZero(cents)
+	case "CR", "C", "K":
+		return abs(cents), checkNonZero(cents)
+	}
+	return 0, fmt.Errorf("unknown debit/credit marker %q", indicator)
+}
+
+// parseDebitCredit reads split columns where a non-zero debit is money out and a credit money in.
+func parseDebitCredit(debit, credit, decimalSep string) (int64, error) {
+	var d, c int64
+	var err error
+	if debit != "" {
+		if d, err = parseCents(debit, decimalSep); err != nil {
+			return 0, err
+		}
+	}
+	if credit != "" {
+		if c, err = parseCents(credit, decimalSep); err != nil {
+			return 0, err
+		}
+	}
+	switch {
+	case d != 0 && c != 0:
+		return 0, errors.New("both debit and credit are set")
+	case d != 0:
+		return -abs(d), nil
+	case c != 0:
+		return abs(c), nil
+	}
+	return 0, errors.New("amount is empty")
+}
+
+// parseCents converts "1,234,567.89", "Rp 1.234.567,89", "(500.00)" or "-500" into signed cents.
+func parseCents(v, decimalSep string) (int64, error) {
+	orig := v
+	s := strings.ToUpper(strings.TrimSpace(v))
+	s = strings.TrimPrefix(s, "IDR")
+	s = strings.TrimPrefix(s, "RP")
+	s = strings.ReplaceAll(s, " ", "")
+	neg := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		neg, s = true, s[1:len(s)-1]
+	}
+	if strings.HasPrefix(s, "-") {
+		neg, s = !neg, s[1:]
+	} else if strings.HasSuffix(s, "-") {
+		neg, s = !neg, s[:len(s)-1]
+	}
+	s = strings.TrimPrefix(s, "+")
+	thousands := ","
+	if decimalSep == "," {
+		thousands = "."
+	}
+	s = strings.ReplaceAll(s, thousands, "")
+	whole, frac, _ := strings.Cut(s, decimalSep)
+	if whole == "" {
+		whole = "0"
+	}
+	if !isDigit(whole) || (frac != "" && !isDigit(frac)) {
+		return 0, fmt.Errorf("invalid amount %q", orig)
+	}
+	if len(frac) > 2 {
+		return 0, fmt.Errorf("amount %q has more than 2 decimal places", orig)
+	}
+	for len(frac) < 2 {
+		frac += "0"
+	}
+	n, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", orig)
+	}
+	if neg {
+		n = -n
+	}
+	return n, nil
+}
+
+func isDigit(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func checkNonZero(n int64) error {
+	if n == 0 {
+		return errors.New("amount is zero")
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"monman-backend/internal/models"
+)
+
+func TestParseCents(t *testing.T) {
+	tests := []struct {
+		in, sep string
+		want    int64
+		wantErr bool
+	}{
+		{"1,234,567.89", ".", 123456789, false},
+		{"Rp 1.234.567,89", ",", 123456789, false},
+		{"IDR 50.000", ",", 5000000, false},
+		{"1.234.567", ",", 123456700, false},
+		{"12,5", ",", 1250, false},
+		{"(500.00)", ".", -50000, false},
+		{"-500", ".", -50000, false},
+		{"500-", ".", -50000, false},
+		{"+75.5", ".", 7550, false},
+		{".75", ".", 75, false},
+		{"1.234", ".", 0, true}, // more than two decimals, e.g. a "." thousands separator
+		{"12a", ".", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseCents(tt.in, tt.sep)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseCents(%q, %q) = %d, %v; want %d, err %v", tt.in, tt.sep, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParseSignedAmount(t *testing.T) {
+	tests := []struct {
+		value, indicator string
+		want             int64
+		wantErr          bool
+	}{
+		{"1,500,000.00", "DB", -150000000, false},
+		{"1,500,000.00", "CR", 150000000, false},
+		{"1,500,000.00 DB", "", -150000000, false},
+		{"250,000.00 CR", "", 25000000, false},
+		{"75000 D", "", -7500000, false},
+		{"75000", "K", 7500000, false},
+		{"-75000", "", -7500000, false},
+		{"-75000", "CR", 7500000, false},
+		{"75000", "XX", 0, true},
+		{"0.00", "", 0, true},
+		{"", "DB", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseSignedAmount(tt.value, tt.indicator, ".")
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseSignedAmount(%q, %q) = %d, %v; want %d, err %v", tt.value, tt.indicator, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParseDebitCredit(t *testing.T) {
+	tests := []struct {
+		debit, credit string
+		want          int64
+		wantErr       bool
+	}{
+		{"50,000.00", "", -5000000, false},
+		{"", "50,000.00", 5000000, false},
+		{"0.00", "1,000.00", 100000, false},
+		{"10.00", "20.00", 0, true},
+		{"", "", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseDebitCredit(tt.debit, tt.credit, ".")
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseDebitCredit(%q, %q) = %d, %v; want %d, err %v", tt.debit, tt.credit, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		value, format string
+		want          string
+		wantErr       bool
+	}{
+		{"31/01/2026", "DD/MM/YYYY", "2026-01-31", false},
+		{"1/3/26", "D/M/YY", "2026-03-01", false},
+		{"01/03/2026 10:15:00", "DD/MM/YYYY", "2026-03-01", false},
+		{"17 Agu 2026", "DD MMM YYYY", "2026-08-17", false},
+		{"05-Mei-2026", "DD-MMM-YYYY", "2026-05-05", false},
+		{"24 Des 2026", "D MMM YYYY", "2026-12-24", false},
+		{"2026-10-01", "YYYY-MM-DD", "2026-10-01", false},
+		{"31/02/2026", "DD/MM/YYYY", "", true},
+	}
+	for _, tt := range tests {
+		layout, err := dateLayout(tt.format)
+		if err != nil {
+			t.Fatalf("dateLayout(%q): %v", tt.format, err)
+		}
+		got, err := parseDate(tt.value, layout)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseDate(%q, %q) = %q, %v; want %q, err %v", tt.value, tt.format, got, err, tt.want, tt.wantErr)
+		}
+	}
+	if _, err := dateLayout("DD/MM"); err == nil {
+		t.Error("dateLayout accepted a format without a year")
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	bca, _ := BuiltInProfile("bca")
+	mandiri, _ := BuiltInProfile("mandiri")
+	bni, _ := BuiltInProfile("bni")
+	tests := []struct {
+		name    string
+		profile models.ImportProfile
+		file    string
+		want    []Row
+	}{
+		{
+			name:    "bca with DB/CR column and apostrophe-prefixed cells",
+			profile: bca,
+			file: "Tanggal Transaksi,Keterangan,Cabang,Jumlah,DB/CR,Saldo\n" +
+				"'01/03/2026,TRSF E-BANKING DB  ALFAMART,0000,\"125,000.00\",DB,\"1,000,000.00\"\n" +
+				"'02/03/2026,SETORAN TUNAI,0000,\"500,000.00\",CR,\"1,500,000.00\"\n",
+			want: []Row{
+				{Line: 2, Date: "2026-03-01", Description: "TRSF E-BANKING DB ALFAMART", Amount: -12500000},
+				{Line: 3, Date: "2026-03-02", Description: "SETORAN TUNAI", Amount: 50000000},
+			},
+		},
+		{
+			name:    "mandiri debit/credit columns with a bad row",
+			profile: mandiri,
+			file: "Tanggal,Keterangan,Debit,Kredit,Saldo\n" +
+				"05/03/2026,Gaji Maret,,\"8,000,000.00\",\"9,000,000.00\"\n" +
+				"06/03/2026,Tarik tunai,\"200,000.00\",,\"8,800,000.00\"\n" +
+				"xx/03/2026,Rusak,\"1.00\",,\n",
+			want: []Row{
+				{Line: 2, Date: "2026-03-05", Description: "Gaji Maret", Amount: 800000000},
+				{Line: 3, Date: "2026-03-06", Description: "Tarik tunai", Amount: -20000000},
+				{Line: 4, Description: "Rusak", Error: `invalid date "xx/03/2026"`},
+			},
+		},
+		{
+			name:    "bni D/K type column",
+			profile: bni,
+			file: "Tanggal,Uraian Transaksi,Tipe,Nominal,Saldo\n" +
+				"10/03/2026,BIAYA ADM,D,\"15,000.00\",\"0\"\n" +
+				"11/03/2026,BUNGA,K,\"1,234.56\",\"0\"\n",
+			want: []Row{
+				{Line: 2, Date: "2026-03-10", Description: "BIAYA ADM", Amount: -1500000},
+				{Line: 3, Date: "2026-03-11", Description: "BUNGA", Amount: 123456},
+			},
+		},
+		{
+			name: "headerless semicolon file with Indonesian decimals",
+			profile: models.ImportProfile{
+				Delimiter:          ";",
+				SkipRows:           1,
+				NoHeader:           true,
+				DateColumn:         "1",
+				DateFormat:         "DD MMM YYYY",
+				DescriptionColumns: []string{"2", "3"},
+				AmountColumn:       "4",
+				DecimalSeparator:   ",",
+			},
+			file: "Mutasi rekening\n" +
+				"17 Agu 2026;Bayar;PLN;-1.250.000,50\n" +
+				"01 Okt 2026;Refund;;75.000\n",
+			want: []Row{
+				{Line: 2, Date: "2026-08-17", Description: "Bayar PLN", Amount: -125000050},
+				{Line: 3, Date: "2026-10-01", Description: "Refund", Amount: 7500000},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCSV(strings.NewReader(tt.file), tt.profile)
+			if err != nil {
+				t.Fatalf("ParseCSV: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCSV =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCSVErrors(t *testing.T) {
+	bca, _ := BuiltInProfile("bca")
+	tests := []struct {
+		name    string
+		profile models.ImportProfile
+		file    string
+		wantErr string
+	}{
+		{"missing column", bca, "Tanggal,Keterangan,Jumlah\n01/03/2026,x,1\n", `column "Tanggal Transaksi" not found in header`},
+		{"header only", bca, "Tanggal Transaksi,Keterangan,Cabang,Jumlah,DB/CR,Saldo\n", "file has no data rows"},
+		{"named column without a header", models.ImportProfile{NoHeader: true, DateColumn: "Tanggal", DateFormat: "DD/MM/YYYY",
+			DescriptionColumns: []string{"2"}, AmountColumn: "3"}, "01/03/2026,x,1\n",
+			`column "Tanggal" must be a column number when the file has no header`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCSV(strings.NewReader(tt.file), tt.profile)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package importer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var ofxField = regexp.MustCompile(`(?i)<([A-Z0-9.]+)>([^<\r\n]*)`)
+
+// ParseOFX reads the STMTTRN records of an OFX statement. Both OFX 1.x (SGML, leaf tags left open)
+// and 2.x (XML) files are accepted; only the transaction list is used, the account in the file is not.
+func ParseOFX(r io.Reader) ([]Row, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+	if !bytes.Contains(bytes.ToUpper(data), []byte("<OFX")) {
+		return nil, errors.New("not an OFX file")
+	}
+
+	// SGML files may leave </STMTTRN> out, so a record runs to whichever tag comes first.
+	upper := bytes.ToUpper(data)
+	open := []byte("<STMTTRN>")
+	var rows []Row
+	for pos := 0; ; {
+		i := bytes.Index(upper[pos:], open)
+		if i < 0 {
+			break
+		}
+		startTag := pos + i
+		start := startTag + len(open)
+		end := len(data)
+		for _, tag := range []string{"</STMTTRN>", "<STMTTRN>", "</BANKTRANLIST>"} {
+			if j := bytes.Index(upper[start:], []byte(tag)); j >= 0 && start+j < end {
+				end = start + j
+			}
+		}
+		pos = end
+		if len(rows) >= MaxRows {
+			return nil, fmt.Errorf("file has more than %d rows", MaxRows)
+		}
+		block := string(data[start:end])
+		fields := map[string]string{}
+		for _, f := range ofxField.FindAllStringSubmatch(block, -1) {
+			tag := strings.ToUpper(f[1])
+			if _, seen := fields[tag]; !seen {
+				fields[tag] = html.UnescapeString(strings.TrimSpace(f[2]))
+			}
+		}
+		rows = append(rows, ofxRow(fields, 1+bytes.Count(data[:startTag], []byte("\n"))))
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("file has no transactions")
+	}
+	return rows, nil
+}
+
+func ofxRow(f map[string]string, line int) Row {
+	row := Row{Line: line, ExternalID: f["FITID"]}
+
+	var parts []string
+	for _, v := range []string{f["NAME"], f["PAYEE"], f["MEMO"]} {
+		if v != "" && !strings.Contains(strings.Join(parts, " "), v) {
+			parts = append(parts, v)
+		}
+	}
+	row.Description = truncate(strings.Join(strings.Fields(strings.Join(parts, " ")), " "))
+
+	// DTPOSTED is YYYYMMDD optionally followed by a time and [offset:TZ].
+	posted := f["DTPOSTED"]
+	if len(posted) < 8 {
+		row.Error = fmt.Sprintf("invalid date %q", posted)
+		return row
+	}
+	date, err := parseDate(posted[:8], "20060102")
+	if err != nil {
+		row.Error = err.Error()
+		return row
+	}
+	row.Date = date
+
+	amount := f["TRNAMT"]
+	sep := "."
+	if strings.Contains(amount, ",") && !strings.Contains(amount, ".") {
+		sep = ","
+	}
+	if row.Amount, err = parseCents(amount, sep); err != nil {
+		row.Error = err.Error()
+		return row
+	}
+	if err := checkNonZero(row.Amount); err != nil {
+		row.Error = err.Error()
+		return row
+	}
+	if row.Description == "" {
+		row.Error = "description is empty"
+	}
+	return row
+}
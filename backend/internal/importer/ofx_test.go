@@ -0,0 +1,77 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseOFX(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want []Row
+	}{
+		{
+			name: "sgml with open leaf tags and a WIB offset",
+			file: "OFXHEADER:100\nDATA:OFXSGML\n\n<OFX>\n<BANKMSGSRSV1><STMTTRNRS><STMTRS><BANKTRANLIST>\n" +
+				"<STMTTRN>\n<TRNTYPE>DEBIT\n<DTPOSTED>20260301120000.000[+7:WIB]\n<TRNAMT>-125000.00\n<FITID>A1\n<NAME>ALFAMART\n<MEMO>Belanja &amp; sabun\n" +
+				"<STMTTRN>\n<TRNTYPE>CREDIT\n<DTPOSTED>20260302\n<TRNAMT>500000\n<FITID>A2\n<NAME>SETORAN\n<MEMO>SETORAN\n" +
+				"</BANKTRANLIST></STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>\n",
+			want: []Row{
+				{Line: 6, Date: "2026-03-01", Description: "ALFAMART Belanja & sabun", Amount: -12500000, ExternalID: "A1"},
+				{Line: 13, Date: "2026-03-02", Description: "SETORAN", Amount: 50000000, ExternalID: "A2"},
+			},
+		},
+		{
+			name: "xml with closing tags and a comma decimal",
+			file: `<?xml version="1.0"?><OFX><BANKTRANLIST>` +
+				"\n<STMTTRN><DTPOSTED>20261231235959[-5:EST]</DTPOSTED><TRNAMT>-1234,5</TRNAMT><FITID>X9</FITID><PAYEE>PLN</PAYEE></STMTTRN>" +
+				"\n</BANKTRANLIST></OFX>",
+			want: []Row{
+				{Line: 2, Date: "2026-12-31", Description: "PLN", Amount: -123450, ExternalID: "X9"},
+			},
+		},
+		{
+			name: "bad rows carry errors",
+			file: "<OFX>\n<STMTTRN><DTPOSTED>2026<TRNAMT>-1<NAME>A</STMTTRN>\n" +
+				"<STMTTRN><DTPOSTED>20260230<TRNAMT>-1<NAME>B</STMTTRN>\n" +
+				"<STMTTRN><DTPOSTED>20260301<TRNAMT>0.00<NAME>C</STMTTRN>\n" +
+				"<STMTTRN><DTPOSTED>20260301<TRNAMT>-1</STMTTRN>\n</OFX>",
+			want: []Row{
+				{Line: 2, Description: "A", Error: `invalid date "2026"`},
+				{Line: 3, Description: "B", Error: `invalid date "20260230"`},
+				{Line: 4, Date: "2026-03-01", Description: "C", Error: "amount is zero"},
+				{Line: 5, Date: "2026-03-01", Amount: -100, Error: "description is empty"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseOFX(strings.NewReader(tt.file))
+			if err != nil {
+				t.Fatalf("ParseOFX: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseOFX =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOFXErrors(t *testing.T) {
+	tests := []struct {
+		name, file, wantErr string
+	}{
+		{"not ofx", "Tanggal,Keterangan\n", "not an OFX file"},
+		{"no transactions", "<OFX><BANKTRANLIST></BANKTRANLIST></OFX>", "file has no transactions"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseOFX(strings.NewReader(tt.file))
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package importer
+
+import (
+	"strings"
+
+	"monman-backend/internal/models"
+)
+
+// builtInProfiles cover the statement CSVs of the banks listed for models.Account.BankName. Layouts
+// follow each bank's internet-banking download; a user whose export differs can save a custom profile.
+var builtInProfiles = []models.ImportProfile{
+	{
+		// KlikBCA "Mutasi Rekening": Tanggal Transaksi, Keterangan, Cabang, Jumlah, DB/CR, Saldo.
+		ID:                 "bca",
+		Name:               "BCA",
+		BankName:           "BCA",
+		BuiltIn:            true,
+		Delimiter:          ",",
+		DateColumn:         "Tanggal Transaksi",
+		DateFormat:         "DD/MM/YYYY",
+		DescriptionColumns: []string{"Keterangan"},
+		AmountColumn:       "Jumlah",
+		IndicatorColumn:    "5",
+		DecimalSeparator:   ".",
+	},
+	{
+		// Livin' by Mandiri: Tanggal, Keterangan, Debit, Kredit, Saldo.
+		ID:                 "mandiri",
+		Name:               "Mandiri",
+		BankName:           "Mandiri",
+		BuiltIn:            true,
+		Delimiter:          ",",
+		DateColumn:         "Tanggal",
+		DateFormat:         "DD/MM/YYYY",
+		DescriptionColumns: []string{"Keterangan"},
+		DebitColumn:        "Debit",
+		CreditColumn:       "Kredit",
+		DecimalSeparator:   ".",
+	},
+	{
+		// BNI Internet Banking: Tanggal, Uraian Transaksi, Tipe (D/K), Nominal, Saldo.
+		ID:                 "bni",
+		Name:               "BNI",
+		BankName:           "BNI",
+		BuiltIn:            true,
+		Delimiter:          ",",
+		DateColumn:         "Tanggal",
+		DateFormat:         "DD/MM/YYYY",
+		DescriptionColumns: []string{"Uraian Transaksi"},
+		AmountColumn:       "Nominal",
+		IndicatorColumn:    "Tipe",
+		DecimalSeparator:   ".",
+	},
+}
+
+// BuiltInProfiles returns copies of the shipped bank profiles.
+func BuiltInProfiles() []models.ImportProfile {
+	out := make([]models.ImportProfile, len(builtInProfiles))
+	for i, p := range builtInProfiles {
+		p.DescriptionColumns = append([]string(nil), p.DescriptionColumns...)
+		out[i] = p
+	}
+	return out
+}
+
+// BuiltInProfile looks up a shipped profile by id (case-insensitive).
+func BuiltInProfile(id string) (models.ImportProfile, bool) {
+	for _, p := range BuiltInProfiles() {
+		if strings.EqualFold(p.ID, strings.TrimSpace(id)) {
+			return p, true
+		}
+	}
+	return models.ImportProfile{}, false
+}
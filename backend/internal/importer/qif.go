@@ -0,0 +1,155 @@
+package importer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DefaultQIFDateFormat is the month-first order Quicken writes; other tools follow the system locale,
+// so callers can pass DD/MM/YYYY instead.
+const DefaultQIFDateFormat = "MM/DD/YYYY"
+
+// ParseQIF reads the transactions of a QIF bank, cash or credit-card register. Dates follow
+// dateFormat (DD, MM and YYYY tokens; single-digit days/months and 'YY years are accepted).
+// Split lines are ignored in favour of the record total, and !Account list blocks are skipped.
+func ParseQIF(r io.Reader, dateFormat string) ([]Row, error) {
+	if dateFormat == "" {
+		dateFormat = DefaultQIFDateFormat
+	}
+	// Quicken pads and abbreviates ("3/ 1'26"), so parse with the unpadded tokens.
+	loose := strings.NewReplacer("DD", "D", "MM", "M").Replace(strings.ToUpper(dateFormat))
+	long, err := dateLayout(loose)
+	if err != nil {
+		return nil, err
+	}
+	short, _ := dateLayout(strings.Replace(loose, "YYYY", "YY", 1))
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	var (
+		rows     []Row
+		fields   map[byte]string
+		start    int
+		line     int
+		sawType  bool
+		skipping bool
+	)
+	flush := func() error {
+		if len(fields) == 0 {
+			return nil
+		}
+		if len(rows) >= MaxRows {
+			return fmt.Errorf("file has more than %d rows", MaxRows)
+		}
+		rows = append(rows, qifRow(fields, start, long, short))
+		fields = nil
+		return nil
+	}
+	for sc.Scan() {
+		line++
+		text := strings.TrimRight(sc.Text(), "\r")
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\xef\xbb\xbf")
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		if text[0] == '!' {
+			header := strings.ToLower(strings.TrimSpace(text))
+			switch {
+			case header == "!account":
+				skipping = true
+			case strings.HasPrefix(header, "!type:"):
+				kind := strings.TrimPrefix(header, "!type:")
+				skipping = kind != "bank" && kind != "cash" && kind != "ccard" && kind != "oth a" && kind != "oth l"
+				sawType = true
+			case strings.HasPrefix(header, "!option:") || strings.HasPrefix(header, "!clear:"):
+			default:
+				skipping = true
+			}
+			fields = nil
+			continue
+		}
+		if skipping {
+			continue
+		}
+		if text[0] == '^' {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if fields == nil {
+			fields = map[byte]string{}
+			start = line
+		}
+		code, value := text[0], strings.TrimSpace(text[1:])
+		if _, seen := fields[code]; !seen {
+			fields[code] = value
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	if !sawType {
+		return nil, errors.New("not a QIF file (missing !Type header)")
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("file has no transactions")
+	}
+	return rows, nil
+}
+
+func qifRow(f map[byte]string, line int, long, short string) Row {
+	row := Row{Line: line}
+
+	desc := f['P']
+	if desc == "" {
+		desc = f['M']
+	} else if m := f['M']; m != "" && !strings.Contains(desc, m) {
+		desc += " " + m
+	}
+	row.Description = truncate(strings.Join(strings.Fields(desc), " "))
+
+	// L is "Category:Subcategory" (a trailing "/Class" is dropped) or "[Other account]" for
+	// transfers, which have no category here.
+	if cat := f['L']; cat != "" && !strings.HasPrefix(cat, "[") {
+		if i := strings.Index(cat, "/"); i >= 0 {
+			cat = cat[:i]
+		}
+		row.Category = strings.TrimSpace(cat)
+	}
+
+	d := strings.ReplaceAll(strings.ReplaceAll(f['D'], " ", ""), "'", "/")
+	date, err := parseDate(d, long)
+	if err != nil {
+		if date, err = parseDate(d, short); err != nil {
+			row.Error = fmt.Sprintf("invalid date %q", f['D'])
+			return row
+		}
+	}
+	row.Date = date
+
+	amount := f['T']
+	if amount == "" {
+		amount = f['U']
+	}
+	if row.Amount, err = parseCents(amount, "."); err != nil {
+		row.Error = err.Error()
+		return row
+	}
+	if err := checkNonZero(row.Amount); err != nil {
+		row.Error = err.Error()
+		return row
+	}
+	if row.Description == "" {
+		row.Error = "description is empty"
+	}
+	return row
+}
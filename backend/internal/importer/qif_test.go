@@ -0,0 +1,83 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseQIF(t *testing.T) {
+	tests := []struct {
+		name       string
+		dateFormat string
+		file       string
+		want       []Row
+	}{
+		{
+			name: "quicken padding, short years, categories and transfers",
+			file: "!Type:Bank\n" +
+				"D3/ 1'26\nT-125,000.00\nPAlfamart\nMsabun\nLRumah:Galon/Keluarga\n^\n" +
+				"D03/02/2026\nU500000\nPTransfer\nL[BCA]\n^\n" +
+				"D3/3/2026\nT-10\nMHanya memo\nLMakan\nSMakan\n$-6\nSTransport\n$-4\n^\n",
+			want: []Row{
+				{Line: 2, Date: "2026-03-01", Description: "Alfamart sabun", Amount: -12500000, Category: "Rumah:Galon"},
+				{Line: 8, Date: "2026-03-02", Description: "Transfer", Amount: 50000000},
+				{Line: 13, Date: "2026-03-03", Description: "Hanya memo", Amount: -1000, Category: "Makan"},
+			},
+		},
+		{
+			name:       "day-first dates",
+			dateFormat: "DD/MM/YYYY",
+			file:       "!Type:CCard\nD31/01/2026\nT-99.99\nPNetflix\n^\n",
+			want: []Row{
+				{Line: 2, Date: "2026-01-31", Description: "Netflix", Amount: -9999},
+			},
+		},
+		{
+			name: "account lists and investment registers are skipped",
+			file: "!Account\nNBCA\nTBank\n^\n!Type:Invst\nD1/2/2026\nT-1\nPBuy\n^\n" +
+				"!Type:Cash\nD1/2/2026\nT-1\nPParkir\n^\n",
+			want: []Row{
+				{Line: 11, Date: "2026-01-02", Description: "Parkir", Amount: -100},
+			},
+		},
+		{
+			name: "bad rows carry errors",
+			file: "!Type:Bank\nD13/45/2026\nT-1\nPA\n^\nD1/2/2026\nT0\nPB\n^\nD1/2/2026\nT1\n^\n",
+			want: []Row{
+				{Line: 2, Description: "A", Error: `invalid date "13/45/2026"`},
+				{Line: 6, Date: "2026-01-02", Description: "B", Error: "amount is zero"},
+				{Line: 10, Date: "2026-01-02", Amount: 100, Error: "description is empty"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseQIF(strings.NewReader(tt.file), tt.dateFormat)
+			if err != nil {
+				t.Fatalf("ParseQIF: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseQIF =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQIFErrors(t *testing.T) {
+	tests := []struct {
+		name, dateFormat, file, wantErr string
+	}{
+		{"missing type header", "", "D1/2/2026\nT-1\nPA\n^\n", "not a QIF file (missing !Type header)"},
+		{"no transactions", "", "!Type:Bank\n", "file has no transactions"},
+		{"bad date format", "DD/MM", "!Type:Bank\n", "date_format needs day, month and year (e.g. DD/MM/YYYY)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseQIF(strings.NewReader(tt.file), tt.dateFormat)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateRow is one line of an exchange-rate CSV. Lines that could not be parsed carry Error.
+type RateRow struct {
+	Line          int
+	Date          string // YYYY-MM-DD
+	Currency      string
+	QuoteCurrency string // empty when the file has no quote_currency column or leaves it blank
+	Rate          float64
+	Error         string
+}
+
+// ParseRates reads an exchange-rate CSV with a header row naming date, currency and rate columns
+// (any order, case-insensitive) and an optional quote_currency column. Dates are YYYY-MM-DD; rates use
+// "." as the decimal separator.
+func ParseRates(r io.Reader) ([]RateRow, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	cols := map[string]int{"quote_currency": -1}
+	for i, h := range header {
+		cols[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, name := range []string{"date", "currency", "rate"} {
+		if _, ok := cols[name]; !ok {
+			return nil, fmt.Errorf("header must include a %s column", name)
+		}
+	}
+
+	field := func(rec []string, name string) string {
+		i := cols[name]
+		if i < 0 || i >= len(rec) {
+			return ""
+		}
+		return strings.TrimSpace(rec[i])
+	}
+	var rows []RateRow
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := cr.FieldPos(0)
+		if err != nil {
+			var pe *csv.ParseError
+			if errors.As(err, &pe) {
+				rows = append(rows, RateRow{Line: pe.StartLine, Error: pe.Err.Error()})
+				continue
+			}
+			return nil, fmt.Errorf("read file: %w", err)
+		}
+		if len(rows) >= MaxRows {
+			return nil, fmt.Errorf("file has more than %d rows", MaxRows)
+		}
+		if len(rec) == 1 && strings.TrimSpace(rec[0]) == "" {
+			continue
+		}
+		row := RateRow{
+			Line:          line,
+			Date:          field(rec, "date"),
+			Currency:      field(rec, "currency"),
+			QuoteCurrency: field(rec, "quote_currency"),
+		}
+		if _, err := time.Parse("2006-01-02", row.Date); err != nil {
+			row.Error = "date must be YYYY-MM-DD"
+		} else if rate, err := strconv.ParseFloat(field(rec, "rate"), 64); err != nil || !(rate > 0) || math.IsInf(rate, 1) {
+			row.Error = "rate must be a positive number"
+		} else {
+			row.Rate = rate
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
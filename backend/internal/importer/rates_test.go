@@ -0,0 +1,54 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseRates(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		want    []RateRow
+		wantErr string
+	}{
+		{
+			name: "columns in any order with a quote currency",
+			file: "\xef\xbb\xbfRate,Date,Currency,Quote_Currency\n16250.5,2026-03-01,USD,IDR\n0.00006,2026-03-01,IDR,\n",
+			want: []RateRow{
+				{Line: 2, Date: "2026-03-01", Currency: "USD", QuoteCurrency: "IDR", Rate: 16250.5},
+				{Line: 3, Date: "2026-03-01", Currency: "IDR", Rate: 0.00006},
+			},
+		},
+		{
+			name: "bad lines are reported, blank lines skipped",
+			file: "date,currency,rate\n01/03/2026,USD,16000\n\n2026-03-02,USD,-1\n2026-03-03,USD,abc\n2026-03-04,SGD,12000\n",
+			want: []RateRow{
+				{Line: 2, Date: "01/03/2026", Currency: "USD", Error: "date must be YYYY-MM-DD"},
+				{Line: 4, Date: "2026-03-02", Currency: "USD", Error: "rate must be a positive number"},
+				{Line: 5, Date: "2026-03-03", Currency: "USD", Error: "rate must be a positive number"},
+				{Line: 6, Date: "2026-03-04", Currency: "SGD", Rate: 12000},
+			},
+		},
+		{name: "missing rate column", file: "date,currency\n2026-03-01,USD\n", wantErr: "header must include a rate column"},
+		{name: "empty file", file: "", wantErr: "file is empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRates(strings.NewReader(tt.file))
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRates: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRates =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -16,6 +16,113 @@ type CreateTransactionRequest struct {
 	Item                 *string    `json:"item,omitempty"`
 	Quantity             *string    `json:"quantity,omitempty"`
 	Store                *string    `json:"store,omitempty"`
+	CommonPurchaseID     *uuid.UUID `json:"common_purchase_id,omitempty"` // preset on budget_id this expense was logged from
+	AllowDuplicate       bool       `json:"allow_duplicate,omitempty"`    // save even if it looks like an existing transaction
+
+	// A purchase in another currency than the account's: magnitude_amount_cents is in currency and is
+	// converted at the rate on transaction_date, unless account_amount_cents gives the amount charged.
+	Currency           string `json:"currency,omitempty"`
+	AccountAmountCents *int64 `json:"account_amount_cents,omitempty"`
+}
+
+// UpdateTransactionRequest is the body for PATCH /api/transactions/{id}.
+// Omitted fields keep their stored value; the same rules as CreateTransactionRequest apply to the merged result.
+// Set remove_budget to drop the budget_transactions link instead of keeping or moving it.
+type UpdateTransactionRequest struct {
+	AccountID            *uuid.UUID `json:"account_id,omitempty"`
+	CategoryID           *uuid.UUID `json:"category_id,omitempty"`
+	MagnitudeAmountCents *int64     `json:"magnitude_amount_cents,omitempty"`
+	Description          *string    `json:"description,omitempty"`
+	TransactionType      *string    `json:"transaction_type,omitempty"`
+	TransactionDate      *string    `json:"transaction_date,omitempty"`
+	LocationName         *string    `json:"location_name,omitempty"`
+	BudgetID             *uuid.UUID `json:"budget_id,omitempty"`
+	RemoveBudget         bool       `json:"remove_budget,omitempty"`
+	Item                 *string    `json:"item,omitempty"`
+	Quantity             *string    `json:"quantity,omitempty"`
+	Store                *string    `json:"store,omitempty"`
+
+	Currency           *string `json:"currency,omitempty"` // "" makes the amount the account's own again
+	AccountAmountCents *int64  `json:"account_amount_cents,omitempty"`
+}
+
+// CreateTransferRequest is the body for POST /api/transfers.
+// Amounts are positive cents; the fee is posted as a separate expense on the source account.
+type CreateTransferRequest struct {
+	FromAccountID        uuid.UUID  `json:"from_account_id"`
+	ToAccountID          uuid.UUID  `json:"to_account_id"`
+	MagnitudeAmountCents int64      `json:"magnitude_amount_cents"`
+	TransferFeeCents     int64      `json:"transfer_fee_cents,omitempty"`
+	FeeCategoryID        *uuid.UUID `json:"fee_category_id,omitempty"` // default: system "Biaya Admin & Transfer"
+	Description          string     `json:"description,omitempty"`     // default: "Transfer"
+	TransactionDate      string     `json:"transaction_date"`          // YYYY-MM-DD
+	Notes                *string    `json:"notes,omitempty"`
+
+	// Between accounts in different currencies: the amount credited to to_account_id (default: converted
+	// at the rate on transaction_date).
+	ToAmountCents *int64 `json:"to_amount_cents,omitempty"`
+}
+
+// CreateRecurringTransactionRequest is the body for POST /api/recurring-transactions.
+// The first occurrence is posted on start_date; monthly/yearly rules keep start_date's day of month.
+type CreateRecurringTransactionRequest struct {
+	AccountID            uuid.UUID `json:"account_id"`
+	CategoryID           uuid.UUID `json:"category_id"`
+	MagnitudeAmountCents int64     `json:"magnitude_amount_cents"`
+	Description          string    `json:"description"`
+	TransactionType      string    `json:"transaction_type"`             // income | expense
+	Frequency            string    `json:"frequency"`                    // daily | weekly | monthly | yearly
+	FrequencyInterval    int       `json:"frequency_interval,omitempty"` // every N periods, default 1
+	StartDate            string    `json:"start_date"`                   // YYYY-MM-DD
+	EndDate              *string   `json:"end_date,omitempty"`           // YYYY-MM-DD, inclusive
+}
+
+// UpdateRecurringTransactionRequest is the body for PATCH /api/recurring-transactions/{id}.
+// An empty end_date clears it; next_occurrence_date can skip ahead (or re-arm) the schedule.
+type UpdateRecurringTransactionRequest struct {
+	AccountID            *uuid.UUID `json:"account_id,omitempty"`
+	CategoryID           *uuid.UUID `json:"category_id,omitempty"`
+	MagnitudeAmountCents *int64     `json:"magnitude_amount_cents,omitempty"`
+	Description          *string    `json:"description,omitempty"`
+	TransactionType      *string    `json:"transaction_type,omitempty"`
+	Frequency            *string    `json:"frequency,omitempty"`
+	FrequencyInterval    *int       `json:"frequency_interval,omitempty"`
+	EndDate              *string    `json:"end_date,omitempty"`
+	NextOccurrenceDate   *string    `json:"next_occurrence_date,omitempty"`
+	IsActive             *bool      `json:"is_active,omitempty"`
+}
+
+// CreateIncomeSourceRequest is the body for POST /api/income-sources.
+// next_expected_date anchors the schedule: its day of month (monthly), weekday (weekly) or month (yearly).
+type CreateIncomeSourceRequest struct {
+	CategoryID       uuid.UUID  `json:"category_id"`
+	Name             string     `json:"name"`
+	Amount           int64      `json:"amount"`      // expected amount in cents
+	Frequency        string     `json:"frequency"`   // daily | weekly | monthly | yearly | irregular
+	SourceType       string     `json:"source_type"` // salary | freelance | investment | business | other
+	EmployerName     *string    `json:"employer_name,omitempty"`
+	AccountID        *uuid.UUID `json:"account_id,omitempty"` // default receiving account
+	NextExpectedDate *string    `json:"next_expected_date,omitempty"`
+}
+
+// UpdateIncomeSourceRequest is the body for PATCH /api/income-sources/{id}.
+// Empty strings clear employer_name and next_expected_date; clear_account unsets account_id.
+type UpdateIncomeSourceRequest struct {
+	CategoryID       *uuid.UUID `json:"category_id,omitempty"`
+	Name             *string    `json:"name,omitempty"`
+	Amount           *int64     `json:"amount,omitempty"`
+	Frequency        *string    `json:"frequency,omitempty"`
+	SourceType       *string    `json:"source_type,omitempty"`
+	EmployerName     *string    `json:"employer_name,omitempty"`
+	AccountID        *uuid.UUID `json:"account_id,omitempty"`
+	ClearAccount     bool       `json:"clear_account,omitempty"`
+	NextExpectedDate *string    `json:"next_expected_date,omitempty"`
+	IsActive         *bool      `json:"is_active,omitempty"`
+}
+
+// UpdateAlertRequest is the body for PATCH /api/alerts/{id}.
+type UpdateAlertRequest struct {
+	IsRead bool `json:"is_read"`
 }
 
 // CreateBudgetRequest is the body for POST /api/budgets.
@@ -27,6 +134,8 @@ type CreateBudgetRequest struct {
 	PeriodStartDate   string                        `json:"period_start_date"`
 	PeriodEndDate     string                        `json:"period_end_date"`
 	ResponsiblePerson *string                       `json:"responsible_person,omitempty"`
+	AutoReset         *bool                         `json:"auto_reset,omitempty"` // default true
+	CarryOver         *string                       `json:"carry_over,omitempty"` // none (default) | unspent | overspent | both
 	AlertPercentage   *int                          `json:"alert_percentage,omitempty"`
 	Icon              *string                       `json:"icon,omitempty"`
 	Color             *string                       `json:"color,omitempty"`
@@ -49,29 +158,118 @@ type AppendCommonPurchasesRequest struct {
 	Purchases []CreateCommonPurchaseInput `json:"purchases"`
 }
 
+// UpdateCommonPurchaseRequest is the body for PATCH /api/budgets/:id/common-purchases/:purchaseId.
+// Omitted fields are left unchanged; an empty quantity or store clears it.
+type UpdateCommonPurchaseRequest struct {
+	Item             *string `json:"item,omitempty"`
+	Quantity         *string `json:"quantity,omitempty"`
+	EstimatedAmount  *int64  `json:"estimated_amount,omitempty"`
+	Store            *string `json:"store,omitempty"`
+	IsFrequentlyUsed *bool   `json:"is_frequently_used,omitempty"`
+	SortOrder        *int    `json:"sort_order,omitempty"`
+}
+
+// ReorderCommonPurchasesRequest is the body for PUT /api/budgets/:id/common-purchases/order.
+type ReorderCommonPurchasesRequest struct {
+	PurchaseIDs []uuid.UUID `json:"purchase_ids"`
+}
+
+// LogCommonPurchaseRequest is the body for POST /api/budgets/:id/common-purchases/:purchaseId/log.
+// Every field is optional: the amount defaults to the preset's estimated_amount, the account to the one
+// used the last time this preset was logged, the date to today and the description to the item.
+type LogCommonPurchaseRequest struct {
+	AccountID            *uuid.UUID `json:"account_id,omitempty"`
+	MagnitudeAmountCents *int64     `json:"magnitude_amount_cents,omitempty"`
+	TransactionDate      *string    `json:"transaction_date,omitempty"`
+	Description          *string    `json:"description,omitempty"`
+	AllowDuplicate       bool       `json:"allow_duplicate,omitempty"`
+}
+
+// LogCommonPurchasePayload is the response for a logged preset purchase.
+type LogCommonPurchasePayload struct {
+	TransactionID string                  `json:"transaction_id"`
+	Purchase      BudgetCommonPurchaseAPI `json:"purchase"`
+}
+
+// CommonPurchasesPayload for GET /api/budgets/:id/common-purchases.
+type CommonPurchasesPayload struct {
+	Purchases []BudgetCommonPurchaseAPI `json:"purchases"`
+}
+
 // CreateAccountRequest is the body for POST /api/accounts.
+// A non-zero opening_balance (signed cents) is posted on opening_balance_date (default today).
 type CreateAccountRequest struct {
-	Name        string  `json:"name"`
-	AccountType string  `json:"account_type,omitempty"` // bank | credit_card | cash | investment | ewallet — default cash
-	Color       string  `json:"color,omitempty"`
+	Name               string  `json:"name"`
+	AccountType        string  `json:"account_type,omitempty"` // bank | credit_card | cash | investment | ewallet — default cash
+	Color              string  `json:"color,omitempty"`
+	BankName           *string `json:"bank_name,omitempty"`
+	AccountNumber      *string `json:"account_number,omitempty"`
+	CreditLimit        *int64  `json:"credit_limit,omitempty"` // cents, credit_card only
+	IsDefault          bool    `json:"is_default,omitempty"`
+	OpeningBalance     *int64  `json:"opening_balance,omitempty"`
+	OpeningBalanceDate *string `json:"opening_balance_date,omitempty"` // YYYY-MM-DD
+	Currency           string  `json:"currency,omitempty"`             // ISO 4217, default the user's base currency
+
+	// credit_card only
+	StatementClosingDay   *int   `json:"statement_closing_day,omitempty"` // 1-31
+	PaymentDueDay         *int   `json:"payment_due_day,omitempty"`       // 1-31
+	MinimumPaymentPercent *int   `json:"minimum_payment_percent,omitempty"`
+	MinimumPaymentFloor   *int64 `json:"minimum_payment_floor,omitempty"` // cents
+}
+
+// UpdateAccountRequest is the body for PATCH /api/accounts/{id}. Omitted fields are left unchanged;
+// empty strings clear bank_name and account_number, clear_credit_limit unsets credit_limit, 0 unsets a
+// statement cycle day, and opening_balance 0 removes the opening balance entry.
+type UpdateAccountRequest struct {
+	Name               *string `json:"name,omitempty"`
+	AccountType        *string `json:"account_type,omitempty"`
+	Color              *string `json:"color,omitempty"`
+	BankName           *string `json:"bank_name,omitempty"`
+	AccountNumber      *string `json:"account_number,omitempty"`
+	CreditLimit        *int64  `json:"credit_limit,omitempty"`
+	ClearCreditLimit   bool    `json:"clear_credit_limit,omitempty"`
+	IsDefault          *bool   `json:"is_default,omitempty"`
+	OpeningBalance     *int64  `json:"opening_balance,omitempty"`
+	OpeningBalanceDate *string `json:"opening_balance_date,omitempty"`
+	Currency           *string `json:"currency,omitempty"` // only while the account has no transactions
+
+	StatementClosingDay   *int   `json:"statement_closing_day,omitempty"`
+	PaymentDueDay         *int   `json:"payment_due_day,omitempty"`
+	MinimumPaymentPercent *int   `json:"minimum_payment_percent,omitempty"`
+	MinimumPaymentFloor   *int64 `json:"minimum_payment_floor,omitempty"`
 }
 
 // AccountSummary for GET /api/accounts.
 type AccountSummary struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	AccountType string `json:"account_type"`
-	Balance     int64  `json:"balance"`
-	Color       string `json:"color"`
+	ID                 string `json:"id"`
+	Name               string `json:"name"`
+	AccountType        string `json:"account_type"`
+	Balance            int64  `json:"balance"`
+	Color              string `json:"color"`
+	BankName           string `json:"bank_name,omitempty"`
+	AccountNumber      string `json:"account_number,omitempty"`
+	CreditLimit        *int64 `json:"credit_limit,omitempty"`
+	IsDefault          bool   `json:"is_default"`
+	IsActive           bool   `json:"is_active"`
+	OpeningBalance     int64  `json:"opening_balance"`                // signed cents, 0 when none was set
+	OpeningBalanceDate string `json:"opening_balance_date,omitempty"` // YYYY-MM-DD
+	Currency           string `json:"currency"`
+
+	// Credit cards: credit_limit + balance, and the statement cycle days
+	AvailableCredit     *int64 `json:"available_credit,omitempty"`
+	StatementClosingDay *int   `json:"statement_closing_day,omitempty"`
+	PaymentDueDay       *int   `json:"payment_due_day,omitempty"`
 }
 
 // CategorySummary for GET /api/categories.
 type CategorySummary struct {
-	ID           string `json:"id"`
-	Name         string `json:"name"`
-	CategoryType string `json:"category_type"`
-	Icon         string `json:"icon"`
-	IsSystem     bool   `json:"is_system"`
+	ID               string `json:"id"`
+	Name             string `json:"name"`
+	CategoryType     string `json:"category_type"`
+	Icon             string `json:"icon"`
+	IsSystem         bool   `json:"is_system"`
+	ParentCategoryID string `json:"parent_category_id,omitempty"`
+	Color            string `json:"color"`
 }
 
 // BudgetCommonPurchaseAPI is a preset row for BudgetCategoryCard.
@@ -83,6 +281,8 @@ type BudgetCommonPurchaseAPI struct {
 	Store            string `json:"store,omitempty"`
 	IsFrequentlyUsed bool   `json:"is_frequently_used"`
 	SortOrder        int    `json:"sort_order"`
+	UseCount         int    `json:"use_count"`              // expenses logged from this preset
+	LastUsedAt       string `json:"last_used_at,omitempty"` // date of the latest logged expense
 }
 
 // BudgetLineItemAPI is a linked expense line with item metadata.
@@ -109,6 +309,11 @@ type BudgetCardAPI struct {
 	BudgetPeriod     string                     `json:"budget_period"`
 	PeriodStartDate  string                     `json:"period_start_date"`
 	PeriodEndDate    string                     `json:"period_end_date"`
+	IsActive         bool                       `json:"is_active"`
+	AutoReset        bool                       `json:"auto_reset"`
+	CarryOver        string                     `json:"carry_over"`
+	CarryoverAmount  int64                      `json:"carryover_amount"`  // signed cents carried into this period
+	LastPeriodSpent  int64                      `json:"last_period_spent"` // from budget_period_history
 	CategoryID       string                     `json:"category_id"`
 	CommonPurchases  []BudgetCommonPurchaseAPI  `json:"common_purchases"`
 	LineItems        []BudgetLineItemAPI        `json:"line_items"`
@@ -118,3 +323,181 @@ type BudgetCardAPI struct {
 type BudgetsPayload struct {
 	Budgets []BudgetCardAPI `json:"budgets"`
 }
+
+// BudgetPeriodHistoryAPI is one closed budget window.
+type BudgetPeriodHistoryAPI struct {
+	PeriodStartDate string `json:"period_start_date"`
+	PeriodEndDate   string `json:"period_end_date"`
+	AllocatedAmount int64  `json:"allocated_amount"`
+	CarryoverIn     int64  `json:"carryover_in"`
+	SpentAmount     int64  `json:"spent_amount"`
+	CarryoverOut    int64  `json:"carryover_out"` // carried into the following window
+}
+
+// BudgetRolloverPayload is returned by POST /api/budgets/{id}/rollover.
+type BudgetRolloverPayload struct {
+	Budget        *Budget                  `json:"budget"`
+	ClosedPeriods []BudgetPeriodHistoryAPI `json:"closed_periods"`
+}
+
+// MergeDuplicateTransactionsRequest is the body for POST /api/transactions/duplicates/merge.
+// RemoveID is deleted; its notes, location and budget line fill whatever KeepID lacks.
+type MergeDuplicateTransactionsRequest struct {
+	KeepID   uuid.UUID `json:"keep_id"`
+	RemoveID uuid.UUID `json:"remove_id"`
+}
+
+// DismissDuplicateTransactionsRequest is the body for POST /api/transactions/duplicates/dismiss:
+// the pair is not a duplicate and stops being suggested.
+type DismissDuplicateTransactionsRequest struct {
+	TransactionIDs []uuid.UUID `json:"transaction_ids"` // exactly two
+}
+
+// StartReconciliationRequest is the body for POST /api/accounts/{id}/reconciliations.
+type StartReconciliationRequest struct {
+	StatementDate    string `json:"statement_date"`    // YYYY-MM-DD, the statement's end date
+	StatementBalance int64  `json:"statement_balance"` // signed cents, the statement's ending balance
+}
+
+// UpdateReconciliationRequest is the body for PATCH /api/reconciliations/{id} (open ones only).
+type UpdateReconciliationRequest struct {
+	StatementDate    *string `json:"statement_date,omitempty"`
+	StatementBalance *int64  `json:"statement_balance,omitempty"`
+}
+
+// ClearReconciliationTransactionsRequest is the body for POST /api/reconciliations/{id}/cleared:
+// the listed transactions are marked cleared (or, with cleared false, uncleared).
+type ClearReconciliationTransactionsRequest struct {
+	TransactionIDs []uuid.UUID `json:"transaction_ids"`
+	Cleared        bool        `json:"cleared"`
+}
+
+// CompleteReconciliationRequest is the body for POST /api/reconciliations/{id}/complete. With a
+// non-zero difference it is refused unless Adjust asks for an adjustment row closing the gap.
+type CompleteReconciliationRequest struct {
+	Adjust bool `json:"adjust"`
+}
+
+// PayCreditCardRequest is the body for POST /api/credit-cards/{id}/pay, a transfer from a bank, e-wallet
+// or cash account into the card. amount_cents wins over pay, which picks the remaining statement
+// balance (statement, the default), the remaining minimum payment (minimum) or everything owed (full).
+type PayCreditCardRequest struct {
+	FromAccountID    uuid.UUID `json:"from_account_id"`
+	Pay              string    `json:"pay,omitempty"`
+	AmountCents      int64     `json:"amount_cents,omitempty"`
+	TransactionDate  string    `json:"transaction_date,omitempty"` // YYYY-MM-DD, default today
+	TransferFeeCents int64     `json:"transfer_fee_cents,omitempty"`
+	Notes            *string   `json:"notes,omitempty"`
+}
+
+// CreateExchangeRateRequest is the body for POST /api/exchange-rates. rate units of quote_currency
+// (default the base currency) buy one unit of currency from rate_date (default today).
+type CreateExchangeRateRequest struct {
+	Currency      string  `json:"currency"`
+	QuoteCurrency string  `json:"quote_currency,omitempty"`
+	Rate          float64 `json:"rate"`
+	RateDate      string  `json:"rate_date,omitempty"` // YYYY-MM-DD
+}
+
+// SetBaseCurrencyRequest is the body for PUT /api/exchange-rates/base-currency.
+type SetBaseCurrencyRequest struct {
+	BaseCurrency string `json:"base_currency"`
+}
+
+// CreateInstrumentRequest is the body for POST /api/instruments.
+type CreateInstrumentRequest struct {
+	Symbol         string `json:"symbol"`               // e.g. BBCA, ANTM-GOLD; unique per user
+	Name           string `json:"name"`                 // default: symbol
+	InstrumentType string `json:"instrument_type"`      // mutual_fund | stock | gold | bond | other
+	Currency       string `json:"currency,omitempty"`   // default the user's base currency
+	UnitLabel      string `json:"unit_label,omitempty"` // default "unit"
+}
+
+// SaveInstrumentPriceRequest is the body for POST /api/instruments/{id}/prices. It replaces any price
+// already stored for price_date.
+type SaveInstrumentPriceRequest struct {
+	Price     int64  `json:"price"`                // cents per unit
+	PriceDate string `json:"price_date,omitempty"` // YYYY-MM-DD, default today
+}
+
+// CreateInvestmentTradeRequest is the body for POST /api/accounts/{id}/trades. amount is the gross value
+// in cents: what the units cost (buy), what they sold for (sell) or the dividend paid. fee is added to a
+// buy's cost and taken from a sale or dividend; the net cash moves in or out of the account.
+type CreateInvestmentTradeRequest struct {
+	InstrumentID uuid.UUID  `json:"instrument_id"`
+	TradeType    string     `json:"trade_type"` // buy | sell | dividend
+	Units        float64    `json:"units,omitempty"`
+	Amount       int64      `json:"amount"`
+	Fee          int64      `json:"fee,omitempty"`
+	TradeDate    string     `json:"trade_date,omitempty"`  // YYYY-MM-DD, default today
+	CategoryID   *uuid.UUID `json:"category_id,omitempty"` // dividends; default system "Dividen Saham"
+	Notes        *string    `json:"notes,omitempty"`
+}
+
+// CreateCategoryRequest is the body for POST /api/categories. A subcategory takes its type from the
+// parent, which must be a top-level system or own category.
+type CreateCategoryRequest struct {
+	Name             string     `json:"name"`
+	CategoryType     string     `json:"category_type"` // income | expense; optional with a parent
+	ParentCategoryID *uuid.UUID `json:"parent_category_id,omitempty"`
+	Icon             string     `json:"icon,omitempty"`  // default "dollar-sign"
+	Color            string     `json:"color,omitempty"` // default "#6B7280"
+}
+
+// UpdateCategoryRequest is the body for PATCH /api/categories/{id}; only user categories can be edited.
+// Omitted fields are left unchanged and clear_parent moves a subcategory to the top level.
+type UpdateCategoryRequest struct {
+	Name             *string    `json:"name,omitempty"`
+	ParentCategoryID *uuid.UUID `json:"parent_category_id,omitempty"`
+	ClearParent      bool       `json:"clear_parent,omitempty"`
+	Icon             *string    `json:"icon,omitempty"`
+	Color            *string    `json:"color,omitempty"`
+}
+
+// CategorizationRuleRequest is the body for POST /api/categorization-rules and PATCH
+// /api/categorization-rules/{id}; on PATCH omitted fields keep their value and the clear_* flags remove
+// optional conditions. Amounts are magnitudes in cents. budget_id implies its category.
+type CategorizationRuleRequest struct {
+	Name         *string    `json:"name,omitempty"`
+	Priority     *int       `json:"priority,omitempty"`    // lowest is tried first, default 100
+	MatchField   *string    `json:"match_field,omitempty"` // description (default) | store | location | any
+	MatchType    *string    `json:"match_type,omitempty"`  // contains (default) | regex; both ignore case
+	Pattern      *string    `json:"pattern,omitempty"`
+	MinAmount    *int64     `json:"min_amount,omitempty"`
+	MaxAmount    *int64     `json:"max_amount,omitempty"`
+	ClearAmounts bool       `json:"clear_amounts,omitempty"`
+	AccountID    *uuid.UUID `json:"account_id,omitempty"`
+	ClearAccount bool       `json:"clear_account,omitempty"`
+	CategoryID   *uuid.UUID `json:"category_id,omitempty"`
+	BudgetID     *uuid.UUID `json:"budget_id,omitempty"`
+	ClearBudget  bool       `json:"clear_budget,omitempty"`
+	Item         *string    `json:"item,omitempty"` // budget line item name, requires budget_id
+	Tags         *[]string  `json:"tags,omitempty"`
+	IsActive     *bool      `json:"is_active,omitempty"`
+}
+
+// CategorizationRuleTestRequest is the sample for POST /api/categorization-rules/test.
+type CategorizationRuleTestRequest struct {
+	Description          string     `json:"description"`
+	Store                string     `json:"store,omitempty"`
+	LocationName         string     `json:"location_name,omitempty"`
+	MagnitudeAmountCents int64      `json:"magnitude_amount_cents,omitempty"`
+	AccountID            *uuid.UUID `json:"account_id,omitempty"`
+	TransactionType      string     `json:"transaction_type,omitempty"` // expense (default) | income
+}
+
+// ApplyCategorizationRulesRequest is the body for POST /api/categorization-rules/apply. from and to
+// (YYYY-MM-DD, inclusive) are optional; overwrite also replaces categories other than the fallback ones.
+type ApplyCategorizationRulesRequest struct {
+	From      string     `json:"from,omitempty"`
+	To        string     `json:"to,omitempty"`
+	AccountID *uuid.UUID `json:"account_id,omitempty"`
+	Overwrite bool       `json:"overwrite,omitempty"`
+	DryRun    bool       `json:"dry_run,omitempty"`
+}
+
+// MergeCategoryRequest is the body for POST /api/categories/{id}/merge; dry_run reports without saving.
+type MergeCategoryRequest struct {
+	TargetCategoryID uuid.UUID `json:"target_category_id"`
+	DryRun           bool      `json:"dry_run,omitempty"`
+}
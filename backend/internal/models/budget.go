@@ -20,6 +20,8 @@ type Budget struct {
 	PeriodEndDate     time.Time    `json:"period_end_date" db:"period_end_date"`
 	ResponsiblePerson *string      `json:"responsible_person,omitempty" db:"responsible_person"`
 	AutoReset         bool         `json:"auto_reset" db:"auto_reset"`
+	CarryOver         string       `json:"carry_over" db:"carry_over"`             // none | unspent | overspent | both
+	CarryoverAmount   int64        `json:"carryover_amount" db:"carryover_amount"` // Signed cents carried into this period
 	AlertPercentage   int          `json:"alert_percentage" db:"alert_percentage"`
 	IsActive          bool         `json:"is_active" db:"is_active"`
 
@@ -117,13 +119,19 @@ type BudgetWithStats struct {
 	RecentTransactions []BudgetTransactionView `json:"recent_transactions"`
 }
 
-// UpdateBudgetRequest represents the request payload for updating budgets
+// UpdateBudgetRequest represents the request payload for updating budgets (PATCH /api/budgets/{id}).
+// Dates are YYYY-MM-DD; an empty responsible_person clears it. Changing category_id or the period
+// window recomputes spent_amount from transactions.
 type UpdateBudgetRequest struct {
 	Name              *string       `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	CategoryID        *uuid.UUID    `json:"category_id,omitempty"`
 	AllocatedAmount   *int64        `json:"allocated_amount,omitempty" validate:"omitempty,min=1"`
 	BudgetPeriod      *BudgetPeriod `json:"budget_period,omitempty" validate:"omitempty,oneof=weekly monthly yearly"`
+	PeriodStartDate   *string       `json:"period_start_date,omitempty"`
+	PeriodEndDate     *string       `json:"period_end_date,omitempty"`
 	ResponsiblePerson *string       `json:"responsible_person,omitempty" validate:"omitempty,max=50"`
 	AutoReset         *bool         `json:"auto_reset,omitempty"`
+	CarryOver         *string       `json:"carry_over,omitempty" validate:"omitempty,oneof=none unspent overspent both"`
 	AlertPercentage   *int          `json:"alert_percentage,omitempty" validate:"omitempty,min=0,max=100"`
 	IsActive          *bool         `json:"is_active,omitempty"`
 	Icon              *string       `json:"icon,omitempty" validate:"omitempty,min=1,max=10"`
@@ -131,6 +139,11 @@ type UpdateBudgetRequest struct {
 	SortOrder         *int          `json:"sort_order,omitempty"`
 }
 
+// ReorderBudgetsRequest is the body for PUT /api/budgets/order: sort_order follows the list position.
+type ReorderBudgetsRequest struct {
+	BudgetIDs []uuid.UUID `json:"budget_ids" validate:"required,min=1"`
+}
+
 // CreateCommonPurchaseRequest represents the request payload for creating common purchases
 type CreateCommonPurchaseRequest struct {
 	Item             string  `json:"item" validate:"required,min=1,max=200"`
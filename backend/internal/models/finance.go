@@ -1,13 +1,29 @@
 package models
 
+import "github.com/google/uuid"
+
 // TransactionAPI is the JSON shape used by list/dashboard endpoints (amounts in cents).
 type TransactionAPI struct {
-	ID          string `json:"id"`
-	Date        string `json:"date"` // YYYY-MM-DD
-	Description string `json:"description"`
-	Category    string `json:"category"`
-	Amount      int64  `json:"amount"` // signed cents
-	Account     string `json:"account"`
+	ID              string   `json:"id"`
+	Date            string   `json:"date"` // YYYY-MM-DD
+	Description     string   `json:"description"`
+	Category        string   `json:"category"`
+	Amount          int64    `json:"amount"` // signed cents
+	Account         string   `json:"account"`
+	AccountID       string   `json:"account_id"`
+	CategoryID      string   `json:"category_id,omitempty"`
+	TransactionType string   `json:"transaction_type"`
+	LocationName    string   `json:"location_name,omitempty"`
+	BudgetID        string   `json:"budget_id,omitempty"`
+	Item            string   `json:"item,omitempty"`
+	Store           string   `json:"store,omitempty"`
+	Cleared         bool     `json:"cleared,omitempty"`    // seen on a bank statement
+	Reconciled      bool     `json:"reconciled,omitempty"` // locked by a completed reconciliation
+	Tags            []string `json:"tags,omitempty"`
+
+	Currency         string `json:"currency,omitempty"` // the account's; amount is in it
+	OriginalAmount   *int64 `json:"original_amount,omitempty"`
+	OriginalCurrency string `json:"original_currency,omitempty"`
 }
 
 // DashboardPayload is returned by GET /api/dashboard.
@@ -15,11 +31,563 @@ type DashboardPayload struct {
 	TotalBalanceCents  int64            `json:"total_balance_cents"`
 	MonthlyNetCents    int64            `json:"monthly_net_cents"` // Sum(amount) for current calendar month
 	RecentTransactions []TransactionAPI `json:"recent_transactions"`
+	BaseCurrency       string           `json:"base_currency"` // the totals are converted into it
+
+	CardPaymentReminders []CardPaymentReminderAPI `json:"card_payment_reminders"` // due within a week or overdue
 }
 
 // TransactionListPayload is returned by GET /api/transactions.
 type TransactionListPayload struct {
 	Transactions      []TransactionAPI `json:"transactions"`
-	MonthIncomeCents  int64            `json:"month_income_cents"`  // current month, positive totals only
-	MonthExpenseCents int64            `json:"month_expense_cents"` // current month, absolute value of negatives
+	TotalCount        int              `json:"total_count"` // rows matching the filters, ignoring limit/offset
+	Limit             int              `json:"limit"`
+	Offset            int              `json:"offset"`                // deprecated: page with cursor instead
+	NextCursor        string           `json:"next_cursor,omitempty"` // pass as ?cursor= for the following page
+	PrevCursor        string           `json:"prev_cursor,omitempty"` // pass as ?cursor= for the preceding page
+	MonthIncomeCents  int64            `json:"month_income_cents"`    // current month, positive totals only
+	MonthExpenseCents int64            `json:"month_expense_cents"`   // current month, absolute value of negatives
+}
+
+// Transaction list sort orders for TransactionFilter.Sort.
+const (
+	TransactionSortDateDesc   = "date_desc" // default: newest first
+	TransactionSortDateAsc    = "date_asc"
+	TransactionSortAmountDesc = "amount_desc" // by absolute amount
+	TransactionSortAmountAsc  = "amount_asc"
+)
+
+// TransactionFilter narrows GET /api/transactions. Zero values mean "no filter"; multi-valued
+// fields match any of their values. Amounts compare against the absolute amount in cents.
+type TransactionFilter struct {
+	From        string // YYYY-MM-DD, inclusive
+	To          string // YYYY-MM-DD, inclusive
+	AccountIDs  []uuid.UUID
+	CategoryIDs []uuid.UUID
+	Types       []string // income | expense | transfer
+	MinAmount   *int64
+	MaxAmount   *int64
+	BudgetID    *uuid.UUID
+	Store       string // substring of the budget line store or the location name
+	Tag         string // one tag, lower case
+	Query       string // free text over description, notes, location, item and store
+	Sort        string
+	Limit       int
+	Cursor      string      // next_cursor/prev_cursor of an earlier page; takes precedence over Offset
+	Offset      int         // deprecated
+	IDs         []uuid.UUID // internal lookups by id; not a query parameter
+}
+
+// DuplicatePairAPI is two transactions that look like the same purchase logged twice.
+type DuplicatePairAPI struct {
+	First  TransactionAPI `json:"first"`
+	Second TransactionAPI `json:"second"`
+}
+
+// DuplicatePairsPayload for GET /api/transactions/duplicates.
+type DuplicatePairsPayload struct {
+	Pairs []DuplicatePairAPI `json:"pairs"`
+}
+
+// TransferAPI is one account-to-account transfer for GET /api/transfers (amounts in positive cents).
+type TransferAPI struct {
+	ID                string `json:"id"`
+	Date              string `json:"date"` // YYYY-MM-DD
+	Description       string `json:"description"`
+	FromAccountID     string `json:"from_account_id"`
+	FromAccount       string `json:"from_account"`
+	ToAccountID       string `json:"to_account_id"`
+	ToAccount         string `json:"to_account"`
+	AmountCents       int64  `json:"amount_cents"`
+	FeeCents          int64  `json:"fee_cents"`
+	Notes             string `json:"notes,omitempty"`
+	FromTransactionID string `json:"from_transaction_id"`
+	ToTransactionID   string `json:"to_transaction_id"`
+	FeeTransactionID  string `json:"fee_transaction_id,omitempty"`
+}
+
+// TransfersPayload is returned by GET /api/transfers.
+type TransfersPayload struct {
+	Transfers []TransferAPI `json:"transfers"`
+}
+
+// RecurringTransactionAPI is one recurring rule for GET /api/recurring-transactions.
+type RecurringTransactionAPI struct {
+	ID                 string `json:"id"`
+	AccountID          string `json:"account_id"`
+	Account            string `json:"account"`
+	CategoryID         string `json:"category_id,omitempty"`
+	Category           string `json:"category"`
+	Amount             int64  `json:"amount"` // signed cents, as posted
+	Description        string `json:"description"`
+	TransactionType    string `json:"transaction_type"`
+	Frequency          string `json:"frequency"`
+	FrequencyInterval  int    `json:"frequency_interval"`
+	StartDate          string `json:"start_date"`
+	EndDate            string `json:"end_date,omitempty"`
+	NextOccurrenceDate string `json:"next_occurrence_date"`
+	IsActive           bool   `json:"is_active"`
+}
+
+// RecurringTransactionsPayload is returned by GET /api/recurring-transactions.
+type RecurringTransactionsPayload struct {
+	RecurringTransactions []RecurringTransactionAPI `json:"recurring_transactions"`
+}
+
+// IncomeSourceAPI is one expected income stream for GET /api/income-sources.
+type IncomeSourceAPI struct {
+	ID               string `json:"id"`
+	CategoryID       string `json:"category_id"`
+	Category         string `json:"category"`
+	Name             string `json:"name"`
+	Amount           int64  `json:"amount"` // expected cents per occurrence
+	Frequency        string `json:"frequency"`
+	SourceType       string `json:"source_type"`
+	EmployerName     string `json:"employer_name,omitempty"`
+	AccountID        string `json:"account_id,omitempty"`
+	Account          string `json:"account,omitempty"`
+	NextExpectedDate string `json:"next_expected_date,omitempty"`
+	IsActive         bool   `json:"is_active"`
+}
+
+// IncomeSourcesPayload is returned by GET /api/income-sources.
+type IncomeSourcesPayload struct {
+	IncomeSources []IncomeSourceAPI `json:"income_sources"`
+}
+
+// IncomeMonthAPI compares one source's expected and received income for a calendar month.
+// Status is received | late | short | missing | pending | unscheduled.
+type IncomeMonthAPI struct {
+	Month             string `json:"month"` // YYYY-MM
+	ExpectedDate      string `json:"expected_date,omitempty"`
+	ExpectedCents     int64  `json:"expected_cents"`
+	ActualCents       int64  `json:"actual_cents"`
+	DifferenceCents   int64  `json:"difference_cents"` // actual - expected
+	TransactionCount  int    `json:"transaction_count"`
+	FirstReceivedDate string `json:"first_received_date,omitempty"`
+	Status            string `json:"status"`
+	IsLate            bool   `json:"is_late"`
+	IsShort           bool   `json:"is_short"`
+}
+
+// IncomeSourceReportAPI is one source with its monthly comparison rows.
+type IncomeSourceReportAPI struct {
+	Source             IncomeSourceAPI  `json:"source"`
+	Months             []IncomeMonthAPI `json:"months"`
+	TotalExpectedCents int64            `json:"total_expected_cents"`
+	TotalActualCents   int64            `json:"total_actual_cents"`
+}
+
+// IncomeReportPayload is returned by GET /api/income-sources/report.
+type IncomeReportPayload struct {
+	From     string                  `json:"from"`     // YYYY-MM
+	To       string                  `json:"to"`       // YYYY-MM
+	Currency string                  `json:"currency"` // actuals are converted into the base currency
+	Sources  []IncomeSourceReportAPI `json:"sources"`
+}
+
+// BudgetAlertAPI is one threshold crossing for GET /api/alerts.
+type BudgetAlertAPI struct {
+	ID               string `json:"id"`
+	BudgetID         string `json:"budget_id"`
+	BudgetName       string `json:"budget_name"`
+	ThresholdPercent int    `json:"threshold_percent"` // alert_percentage or 100
+	SpentAmount      int64  `json:"spent_amount"`
+	AvailableAmount  int64  `json:"available_amount"` // allocated + carry-over at the time of the alert
+	PeriodStartDate  string `json:"period_start_date"`
+	PeriodEndDate    string `json:"period_end_date"`
+	Message          string `json:"message"`
+	IsRead           bool   `json:"is_read"`
+	ReadAt           string `json:"read_at,omitempty"`
+	CreatedAt        string `json:"created_at"`
+}
+
+// AlertsPayload is returned by GET /api/alerts.
+type AlertsPayload struct {
+	Alerts      []BudgetAlertAPI `json:"alerts"`
+	UnreadCount int              `json:"unread_count"`
+}
+
+// ReconciliationAPI is one statement reconciliation. ClearedBalance is the account balance counting
+// only cleared and already reconciled rows up to the statement date; Difference is
+// StatementBalance - ClearedBalance, which completing must bring to zero.
+type ReconciliationAPI struct {
+	ID                      string `json:"id"`
+	AccountID               string `json:"account_id"`
+	StatementDate           string `json:"statement_date"`
+	StatementBalance        int64  `json:"statement_balance"`
+	ClearedBalance          int64  `json:"cleared_balance"`
+	Difference              int64  `json:"difference"`
+	Status                  string `json:"status"` // open | completed
+	AdjustmentTransactionID string `json:"adjustment_transaction_id,omitempty"`
+	AdjustmentAmount        int64  `json:"adjustment_amount,omitempty"`
+	CreatedAt               string `json:"created_at"`
+	CompletedAt             string `json:"completed_at,omitempty"`
+}
+
+// ReconciliationTransactionAPI is an account row up to the statement date still open for matching.
+type ReconciliationTransactionAPI struct {
+	ID          string `json:"id"`
+	Date        string `json:"date"`
+	Description string `json:"description"`
+	Amount      int64  `json:"amount"` // signed cents
+	Cleared     bool   `json:"cleared"`
+}
+
+// ReconciliationPayload is returned by GET /api/reconciliations/{id}. Transactions lists the rows
+// not yet reconciled (empty once the reconciliation is completed).
+type ReconciliationPayload struct {
+	Reconciliation ReconciliationAPI              `json:"reconciliation"`
+	Transactions   []ReconciliationTransactionAPI `json:"transactions"`
+	ClearedCount   int                            `json:"cleared_count"`
+	UnclearedCount int                            `json:"uncleared_count"`
+}
+
+// ReconciliationsPayload is returned by GET /api/accounts/{id}/reconciliations, newest first.
+type ReconciliationsPayload struct {
+	Reconciliations []ReconciliationAPI `json:"reconciliations"`
+}
+
+// CreditCardAPI is a credit card's standing. Owed amounts are positive cents; Statement is set once
+// both statement_closing_day and payment_due_day are.
+type CreditCardAPI struct {
+	AccountID           string                  `json:"account_id"`
+	Name                string                  `json:"name"`
+	BankName            string                  `json:"bank_name,omitempty"`
+	Balance             int64                   `json:"balance"`      // signed cents, negative while money is owed
+	CurrentOwed         int64                   `json:"current_owed"` // everything owed now, billed or not
+	CreditLimit         *int64                  `json:"credit_limit,omitempty"`
+	AvailableCredit     *int64                  `json:"available_credit,omitempty"`
+	UtilizationPercent  *int                    `json:"utilization_percent,omitempty"`
+	StatementClosingDay *int                    `json:"statement_closing_day,omitempty"`
+	PaymentDueDay       *int                    `json:"payment_due_day,omitempty"`
+	Statement           *CreditCardStatementAPI `json:"statement,omitempty"`
+}
+
+// CreditCardStatementAPI is one closed statement cycle and how much of it is still unpaid.
+type CreditCardStatementAPI struct {
+	PeriodStart      string `json:"period_start"` // day after the previous closing
+	ClosingDate      string `json:"closing_date"`
+	DueDate          string `json:"due_date"`
+	NextClosingDate  string `json:"next_closing_date"`
+	StatementBalance int64  `json:"statement_balance"` // owed at closing
+	MinimumPayment   int64  `json:"minimum_payment"`
+	PaidSinceClosing int64  `json:"paid_since_closing"` // payments and refunds dated after closing
+	RemainingDue     int64  `json:"remaining_due"`
+	MinimumRemaining int64  `json:"minimum_remaining"`
+	DaysUntilDue     int    `json:"days_until_due"` // negative once past due
+	Status           string `json:"status"`         // paid | due | overdue
+}
+
+// CreditCardsPayload is returned by GET /api/credit-cards.
+type CreditCardsPayload struct {
+	CreditCards []CreditCardAPI `json:"credit_cards"`
+	TotalOwed   int64           `json:"total_owed"`
+}
+
+// CreditCardStatementPayload is returned by GET /api/credit-cards/{id}/statement: the card as of the
+// chosen statement and the card's transactions in that cycle.
+type CreditCardStatementPayload struct {
+	CreditCard   CreditCardAPI    `json:"credit_card"`
+	Transactions []TransactionAPI `json:"transactions"`
+}
+
+// CreditCardPaymentPayload is returned by POST /api/credit-cards/{id}/pay.
+type CreditCardPaymentPayload struct {
+	TransferID string        `json:"transfer_id"`
+	AmountPaid int64         `json:"amount_paid"`
+	CreditCard CreditCardAPI `json:"credit_card"`
+}
+
+// CardPaymentReminderAPI is a credit card statement coming due (or overdue) on the dashboard.
+type CardPaymentReminderAPI struct {
+	AccountID        string `json:"account_id"`
+	Name             string `json:"name"`
+	DueDate          string `json:"due_date"`
+	DaysUntilDue     int    `json:"days_until_due"`
+	RemainingDue     int64  `json:"remaining_due"`
+	MinimumRemaining int64  `json:"minimum_remaining"`
+	Status           string `json:"status"` // due | overdue
+}
+
+// NetWorthPointAPI is the net worth at the end of one report interval. Liabilities is what is owed on
+// credit cards, as a positive amount; NetWorth is Assets - Liabilities.
+type NetWorthPointAPI struct {
+	Date        string `json:"date"` // last day of the interval, or to for the final one
+	Assets      int64  `json:"assets"`
+	Liabilities int64  `json:"liabilities"`
+	NetWorth    int64  `json:"net_worth"`
+}
+
+// AccountBalanceHistoryAPI is one account's balance at each point of a net-worth report.
+type AccountBalanceHistoryAPI struct {
+	AccountID   string  `json:"account_id"`
+	Name        string  `json:"name"`
+	AccountType string  `json:"account_type"`
+	Currency    string  `json:"currency"`
+	IsLiability bool    `json:"is_liability"`
+	IsActive    bool    `json:"is_active"`
+	Balances    []int64 `json:"balances"` // cash plus holdings, in the base currency, aligned with NetWorthPayload.Points
+}
+
+// NetWorthPayload is returned by GET /api/reports/net-worth.
+type NetWorthPayload struct {
+	From     string                     `json:"from"`
+	To       string                     `json:"to"`
+	Interval string                     `json:"interval"` // day | week | month
+	Currency string                     `json:"currency"` // the base currency; balances are valued at each point's rate
+	Points   []NetWorthPointAPI         `json:"points"`
+	Accounts []AccountBalanceHistoryAPI `json:"accounts"`
+}
+
+// ExchangeRateAPI is one stored rate: Rate units of QuoteCurrency buy one unit of Currency, from
+// RateDate until the pair's next rate.
+type ExchangeRateAPI struct {
+	ID            string  `json:"id"`
+	Currency      string  `json:"currency"`
+	QuoteCurrency string  `json:"quote_currency"`
+	Rate          float64 `json:"rate"`
+	RateDate      string  `json:"rate_date"` // YYYY-MM-DD
+	Source        string  `json:"source"`    // manual | import
+	CreatedAt     string  `json:"created_at"`
+	UpdatedAt     string  `json:"updated_at"`
+}
+
+// ExchangeRatesPayload is returned by GET /api/exchange-rates.
+type ExchangeRatesPayload struct {
+	BaseCurrency string            `json:"base_currency"`
+	Currencies   []string          `json:"currencies"` // codes accounts and rates may use
+	Rates        []ExchangeRateAPI `json:"rates"`
+}
+
+// ExchangeRateImportRowAPI is a CSV line that could not be imported.
+type ExchangeRateImportRowAPI struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// ExchangeRateImportPayload is returned by POST /api/exchange-rates/import.
+type ExchangeRateImportPayload struct {
+	Imported int                        `json:"imported"`
+	Errors   []ExchangeRateImportRowAPI `json:"errors"`
+}
+
+// InstrumentAPI is one instrument the user tracks, with its most recent price.
+type InstrumentAPI struct {
+	ID              string `json:"id"`
+	Symbol          string `json:"symbol"`
+	Name            string `json:"name"`
+	InstrumentType  string `json:"instrument_type"`
+	Currency        string `json:"currency"`
+	UnitLabel       string `json:"unit_label"`
+	LatestPrice     *int64 `json:"latest_price,omitempty"` // cents per unit
+	LatestPriceDate string `json:"latest_price_date,omitempty"`
+	CreatedAt       string `json:"created_at"`
+}
+
+// InstrumentsPayload is returned by GET /api/instruments.
+type InstrumentsPayload struct {
+	Instruments []InstrumentAPI `json:"instruments"`
+}
+
+// InstrumentPriceAPI is one entry of an instrument's price table.
+type InstrumentPriceAPI struct {
+	ID        string `json:"id"`
+	PriceDate string `json:"price_date"`
+	Price     int64  `json:"price"` // cents per unit
+}
+
+// InstrumentPricesPayload is returned by GET /api/instruments/{id}/prices, newest first.
+type InstrumentPricesPayload struct {
+	Instrument InstrumentAPI        `json:"instrument"`
+	Prices     []InstrumentPriceAPI `json:"prices"`
+}
+
+// InvestmentTradeAPI is one buy, sell or dividend. RealizedGain is set on sells: the net proceeds less
+// the average cost of the units sold.
+type InvestmentTradeAPI struct {
+	ID            string  `json:"id"`
+	AccountID     string  `json:"account_id"`
+	InstrumentID  string  `json:"instrument_id"`
+	Symbol        string  `json:"symbol"`
+	TradeType     string  `json:"trade_type"`
+	TradeDate     string  `json:"trade_date"`
+	Units         float64 `json:"units"`
+	Amount        int64   `json:"amount"`
+	Fee           int64   `json:"fee"`
+	RealizedGain  *int64  `json:"realized_gain,omitempty"`
+	TransactionID string  `json:"transaction_id,omitempty"` // the cash movement on the account
+	Notes         string  `json:"notes,omitempty"`
+	CreatedAt     string  `json:"created_at"`
+}
+
+// InvestmentTradesPayload is returned by GET /api/accounts/{id}/trades, newest first.
+type InvestmentTradesPayload struct {
+	Trades []InvestmentTradeAPI `json:"trades"`
+}
+
+// HoldingAPI is one instrument held in an investment account, in the account's currency. Without a
+// price on or before the valuation date the holding is valued at cost.
+type HoldingAPI struct {
+	AccountID      string  `json:"account_id"`
+	AccountName    string  `json:"account_name"`
+	InstrumentID   string  `json:"instrument_id"`
+	Symbol         string  `json:"symbol"`
+	Name           string  `json:"name"`
+	InstrumentType string  `json:"instrument_type"`
+	UnitLabel      string  `json:"unit_label"`
+	Currency       string  `json:"currency"`
+	Units          float64 `json:"units"`
+	CostBasis      int64   `json:"cost_basis"`
+	AverageCost    int64   `json:"average_cost"` // cents per unit
+	Price          *int64  `json:"price,omitempty"`
+	PriceDate      string  `json:"price_date,omitempty"`
+	MarketValue    int64   `json:"market_value"`
+	UnrealizedGain int64   `json:"unrealized_gain"`
+	RealizedGain   int64   `json:"realized_gain"` // all sells so far, or those in the report range
+	Dividends      int64   `json:"dividends"`     // net of fees, likewise
+}
+
+// InvestmentAccountAPI totals one investment account: the cash left in it plus its holdings.
+type InvestmentAccountAPI struct {
+	AccountID   string `json:"account_id"`
+	Name        string `json:"name"`
+	Currency    string `json:"currency"`
+	Cash        int64  `json:"cash"` // accounts.balance
+	CostBasis   int64  `json:"cost_basis"`
+	MarketValue int64  `json:"market_value"`
+	Total       int64  `json:"total"` // cash + market value
+}
+
+// InvestmentsPayload is returned by GET /api/investments. The totals are in the base currency.
+type InvestmentsPayload struct {
+	Date             string                 `json:"date"` // valuation date, YYYY-MM-DD
+	Currency         string                 `json:"currency"`
+	Accounts         []InvestmentAccountAPI `json:"accounts"`
+	Holdings         []HoldingAPI           `json:"holdings"`
+	TotalCostBasis   int64                  `json:"total_cost_basis"`
+	TotalMarketValue int64                  `json:"total_market_value"`
+	UnrealizedGain   int64                  `json:"unrealized_gain"`
+}
+
+// InvestmentGainsPayload is returned by GET /api/reports/investment-gains: gains realized and dividends
+// received between from and to, and unrealized gains at to. The totals are in the base currency.
+type InvestmentGainsPayload struct {
+	From           string       `json:"from"`
+	To             string       `json:"to"`
+	Currency       string       `json:"currency"`
+	RealizedGain   int64        `json:"realized_gain"`
+	Dividends      int64        `json:"dividends"`
+	UnrealizedGain int64        `json:"unrealized_gain"`
+	Holdings       []HoldingAPI `json:"holdings"`
+}
+
+// CategoryReportRowAPI is one category's income or spending. Total adds its subcategories to Amount.
+type CategoryReportRowAPI struct {
+	CategoryID    string                 `json:"category_id"`
+	Name          string                 `json:"name"`
+	Icon          string                 `json:"icon"`
+	Color         string                 `json:"color"`
+	Amount        int64                  `json:"amount"` // booked on the category itself
+	Total         int64                  `json:"total"`
+	Count         int                    `json:"count"` // subcategories included
+	Subcategories []CategoryReportRowAPI `json:"subcategories,omitempty"`
+}
+
+// CategoryReportPayload is returned by GET /api/reports/categories, largest first, in the base currency.
+type CategoryReportPayload struct {
+	From          string                 `json:"from"`
+	To            string                 `json:"to"`
+	Type          string                 `json:"type"` // income | expense
+	Currency      string                 `json:"currency"`
+	Total         int64                  `json:"total"`
+	Uncategorized int64                  `json:"uncategorized"`
+	Categories    []CategoryReportRowAPI `json:"categories"`
+}
+
+// CategorizationRuleAPI is one auto-categorization rule. budget_id is empty while the budget is archived
+// or on another category; the rule then only sets the category.
+type CategorizationRuleAPI struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Priority   int      `json:"priority"`
+	MatchField string   `json:"match_field"`
+	MatchType  string   `json:"match_type"`
+	Pattern    string   `json:"pattern,omitempty"`
+	MinAmount  *int64   `json:"min_amount,omitempty"`
+	MaxAmount  *int64   `json:"max_amount,omitempty"`
+	AccountID  string   `json:"account_id,omitempty"`
+	Account    string   `json:"account,omitempty"`
+	CategoryID string   `json:"category_id"`
+	Category   string   `json:"category"`
+	BudgetID   string   `json:"budget_id,omitempty"`
+	Budget     string   `json:"budget,omitempty"`
+	Item       string   `json:"item,omitempty"`
+	Tags       []string `json:"tags"`
+	IsActive   bool     `json:"is_active"`
+}
+
+// CategorizationRulesPayload is returned by GET /api/categorization-rules, in the order rules are tried.
+type CategorizationRulesPayload struct {
+	Rules []CategorizationRuleAPI `json:"rules"`
+}
+
+// CategorizationRuleTestPayload is returned by POST /api/categorization-rules/test: the rule that would
+// apply (null when none) and every active rule the sample matches.
+type CategorizationRuleTestPayload struct {
+	Rule    *CategorizationRuleAPI  `json:"rule"`
+	Matches []CategorizationRuleAPI `json:"matches"`
+}
+
+// CategorizationRuleCountAPI is how many transactions one rule changed in a re-apply.
+type CategorizationRuleCountAPI struct {
+	RuleID string `json:"rule_id"`
+	Name   string `json:"name"`
+	Count  int    `json:"count"`
+}
+
+// CategorizationChangeAPI is one transaction a re-apply changed (or would change).
+type CategorizationChangeAPI struct {
+	TransactionID string   `json:"transaction_id"`
+	Date          string   `json:"date"`
+	Description   string   `json:"description"`
+	FromCategory  string   `json:"from_category"`
+	ToCategory    string   `json:"to_category"`
+	Budget        string   `json:"budget,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+	RuleID        string   `json:"rule_id"`
+}
+
+// ApplyCategorizationRulesPayload is returned by POST /api/categorization-rules/apply. changes lists at
+// most the first 200 changed transactions; changed counts all of them.
+type ApplyCategorizationRulesPayload struct {
+	DryRun  bool                         `json:"dry_run"`
+	Scanned int                          `json:"scanned"`
+	Changed int                          `json:"changed"`
+	ByRule  []CategorizationRuleCountAPI `json:"by_rule"`
+	Changes []CategorizationChangeAPI    `json:"changes"`
+}
+
+// BudgetSpentChangeAPI is an active budget whose spent amount a category merge changes.
+type BudgetSpentChangeAPI struct {
+	BudgetID    string `json:"budget_id"`
+	Name        string `json:"name"`
+	CategoryID  string `json:"category_id"` // after the merge
+	SpentBefore int64  `json:"spent_before"`
+	SpentAfter  int64  `json:"spent_after"`
+}
+
+// CategoryMergePayload is returned by POST /api/categories/{id}/merge. With dry_run nothing is saved and
+// the counts describe what the merge would move.
+type CategoryMergePayload struct {
+	Source              CategorySummary        `json:"source"`
+	Target              CategorySummary        `json:"target"`
+	DryRun              bool                   `json:"dry_run"`
+	SourceAction        string                 `json:"source_action"` // deleted | hidden
+	Transactions        int                    `json:"transactions"`
+	Budgets             int                    `json:"budgets"`
+	RecurringRules      int                    `json:"recurring_rules"`
+	IncomeSources       int                    `json:"income_sources"`
+	CategorizationRules int                    `json:"categorization_rules"`
+	Subcategories       int                    `json:"subcategories"`
+	BudgetChanges       []BudgetSpentChangeAPI `json:"budget_changes"`
 }
@@ -0,0 +1,94 @@
+package models
+
+import "github.com/google/uuid"
+
+// ImportProfile maps a bank's CSV export onto transaction fields. Column references are header
+// names (case-insensitive) or 1-based column numbers. Amounts come either from amount_column
+// (signed, or marked DB/CR by indicator_column or a suffix) or from separate debit/credit columns.
+type ImportProfile struct {
+	ID                 string   `json:"id,omitempty"`
+	Name               string   `json:"name"`
+	BankName           string   `json:"bank_name,omitempty"`
+	BuiltIn            bool     `json:"built_in"`
+	Delimiter          string   `json:"delimiter,omitempty"` // one character, default ","
+	SkipRows           int      `json:"skip_rows,omitempty"` // lines before the header row
+	NoHeader           bool     `json:"no_header,omitempty"` // columns must then be numbers
+	DateColumn         string   `json:"date_column"`
+	DateFormat         string   `json:"date_format"`         // DD, D, MM, M, MMM, YYYY, YY tokens, e.g. DD/MM/YYYY
+	DescriptionColumns []string `json:"description_columns"` // joined with a space
+	AmountColumn       string   `json:"amount_column,omitempty"`
+	DebitColumn        string   `json:"debit_column,omitempty"`  // money out
+	CreditColumn       string   `json:"credit_column,omitempty"` // money in
+	IndicatorColumn    string   `json:"indicator_column,omitempty"`
+	DecimalSeparator   string   `json:"decimal_separator,omitempty"` // "." (default) or ","
+}
+
+// ImportInput carries the form fields of POST /api/import/{csv,ofx,qif}.
+// Without Commit the file is only parsed and previewed.
+type ImportInput struct {
+	Format            string // csv, ofx or qif
+	AccountID         uuid.UUID
+	ProfileID         string         // csv: built-in id (bca, mandiri, bni) or a saved profile id
+	Mapping           *ImportProfile // csv: ad-hoc mapping, used instead of ProfileID
+	DateFormat        string         // qif: e.g. DD/MM/YYYY; default MM/DD/YYYY
+	ExpenseCategoryID *uuid.UUID     // default: system "Lain-lain"
+	IncomeCategoryID  *uuid.UUID     // default: system "Pendapatan Lainnya"
+	Filename          string
+	Commit            bool
+	SkipInvalid       bool // commit the valid rows even when others have errors
+}
+
+// ImportRowAPI is one parsed statement line (amount in signed cents, negative = money out).
+// Duplicate rows are skipped on commit: an OFX FITID already imported into the account, or a line
+// matching an existing transaction (same amount, date ±1 day, similar description), named by DuplicateOf.
+type ImportRowAPI struct {
+	Line        int    `json:"line"`
+	Date        string `json:"date,omitempty"`
+	Description string `json:"description,omitempty"`
+	Amount      int64  `json:"amount"`
+	ExternalID  string `json:"external_id,omitempty"`
+	Category    string `json:"category,omitempty"` // from the file; unknown names fall back to the default category
+	Rule        string `json:"rule,omitempty"`     // categorization rule that sets the category when the file names none
+	Duplicate   bool   `json:"duplicate,omitempty"`
+	DuplicateOf string `json:"duplicate_of,omitempty"` // existing transaction id
+	Error       string `json:"error,omitempty"`
+}
+
+// ImportBatchAPI is one committed import; reverting it deletes every transaction it created.
+type ImportBatchAPI struct {
+	ID           string `json:"id"`
+	AccountID    string `json:"account_id"`
+	Account      string `json:"account"`
+	Source       string `json:"source"` // csv, ofx, qif
+	Filename     string `json:"filename,omitempty"`
+	ProfileName  string `json:"profile_name,omitempty"`
+	RowCount     int    `json:"row_count"`
+	IncomeCents  int64  `json:"income_cents"`
+	ExpenseCents int64  `json:"expense_cents"` // absolute value
+	Status       string `json:"status"`        // committed | reverted
+	CreatedAt    string `json:"created_at"`
+	RevertedAt   string `json:"reverted_at,omitempty"`
+}
+
+// ImportPreviewAPI is returned by POST /api/import/{format}; Batch is set once the rows are committed.
+// Profile is only set for CSV.
+type ImportPreviewAPI struct {
+	Profile        *ImportProfile  `json:"profile,omitempty"`
+	Rows           []ImportRowAPI  `json:"rows"`
+	ValidCount     int             `json:"valid_count"` // excludes duplicates
+	DuplicateCount int             `json:"duplicate_count"`
+	ErrorCount     int             `json:"error_count"`
+	IncomeCents    int64           `json:"income_cents"`  // valid rows only
+	ExpenseCents   int64           `json:"expense_cents"` // valid rows only, absolute value
+	Batch          *ImportBatchAPI `json:"batch,omitempty"`
+}
+
+// ImportProfilesPayload for GET /api/import/profiles (built-in profiles first).
+type ImportProfilesPayload struct {
+	Profiles []ImportProfile `json:"profiles"`
+}
+
+// ImportBatchesPayload for GET /api/import/batches.
+type ImportBatchesPayload struct {
+	Batches []ImportBatchAPI `json:"batches"`
+}
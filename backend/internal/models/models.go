@@ -38,9 +38,16 @@ type Account struct {
 	IsDefault     bool      `json:"is_default" db:"is_default"`               // Default account for transactions
 	Color         string    `json:"color" db:"color"`                         // Hex color for UI display
 	IsActive      bool      `json:"is_active" db:"is_active"`
+	Currency      string    `json:"currency" db:"currency"` // ISO 4217; amounts are still units * 100
 	CreatedAt     time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
 
+	// Credit card statement cycle (days of month, clamped to short months) and minimum payment rule
+	StatementClosingDay   *int  `json:"statement_closing_day,omitempty" db:"statement_closing_day"`
+	PaymentDueDay         *int  `json:"payment_due_day,omitempty" db:"payment_due_day"`
+	MinimumPaymentPercent int   `json:"minimum_payment_percent" db:"minimum_payment_percent"` // of the statement balance
+	MinimumPaymentFloor   int64 `json:"minimum_payment_floor" db:"minimum_payment_floor"`     // cents
+
 	// Computed fields (not stored in DB)
 	FormattedBalance string `json:"formatted_balance" db:"-"` // For Indonesian Rupiah formatting
 }
@@ -84,6 +91,10 @@ type Transaction struct {
 	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
 
+	// Set when the purchase was made in another currency than the account's
+	OriginalAmount   *int64  `json:"original_amount,omitempty" db:"original_amount"` // signed, in OriginalCurrency
+	OriginalCurrency *string `json:"original_currency,omitempty" db:"original_currency"`
+
 	// Related data (loaded via joins)
 	Account         *Account  `json:"account,omitempty" db:"-"`
 	Category        *Category `json:"category,omitempty" db:"-"`
@@ -210,22 +221,31 @@ func (t *Transaction) GetAbsoluteAmount() int64 {
 	return t.Amount
 }
 
+// AvailableAmount is the allocation for the current period including any carried-over amount
+func (b *Budget) AvailableAmount() int64 {
+	return b.AllocatedAmount + b.CarryoverAmount
+}
+
 // CalculateRemainingAmount calculates the remaining budget amount
 func (b *Budget) CalculateRemainingAmount() int64 {
-	return b.AllocatedAmount - b.SpentAmount
+	return b.AvailableAmount() - b.SpentAmount
 }
 
 // CalculateSpentPercentage calculates the percentage of budget spent
 func (b *Budget) CalculateSpentPercentage() float64 {
-	if b.AllocatedAmount == 0 {
+	available := b.AvailableAmount()
+	if available <= 0 {
+		if b.SpentAmount > 0 {
+			return 100
+		}
 		return 0
 	}
-	return float64(b.SpentAmount) / float64(b.AllocatedAmount) * 100
+	return float64(b.SpentAmount) / float64(available) * 100
 }
 
 // IsOverBudget checks if the budget is exceeded
 func (b *Budget) IsOverBudget() bool {
-	return b.SpentAmount > b.AllocatedAmount
+	return b.SpentAmount > b.AvailableAmount()
 }
 
 // ShouldAlert checks if an alert should be shown based on alert percentage
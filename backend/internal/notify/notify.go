@@ -0,0 +1,137 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"monman-backend/internal/config"
+)
+
+// Alert is one budget threshold crossing handed to notifiers. Amounts are cents.
+type Alert struct {
+	ID               string `json:"id"`
+	UserID           string `json:"user_id"`
+	Username         string `json:"username"`
+	Email            string `json:"-"`
+	BudgetID         string `json:"budget_id"`
+	BudgetName       string `json:"budget_name"`
+	ThresholdPercent int    `json:"threshold_percent"`
+	SpentAmount      int64  `json:"spent_amount"`
+	AvailableAmount  int64  `json:"available_amount"`
+	PeriodStartDate  string `json:"period_start_date"`
+	PeriodEndDate    string `json:"period_end_date"`
+	Message          string `json:"message"`
+	CreatedAt        string `json:"created_at"`
+}
+
+// Notifier delivers alerts to one channel.
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, a Alert) error
+}
+
+// FromConfig builds the notifiers listed in cfg.Notifiers. Unknown names and channels missing
+// their settings are logged and skipped; "none" yields no notifiers.
+func FromConfig(cfg config.AlertsConfig) []Notifier {
+	var out []Notifier
+	for _, name := range cfg.Notifiers {
+		switch name {
+		case "none":
+		case "log":
+			out = append(out, LogNotifier{})
+		case "webhook":
+			if cfg.WebhookURL == "" {
+				log.Printf("notify: webhook notifier needs ALERT_WEBHOOK_URL; skipping")
+				continue
+			}
+			out = append(out, NewWebhookNotifier(cfg.WebhookURL))
+		case "smtp":
+			if cfg.SMTP.Host == "" || cfg.SMTP.From == "" {
+				log.Printf("notify: smtp notifier needs SMTP_HOST and SMTP_FROM; skipping")
+				continue
+			}
+			out = append(out, SMTPNotifier{cfg: cfg.SMTP})
+		default:
+			log.Printf("notify: unknown notifier %q; skipping", name)
+		}
+	}
+	return out
+}
+
+// LogNotifier writes alerts to the server log.
+type LogNotifier struct{}
+
+func (LogNotifier) Name() string { return "log" }
+
+func (LogNotifier) Notify(_ context.Context, a Alert) error {
+	log.Printf("budget alert for %s: %s", a.Username, a.Message)
+	return nil
+}
+
+// WebhookNotifier POSTs each alert as JSON to a fixed URL and expects a 2xx reply.
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (n *WebhookNotifier) Name() string { return "webhook" }
+
+func (n *WebhookNotifier) Notify(ctx context.Context, a Alert) error {
+	body, err := json.Marshal(map[string]interface{}{"type": "budget_alert", "alert": a})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// SMTPNotifier emails the alert to the user's address; users without an email are skipped.
+type SMTPNotifier struct {
+	cfg config.SMTPConfig
+}
+
+func (SMTPNotifier) Name() string { return "smtp" }
+
+func (n SMTPNotifier) Notify(_ context.Context, a Alert) error {
+	if a.Email == "" {
+		return nil
+	}
+	addr := net.JoinHostPort(n.cfg.Host, strconv.Itoa(n.cfg.Port))
+	var auth smtp.Auth
+	if n.cfg.Username != "" {
+		auth = smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)
+	}
+	subject := fmt.Sprintf("MonMan: %s reached %d%%", a.BudgetName, a.ThresholdPercent)
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", n.cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", a.Email)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	msg.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n")
+	fmt.Fprintf(&msg, "%s\r\n\r\nPeriod: %s to %s\r\n", a.Message, a.PeriodStartDate, a.PeriodEndDate)
+	return smtp.SendMail(addr, auth, n.cfg.From, []string{a.Email}, []byte(msg.String()))
+}
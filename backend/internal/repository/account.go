@@ -2,6 +2,7 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 
 	"monman-backend/internal/models"
@@ -9,6 +10,12 @@ import (
 	"github.com/google/uuid"
 )
 
+// ErrAccountNotFound indicates the account id does not exist for this user.
+var ErrAccountNotFound = errors.New("account not found")
+
+// OpeningBalanceDescription is the description of opening balance rows.
+const OpeningBalanceDescription = "Saldo awal"
+
 type AccountRepository struct {
 	db *sql.DB
 }
@@ -18,44 +25,298 @@ func NewAccountRepository(db *sql.DB) *AccountRepository {
 }
 
 func (r *AccountRepository) ListActiveByUser(userID uuid.UUID) ([]models.AccountSummary, error) {
-	q := `
-		SELECT id, name, account_type, balance, color
-		FROM accounts WHERE user_id = ? AND is_active = 1
-		ORDER BY is_default DESC, name ASC`
-	rows, err := r.db.Query(q, userID.String())
+	return r.listByUser(userID, true)
+}
+
+// ListArchivedByUser returns the user's archived accounts by name.
+func (r *AccountRepository) ListArchivedByUser(userID uuid.UUID) ([]models.AccountSummary, error) {
+	return r.listByUser(userID, false)
+}
+
+// accountSummarySelect lists accounts with their opening balance row; callers append the WHERE clause.
+const accountSummarySelect = `
+	SELECT a.id, a.name, a.account_type, a.balance, a.color,
+		COALESCE(a.bank_name, ''), COALESCE(a.account_number, ''), a.credit_limit,
+		a.is_default, a.is_active, COALESCE(o.amount, 0), COALESCE(o.transaction_date, ''),
+		a.statement_closing_day, a.payment_due_day, a.currency
+	FROM accounts a
+	LEFT JOIN transactions o ON o.id = a.opening_transaction_id`
+
+func (r *AccountRepository) listByUser(userID uuid.UUID, active bool) ([]models.AccountSummary, error) {
+	return r.querySummaries(accountSummarySelect+`
+		WHERE a.user_id = ? AND a.is_active = ?
+		ORDER BY a.is_default DESC, a.name ASC`, userID.String(), sqlBool(active))
+}
+
+// GetSummary returns one owned account, archived or not, in the list shape.
+func (r *AccountRepository) GetSummary(userID, accountID uuid.UUID) (*models.AccountSummary, error) {
+	list, err := r.querySummaries(accountSummarySelect+`
+		WHERE a.id = ? AND a.user_id = ?`, accountID.String(), userID.String())
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, ErrAccountNotFound
+	}
+	return &list[0], nil
+}
+
+func (r *AccountRepository) querySummaries(q string, args ...any) ([]models.AccountSummary, error) {
+	rows, err := r.db.Query(q, args...)
 	if err != nil {
 		return nil, fmt.Errorf("list accounts: %w", err)
 	}
 	defer rows.Close()
 	var out []models.AccountSummary
 	for rows.Next() {
-		var a models.AccountSummary
-		var idStr string
-		if err := rows.Scan(&idStr, &a.Name, &a.AccountType, &a.Balance, &a.Color); err != nil {
+		var (
+			a                   models.AccountSummary
+			idStr               string
+			color               sql.NullString
+			creditLimit         sql.NullInt64
+			isDefault, isActive int
+			closingDay, dueDay  sql.NullInt64
+		)
+		if err := rows.Scan(&idStr, &a.Name, &a.AccountType, &a.Balance, &color,
+			&a.BankName, &a.AccountNumber, &creditLimit,
+			&isDefault, &isActive, &a.OpeningBalance, &a.OpeningBalanceDate,
+			&closingDay, &dueDay, &a.Currency); err != nil {
 			return nil, err
 		}
 		a.ID = idStr
+		a.Color = color.String
+		if creditLimit.Valid {
+			a.CreditLimit = &creditLimit.Int64
+			available := creditLimit.Int64 + a.Balance
+			a.AvailableCredit = &available
+		}
+		a.StatementClosingDay = nullIntPtr(closingDay)
+		a.PaymentDueDay = nullIntPtr(dueDay)
+		a.IsDefault = isDefault == 1
+		a.IsActive = isActive == 1
 		out = append(out, a)
 	}
 	return out, rows.Err()
 }
 
-// Create inserts a new active non-default account (balance starts at zero).
-func (r *AccountRepository) Create(userID uuid.UUID, name, accountType, color string) (uuid.UUID, error) {
+// Create inserts a new active account (balance starts at zero; see SetOpeningBalance). When
+// a.IsDefault is set the user's other accounts stop being the default.
+func (r *AccountRepository) Create(userID uuid.UUID, a *models.Account) (uuid.UUID, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if a.IsDefault {
+		if err := clearDefaultAccountTx(tx, userID); err != nil {
+			return uuid.Nil, err
+		}
+	}
 	id := uuid.New()
 	q := `
 		INSERT INTO accounts (
-			id, user_id, name, account_type, balance, is_default, color, is_active,
-			created_at, updated_at
-		) VALUES (?, ?, ?, ?, 0, 0, ?, 1, datetime('now'), datetime('now'))
+			id, user_id, name, account_type, bank_name, account_number, balance, credit_limit,
+			statement_closing_day, payment_due_day, minimum_payment_percent, minimum_payment_floor,
+			is_default, color, currency, is_active, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, 0, ?, ?, ?, ?, ?, ?, ?, ?, 1, datetime('now'), datetime('now'))
 	`
-	_, err := r.db.Exec(q, id.String(), userID.String(), name, accountType, color)
-	if err != nil {
+	if _, err := tx.Exec(q, id.String(), userID.String(), a.Name, a.AccountType,
+		nullableString(a.BankName), nullableString(a.AccountNumber), nullableInt64(a.CreditLimit),
+		nullableInt(a.StatementClosingDay), nullableInt(a.PaymentDueDay), a.MinimumPaymentPercent, a.MinimumPaymentFloor,
+		sqlBool(a.IsDefault), a.Color, a.Currency,
+	); err != nil {
 		return uuid.Nil, fmt.Errorf("insert account: %w", err)
 	}
+	if err := tx.Commit(); err != nil {
+		return uuid.Nil, fmt.Errorf("commit: %w", err)
+	}
 	return id, nil
 }
 
+// OpeningBalanceChange is the opening balance Update writes along with the account; Amount 0 removes it.
+type OpeningBalanceChange struct {
+	Amount int64
+	Date   string
+}
+
+// Update saves the editable fields of an owned account, archived or not, and, when opening is set, its
+// opening balance row in the same DB transaction. Setting IsDefault clears it on the user's other accounts.
+func (r *AccountRepository) Update(a *models.Account, opening *OpeningBalanceChange) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if a.IsDefault {
+		if err := clearDefaultAccountTx(tx, a.UserID); err != nil {
+			return err
+		}
+	}
+	res, err := tx.Exec(`
+		UPDATE accounts
+		SET name = ?, account_type = ?, bank_name = ?, account_number = ?, credit_limit = ?,
+			statement_closing_day = ?, payment_due_day = ?, minimum_payment_percent = ?, minimum_payment_floor = ?,
+			is_default = ?, color = ?, currency = ?, is_active = ?, updated_at = datetime('now')
+		WHERE id = ? AND user_id = ?
+	`, a.Name, a.AccountType, nullableString(a.BankName), nullableString(a.AccountNumber),
+		nullableInt64(a.CreditLimit),
+		nullableInt(a.StatementClosingDay), nullableInt(a.PaymentDueDay), a.MinimumPaymentPercent, a.MinimumPaymentFloor,
+		sqlBool(a.IsDefault), a.Color, a.Currency, sqlBool(a.IsActive),
+		a.ID.String(), a.UserID.String())
+	if err != nil {
+		return fmt.Errorf("update account: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("update account: %w", err)
+	} else if n == 0 {
+		return ErrAccountNotFound
+	}
+	if opening != nil {
+		if err := setOpeningBalanceTx(tx, a.UserID, a.ID, opening.Amount, opening.Date); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+func clearDefaultAccountTx(tx *sql.Tx, userID uuid.UUID) error {
+	if _, err := tx.Exec(`
+		UPDATE accounts SET is_default = 0, updated_at = datetime('now')
+		WHERE user_id = ? AND is_default = 1
+	`, userID.String()); err != nil {
+		return fmt.Errorf("clear default account: %w", err)
+	}
+	return nil
+}
+
+// CountActive returns how many active accounts the user has.
+func (r *AccountRepository) CountActive(userID uuid.UUID) (int, error) {
+	var n int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM accounts WHERE user_id = ? AND is_active = 1`, userID.String()).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("count accounts: %w", err)
+	}
+	return n, nil
+}
+
+// SetOpeningBalance creates, moves or (for amount 0) deletes the account's opening balance row, a
+// 'transfer'-type transaction without category; the balance triggers apply the difference.
+func (r *AccountRepository) SetOpeningBalance(userID, accountID uuid.UUID, amount int64, date string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := setOpeningBalanceTx(tx, userID, accountID, amount, date); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+func setOpeningBalanceTx(tx *sql.Tx, userID, accountID uuid.UUID, amount int64, date string) error {
+	var current sql.NullString
+	err := tx.QueryRow(`SELECT opening_transaction_id FROM accounts WHERE id = ? AND user_id = ?`,
+		accountID.String(), userID.String()).Scan(&current)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrAccountNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("load opening balance: %w", err)
+	}
+	if current.Valid {
+		if err := ensureUnreconciled(tx, "id = ?", current.String); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case current.Valid && amount == 0:
+		if _, err := tx.Exec(`DELETE FROM transactions WHERE id = ? AND user_id = ?`, current.String, userID.String()); err != nil {
+			return fmt.Errorf("delete opening balance: %w", err)
+		}
+		if _, err := tx.Exec(`UPDATE accounts SET opening_transaction_id = NULL, updated_at = datetime('now') WHERE id = ?`,
+			accountID.String()); err != nil {
+			return fmt.Errorf("unlink opening balance: %w", err)
+		}
+	case current.Valid:
+		if _, err := tx.Exec(`
+			UPDATE transactions SET amount = ?, transaction_date = ?, updated_at = datetime('now')
+			WHERE id = ? AND user_id = ?
+		`, amount, date, current.String, userID.String()); err != nil {
+			return fmt.Errorf("update opening balance: %w", err)
+		}
+		if err := rebaseTx(tx, userID, "t.id = ?", current.String); err != nil {
+			return err
+		}
+	case amount != 0:
+		id := uuid.New()
+		if _, err := tx.Exec(`
+			INSERT INTO transactions (
+				id, user_id, account_id, category_id, amount, description,
+				transaction_type, transaction_date, created_at, updated_at
+			) VALUES (?, ?, ?, NULL, ?, ?, 'transfer', ?, datetime('now'), datetime('now'))
+		`, id.String(), userID.String(), accountID.String(), amount, OpeningBalanceDescription, date); err != nil {
+			return fmt.Errorf("insert opening balance: %w", err)
+		}
+		if err := rebaseTx(tx, userID, "t.id = ?", id.String()); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE accounts SET opening_transaction_id = ?, updated_at = datetime('now') WHERE id = ?`,
+			id.String(), accountID.String()); err != nil {
+			return fmt.Errorf("link opening balance: %w", err)
+		}
+	}
+	return nil
+}
+
+// OpeningBalance returns the account's opening balance row amount and date (0 and "" when unset).
+func (r *AccountRepository) OpeningBalance(userID, accountID uuid.UUID) (int64, string, error) {
+	var (
+		amount int64
+		date   string
+	)
+	err := r.db.QueryRow(`
+		SELECT COALESCE(o.amount, 0), COALESCE(o.transaction_date, '')
+		FROM accounts a
+		LEFT JOIN transactions o ON o.id = a.opening_transaction_id
+		WHERE a.id = ? AND a.user_id = ?
+	`, accountID.String(), userID.String()).Scan(&amount, &date)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, "", ErrAccountNotFound
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("load opening balance: %w", err)
+	}
+	return amount, date, nil
+}
+
+// IsOpeningTransaction reports whether txID is some account's opening balance row.
+func (r *AccountRepository) IsOpeningTransaction(txID uuid.UUID) (bool, error) {
+	var n int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM accounts WHERE opening_transaction_id = ?`, txID.String()).Scan(&n); err != nil {
+		return false, fmt.Errorf("check opening balance: %w", err)
+	}
+	return n > 0, nil
+}
+
+// IsTradeTransaction reports whether txID is the cash row of an investment trade.
+func (r *AccountRepository) IsTradeTransaction(txID uuid.UUID) (bool, error) {
+	var n int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM investment_trades WHERE transaction_id = ?`, txID.String()).Scan(&n); err != nil {
+		return false, fmt.Errorf("check investment trade: %w", err)
+	}
+	return n > 0, nil
+}
+
 // AccountBelongs verifies an account belongs to user and is active.
 func (r *AccountRepository) AccountBelongs(accountID, userID uuid.UUID) (bool, error) {
 	var n int
@@ -66,17 +327,125 @@ func (r *AccountRepository) AccountBelongs(accountID, userID uuid.UUID) (bool, e
 	return n > 0, nil
 }
 
+const accountSelect = `
+	SELECT id, user_id, name, account_type, bank_name, account_number, balance, credit_limit,
+		is_default, color, is_active,
+		statement_closing_day, payment_due_day, minimum_payment_percent, minimum_payment_floor, currency
+	FROM accounts`
+
+// GetForUser loads one of the user's accounts, archived ones included.
+func (r *AccountRepository) GetForUser(accountID, userID uuid.UUID) (*models.Account, error) {
+	a, err := scanAccount(r.db.QueryRow(accountSelect+` WHERE id = ? AND user_id = ?`, accountID.String(), userID.String()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAccountNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get account: %w", err)
+	}
+	return a, nil
+}
+
+// ListCreditCards returns the user's active credit_card accounts by name.
+func (r *AccountRepository) ListCreditCards(userID uuid.UUID) ([]models.Account, error) {
+	rows, err := r.db.Query(accountSelect+`
+		WHERE user_id = ? AND account_type = 'credit_card' AND is_active = 1
+		ORDER BY name`, userID.String())
+	if err != nil {
+		return nil, fmt.Errorf("list credit cards: %w", err)
+	}
+	defer rows.Close()
+	var out []models.Account
+	for rows.Next() {
+		a, err := scanAccount(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan credit card: %w", err)
+		}
+		out = append(out, *a)
+	}
+	return out, rows.Err()
+}
+
+// ActivityAfter sums the account's rows dated after date: the net amount, and the credits (payments
+// and refunds) alone.
+func (r *AccountRepository) ActivityAfter(accountID uuid.UUID, date string) (net, credits int64, err error) {
+	err = r.db.QueryRow(`
+		SELECT COALESCE(SUM(amount), 0), COALESCE(SUM(CASE WHEN amount > 0 THEN amount END), 0)
+		FROM transactions
+		WHERE account_id = ? AND transaction_date > ?
+	`, accountID.String(), date).Scan(&net, &credits)
+	if err != nil {
+		return 0, 0, fmt.Errorf("account activity: %w", err)
+	}
+	return net, credits, nil
+}
+
+// HasTransactions reports whether any row is posted to the account.
+func (r *AccountRepository) HasTransactions(accountID uuid.UUID) (bool, error) {
+	var n int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM (SELECT 1 FROM transactions WHERE account_id = ? LIMIT 1)`,
+		accountID.String()).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("account transactions: %w", err)
+	}
+	return n > 0, nil
+}
+
+func scanAccount(s rowScanner) (*models.Account, error) {
+	var (
+		a                   models.Account
+		id, uid             string
+		bankName, number    sql.NullString
+		creditLimit         sql.NullInt64
+		isDefault, isActive int
+		color               sql.NullString
+		closingDay, dueDay  sql.NullInt64
+	)
+	if err := s.Scan(
+		&id, &uid, &a.Name, &a.AccountType, &bankName, &number, &a.Balance, &creditLimit,
+		&isDefault, &color, &isActive,
+		&closingDay, &dueDay, &a.MinimumPaymentPercent, &a.MinimumPaymentFloor, &a.Currency,
+	); err != nil {
+		return nil, err
+	}
+	a.ID, _ = uuid.Parse(id)
+	a.UserID, _ = uuid.Parse(uid)
+	if bankName.Valid {
+		a.BankName = &bankName.String
+	}
+	if number.Valid {
+		a.AccountNumber = &number.String
+	}
+	if creditLimit.Valid {
+		a.CreditLimit = &creditLimit.Int64
+	}
+	a.IsDefault = isDefault == 1
+	a.IsActive = isActive == 1
+	a.Color = color.String
+	a.StatementClosingDay = nullIntPtr(closingDay)
+	a.PaymentDueDay = nullIntPtr(dueDay)
+	return &a, nil
+}
+
+func nullIntPtr(v sql.NullInt64) *int {
+	if !v.Valid {
+		return nil
+	}
+	n := int(v.Int64)
+	return &n
+}
+
 // EnsureDefaultCashWallet inserts one default cash account if the user has no active accounts.
 // New registrations do not otherwise create rows in `accounts`, which would leave transactions and budgets unusable.
 func (r *AccountRepository) EnsureDefaultCashWallet(userID uuid.UUID) error {
 	id := uuid.New()
 	q := `
 		INSERT INTO accounts (
-			id, user_id, name, account_type, balance, is_default, color, is_active,
+			id, user_id, name, account_type, balance, is_default, color, currency, is_active,
 			created_at, updated_at
-		) VALUES (?, ?, ?, 'cash', 0, 1, '#22C55E', 1, datetime('now'), datetime('now'))
+		) VALUES (?, ?, ?, 'cash', 0, 1, '#22C55E', (SELECT base_currency FROM users WHERE id = ?), 1,
+			datetime('now'), datetime('now'))
 	`
-	_, err := r.db.Exec(q, id.String(), userID.String(), "Dompet utama")
+	_, err := r.db.Exec(q, id.String(), userID.String(), "Dompet utama", userID.String())
 	if err != nil {
 		return fmt.Errorf("ensure default cash wallet: %w", err)
 	}
@@ -92,12 +461,15 @@ func NewCategoryRepository(db *sql.DB) *CategoryRepository {
 	return &CategoryRepository{db: db}
 }
 
+// ListActiveForUser returns the active system and own categories, leaving out system ones the user hid.
 func (r *CategoryRepository) ListActiveForUser(userID uuid.UUID, typeFilter string) ([]models.CategorySummary, error) {
 	q := `
-		SELECT id, name, category_type, icon, is_system FROM categories
+		SELECT id, name, category_type, icon, is_system, COALESCE(parent_category_id, ''), COALESCE(color, '')
+		FROM categories
 		WHERE is_active = 1 AND (user_id IS NULL OR user_id = ?)
+		  AND id NOT IN (SELECT category_id FROM hidden_categories WHERE user_id = ?)
 	`
-	args := []any{userID.String()}
+	args := []any{userID.String(), userID.String()}
 	if typeFilter == "income" || typeFilter == "expense" {
 		q += " AND category_type = ?"
 		args = append(args, typeFilter)
@@ -115,7 +487,7 @@ func (r *CategoryRepository) ListActiveForUser(userID uuid.UUID, typeFilter stri
 		var c models.CategorySummary
 		var idStr string
 		var isSys int
-		if err := rows.Scan(&idStr, &c.Name, &c.CategoryType, &c.Icon, &isSys); err != nil {
+		if err := rows.Scan(&idStr, &c.Name, &c.CategoryType, &c.Icon, &isSys, &c.ParentCategoryID, &c.Color); err != nil {
 			return nil, err
 		}
 		c.ID = idStr
@@ -140,3 +512,26 @@ func (r *CategoryRepository) CategoryOwnedOrSystem(categoryID, userID uuid.UUID)
 	}
 	return ctype, true, nil
 }
+
+// ParentCategoryNames maps each visible subcategory id to its parent category's name.
+func (r *CategoryRepository) ParentCategoryNames(userID uuid.UUID) (map[string]string, error) {
+	rows, err := r.db.Query(`
+		SELECT c.id, p.name FROM categories c
+		INNER JOIN categories p ON p.id = c.parent_category_id
+		WHERE c.user_id IS NULL OR c.user_id = ?
+	`, userID.String())
+	if err != nil {
+		return nil, fmt.Errorf("list parent categories: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[string]string)
+	for rows.Next() {
+		var id, parent string
+		if err := rows.Scan(&id, &parent); err != nil {
+			return nil, fmt.Errorf("scan parent category: %w", err)
+		}
+		out[id] = parent
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"monman-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ErrAlertNotFound indicates the alert id does not exist for this user.
+var ErrAlertNotFound = errors.New("alert not found")
+
+// PendingAlert is an alert not yet handed to notifiers, with the recipient's contact details.
+type PendingAlert struct {
+	models.BudgetAlertAPI
+	UserID   string
+	Username string
+	Email    string
+}
+
+// AlertRepository stores budget_alerts rows.
+type AlertRepository struct {
+	db *sql.DB
+}
+
+func NewAlertRepository(db *sql.DB) *AlertRepository {
+	return &AlertRepository{db: db}
+}
+
+// Record inserts the alert for b's current period at threshold unless that threshold already fired
+// in this period. created reports whether a new row was written.
+func (r *AlertRepository) Record(b models.Budget, threshold int, message string) (id uuid.UUID, created bool, err error) {
+	id = uuid.New()
+	res, err := r.db.Exec(`
+		INSERT OR IGNORE INTO budget_alerts (
+			id, user_id, budget_id, period_start_date, period_end_date,
+			threshold_percent, spent_amount, available_amount, message, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'))
+	`,
+		id.String(), b.UserID.String(), b.ID.String(),
+		b.PeriodStartDate.Format("2006-01-02"), b.PeriodEndDate.Format("2006-01-02"),
+		threshold, b.SpentAmount, b.AvailableAmount(), message,
+	)
+	if err != nil {
+		return uuid.Nil, false, fmt.Errorf("insert budget alert: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return uuid.Nil, false, fmt.Errorf("insert budget alert: %w", err)
+	}
+	return id, n > 0, nil
+}
+
+// ListForUser returns the user's alerts, newest first, plus the total unread count.
+func (r *AlertRepository) ListForUser(userID uuid.UUID, unreadOnly bool, limit, offset int) ([]models.BudgetAlertAPI, int, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	q := alertSelect + `
+		WHERE a.user_id = ? AND (? = 0 OR a.is_read = 0)
+		ORDER BY a.created_at DESC, a.threshold_percent DESC
+		LIMIT ? OFFSET ?`
+	rows, err := r.db.Query(q, userID.String(), sqlBool(unreadOnly), limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list alerts: %w", err)
+	}
+	defer rows.Close()
+
+	out := []models.BudgetAlertAPI{}
+	for rows.Next() {
+		a, err := scanAlert(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		out = append(out, *a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var unread int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM budget_alerts WHERE user_id = ? AND is_read = 0`,
+		userID.String()).Scan(&unread); err != nil {
+		return nil, 0, fmt.Errorf("count unread alerts: %w", err)
+	}
+	return out, unread, nil
+}
+
+// SetRead marks one owned alert read or unread.
+func (r *AlertRepository) SetRead(id, userID uuid.UUID, read bool) error {
+	res, err := r.db.Exec(`
+		UPDATE budget_alerts
+		SET is_read = ?, read_at = CASE WHEN ? = 1 THEN COALESCE(read_at, datetime('now')) END
+		WHERE id = ? AND user_id = ?
+	`, sqlBool(read), sqlBool(read), id.String(), userID.String())
+	if err != nil {
+		return fmt.Errorf("update alert: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("update alert: %w", err)
+	} else if n == 0 {
+		return ErrAlertNotFound
+	}
+	return nil
+}
+
+// MarkAllRead marks every unread alert of the user read and returns how many changed.
+func (r *AlertRepository) MarkAllRead(userID uuid.UUID) (int64, error) {
+	res, err := r.db.Exec(`
+		UPDATE budget_alerts SET is_read = 1, read_at = datetime('now')
+		WHERE user_id = ? AND is_read = 0
+	`, userID.String())
+	if err != nil {
+		return 0, fmt.Errorf("mark alerts read: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// ListUndelivered returns alerts no notifier run has claimed yet, oldest first.
+func (r *AlertRepository) ListUndelivered(limit int) ([]PendingAlert, error) {
+	rows, err := r.db.Query(`
+		SELECT a.id, a.budget_id, COALESCE(b.name, ''), a.threshold_percent,
+			a.spent_amount, a.available_amount, a.period_start_date, a.period_end_date,
+			a.message, a.is_read, a.read_at, a.created_at,
+			u.id, u.username, COALESCE(u.email, '')
+		FROM budget_alerts a
+		LEFT JOIN budgets b ON b.id = a.budget_id
+		INNER JOIN users u ON u.id = a.user_id
+		WHERE a.notified_at IS NULL
+		ORDER BY a.created_at, a.id
+		LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list undelivered alerts: %w", err)
+	}
+	defer rows.Close()
+
+	var out []PendingAlert
+	for rows.Next() {
+		var (
+			p      PendingAlert
+			read   int
+			readAt sql.NullString
+		)
+		if err := rows.Scan(
+			&p.ID, &p.BudgetID, &p.BudgetName, &p.ThresholdPercent,
+			&p.SpentAmount, &p.AvailableAmount, &p.PeriodStartDate, &p.PeriodEndDate,
+			&p.Message, &read, &readAt, &p.CreatedAt,
+			&p.UserID, &p.Username, &p.Email,
+		); err != nil {
+			return nil, fmt.Errorf("scan undelivered alert: %w", err)
+		}
+		p.IsRead = read == 1
+		p.ReadAt = readAt.String
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// ClaimDelivery marks the alert as handed to notifiers. It returns false when another run
+// already claimed it, so each alert is delivered at most once.
+func (r *AlertRepository) ClaimDelivery(id string) (bool, error) {
+	res, err := r.db.Exec(`UPDATE budget_alerts SET notified_at = datetime('now') WHERE id = ? AND notified_at IS NULL`, id)
+	if err != nil {
+		return false, fmt.Errorf("claim alert delivery: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("claim alert delivery: %w", err)
+	}
+	return n > 0, nil
+}
+
+// SetDeliveryError stores the last notifier failure for an alert (empty clears it).
+func (r *AlertRepository) SetDeliveryError(id, msg string) error {
+	var v interface{}
+	if msg != "" {
+		v = msg
+	}
+	if _, err := r.db.Exec(`UPDATE budget_alerts SET delivery_error = ? WHERE id = ?`, v, id); err != nil {
+		return fmt.Errorf("record alert delivery error: %w", err)
+	}
+	return nil
+}
+
+const alertSelect = `
+	SELECT a.id, a.budget_id, COALESCE(b.name, ''), a.threshold_percent,
+		a.spent_amount, a.available_amount, a.period_start_date, a.period_end_date,
+		a.message, a.is_read, a.read_at, a.created_at
+	FROM budget_alerts a
+	LEFT JOIN budgets b ON b.id = a.budget_id`
+
+func scanAlert(s rowScanner) (*models.BudgetAlertAPI, error) {
+	var (
+		a      models.BudgetAlertAPI
+		read   int
+		readAt sql.NullString
+	)
+	if err := s.Scan(
+		&a.ID, &a.BudgetID, &a.BudgetName, &a.ThresholdPercent,
+		&a.SpentAmount, &a.AvailableAmount, &a.PeriodStartDate, &a.PeriodEndDate,
+		&a.Message, &read, &readAt, &a.CreatedAt,
+	); err != nil {
+		return nil, fmt.Errorf("scan alert: %w", err)
+	}
+	a.IsRead = read == 1
+	a.ReadAt = readAt.String
+	return &a, nil
+}
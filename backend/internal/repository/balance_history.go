@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BalanceHistoryRepository reconstructs account balances at past dates from transactions, using the
+// account_balance_snapshots month-end cache for everything before the requested range.
+type BalanceHistoryRepository struct {
+	db *sql.DB
+}
+
+func NewBalanceHistoryRepository(db *sql.DB) *BalanceHistoryRepository {
+	return &BalanceHistoryRepository{db: db}
+}
+
+// HistoryAccount is one account in a balance history, archived ones included.
+type HistoryAccount struct {
+	ID          string
+	Name        string
+	AccountType string
+	Currency    string
+	IsActive    bool
+}
+
+// BalanceChange is the net amount posted to an account on one day.
+type BalanceChange struct {
+	AccountID string
+	Date      string // YYYY-MM-DD
+	Amount    int64
+}
+
+// Accounts lists all of the user's accounts, active first.
+func (r *BalanceHistoryRepository) Accounts(userID uuid.UUID) ([]HistoryAccount, error) {
+	rows, err := r.db.Query(`
+		SELECT id, name, account_type, currency, is_active
+		FROM accounts
+		WHERE user_id = ?
+		ORDER BY is_active DESC, name`, userID.String())
+	if err != nil {
+		return nil, fmt.Errorf("history accounts: %w", err)
+	}
+	defer rows.Close()
+	var out []HistoryAccount
+	for rows.Next() {
+		var (
+			a      HistoryAccount
+			active int
+		)
+		if err := rows.Scan(&a.ID, &a.Name, &a.AccountType, &a.Currency, &active); err != nil {
+			return nil, fmt.Errorf("scan history account: %w", err)
+		}
+		a.IsActive = active == 1
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// BalancesBefore returns each account's balance at the end of the day before date (YYYY-MM-DD): the
+// month-end snapshot of the previous month plus this month's rows dated before date. Missing snapshots
+// are computed from the newest one still valid and stored for the next call.
+func (r *BalanceHistoryRepository) BalancesBefore(userID uuid.UUID, date string) (map[string]int64, error) {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, fmt.Errorf("balances before: %w", err)
+	}
+	monthStart := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	prevMonth := monthStart.AddDate(0, -1, 0).Format("2006-01")
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin balance snapshots: %w", err)
+	}
+	defer tx.Rollback()
+
+	balances, err := snapshotBalancesTx(tx, userID, prevMonth)
+	if err != nil {
+		return nil, err
+	}
+
+	partial, err := tx.Query(`
+		SELECT t.account_id, SUM(t.amount)
+		FROM accounts a
+		JOIN transactions t ON t.account_id = a.id
+		WHERE a.user_id = ? AND t.transaction_date >= ? AND t.transaction_date < ?
+		GROUP BY t.account_id`, userID.String(), monthStart.Format("2006-01-02"), date)
+	if err != nil {
+		return nil, fmt.Errorf("month to date balances: %w", err)
+	}
+	defer partial.Close()
+	for partial.Next() {
+		var (
+			id  string
+			sum int64
+		)
+		if err := partial.Scan(&id, &sum); err != nil {
+			return nil, fmt.Errorf("scan month to date balance: %w", err)
+		}
+		balances[id] += sum
+	}
+	if err := partial.Err(); err != nil {
+		return nil, err
+	}
+	partial.Close()
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit balance snapshots: %w", err)
+	}
+	return balances, nil
+}
+
+// snapshotBalancesTx returns every account's balance at the end of month (YYYY-MM), filling in the
+// snapshots between each account's newest valid one and month.
+func snapshotBalancesTx(tx *sql.Tx, userID uuid.UUID, month string) (map[string]int64, error) {
+	rows, err := tx.Query(`
+		SELECT a.id, s.month, COALESCE(s.balance, 0)
+		FROM accounts a
+		LEFT JOIN account_balance_snapshots s ON s.account_id = a.id AND s.month = (
+			SELECT MAX(month) FROM account_balance_snapshots
+			WHERE account_id = a.id AND month <= ?)
+		WHERE a.user_id = ?`, month, userID.String())
+	if err != nil {
+		return nil, fmt.Errorf("latest balance snapshots: %w", err)
+	}
+	defer rows.Close()
+	balances := make(map[string]int64)
+	stale := make(map[string]string) // account id -> newest snapshot month, "" when none
+	for rows.Next() {
+		var (
+			id      string
+			snapped sql.NullString
+			balance int64
+		)
+		if err := rows.Scan(&id, &snapped, &balance); err != nil {
+			return nil, fmt.Errorf("scan balance snapshot: %w", err)
+		}
+		balances[id] = balance
+		if snapped.String != month {
+			stale[id] = snapped.String
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+	if len(stale) == 0 {
+		return balances, nil
+	}
+
+	end, err := time.Parse("2006-01", month)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot month: %w", err)
+	}
+	until := end.AddDate(0, 1, 0).Format("2006-01-02")
+	for id, since := range stale {
+		from := "0000-01-01"
+		if since != "" {
+			t, err := time.Parse("2006-01", since)
+			if err != nil {
+				return nil, fmt.Errorf("snapshot month: %w", err)
+			}
+			from = t.AddDate(0, 1, 0).Format("2006-01-02")
+		}
+		sums, err := tx.Query(`
+			SELECT substr(transaction_date, 1, 7), SUM(amount)
+			FROM transactions
+			WHERE account_id = ? AND transaction_date >= ? AND transaction_date < ?
+			GROUP BY 1
+			ORDER BY 1`, id, from, until)
+		if err != nil {
+			return nil, fmt.Errorf("monthly account sums: %w", err)
+		}
+		type monthSum struct {
+			month string
+			sum   int64
+		}
+		var months []monthSum
+		for sums.Next() {
+			var m monthSum
+			if err := sums.Scan(&m.month, &m.sum); err != nil {
+				sums.Close()
+				return nil, fmt.Errorf("scan monthly account sum: %w", err)
+			}
+			months = append(months, m)
+		}
+		if err := sums.Err(); err != nil {
+			sums.Close()
+			return nil, err
+		}
+		sums.Close()
+
+		// Store a snapshot for every month with activity and for month itself, so later reports over
+		// earlier or later ranges start from a nearby month end.
+		balance := balances[id]
+		for _, m := range months {
+			balance += m.sum
+			if err := putSnapshotTx(tx, id, m.month, balance); err != nil {
+				return nil, err
+			}
+		}
+		if err := putSnapshotTx(tx, id, month, balance); err != nil {
+			return nil, err
+		}
+		balances[id] = balance
+	}
+	return balances, nil
+}
+
+func putSnapshotTx(tx *sql.Tx, accountID, month string, balance int64) error {
+	_, err := tx.Exec(`
+		INSERT INTO account_balance_snapshots (account_id, month, balance, created_at)
+		VALUES (?, ?, ?, datetime('now'))
+		ON CONFLICT(account_id, month) DO UPDATE SET balance = excluded.balance, created_at = excluded.created_at
+	`, accountID, month, balance)
+	if err != nil {
+		return fmt.Errorf("store balance snapshot: %w", err)
+	}
+	return nil
+}
+
+// DailyChanges sums each account's rows per day between from and to (YYYY-MM-DD, inclusive), oldest
+// first.
+func (r *BalanceHistoryRepository) DailyChanges(userID uuid.UUID, from, to string) ([]BalanceChange, error) {
+	rows, err := r.db.Query(`
+		SELECT t.account_id, t.transaction_date, SUM(t.amount)
+		FROM accounts a
+		JOIN transactions t ON t.account_id = a.id
+		WHERE a.user_id = ? AND t.transaction_date >= ? AND t.transaction_date <= ?
+		GROUP BY t.account_id, t.transaction_date
+		ORDER BY t.transaction_date`, userID.String(), from, to)
+	if err != nil {
+		return nil, fmt.Errorf("daily balance changes: %w", err)
+	}
+	defer rows.Close()
+	var out []BalanceChange
+	for rows.Next() {
+		var c BalanceChange
+		if err := rows.Scan(&c.AccountID, &c.Date, &c.Amount); err != nil {
+			return nil, fmt.Errorf("scan balance change: %w", err)
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
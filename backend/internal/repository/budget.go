@@ -60,6 +60,8 @@ func (r *BudgetRepository) Create(
 	allocated int64,
 	period, start, end string,
 	responsible *string,
+	autoReset bool,
+	carryOver string,
 	alert int,
 	icon, color string,
 	sort int,
@@ -84,21 +86,21 @@ func (r *BudgetRepository) Create(
 		INSERT INTO budgets (
 			id, user_id, category_id, name, allocated_amount, spent_amount,
 			budget_period, period_start_date, period_end_date, responsible_person,
-			auto_reset, alert_percentage, is_active, icon, color, sort_order,
+			auto_reset, carry_over, alert_percentage, is_active, icon, color, sort_order,
 			created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, 0, ?, ?, ?, ?, 1, ?, 1, ?, ?, ?, datetime('now'), datetime('now'))
+		) VALUES (?, ?, ?, ?, ?, 0, ?, ?, ?, ?, ?, ?, ?, 1, ?, ?, ?, datetime('now'), datetime('now'))
 	`
 	_, err = tx.Exec(q,
 		id.String(), userID.String(), categoryID.String(), name, allocated,
 		period, start, end,
-		rp, a,
+		rp, sqlBool(autoReset), carryOver, a,
 		icon, color, sort,
 	)
 	if err != nil {
 		return uuid.Nil, fmt.Errorf("insert budget: %w", err)
 	}
 
-	if err := insertBudgetCommonPurchasesTx(tx, id, commonPurchases, 80); err != nil {
+	if err := insertBudgetCommonPurchasesTx(tx, id, commonPurchases, maxCommonPurchasesPerBudget); err != nil {
 		return uuid.Nil, err
 	}
 
@@ -123,7 +125,7 @@ func (r *BudgetRepository) AppendCommonPurchases(budgetID, userID uuid.UUID, ite
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	if err := insertBudgetCommonPurchasesTx(tx, budgetID, items, 80); err != nil {
+	if err := insertBudgetCommonPurchasesTx(tx, budgetID, items, maxCommonPurchasesPerBudget); err != nil {
 		return err
 	}
 	if err := tx.Commit(); err != nil {
@@ -132,14 +134,21 @@ func (r *BudgetRepository) AppendCommonPurchases(budgetID, userID uuid.UUID, ite
 	return nil
 }
 
+// insertBudgetCommonPurchasesTx appends presets after the budget's existing ones. maxRows caps the
+// budget's total preset count, and an item+store pair already on the budget (or repeated in items)
+// is rejected with *DuplicateCommonPurchaseError.
 func insertBudgetCommonPurchasesTx(
 	tx *sql.Tx,
 	budgetID uuid.UUID,
 	items []models.CreateCommonPurchaseInput,
 	maxRows int,
 ) error {
-	if len(items) > maxRows {
-		return fmt.Errorf("too many common_purchases (max %d)", maxRows)
+	existing, nextSort, err := commonPurchaseKeysTx(tx, budgetID, uuid.Nil)
+	if err != nil {
+		return err
+	}
+	if len(existing)+len(items) > maxRows {
+		return ErrTooManyCommonPurchases
 	}
 	insert := `
 		INSERT INTO budget_common_purchases (
@@ -164,11 +173,16 @@ func insertBudgetCommonPurchasesTx(
 		if it.Store != nil && strings.TrimSpace(*it.Store) != "" {
 			store = sql.NullString{String: strings.TrimSpace(*it.Store), Valid: true}
 		}
+		key := commonPurchaseKey(it.Item, store.String)
+		if existing[key] {
+			return &DuplicateCommonPurchaseError{Item: strings.TrimSpace(it.Item), Store: store.String}
+		}
+		existing[key] = true
 		freq := 1
 		if it.IsFrequentlyUsed != nil && !*it.IsFrequentlyUsed {
 			freq = 0
 		}
-		sortOrd := i
+		sortOrd := nextSort + i
 		if it.SortOrder != nil {
 			sortOrd = *it.SortOrder
 		}
@@ -184,27 +198,35 @@ func insertBudgetCommonPurchasesTx(
 	return nil
 }
 
-// ListBudgetCardsPayload loads active budgets with category display and nested preset / line-item rows for the showcase API.
-func (r *BudgetRepository) ListBudgetCardsPayload(userID uuid.UUID) ([]models.BudgetCardAPI, error) {
+// ListBudgetCardsPayload loads active (or, with archived, only archived) budgets with category display and
+// nested preset / line-item rows for the showcase API.
+func (r *BudgetRepository) ListBudgetCardsPayload(userID uuid.UUID, archived bool) ([]models.BudgetCardAPI, error) {
 	q := `
 		SELECT b.id, b.name, b.icon, b.color,
 			b.allocated_amount, b.spent_amount,
 			b.budget_period, b.period_start_date, b.period_end_date,
+			b.auto_reset, b.carry_over, b.carryover_amount,
+			COALESCE((
+				SELECT h.spent_amount FROM budget_period_history h
+				WHERE h.budget_id = b.id
+				ORDER BY h.period_start_date DESC LIMIT 1
+			), 0) AS last_period_spent,
 			b.category_id, c.name AS category_name
 		FROM budgets b
 		INNER JOIN categories c ON c.id = b.category_id
-		WHERE b.user_id = ? AND b.is_active = 1 AND c.is_active = 1
+		WHERE b.user_id = ? AND b.is_active = ? AND c.is_active = 1
 		ORDER BY b.sort_order, b.name`
 
-	rows, err := r.db.Query(q, userID.String())
+	rows, err := r.db.Query(q, userID.String(), sqlBool(!archived))
 	if err != nil {
 		return nil, fmt.Errorf("list budgets: %w", err)
 	}
 	defer rows.Close()
 
 	type row struct {
-		id, name, icon, color, period, start, end, catID, catName string
-		allocated, spent                                            int64
+		id, name, icon, color, period, start, end, carryOver, catID, catName string
+		allocated, spent, carryover, lastSpent                               int64
+		autoReset                                                            int
 	}
 	var budgetRows []row
 	idOrder := []string{}
@@ -214,6 +236,7 @@ func (r *BudgetRepository) ListBudgetCardsPayload(userID uuid.UUID) ([]models.Bu
 			&rr.id, &rr.name, &rr.icon, &rr.color,
 			&rr.allocated, &rr.spent,
 			&rr.period, &rr.start, &rr.end,
+			&rr.autoReset, &rr.carryOver, &rr.carryover, &rr.lastSpent,
 			&rr.catID, &rr.catName); err != nil {
 			return nil, fmt.Errorf("scan budget: %w", err)
 		}
@@ -227,7 +250,7 @@ func (r *BudgetRepository) ListBudgetCardsPayload(userID uuid.UUID) ([]models.Bu
 		return []models.BudgetCardAPI{}, nil
 	}
 
-	commonByBudget, err := r.loadCommonPurchasesMapped(userID, idOrder)
+	commonByBudget, err := r.loadCommonPurchasesMapped(userID, idOrder, false)
 	if err != nil {
 		return nil, err
 	}
@@ -253,6 +276,11 @@ func (r *BudgetRepository) ListBudgetCardsPayload(userID uuid.UUID) ([]models.Bu
 			BudgetPeriod:    br.period,
 			PeriodStartDate: br.start,
 			PeriodEndDate:   br.end,
+			IsActive:        !archived,
+			AutoReset:       br.autoReset == 1,
+			CarryOver:       br.carryOver,
+			CarryoverAmount: br.carryover,
+			LastPeriodSpent: br.lastSpent,
 			CategoryID:      br.catID,
 			CommonPurchases: commonByBudget[br.id],
 			LineItems:       linesByBudget[br.id],
@@ -261,7 +289,9 @@ func (r *BudgetRepository) ListBudgetCardsPayload(userID uuid.UUID) ([]models.Bu
 	return out, nil
 }
 
-func (r *BudgetRepository) loadCommonPurchasesMapped(userID uuid.UUID, budgetIDs []string) (map[string][]models.BudgetCommonPurchaseAPI, error) {
+// loadCommonPurchasesMapped groups presets (with usage from logged expenses) by budget id, in
+// sort_order or, with byUsage, most-used first.
+func (r *BudgetRepository) loadCommonPurchasesMapped(userID uuid.UUID, budgetIDs []string, byUsage bool) (map[string][]models.BudgetCommonPurchaseAPI, error) {
 	out := make(map[string][]models.BudgetCommonPurchaseAPI)
 	if len(budgetIDs) == 0 {
 		return out, nil
@@ -275,12 +305,24 @@ func (r *BudgetRepository) loadCommonPurchasesMapped(userID uuid.UUID, budgetIDs
 		placeholders += "?"
 		args = append(args, id)
 	}
+	order := "p.sort_order, p.item"
+	if byUsage {
+		order = "use_count DESC, last_used_at DESC, p.sort_order, p.item"
+	}
 	q := fmt.Sprintf(`
-		SELECT p.id, p.budget_id, p.item, p.quantity, p.estimated_amount, p.store, p.is_frequently_used, p.sort_order
+		SELECT p.id, p.budget_id, p.item, p.quantity, p.estimated_amount, p.store, p.is_frequently_used, p.sort_order,
+			COALESCE(u.use_count, 0) AS use_count, u.last_used_at
 		FROM budget_common_purchases p
 		INNER JOIN budgets b ON b.id = p.budget_id AND b.user_id = ?
+		LEFT JOIN (
+			SELECT bt.common_purchase_id, COUNT(*) AS use_count, MAX(t.transaction_date) AS last_used_at
+			FROM budget_transactions bt
+			INNER JOIN transactions t ON t.id = bt.transaction_id
+			WHERE bt.common_purchase_id IS NOT NULL
+			GROUP BY bt.common_purchase_id
+		) u ON u.common_purchase_id = p.id
 		WHERE p.budget_id IN (%s)
-		ORDER BY p.budget_id, p.sort_order, p.item`, placeholders)
+		ORDER BY p.budget_id, %s`, placeholders, order)
 
 	rows, err := r.db.Query(q, args...)
 	if err != nil {
@@ -294,8 +336,10 @@ func (r *BudgetRepository) loadCommonPurchasesMapped(userID uuid.UUID, budgetIDs
 			qty, store    sql.NullString
 			amt           int64
 			freq, sort    int
+			uses          int
+			lastUsed      sql.NullString
 		)
-		if err := rows.Scan(&id, &bid, &item, &qty, &amt, &store, &freq, &sort); err != nil {
+		if err := rows.Scan(&id, &bid, &item, &qty, &amt, &store, &freq, &sort, &uses, &lastUsed); err != nil {
 			return nil, fmt.Errorf("scan common purchase: %w", err)
 		}
 		cp := models.BudgetCommonPurchaseAPI{
@@ -304,6 +348,8 @@ func (r *BudgetRepository) loadCommonPurchasesMapped(userID uuid.UUID, budgetIDs
 			EstimatedAmount:  amt,
 			IsFrequentlyUsed: freq == 1,
 			SortOrder:        sort,
+			UseCount:         uses,
+			LastUsedAt:       lastUsed.String,
 		}
 		if qty.Valid {
 			cp.Quantity = qty.String
@@ -332,8 +378,8 @@ func (r *BudgetRepository) loadBudgetLineItemsMapped(userID uuid.UUID, budgetIDs
 		placeholders += "?"
 		args = append(args, id)
 	}
-	// Same scope as DB triggers that bump budgets.spent_amount: expense rows whose category and date
-	// fall into this budget's window. Optional budget_transactions row enriches item/qty/store when
+	// Same scope as DB triggers that bump budgets.spent_amount: expense rows whose category (or its
+	// parent) and date fall into this budget's window. Optional budget_transactions row enriches item/qty/store when
 	// the expense was created with budget_id (e.g. from the Budget page).
 	q := fmt.Sprintf(`
 		SELECT
@@ -348,7 +394,7 @@ func (r *BudgetRepository) loadBudgetLineItemsMapped(userID uuid.UUID, budgetIDs
 			ABS(t.amount), t.transaction_date, t.description
 		FROM budgets b
 		INNER JOIN transactions t ON t.user_id = b.user_id
-			AND t.category_id = b.category_id
+			AND t.category_id IN (SELECT id FROM categories WHERE id = b.category_id OR parent_category_id = b.category_id)
 			AND t.transaction_type = 'expense'
 			AND t.transaction_date >= b.period_start_date
 			AND t.transaction_date <= b.period_end_date
@@ -406,3 +452,190 @@ func (r *BudgetRepository) loadBudgetLineItemsMapped(userID uuid.UUID, budgetIDs
 	}
 	return out, nil
 }
+
+const budgetSelect = `
+	SELECT b.id, b.user_id, b.category_id, b.name, b.allocated_amount, b.spent_amount, b.budget_period,
+		b.period_start_date, b.period_end_date, b.responsible_person,
+		b.auto_reset, b.carry_over, b.carryover_amount, COALESCE(b.alert_percentage, 80), b.is_active,
+		COALESCE(b.icon, ''), COALESCE(b.color, ''), COALESCE(b.sort_order, 0),
+		b.created_at, b.updated_at,
+		COALESCE((
+			SELECT h.spent_amount FROM budget_period_history h
+			WHERE h.budget_id = b.id
+			ORDER BY h.period_start_date DESC LIMIT 1
+		), 0)
+	FROM budgets b`
+
+// scanBudget reads one budgetSelect row; LastPeriodSpent comes from the most recent closed window.
+func scanBudget(s rowScanner) (*models.Budget, error) {
+	var (
+		b                                    models.Budget
+		idStr, userStr, catStr               string
+		startStr, endStr, createdStr, updStr string
+		responsible                          sql.NullString
+		autoReset, active                    int
+	)
+	if err := s.Scan(
+		&idStr, &userStr, &catStr, &b.Name, &b.AllocatedAmount, &b.SpentAmount, &b.BudgetPeriod,
+		&startStr, &endStr, &responsible,
+		&autoReset, &b.CarryOver, &b.CarryoverAmount, &b.AlertPercentage, &active,
+		&b.Icon, &b.Color, &b.SortOrder,
+		&createdStr, &updStr,
+		&b.LastPeriodSpent,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scan budget: %w", err)
+	}
+	var err error
+	if b.ID, err = uuid.Parse(idStr); err != nil {
+		return nil, fmt.Errorf("parse budget id: %w", err)
+	}
+	if b.UserID, err = uuid.Parse(userStr); err != nil {
+		return nil, fmt.Errorf("parse user id: %w", err)
+	}
+	if b.CategoryID, err = uuid.Parse(catStr); err != nil {
+		return nil, fmt.Errorf("parse category id: %w", err)
+	}
+	if b.PeriodStartDate, err = parseSQLiteTime(startStr); err != nil {
+		return nil, fmt.Errorf("parse period_start_date: %w", err)
+	}
+	if b.PeriodEndDate, err = parseSQLiteTime(endStr); err != nil {
+		return nil, fmt.Errorf("parse period_end_date: %w", err)
+	}
+	b.AutoReset = autoReset == 1
+	b.IsActive = active == 1
+	if responsible.Valid {
+		b.ResponsiblePerson = &responsible.String
+	}
+	b.CreatedAt, _ = parseSQLiteTime(createdStr)
+	b.UpdatedAt, _ = parseSQLiteTime(updStr)
+	b.RemainingAmount = b.CalculateRemainingAmount()
+	return &b, nil
+}
+
+// GetForUser loads one budget owned by the user, active or not.
+func (r *BudgetRepository) GetForUser(id, userID uuid.UUID) (*models.Budget, error) {
+	row := r.db.QueryRow(budgetSelect+` WHERE b.id = ? AND b.user_id = ?`, id.String(), userID.String())
+	b, err := scanBudget(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrBudgetNotOwned
+	}
+	return b, err
+}
+
+// ListActive returns active budgets for one user, or for all users when userID is uuid.Nil.
+// Rows with non-UUID ids (hand-written sample data) are skipped.
+func (r *BudgetRepository) ListActive(userID uuid.UUID) ([]models.Budget, error) {
+	q := budgetSelect + ` WHERE b.is_active = 1 AND (? = '' OR b.user_id = ?) ORDER BY b.user_id, b.sort_order, b.name`
+	uid := ""
+	if userID != uuid.Nil {
+		uid = userID.String()
+	}
+	rows, err := r.db.Query(q, uid, uid)
+	if err != nil {
+		return nil, fmt.Errorf("list active budgets: %w", err)
+	}
+	defer rows.Close()
+
+	var out []models.Budget
+	for rows.Next() {
+		b, err := scanBudget(rows)
+		if err != nil {
+			continue
+		}
+		out = append(out, *b)
+	}
+	return out, rows.Err()
+}
+
+// Update rewrites the editable columns of one owned budget; a new period start also becomes the
+// rollover anchor day. The tr_budgets_* triggers only adjust
+// spent_amount incrementally for active budgets, so it is recomputed from transactions in the same
+// DB transaction when the category or period window changes or an archived budget is restored.
+func (r *BudgetRepository) Update(b *models.Budget) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var (
+		curCat, curStart, curEnd string
+		curActive                int
+	)
+	err = tx.QueryRow(`SELECT category_id, period_start_date, period_end_date, is_active FROM budgets WHERE id = ? AND user_id = ?`,
+		b.ID.String(), b.UserID.String()).Scan(&curCat, &curStart, &curEnd, &curActive)
+	if err == sql.ErrNoRows {
+		return ErrBudgetNotOwned
+	}
+	if err != nil {
+		return fmt.Errorf("load budget: %w", err)
+	}
+
+	start := b.PeriodStartDate.Format("2006-01-02")
+	end := b.PeriodEndDate.Format("2006-01-02")
+	spent := b.SpentAmount
+	if curCat != b.CategoryID.String() || curStart != start || curEnd != end || (curActive == 0 && b.IsActive) {
+		if spent, err = windowSpentTx(tx, b.UserID, b.CategoryID, start, end); err != nil {
+			return err
+		}
+	}
+
+	var rp interface{}
+	if b.ResponsiblePerson != nil {
+		rp = *b.ResponsiblePerson
+	}
+	_, err = tx.Exec(`
+		UPDATE budgets SET
+			category_id = ?, name = ?, allocated_amount = ?, spent_amount = ?,
+			budget_period = ?, period_start_date = ?, period_end_date = ?, responsible_person = ?,
+			auto_reset = ?, carry_over = ?, alert_percentage = ?, is_active = ?,
+			icon = ?, color = ?, sort_order = ?,
+			period_anchor_day = CASE WHEN period_start_date = ? THEN period_anchor_day END,
+			updated_at = datetime('now')
+		WHERE id = ? AND user_id = ?
+	`,
+		b.CategoryID.String(), b.Name, b.AllocatedAmount, spent,
+		string(b.BudgetPeriod), start, end, rp,
+		sqlBool(b.AutoReset), b.CarryOver, b.AlertPercentage, sqlBool(b.IsActive),
+		b.Icon, b.Color, b.SortOrder,
+		start,
+		b.ID.String(), b.UserID.String(),
+	)
+	if err != nil {
+		return fmt.Errorf("update budget: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit budget: %w", err)
+	}
+	b.SpentAmount = spent
+	return nil
+}
+
+// Reorder sets sort_order to each budget's position in ids. Every id must belong to the user.
+func (r *BudgetRepository) Reorder(userID uuid.UUID, ids []uuid.UUID) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for i, id := range ids {
+		res, err := tx.Exec(`UPDATE budgets SET sort_order = ?, updated_at = datetime('now') WHERE id = ? AND user_id = ?`,
+			i, id.String(), userID.String())
+		if err != nil {
+			return fmt.Errorf("reorder budgets: %w", err)
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return fmt.Errorf("reorder budgets: %w", err)
+		} else if n == 0 {
+			return ErrBudgetNotOwned
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit reorder: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"monman-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// BudgetWindow is the part of a budgets row that period rollover reads. Dates are YYYY-MM-DD.
+type BudgetWindow struct {
+	ID              uuid.UUID
+	UserID          uuid.UUID
+	CategoryID      uuid.UUID
+	AllocatedAmount int64
+	CarryoverAmount int64
+	BudgetPeriod    string
+	PeriodStartDate string
+	PeriodEndDate   string
+	CarryOver       string
+	AutoReset       bool
+	AnchorDay       int // day of month monthly/yearly windows start on, before clamping
+}
+
+const budgetWindowSelect = `
+	SELECT id, user_id, category_id, allocated_amount, carryover_amount,
+		budget_period, period_start_date, period_end_date, carry_over, auto_reset,
+		COALESCE(period_anchor_day, CAST(strftime('%d', period_start_date) AS INTEGER))
+	FROM budgets`
+
+func scanBudgetWindow(s rowScanner) (*BudgetWindow, error) {
+	var (
+		w                      BudgetWindow
+		idStr, userStr, catStr string
+		autoReset              int
+	)
+	if err := s.Scan(
+		&idStr, &userStr, &catStr, &w.AllocatedAmount, &w.CarryoverAmount,
+		&w.BudgetPeriod, &w.PeriodStartDate, &w.PeriodEndDate, &w.CarryOver, &autoReset,
+		&w.AnchorDay,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scan budget window: %w", err)
+	}
+	var err error
+	if w.ID, err = uuid.Parse(idStr); err != nil {
+		return nil, fmt.Errorf("parse budget id: %w", err)
+	}
+	if w.UserID, err = uuid.Parse(userStr); err != nil {
+		return nil, fmt.Errorf("parse user id: %w", err)
+	}
+	if w.CategoryID, err = uuid.Parse(catStr); err != nil {
+		return nil, fmt.Errorf("parse category id: %w", err)
+	}
+	w.AutoReset = autoReset == 1
+	return &w, nil
+}
+
+// GetWindow loads the current window of an active budget owned by the user.
+func (r *BudgetRepository) GetWindow(id, userID uuid.UUID) (*BudgetWindow, error) {
+	row := r.db.QueryRow(budgetWindowSelect+` WHERE id = ? AND user_id = ? AND is_active = 1`, id.String(), userID.String())
+	w, err := scanBudgetWindow(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrBudgetNotOwned
+	}
+	return w, err
+}
+
+// ListRolloverDue returns active auto_reset budgets (all users) whose window ended before today.
+// Rows with non-UUID ids (hand-written sample data) are skipped.
+func (r *BudgetRepository) ListRolloverDue(today string) ([]BudgetWindow, error) {
+	rows, err := r.db.Query(budgetWindowSelect+`
+		WHERE is_active = 1 AND auto_reset = 1 AND period_end_date < ?
+		ORDER BY period_end_date, id`, today)
+	if err != nil {
+		return nil, fmt.Errorf("list rollover due budgets: %w", err)
+	}
+	defer rows.Close()
+
+	var out []BudgetWindow
+	for rows.Next() {
+		w, err := scanBudgetWindow(rows)
+		if err != nil {
+			continue
+		}
+		out = append(out, *w)
+	}
+	return out, rows.Err()
+}
+
+// ClosePeriod records w's current window in budget_period_history and moves the budget to
+// [nextStart, nextEnd] in one DB transaction. Spent amounts are recomputed from transactions with
+// the same scope as the tr_budgets_* triggers, so windows the triggers never saw are still correct.
+// carryover maps (available, spent) to the signed amount carried into the next window.
+// closed is nil when the window was already rolled by another caller.
+func (r *BudgetRepository) ClosePeriod(w BudgetWindow, nextStart, nextEnd string, carryover func(available, spent int64) int64) (closed *models.BudgetPeriodHistoryAPI, err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	spent, err := windowSpentTx(tx, w.UserID, w.CategoryID, w.PeriodStartDate, w.PeriodEndDate)
+	if err != nil {
+		return nil, err
+	}
+	carry := carryover(w.AllocatedAmount+w.CarryoverAmount, spent)
+	nextSpent, err := windowSpentTx(tx, w.UserID, w.CategoryID, nextStart, nextEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := tx.Exec(`
+		UPDATE budgets SET
+			period_start_date = ?, period_end_date = ?,
+			spent_amount = ?, carryover_amount = ?, period_anchor_day = ?,
+			updated_at = datetime('now')
+		WHERE id = ? AND period_start_date = ? AND is_active = 1
+	`, nextStart, nextEnd, nextSpent, carry, w.AnchorDay, w.ID.String(), w.PeriodStartDate)
+	if err != nil {
+		return nil, fmt.Errorf("advance budget period: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("advance budget period: %w", err)
+	} else if n == 0 {
+		return nil, nil
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO budget_period_history (
+			id, budget_id, user_id, budget_period, period_start_date, period_end_date,
+			allocated_amount, carryover_in, spent_amount, carryover_out, closed_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'))
+		ON CONFLICT (budget_id, period_start_date) DO UPDATE SET
+			period_end_date = excluded.period_end_date,
+			allocated_amount = excluded.allocated_amount,
+			carryover_in = excluded.carryover_in,
+			spent_amount = excluded.spent_amount,
+			carryover_out = excluded.carryover_out,
+			closed_at = excluded.closed_at
+	`,
+		uuid.New().String(), w.ID.String(), w.UserID.String(), w.BudgetPeriod,
+		w.PeriodStartDate, w.PeriodEndDate,
+		w.AllocatedAmount, w.CarryoverAmount, spent, carry,
+	); err != nil {
+		return nil, fmt.Errorf("insert budget period history: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+	return &models.BudgetPeriodHistoryAPI{
+		PeriodStartDate: w.PeriodStartDate,
+		PeriodEndDate:   w.PeriodEndDate,
+		AllocatedAmount: w.AllocatedAmount,
+		CarryoverIn:     w.CarryoverAmount,
+		SpentAmount:     spent,
+		CarryoverOut:    carry,
+	}, nil
+}
+
+func windowSpentTx(tx *sql.Tx, userID, categoryID uuid.UUID, start, end string) (int64, error) {
+	var spent int64
+	err := tx.QueryRow(`
+		SELECT COALESCE(SUM(ABS(COALESCE(base_amount, amount))), 0) FROM transactions
+		WHERE user_id = ? AND category_id IN `+categorySubtree+` AND transaction_type = 'expense'
+		  AND transaction_date >= ? AND transaction_date <= ?
+	`, userID.String(), categoryID.String(), categoryID.String(), start, end).Scan(&spent)
+	if err != nil {
+		return 0, fmt.Errorf("budget window spent: %w", err)
+	}
+	return spent, nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import "testing"
+
+func TestClosePeriodRecomputesSpentAndCarriesOver(t *testing.T) {
+	conn := migratedTestDB(t)
+	txRepo, budRepo := NewTransactionRepository(conn), NewBudgetRepository(conn)
+	user := testUser(t, conn)
+	acc := testAccount(t, conn, user, "BCA")
+	food := testCategory(t, conn, user, "Makan")
+	budget := testBudget(t, conn, user, food, "Makan", 100000, "2026-10-01", "2026-10-31")
+	testExec(t, conn, `UPDATE budgets SET carry_over = 'both' WHERE id = ?`, budget.String())
+
+	testExpense(t, txRepo, user, acc, food, 30000, "2026-10-10", nil)
+	testExpense(t, txRepo, user, acc, food, 5000, "2026-11-02", nil) // already in the next window
+	// Drift the triggers never caused must not survive the rollover.
+	testExec(t, conn, `UPDATE budgets SET spent_amount = 99999 WHERE id = ?`, budget.String())
+
+	w, err := budRepo.GetWindow(budget, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed, err := budRepo.ClosePeriod(*w, "2026-11-01", "2026-11-30", func(available, spent int64) int64 {
+		return available - spent
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if closed == nil || closed.SpentAmount != 30000 || closed.CarryoverOut != 70000 {
+		t.Fatalf("closed window = %+v, want spent 30000 and carryover 70000", closed)
+	}
+	if got := budgetSpent(t, conn, budget); got != 5000 {
+		t.Errorf("spent in the new window = %d, want 5000", got)
+	}
+	if got := testInt(t, conn, `SELECT carryover_amount FROM budgets WHERE id = ?`, budget.String()); got != 70000 {
+		t.Errorf("carryover_amount = %d, want 70000", got)
+	}
+
+	// The triggers keep counting in the new window.
+	testExpense(t, txRepo, user, acc, food, 1000, "2026-11-20", nil)
+	if got := budgetSpent(t, conn, budget); got != 6000 {
+		t.Errorf("spent after a new expense = %d, want 6000", got)
+	}
+
+	// A second caller holding the old window changes nothing.
+	again, err := budRepo.ClosePeriod(*w, "2026-11-01", "2026-11-30", func(available, spent int64) int64 { return 0 })
+	if err != nil || again != nil {
+		t.Errorf("stale ClosePeriod = %+v, %v; want nil, nil", again, err)
+	}
+	if got := testInt(t, conn, `SELECT COUNT(*) FROM budget_period_history WHERE budget_id = ?`, budget.String()); got != 1 {
+		t.Errorf("history rows = %d, want 1", got)
+	}
+}
@@ -0,0 +1,342 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ErrCategorizationRuleNotFound indicates the rule id does not exist for this user.
+var ErrCategorizationRuleNotFound = errors.New("categorization rule not found")
+
+// CategorizationRule is one categorization_rules row. Amount bounds are magnitudes in cents, inclusive.
+// CategoryType, Category, Account and Budget are read-only names from the joined rows; BudgetID is only
+// set on reads while the budget is active and still on the rule's category.
+type CategorizationRule struct {
+	ID           uuid.UUID
+	UserID       uuid.UUID
+	Name         string
+	Priority     int
+	MatchField   string // description | store | location | any
+	MatchType    string // contains | regex
+	Pattern      string
+	MinAmount    *int64
+	MaxAmount    *int64
+	AccountID    *uuid.UUID
+	CategoryID   uuid.UUID
+	BudgetID     *uuid.UUID
+	Item         *string
+	Tags         []string
+	IsActive     bool
+	CategoryType string
+	Category     string
+	Account      string
+	Budget       string
+}
+
+// RuleCandidate is an income or expense transaction the history re-apply may recategorize.
+type RuleCandidate struct {
+	ID          uuid.UUID
+	AccountID   uuid.UUID
+	Date        string
+	Description string
+	Location    string
+	Amount      int64 // magnitude in cents
+	Type        string
+	CategoryID  string
+	Category    string
+	Tags        []string
+}
+
+// RuleChange is what re-applying rules does to one transaction. Budget, when set, links it to a budget
+// on CategoryID; Tags are the ones it does not have yet.
+type RuleChange struct {
+	TransactionID uuid.UUID
+	Description   string
+	Amount        int64 // magnitude in cents
+	FromCategory  string
+	CategoryID    uuid.UUID
+	Budget        *BudgetLinkParams
+	Tags          []string
+}
+
+// CategorizationRuleRepository stores auto-categorization rules and applies them to past transactions.
+type CategorizationRuleRepository struct {
+	db *sql.DB
+}
+
+func NewCategorizationRuleRepository(db *sql.DB) *CategorizationRuleRepository {
+	return &CategorizationRuleRepository{db: db}
+}
+
+const categorizationRuleSelect = `
+	SELECT r.id, r.user_id, r.name, r.priority, r.match_field, r.match_type, r.pattern,
+		r.min_amount, r.max_amount, r.account_id, r.category_id, b.id, r.item, r.tags, r.is_active,
+		c.category_type, c.name, COALESCE(a.name, ''), COALESCE(b.name, '')
+	FROM categorization_rules r
+	INNER JOIN categories c ON c.id = r.category_id
+	LEFT JOIN accounts a ON a.id = r.account_id
+	LEFT JOIN budgets b ON b.id = r.budget_id AND b.category_id = r.category_id AND b.is_active = 1`
+
+// ListForUser returns the user's rules in the order they are tried: priority, then oldest first.
+func (r *CategorizationRuleRepository) ListForUser(userID uuid.UUID) ([]CategorizationRule, error) {
+	rows, err := r.db.Query(categorizationRuleSelect+`
+		WHERE r.user_id = ?
+		ORDER BY r.priority, r.created_at, r.id`, userID.String())
+	if err != nil {
+		return nil, fmt.Errorf("list categorization rules: %w", err)
+	}
+	defer rows.Close()
+	out := []CategorizationRule{}
+	for rows.Next() {
+		rule, err := scanCategorizationRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *rule)
+	}
+	return out, rows.Err()
+}
+
+// GetForUser loads one rule owned by the user.
+func (r *CategorizationRuleRepository) GetForUser(id, userID uuid.UUID) (*CategorizationRule, error) {
+	rule, err := scanCategorizationRule(r.db.QueryRow(categorizationRuleSelect+`
+		WHERE r.id = ? AND r.user_id = ?`, id.String(), userID.String()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCategorizationRuleNotFound
+	}
+	return rule, err
+}
+
+func scanCategorizationRule(s rowScanner) (*CategorizationRule, error) {
+	var (
+		rule                   CategorizationRule
+		idStr, userStr, catStr string
+		accStr, budStr, item   sql.NullString
+		minAmt, maxAmt         sql.NullInt64
+		tags                   string
+		active                 int
+	)
+	if err := s.Scan(&idStr, &userStr, &rule.Name, &rule.Priority, &rule.MatchField, &rule.MatchType, &rule.Pattern,
+		&minAmt, &maxAmt, &accStr, &catStr, &budStr, &item, &tags, &active,
+		&rule.CategoryType, &rule.Category, &rule.Account, &rule.Budget); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scan categorization rule: %w", err)
+	}
+	var err error
+	if rule.ID, err = uuid.Parse(idStr); err != nil {
+		return nil, fmt.Errorf("parse rule id: %w", err)
+	}
+	if rule.UserID, err = uuid.Parse(userStr); err != nil {
+		return nil, fmt.Errorf("parse rule user id: %w", err)
+	}
+	if rule.CategoryID, err = uuid.Parse(catStr); err != nil {
+		return nil, fmt.Errorf("parse rule category id: %w", err)
+	}
+	if accStr.Valid {
+		id, err := uuid.Parse(accStr.String)
+		if err != nil {
+			return nil, fmt.Errorf("parse rule account id: %w", err)
+		}
+		rule.AccountID = &id
+	}
+	if budStr.Valid {
+		id, err := uuid.Parse(budStr.String)
+		if err != nil {
+			return nil, fmt.Errorf("parse rule budget id: %w", err)
+		}
+		rule.BudgetID = &id
+	}
+	if minAmt.Valid {
+		rule.MinAmount = &minAmt.Int64
+	}
+	if maxAmt.Valid {
+		rule.MaxAmount = &maxAmt.Int64
+	}
+	if item.Valid {
+		rule.Item = &item.String
+	}
+	if tags != "" {
+		rule.Tags = strings.Split(tags, ",")
+	}
+	rule.IsActive = active == 1
+	return &rule, nil
+}
+
+// Create stores a validated rule.
+func (r *CategorizationRuleRepository) Create(rule CategorizationRule) (uuid.UUID, error) {
+	id := uuid.New()
+	_, err := r.db.Exec(`
+		INSERT INTO categorization_rules (
+			id, user_id, name, priority, match_field, match_type, pattern, min_amount, max_amount,
+			account_id, category_id, budget_id, item, tags, is_active, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'), datetime('now'))
+	`, id.String(), rule.UserID.String(), rule.Name, rule.Priority, rule.MatchField, rule.MatchType, rule.Pattern,
+		nullableInt64(rule.MinAmount), nullableInt64(rule.MaxAmount),
+		nullableUUID(rule.AccountID), rule.CategoryID.String(), nullableUUID(rule.BudgetID), nullableString(rule.Item),
+		strings.Join(rule.Tags, ","), sqlBool(rule.IsActive))
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("insert categorization rule: %w", err)
+	}
+	return id, nil
+}
+
+// Update rewrites the editable columns of one owned rule.
+func (r *CategorizationRuleRepository) Update(rule CategorizationRule) error {
+	res, err := r.db.Exec(`
+		UPDATE categorization_rules SET
+			name = ?, priority = ?, match_field = ?, match_type = ?, pattern = ?, min_amount = ?, max_amount = ?,
+			account_id = ?, category_id = ?, budget_id = ?, item = ?, tags = ?, is_active = ?,
+			updated_at = datetime('now')
+		WHERE id = ? AND user_id = ?
+	`, rule.Name, rule.Priority, rule.MatchField, rule.MatchType, rule.Pattern,
+		nullableInt64(rule.MinAmount), nullableInt64(rule.MaxAmount),
+		nullableUUID(rule.AccountID), rule.CategoryID.String(), nullableUUID(rule.BudgetID), nullableString(rule.Item),
+		strings.Join(rule.Tags, ","), sqlBool(rule.IsActive),
+		rule.ID.String(), rule.UserID.String())
+	if err != nil {
+		return fmt.Errorf("update categorization rule: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("update categorization rule: %w", err)
+	} else if n == 0 {
+		return ErrCategorizationRuleNotFound
+	}
+	return nil
+}
+
+// Delete removes a rule; transactions it already categorized keep their category and tags.
+func (r *CategorizationRuleRepository) Delete(id, userID uuid.UUID) error {
+	res, err := r.db.Exec(`DELETE FROM categorization_rules WHERE id = ? AND user_id = ?`, id.String(), userID.String())
+	if err != nil {
+		return fmt.Errorf("delete categorization rule: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("delete categorization rule: %w", err)
+	} else if n == 0 {
+		return ErrCategorizationRuleNotFound
+	}
+	return nil
+}
+
+// Candidates returns the user's income and expense transactions between from and to (inclusive, either
+// may be empty) that rules may recategorize, oldest first. Rows logged against a budget or posted by an
+// investment trade keep their category and are left out, as are rows outside categoryIDs when given.
+func (r *CategorizationRuleRepository) Candidates(userID uuid.UUID, from, to string, accountID *uuid.UUID, categoryIDs []string) ([]RuleCandidate, error) {
+	q := `
+		SELECT t.id, t.account_id, t.transaction_date, t.description, COALESCE(t.location_name, ''),
+			ABS(t.amount), t.transaction_type, COALESCE(t.category_id, ''), COALESCE(c.name, ''),
+			(SELECT COALESCE(GROUP_CONCAT(tag, ','), '') FROM transaction_tags WHERE transaction_id = t.id)
+		FROM transactions t
+		LEFT JOIN categories c ON c.id = t.category_id
+		WHERE t.user_id = ? AND t.transaction_type IN ('income', 'expense')
+			AND NOT EXISTS (SELECT 1 FROM budget_transactions bt WHERE bt.transaction_id = t.id)
+			AND NOT EXISTS (SELECT 1 FROM investment_trades it WHERE it.transaction_id = t.id)`
+	args := []any{userID.String()}
+	if from != "" {
+		q += ` AND t.transaction_date >= ?`
+		args = append(args, from)
+	}
+	if to != "" {
+		q += ` AND t.transaction_date <= ?`
+		args = append(args, to)
+	}
+	if accountID != nil {
+		q += ` AND t.account_id = ?`
+		args = append(args, accountID.String())
+	}
+	if len(categoryIDs) > 0 {
+		q += ` AND (t.category_id IS NULL OR t.category_id IN (` + placeholders(len(categoryIDs)) + `))`
+		for _, id := range categoryIDs {
+			args = append(args, id)
+		}
+	}
+	rows, err := r.db.Query(q+` ORDER BY t.transaction_date, t.created_at, t.id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list rule candidates: %w", err)
+	}
+	defer rows.Close()
+	var out []RuleCandidate
+	for rows.Next() {
+		var (
+			c                   RuleCandidate
+			idStr, accStr, tags string
+		)
+		if err := rows.Scan(&idStr, &accStr, &c.Date, &c.Description, &c.Location, &c.Amount, &c.Type,
+			&c.CategoryID, &c.Category, &tags); err != nil {
+			return nil, fmt.Errorf("scan rule candidate: %w", err)
+		}
+		if c.ID, err = uuid.Parse(idStr); err != nil {
+			return nil, fmt.Errorf("parse transaction id: %w", err)
+		}
+		if c.AccountID, err = uuid.Parse(accStr); err != nil {
+			return nil, fmt.Errorf("parse account id: %w", err)
+		}
+		if tags != "" {
+			c.Tags = strings.Split(tags, ",")
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// ApplyChanges recategorizes the user's transactions in one DB transaction, links budgets, adds tags
+// and recomputes the spent amount of every budget on the old and new categories (and their parents).
+func (r *CategorizationRuleRepository) ApplyChanges(userID uuid.UUID, changes []RuleChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	seen := make(map[string]bool)
+	var ids []string
+	for _, c := range changes {
+		for _, id := range []string{c.FromCategory, c.CategoryID.String()} {
+			if id != "" && !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	affected, err := withParentCategoriesTx(tx, ids)
+	if err != nil {
+		return err
+	}
+	// Start from exact totals so the spent triggers cannot drive a drifted budget below zero.
+	if err := refreshBudgetSpentTx(tx, userID, affected...); err != nil {
+		return err
+	}
+	for _, c := range changes {
+		if c.FromCategory != c.CategoryID.String() {
+			if _, err := tx.Exec(`
+				UPDATE transactions SET category_id = ?, updated_at = datetime('now')
+				WHERE id = ? AND user_id = ?`, c.CategoryID.String(), c.TransactionID.String(), userID.String()); err != nil {
+				return fmt.Errorf("recategorize transaction: %w", err)
+			}
+		}
+		if c.Budget != nil {
+			if err := insertBudgetLinkTx(tx, c.TransactionID, c.Description, c.Budget); err != nil {
+				return err
+			}
+		}
+		if err := insertTagsTx(tx, c.TransactionID, c.Tags); err != nil {
+			return err
+		}
+	}
+	if err := refreshBudgetSpentTx(tx, userID, affected...); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
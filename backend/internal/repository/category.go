@@ -0,0 +1,498 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+
+	"monman-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrCategoryNotFound indicates the category id does not exist or is not visible to this user.
+	ErrCategoryNotFound = errors.New("category not found")
+	// ErrCategoryInUse indicates a category still has transactions, budgets, rules or subcategories.
+	ErrCategoryInUse = errors.New("category is in use")
+)
+
+// categorySubtree selects a category and its subcategories; it takes the category id twice.
+const categorySubtree = `(SELECT id FROM categories WHERE id = ? OR parent_category_id = ?)`
+
+// categorySelect lists the categories visible to a user (system and own); it takes the user id twice.
+// is_active is false for system categories the user has hidden.
+const categorySelect = `
+	SELECT c.id, c.user_id, c.name, c.category_type, c.parent_category_id, COALESCE(c.icon, ''),
+		COALESCE(c.color, ''), c.is_system,
+		c.is_active = 1 AND NOT EXISTS (
+			SELECT 1 FROM hidden_categories h WHERE h.category_id = c.id AND h.user_id = ?),
+		c.created_at, c.updated_at
+	FROM categories c
+	WHERE (c.user_id IS NULL OR c.user_id = ?)`
+
+// ListForUser returns every system and own category, archived and hidden ones included, system
+// categories first and then by name.
+func (r *CategoryRepository) ListForUser(userID uuid.UUID) ([]models.Category, error) {
+	rows, err := r.db.Query(categorySelect+` ORDER BY c.is_system DESC, c.name`, userID.String(), userID.String())
+	if err != nil {
+		return nil, fmt.Errorf("list categories: %w", err)
+	}
+	defer rows.Close()
+	var out []models.Category
+	for rows.Next() {
+		c, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *c)
+	}
+	return out, rows.Err()
+}
+
+// Get returns one category visible to the user.
+func (r *CategoryRepository) Get(userID, id uuid.UUID) (*models.Category, error) {
+	c, err := scanCategory(r.db.QueryRow(categorySelect+` AND c.id = ?`, userID.String(), userID.String(), id.String()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCategoryNotFound
+	}
+	return c, err
+}
+
+func scanCategory(s rowScanner) (*models.Category, error) {
+	var (
+		c                      models.Category
+		idStr                  string
+		userStr, parentStr     sql.NullString
+		isSystem, active       int
+		createdStr, updatedStr string
+	)
+	if err := s.Scan(&idStr, &userStr, &c.Name, &c.CategoryType, &parentStr, &c.Icon, &c.Color, &isSystem, &active,
+		&createdStr, &updatedStr); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scan category: %w", err)
+	}
+	var err error
+	if c.ID, err = uuid.Parse(idStr); err != nil {
+		return nil, fmt.Errorf("parse category id: %w", err)
+	}
+	if userStr.Valid {
+		id, err := uuid.Parse(userStr.String)
+		if err != nil {
+			return nil, fmt.Errorf("parse category user id: %w", err)
+		}
+		c.UserID = &id
+	}
+	if parentStr.Valid {
+		id, err := uuid.Parse(parentStr.String)
+		if err != nil {
+			return nil, fmt.Errorf("parse parent category id: %w", err)
+		}
+		c.ParentCategoryID = &id
+	}
+	c.IsSystem = isSystem == 1
+	c.IsActive = active == 1
+	c.CreatedAt, _ = parseSQLiteTime(createdStr)
+	c.UpdatedAt, _ = parseSQLiteTime(updatedStr)
+	return &c, nil
+}
+
+// Create stores a new user category.
+func (r *CategoryRepository) Create(userID uuid.UUID, c *models.Category) (uuid.UUID, error) {
+	id := uuid.New()
+	_, err := r.db.Exec(`
+		INSERT INTO categories (
+			id, user_id, name, category_type, parent_category_id, icon, color, is_system, is_active,
+			created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, 0, 1, datetime('now'), datetime('now'))
+	`, id.String(), userID.String(), c.Name, c.CategoryType, nullableUUID(c.ParentCategoryID), c.Icon, c.Color)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("insert category: %w", err)
+	}
+	return id, nil
+}
+
+// Update saves the name, parent, icon and color of one of the user's own categories. Moving it to
+// another parent recomputes the active budgets on the old and new parent, which count its spending.
+func (r *CategoryRepository) Update(userID uuid.UUID, c *models.Category) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var oldParent sql.NullString
+	err = tx.QueryRow(`SELECT parent_category_id FROM categories WHERE id = ? AND user_id = ?`,
+		c.ID.String(), userID.String()).Scan(&oldParent)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrCategoryNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("load category: %w", err)
+	}
+	if _, err := tx.Exec(`
+		UPDATE categories SET name = ?, parent_category_id = ?, icon = ?, color = ?, updated_at = datetime('now')
+		WHERE id = ? AND user_id = ?
+	`, c.Name, nullableUUID(c.ParentCategoryID), c.Icon, c.Color, c.ID.String(), userID.String()); err != nil {
+		return fmt.Errorf("update category: %w", err)
+	}
+	newParent := ""
+	if c.ParentCategoryID != nil {
+		newParent = c.ParentCategoryID.String()
+	}
+	if oldParent.String != newParent {
+		if err := refreshBudgetSpentTx(tx, userID, oldParent.String, newParent); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// refreshBudgetSpentTx recomputes spent_amount of the user's active budgets on the given categories.
+func refreshBudgetSpentTx(tx *sql.Tx, userID uuid.UUID, categoryIDs ...string) error {
+	var ids []any
+	for _, id := range categoryIDs {
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	rows, err := tx.Query(`
+		SELECT id, category_id, period_start_date, period_end_date FROM budgets
+		WHERE user_id = ? AND is_active = 1 AND category_id IN (`+placeholders(len(ids))+`)`,
+		append([]any{userID.String()}, ids...)...)
+	if err != nil {
+		return fmt.Errorf("load budgets to refresh: %w", err)
+	}
+	type window struct{ id, category, start, end string }
+	var windows []window
+	for rows.Next() {
+		var w window
+		if err := rows.Scan(&w.id, &w.category, &w.start, &w.end); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan budget to refresh: %w", err)
+		}
+		windows = append(windows, w)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("load budgets to refresh: %w", err)
+	}
+	for _, w := range windows {
+		categoryID, err := uuid.Parse(w.category)
+		if err != nil {
+			return fmt.Errorf("parse category id: %w", err)
+		}
+		spent, err := windowSpentTx(tx, userID, categoryID, w.start, w.end)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE budgets SET spent_amount = ? WHERE id = ?`, spent, w.id); err != nil {
+			return fmt.Errorf("refresh budget spent: %w", err)
+		}
+	}
+	return nil
+}
+
+// SetActive archives or restores one of the user's own categories.
+func (r *CategoryRepository) SetActive(userID, id uuid.UUID, active bool) error {
+	res, err := r.db.Exec(`
+		UPDATE categories SET is_active = ?, updated_at = datetime('now')
+		WHERE id = ? AND user_id = ?`, sqlBool(active), id.String(), userID.String())
+	if err != nil {
+		return fmt.Errorf("set category active: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("set category active: %w", err)
+	} else if n == 0 {
+		return ErrCategoryNotFound
+	}
+	return nil
+}
+
+// SetHidden hides a system category from the user's lists, or shows it again.
+func (r *CategoryRepository) SetHidden(userID, id uuid.UUID, hidden bool) error {
+	q := `DELETE FROM hidden_categories WHERE user_id = ? AND category_id = ?`
+	if hidden {
+		q = `INSERT INTO hidden_categories (user_id, category_id) VALUES (?, ?) ON CONFLICT DO NOTHING`
+	}
+	if _, err := r.db.Exec(q, userID.String(), id.String()); err != nil {
+		return fmt.Errorf("set category hidden: %w", err)
+	}
+	return nil
+}
+
+// CountActiveChildren returns how many active subcategories the category has for the user.
+func (r *CategoryRepository) CountActiveChildren(userID, id uuid.UUID) (int, error) {
+	var n int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM categories
+		WHERE parent_category_id = ? AND is_active = 1 AND (user_id IS NULL OR user_id = ?)`,
+		id.String(), userID.String()).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("count subcategories: %w", err)
+	}
+	return n, nil
+}
+
+// HasOwnChildren reports whether the user has subcategories under the category, archived ones included.
+func (r *CategoryRepository) HasOwnChildren(userID, id uuid.UUID) (bool, error) {
+	var n int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM categories WHERE parent_category_id = ? AND user_id = ?`,
+		id.String(), userID.String()).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("count subcategories: %w", err)
+	}
+	return n > 0, nil
+}
+
+// NameTaken reports whether another category visible to the user already has name (case-insensitive)
+// with the same type and parent.
+func (r *CategoryRepository) NameTaken(userID uuid.UUID, name, ctype string, parentID *uuid.UUID, exceptID uuid.UUID) (bool, error) {
+	var n int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM categories
+		WHERE (user_id IS NULL OR user_id = ?) AND LOWER(name) = LOWER(?) AND category_type = ?
+		  AND COALESCE(parent_category_id, '') = COALESCE(?, '') AND id != ?`,
+		userID.String(), name, ctype, nullableUUID(parentID), exceptID.String()).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("check category name: %w", err)
+	}
+	return n > 0, nil
+}
+
+// Delete removes one of the user's own categories. It fails with ErrCategoryInUse while transactions,
+// budgets, recurring rules, income sources, categorization rules or subcategories refer to it.
+func (r *CategoryRepository) Delete(userID, id uuid.UUID) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var used int
+	err = tx.QueryRow(`
+		SELECT (SELECT COUNT(*) FROM transactions WHERE category_id = ?)
+			+ (SELECT COUNT(*) FROM budgets WHERE category_id = ?)
+			+ (SELECT COUNT(*) FROM recurring_transactions WHERE category_id = ?)
+			+ (SELECT COUNT(*) FROM income_sources WHERE category_id = ?)
+			+ (SELECT COUNT(*) FROM categorization_rules WHERE category_id = ?)
+			+ (SELECT COUNT(*) FROM categories WHERE parent_category_id = ?)`,
+		id.String(), id.String(), id.String(), id.String(), id.String(), id.String()).Scan(&used)
+	if err != nil {
+		return fmt.Errorf("check category use: %w", err)
+	}
+	if used > 0 {
+		return ErrCategoryInUse
+	}
+	res, err := tx.Exec(`DELETE FROM categories WHERE id = ? AND user_id = ?`, id.String(), userID.String())
+	if err != nil {
+		return fmt.Errorf("delete category: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("delete category: %w", err)
+	} else if n == 0 {
+		return ErrCategoryNotFound
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// CategoryTotal is what one category's transactions add up to in a range.
+type CategoryTotal struct {
+	Amount int64 // positive, in the base currency
+	Count  int
+}
+
+// Totals sums the user's income or expense transactions between from and to (YYYY-MM-DD, inclusive)
+// by category id; uncategorized rows are keyed "".
+func (r *CategoryRepository) Totals(userID uuid.UUID, ctype, from, to string) (map[string]CategoryTotal, error) {
+	rows, err := r.db.Query(`
+		SELECT COALESCE(category_id, ''), SUM(ABS(COALESCE(base_amount, amount))), COUNT(*)
+		FROM transactions
+		WHERE user_id = ? AND transaction_type = ? AND transaction_date >= ? AND transaction_date <= ?
+		GROUP BY 1`, userID.String(), ctype, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("category totals: %w", err)
+	}
+	defer rows.Close()
+	out := make(map[string]CategoryTotal)
+	for rows.Next() {
+		var (
+			id string
+			t  CategoryTotal
+		)
+		if err := rows.Scan(&id, &t.Amount, &t.Count); err != nil {
+			return nil, fmt.Errorf("scan category total: %w", err)
+		}
+		out[id] = t
+	}
+	return out, rows.Err()
+}
+
+// CategoryMergeResult counts what a merge moved; BudgetChanges covers every active budget whose spending
+// it touched.
+type CategoryMergeResult struct {
+	Transactions        int
+	Budgets             int
+	RecurringRules      int
+	IncomeSources       int
+	CategorizationRules int
+	Subcategories       int
+	BudgetChanges       []models.BudgetSpentChangeAPI
+}
+
+// Merge moves the user's transactions, budgets, recurring rules, income sources, categorization rules and
+// subcategories from source to target in one DB transaction, recomputes the spent amount of the affected
+// budgets and then deletes source (removeSource) or hides it for the user. With dryRun everything is rolled back after
+// counting, so the result shows exactly what the merge would do. Reconciled transactions move too:
+// their category does not affect the reconciled balance.
+func (r *CategoryRepository) Merge(userID, sourceID, targetID uuid.UUID, removeSource, dryRun bool) (*CategoryMergeResult, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Budgets on source, target and their parents count the moved spending.
+	affected, err := withParentCategoriesTx(tx, []string{sourceID.String(), targetID.String()})
+	if err != nil {
+		return nil, err
+	}
+	before, err := budgetSpentTx(tx, userID, affected)
+	if err != nil {
+		return nil, err
+	}
+	// The spent triggers adjust these budgets as transactions move, so start them from exact totals
+	// rather than whatever drift they carry.
+	if err := refreshBudgetSpentTx(tx, userID, affected...); err != nil {
+		return nil, err
+	}
+
+	res := &CategoryMergeResult{}
+	src, dst, uid := sourceID.String(), targetID.String(), userID.String()
+	for _, m := range []struct {
+		q     string
+		args  []any
+		count *int
+	}{
+		{`UPDATE transactions SET category_id = ?, updated_at = datetime('now') WHERE category_id = ? AND user_id = ?`,
+			[]any{dst, src, uid}, &res.Transactions},
+		{`UPDATE budgets SET category_id = ?, updated_at = datetime('now') WHERE category_id = ? AND user_id = ?`,
+			[]any{dst, src, uid}, &res.Budgets},
+		{`UPDATE recurring_transactions SET category_id = ?, updated_at = datetime('now') WHERE category_id = ? AND user_id = ?`,
+			[]any{dst, src, uid}, &res.RecurringRules},
+		{`UPDATE income_sources SET category_id = ?, updated_at = datetime('now') WHERE category_id = ? AND user_id = ?`,
+			[]any{dst, src, uid}, &res.IncomeSources},
+		{`UPDATE categorization_rules SET category_id = ?, updated_at = datetime('now') WHERE category_id = ? AND user_id = ?`,
+			[]any{dst, src, uid}, &res.CategorizationRules},
+		{`UPDATE categories SET parent_category_id = ?, updated_at = datetime('now') WHERE parent_category_id = ? AND user_id = ?`,
+			[]any{dst, src, uid}, &res.Subcategories},
+	} {
+		out, err := tx.Exec(m.q, m.args...)
+		if err != nil {
+			return nil, fmt.Errorf("merge category: %w", err)
+		}
+		n, err := out.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("merge category: %w", err)
+		}
+		*m.count = int(n)
+	}
+	if err := refreshBudgetSpentTx(tx, userID, affected...); err != nil {
+		return nil, err
+	}
+	after, err := budgetSpentTx(tx, userID, affected)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range after {
+		b.SpentBefore = before[b.BudgetID].SpentAfter
+		res.BudgetChanges = append(res.BudgetChanges, b)
+	}
+	sort.Slice(res.BudgetChanges, func(i, j int) bool { return res.BudgetChanges[i].Name < res.BudgetChanges[j].Name })
+
+	if removeSource {
+		_, err = tx.Exec(`DELETE FROM categories WHERE id = ? AND user_id = ?`, src, uid)
+	} else {
+		_, err = tx.Exec(`INSERT INTO hidden_categories (user_id, category_id) VALUES (?, ?) ON CONFLICT DO NOTHING`, uid, src)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("remove merged category: %w", err)
+	}
+	if dryRun {
+		return res, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+	return res, nil
+}
+
+// withParentCategoriesTx returns categoryIDs together with their parent categories.
+func withParentCategoriesTx(tx *sql.Tx, categoryIDs []string) ([]string, error) {
+	if len(categoryIDs) == 0 {
+		return nil, nil
+	}
+	args := make([]any, 0, 2*len(categoryIDs))
+	for range 2 {
+		for _, id := range categoryIDs {
+			args = append(args, id)
+		}
+	}
+	rows, err := tx.Query(`
+		SELECT id FROM categories WHERE id IN (`+placeholders(len(categoryIDs))+`)
+		UNION SELECT parent_category_id FROM categories
+		WHERE id IN (`+placeholders(len(categoryIDs))+`) AND parent_category_id IS NOT NULL`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("load parent categories: %w", err)
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan parent category: %w", err)
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
+
+// budgetSpentTx returns the user's active budgets on categoryIDs by id, with their spent amount in
+// SpentAfter.
+func budgetSpentTx(tx *sql.Tx, userID uuid.UUID, categoryIDs []string) (map[string]models.BudgetSpentChangeAPI, error) {
+	out := make(map[string]models.BudgetSpentChangeAPI)
+	if len(categoryIDs) == 0 {
+		return out, nil
+	}
+	args := []any{userID.String()}
+	for _, id := range categoryIDs {
+		args = append(args, id)
+	}
+	rows, err := tx.Query(`
+		SELECT id, name, category_id, spent_amount FROM budgets
+		WHERE user_id = ? AND is_active = 1 AND category_id IN (`+placeholders(len(categoryIDs))+`)`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("load budget spent: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var b models.BudgetSpentChangeAPI
+		if err := rows.Scan(&b.BudgetID, &b.Name, &b.CategoryID, &b.SpentAfter); err != nil {
+			return nil, fmt.Errorf("scan budget spent: %w", err)
+		}
+		out[b.BudgetID] = b
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// mergeSnapshot dumps the rows a category merge may touch.
+func mergeSnapshot(t *testing.T, conn *sql.DB) []string {
+	t.Helper()
+	var out []string
+	for _, q := range []string{
+		`SELECT id, category_id, updated_at FROM transactions ORDER BY id`,
+		`SELECT id, category_id, spent_amount, updated_at FROM budgets ORDER BY id`,
+		`SELECT id, COALESCE(parent_category_id, ''), updated_at FROM categories WHERE user_id IS NOT NULL ORDER BY id`,
+		`SELECT user_id, category_id FROM hidden_categories ORDER BY category_id`,
+	} {
+		rows, err := conn.Query(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cols, _ := rows.Columns()
+		for rows.Next() {
+			vals := make([]any, len(cols))
+			ptrs := make([]any, len(cols))
+			for i := range vals {
+				ptrs[i] = &vals[i]
+			}
+			if err := rows.Scan(ptrs...); err != nil {
+				t.Fatal(err)
+			}
+			out = append(out, fmt.Sprintf("%v", vals))
+		}
+		rows.Close()
+	}
+	return out
+}
+
+func TestCategoryMerge(t *testing.T) {
+	conn := migratedTestDB(t)
+	txRepo, catRepo := NewTransactionRepository(conn), NewCategoryRepository(conn)
+	user := testUser(t, conn)
+	acc := testAccount(t, conn, user, "BCA")
+	snacks, food := testCategory(t, conn, user, "Jajan"), testCategory(t, conn, user, "Makan")
+	snackBudget := testBudget(t, conn, user, snacks, "Jajan", 100000, "2026-10-01", "2026-10-31")
+	foodBudget := testBudget(t, conn, user, food, "Makan", 500000, "2026-10-01", "2026-10-31")
+
+	testExpense(t, txRepo, user, acc, snacks, 10000, "2026-10-03", nil)
+	testExpense(t, txRepo, user, acc, food, 20000, "2026-10-04", nil)
+	testExpense(t, txRepo, user, acc, food, 7000, "2026-09-30", nil) // outside both windows
+	// Drift on the target budget: the merge recomputes it from transactions.
+	testExec(t, conn, `UPDATE budgets SET spent_amount = 1 WHERE id = ?`, foodBudget.String())
+
+	before := mergeSnapshot(t, conn)
+	dry, err := catRepo.Merge(user, snacks, food, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dry.Transactions != 1 || dry.Budgets != 1 {
+		t.Errorf("dry run moved %d transactions and %d budgets, want 1 and 1", dry.Transactions, dry.Budgets)
+	}
+	if after := mergeSnapshot(t, conn); !reflect.DeepEqual(after, before) {
+		t.Errorf("dry run changed the database:\nbefore %v\nafter  %v", before, after)
+	}
+
+	res, err := catRepo.Merge(user, snacks, food, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, dry) {
+		t.Errorf("merge result = %+v, dry run promised %+v", res, dry)
+	}
+	// Both budgets now track the target category: 10000 moved + 20000 already there.
+	for _, b := range []struct {
+		name string
+		id   uuid.UUID
+	}{{"snack budget", snackBudget}, {"food budget", foodBudget}} {
+		var spent int64
+		var category string
+		if err := conn.QueryRow(`SELECT spent_amount, category_id FROM budgets WHERE id = ?`, b.id.String()).Scan(&spent, &category); err != nil {
+			t.Fatal(err)
+		}
+		if spent != 30000 || category != food.String() {
+			t.Errorf("%s = spent %d on %s, want 30000 on %s", b.name, spent, category, food)
+		}
+	}
+	if got := testInt(t, conn, `SELECT COUNT(*) FROM transactions WHERE category_id = ?`, snacks.String()); got != 0 {
+		t.Errorf("transactions left on the source = %d, want 0", got)
+	}
+	if got := testInt(t, conn, `SELECT COUNT(*) FROM hidden_categories WHERE category_id = ?`, snacks.String()); got != 1 {
+		t.Errorf("source hidden = %d, want 1", got)
+	}
+
+	// The triggers keep both budgets right afterwards.
+	testExpense(t, txRepo, user, acc, food, 500, "2026-10-20", nil)
+	if got := budgetSpent(t, conn, foodBudget); got != 30500 {
+		t.Errorf("food budget after a new expense = %d, want 30500", got)
+	}
+}
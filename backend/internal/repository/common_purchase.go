@@ -0,0 +1,350 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"monman-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// maxCommonPurchasesPerBudget caps the pembelian umum presets stored on one budget.
+const maxCommonPurchasesPerBudget = 80
+
+var (
+	// ErrCommonPurchaseNotFound indicates the preset id does not exist on this budget.
+	ErrCommonPurchaseNotFound = errors.New("common purchase not found")
+	// ErrTooManyCommonPurchases indicates a write would push the budget past maxCommonPurchasesPerBudget.
+	ErrTooManyCommonPurchases = fmt.Errorf("too many common purchases (max %d per budget)", maxCommonPurchasesPerBudget)
+)
+
+// DuplicateCommonPurchaseError reports a preset whose item+store already exists on the budget.
+type DuplicateCommonPurchaseError struct {
+	Item  string
+	Store string
+}
+
+func (e *DuplicateCommonPurchaseError) Error() string {
+	if e.Store == "" {
+		return fmt.Sprintf("common purchase %q already exists", e.Item)
+	}
+	return fmt.Sprintf("common purchase %q at %q already exists", e.Item, e.Store)
+}
+
+// commonPurchaseKey is the case-insensitive item+store identity used for duplicate detection.
+func commonPurchaseKey(item, store string) string {
+	return strings.ToLower(strings.TrimSpace(item)) + "\x00" + strings.ToLower(strings.TrimSpace(store))
+}
+
+// commonPurchaseKeysTx returns the item+store keys on the budget (skipping exclude) and the next
+// free sort_order.
+func commonPurchaseKeysTx(tx *sql.Tx, budgetID, exclude uuid.UUID) (map[string]bool, int, error) {
+	rows, err := tx.Query(`
+		SELECT id, item, COALESCE(store, ''), COALESCE(sort_order, 0)
+		FROM budget_common_purchases WHERE budget_id = ?`, budgetID.String())
+	if err != nil {
+		return nil, 0, fmt.Errorf("load common purchases: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make(map[string]bool)
+	next := 0
+	for rows.Next() {
+		var (
+			id, item, store string
+			sort            int
+		)
+		if err := rows.Scan(&id, &item, &store, &sort); err != nil {
+			return nil, 0, fmt.Errorf("scan common purchase: %w", err)
+		}
+		if sort >= next {
+			next = sort + 1
+		}
+		if id == exclude.String() {
+			continue
+		}
+		keys[commonPurchaseKey(item, store)] = true
+	}
+	return keys, next, rows.Err()
+}
+
+// ListCommonPurchases returns the presets of an owned active budget in display order, or most-used
+// first with byUsage.
+func (r *BudgetRepository) ListCommonPurchases(budgetID, userID uuid.UUID, byUsage bool) ([]models.BudgetCommonPurchaseAPI, error) {
+	ok, err := r.BudgetOwned(budgetID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrBudgetNotOwned
+	}
+	m, err := r.loadCommonPurchasesMapped(userID, []string{budgetID.String()}, byUsage)
+	if err != nil {
+		return nil, err
+	}
+	if list := m[budgetID.String()]; list != nil {
+		return list, nil
+	}
+	return []models.BudgetCommonPurchaseAPI{}, nil
+}
+
+// GetCommonPurchase loads one preset of an owned active budget.
+func (r *BudgetRepository) GetCommonPurchase(budgetID, userID, id uuid.UUID) (*models.BudgetCommonPurchaseAPI, error) {
+	list, err := r.ListCommonPurchases(budgetID, userID, false)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		if list[i].ID == id.String() {
+			return &list[i], nil
+		}
+	}
+	return nil, ErrCommonPurchaseNotFound
+}
+
+// UpdateCommonPurchase overwrites the editable fields of one preset, rejecting an item+store that
+// another preset on the budget already uses.
+func (r *BudgetRepository) UpdateCommonPurchase(budgetID, userID uuid.UUID, cp *models.BudgetCommonPurchaseAPI) error {
+	ok, err := r.BudgetOwned(budgetID, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrBudgetNotOwned
+	}
+	id, err := uuid.Parse(cp.ID)
+	if err != nil {
+		return ErrCommonPurchaseNotFound
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	keys, _, err := commonPurchaseKeysTx(tx, budgetID, id)
+	if err != nil {
+		return err
+	}
+	if keys[commonPurchaseKey(cp.Item, cp.Store)] {
+		return &DuplicateCommonPurchaseError{Item: cp.Item, Store: cp.Store}
+	}
+	res, err := tx.Exec(`
+		UPDATE budget_common_purchases
+		SET item = ?, quantity = ?, estimated_amount = ?, store = ?,
+			is_frequently_used = ?, sort_order = ?, updated_at = datetime('now')
+		WHERE id = ? AND budget_id = ?
+	`,
+		cp.Item, emptyAsNull(cp.Quantity), cp.EstimatedAmount, emptyAsNull(cp.Store),
+		sqlBool(cp.IsFrequentlyUsed), cp.SortOrder, cp.ID, budgetID.String(),
+	)
+	if err != nil {
+		return fmt.Errorf("update common purchase: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("update common purchase: %w", err)
+	} else if n == 0 {
+		return ErrCommonPurchaseNotFound
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// DeleteCommonPurchase removes one preset of an owned active budget.
+func (r *BudgetRepository) DeleteCommonPurchase(budgetID, userID, id uuid.UUID) error {
+	ok, err := r.BudgetOwned(budgetID, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrBudgetNotOwned
+	}
+	res, err := r.db.Exec(`DELETE FROM budget_common_purchases WHERE id = ? AND budget_id = ?`,
+		id.String(), budgetID.String())
+	if err != nil {
+		return fmt.Errorf("delete common purchase: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("delete common purchase: %w", err)
+	} else if n == 0 {
+		return ErrCommonPurchaseNotFound
+	}
+	return nil
+}
+
+// ReplaceCommonPurchases makes items the budget's complete preset list. Presets whose item+store
+// match an existing row update that row in place (keeping its id); the rest are inserted, and rows
+// not in items are deleted. Sort order follows items unless an item sets its own.
+func (r *BudgetRepository) ReplaceCommonPurchases(budgetID, userID uuid.UUID, items []models.CreateCommonPurchaseInput) error {
+	ok, err := r.BudgetOwned(budgetID, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrBudgetNotOwned
+	}
+	if len(items) > maxCommonPurchasesPerBudget {
+		return ErrTooManyCommonPurchases
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows, err := tx.Query(`SELECT id, item, COALESCE(store, '') FROM budget_common_purchases WHERE budget_id = ?`,
+		budgetID.String())
+	if err != nil {
+		return fmt.Errorf("load common purchases: %w", err)
+	}
+	existing := make(map[string]string)
+	for rows.Next() {
+		var id, item, store string
+		if err := rows.Scan(&id, &item, &store); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan common purchase: %w", err)
+		}
+		existing[commonPurchaseKey(item, store)] = id
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return fmt.Errorf("load common purchases: %w", err)
+	}
+	rows.Close()
+
+	seen := make(map[string]bool, len(items))
+	kept := make(map[string]bool, len(items))
+	for i, it := range items {
+		item := strings.TrimSpace(it.Item)
+		var qty, store string
+		if it.Quantity != nil {
+			qty = strings.TrimSpace(*it.Quantity)
+		}
+		if it.Store != nil {
+			store = strings.TrimSpace(*it.Store)
+		}
+		key := commonPurchaseKey(item, store)
+		if seen[key] {
+			return &DuplicateCommonPurchaseError{Item: item, Store: store}
+		}
+		seen[key] = true
+		freq := it.IsFrequentlyUsed == nil || *it.IsFrequentlyUsed
+		sortOrd := i
+		if it.SortOrder != nil {
+			sortOrd = *it.SortOrder
+		}
+
+		if id, ok := existing[key]; ok {
+			kept[id] = true
+			_, err := tx.Exec(`
+				UPDATE budget_common_purchases
+				SET item = ?, quantity = ?, estimated_amount = ?, store = ?,
+					is_frequently_used = ?, sort_order = ?, updated_at = datetime('now')
+				WHERE id = ?
+			`, item, emptyAsNull(qty), it.EstimatedAmount, emptyAsNull(store), sqlBool(freq), sortOrd, id)
+			if err != nil {
+				return fmt.Errorf("update common purchase: %w", err)
+			}
+			continue
+		}
+		_, err := tx.Exec(`
+			INSERT INTO budget_common_purchases (
+				id, budget_id, item, quantity, estimated_amount, store,
+				is_frequently_used, sort_order,
+				created_at, updated_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, datetime('now'), datetime('now'))
+		`,
+			uuid.New().String(), budgetID.String(), item, emptyAsNull(qty), it.EstimatedAmount,
+			emptyAsNull(store), sqlBool(freq), sortOrd,
+		)
+		if err != nil {
+			return fmt.Errorf("insert common purchase: %w", err)
+		}
+	}
+	for _, id := range existing {
+		if kept[id] {
+			continue
+		}
+		if _, err := tx.Exec(`DELETE FROM budget_common_purchases WHERE id = ?`, id); err != nil {
+			return fmt.Errorf("delete common purchase: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// ReorderCommonPurchases sets sort_order from the position of each preset id in the list.
+func (r *BudgetRepository) ReorderCommonPurchases(budgetID, userID uuid.UUID, ids []uuid.UUID) error {
+	ok, err := r.BudgetOwned(budgetID, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrBudgetNotOwned
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for i, id := range ids {
+		res, err := tx.Exec(`
+			UPDATE budget_common_purchases SET sort_order = ?, updated_at = datetime('now')
+			WHERE id = ? AND budget_id = ?`, i, id.String(), budgetID.String())
+		if err != nil {
+			return fmt.Errorf("reorder common purchases: %w", err)
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return fmt.Errorf("reorder common purchases: %w", err)
+		} else if n == 0 {
+			return ErrCommonPurchaseNotFound
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit reorder: %w", err)
+	}
+	return nil
+}
+
+// emptyAsNull stores an empty optional text column as NULL.
+func emptyAsNull(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// CommonPurchaseLastAccount returns the account of the most recent expense logged from the preset,
+// or nil when it has never been logged.
+func (r *BudgetRepository) CommonPurchaseLastAccount(purchaseID, userID uuid.UUID) (*uuid.UUID, error) {
+	var acc string
+	err := r.db.QueryRow(`
+		SELECT t.account_id
+		FROM budget_transactions bt
+		INNER JOIN transactions t ON t.id = bt.transaction_id
+		WHERE bt.common_purchase_id = ? AND bt.user_id = ?
+		ORDER BY t.transaction_date DESC, t.created_at DESC
+		LIMIT 1`, purchaseID.String(), userID.String()).Scan(&acc)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("last preset account: %w", err)
+	}
+	id, err := uuid.Parse(acc)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
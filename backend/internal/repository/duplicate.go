@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// maxDuplicateCandidatePairs bounds the raw pairs scanned by DuplicateCandidatePairs.
+const maxDuplicateCandidatePairs = 2000
+
+// DuplicateCandidate is one side of a candidate pair with the text used to judge similarity.
+type DuplicateCandidate struct {
+	ID          uuid.UUID
+	Description string
+	Store       string // budget line store, else location
+}
+
+// DuplicateCandidatePairs returns pairs of the user's income/expense rows on the same account with the
+// same amount and dates at most one day apart, newest first. Pairs the user dismissed, lines of the same
+// import batch and two distinct bank lines (both with an external id) are left out; from/to (YYYY-MM-DD)
+// restrict the first row's date when set.
+func (r *TransactionRepository) DuplicateCandidatePairs(userID uuid.UUID, from, to string) ([][2]DuplicateCandidate, error) {
+	q := `
+		SELECT a.id, a.description, COALESCE(ba.store, a.location_name, ''),
+			b.id, b.description, COALESCE(bb.store, b.location_name, '')
+		FROM transactions a
+		INNER JOIN transactions b
+			ON b.account_id = a.account_id
+			AND b.amount = a.amount
+			AND b.transaction_type = a.transaction_type
+			AND b.transaction_date BETWEEN date(a.transaction_date, '-1 day') AND date(a.transaction_date, '+1 day')
+			AND b.id > a.id
+		LEFT JOIN budget_transactions ba ON ba.transaction_id = a.id
+		LEFT JOIN budget_transactions bb ON bb.transaction_id = b.id
+		WHERE a.user_id = ?
+			AND a.transaction_type != 'transfer'
+			AND NOT (a.import_batch_id IS NOT NULL AND a.import_batch_id = b.import_batch_id)
+			AND NOT (a.external_id IS NOT NULL AND b.external_id IS NOT NULL)
+			AND NOT EXISTS (
+				SELECT 1 FROM transaction_duplicate_dismissals d
+				WHERE d.first_id = a.id AND d.second_id = b.id
+			)`
+	args := []any{userID.String()}
+	if from != "" {
+		q += " AND a.transaction_date >= ?"
+		args = append(args, from)
+	}
+	if to != "" {
+		q += " AND a.transaction_date <= ?"
+		args = append(args, to)
+	}
+	q += `
+		ORDER BY MAX(a.transaction_date, b.transaction_date) DESC, a.id
+		LIMIT ?`
+	args = append(args, maxDuplicateCandidatePairs)
+
+	rows, err := r.db.Query(q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("duplicate candidates: %w", err)
+	}
+	defer rows.Close()
+
+	var out [][2]DuplicateCandidate
+	for rows.Next() {
+		var (
+			pair     [2]DuplicateCandidate
+			idA, idB string
+		)
+		if err := rows.Scan(&idA, &pair[0].Description, &pair[0].Store, &idB, &pair[1].Description, &pair[1].Store); err != nil {
+			return nil, fmt.Errorf("scan duplicate candidate: %w", err)
+		}
+		var errA, errB error
+		pair[0].ID, errA = uuid.Parse(idA)
+		pair[1].ID, errB = uuid.Parse(idB)
+		if errA != nil || errB != nil {
+			continue
+		}
+		out = append(out, pair)
+	}
+	return out, rows.Err()
+}
+
+// DismissDuplicatePair records that a and b are separate transactions. Both must belong to the user.
+func (r *TransactionRepository) DismissDuplicatePair(userID, a, b uuid.UUID) error {
+	first, second := a.String(), b.String()
+	if second < first {
+		first, second = second, first
+	}
+	res, err := r.db.Exec(`
+		INSERT OR IGNORE INTO transaction_duplicate_dismissals (user_id, first_id, second_id, created_at)
+		SELECT ?, ?, ?, datetime('now')
+		WHERE (SELECT COUNT(*) FROM transactions WHERE user_id = ? AND id IN (?, ?)) = 2
+	`, userID.String(), first, second, userID.String(), first, second)
+	if err != nil {
+		return fmt.Errorf("dismiss duplicate pair: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("dismiss duplicate pair: %w", err)
+	} else if n == 0 {
+		// Either already dismissed or an id is not the user's; only the latter is an error.
+		var exists int
+		if err := r.db.QueryRow(`SELECT COUNT(*) FROM transactions WHERE user_id = ? AND id IN (?, ?)`,
+			userID.String(), first, second).Scan(&exists); err != nil {
+			return fmt.Errorf("dismiss duplicate pair: %w", err)
+		}
+		if exists != 2 {
+			return ErrTransactionNotFound
+		}
+	}
+	return nil
+}
+
+// MergeDuplicate deletes removeID after copying what keepID lacks from it: notes, location and the
+// budget line. Caller checks both rows are the user's and describe the same money movement.
+func (r *TransactionRepository) MergeDuplicate(userID, keepID, removeID uuid.UUID) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := ensureUnreconciled(tx, "id = ? AND user_id = ?", removeID.String(), userID.String()); err != nil {
+		return err
+	}
+	var trades int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM investment_trades WHERE transaction_id = ?`, removeID.String()).Scan(&trades); err != nil {
+		return fmt.Errorf("check investment trade: %w", err)
+	}
+	if trades > 0 {
+		return ErrTradeTransaction
+	}
+	var fees int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM transfer_transactions WHERE fee_transaction_id = ?`, removeID.String()).Scan(&fees); err != nil {
+		return fmt.Errorf("check transfer fee: %w", err)
+	}
+	if fees > 0 {
+		return ErrTransferFeeTransaction
+	}
+	if _, err := tx.Exec(`
+		UPDATE transactions
+		SET notes = COALESCE(notes, (SELECT notes FROM transactions WHERE id = ? AND user_id = ?)),
+			location_name = COALESCE(location_name, (SELECT location_name FROM transactions WHERE id = ? AND user_id = ?)),
+			updated_at = datetime('now')
+		WHERE id = ? AND user_id = ?
+	`, removeID.String(), userID.String(), removeID.String(), userID.String(), keepID.String(), userID.String()); err != nil {
+		return fmt.Errorf("merge transaction fields: %w", err)
+	}
+
+	// Copy (rather than re-point) the budget line so the insert trigger refreshes the search index.
+	var hasLink int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM budget_transactions WHERE transaction_id = ?`, keepID.String()).Scan(&hasLink)
+	if err != nil {
+		return fmt.Errorf("load budget link: %w", err)
+	}
+	if hasLink == 0 {
+		if _, err := tx.Exec(`
+			INSERT INTO budget_transactions (
+				id, transaction_id, budget_id, user_id,
+				item, quantity, store, unit_price, common_purchase_id,
+				created_at
+			)
+			SELECT ?, ?, budget_id, user_id, item, quantity, store, unit_price, common_purchase_id, created_at
+			FROM budget_transactions
+			WHERE transaction_id = ? AND user_id = ?
+		`, uuid.New().String(), keepID.String(), removeID.String(), userID.String()); err != nil {
+			return fmt.Errorf("copy budget link: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM budget_transactions WHERE transaction_id = ? AND user_id = ?`,
+		removeID.String(), userID.String()); err != nil {
+		return fmt.Errorf("delete budget_transactions: %w", err)
+	}
+	res, err := tx.Exec(`DELETE FROM transactions WHERE id = ? AND user_id = ?`, removeID.String(), userID.String())
+	if err != nil {
+		return fmt.Errorf("delete transaction: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("delete transaction: %w", err)
+	} else if n == 0 {
+		return ErrTransactionNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,339 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"monman-backend/internal/models"
+	"monman-backend/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrExchangeRateNotFound indicates the rate id does not exist for this user.
+	ErrExchangeRateNotFound = errors.New("exchange rate not found")
+	// ErrNoExchangeRate indicates a conversion needs a currency pair the user has no rate for.
+	ErrNoExchangeRate = errors.New("no exchange rate")
+)
+
+// ExchangeRateRepository stores the user's exchange rates and keeps transactions.base_amount in step
+// with them.
+type ExchangeRateRepository struct {
+	db *sql.DB
+}
+
+func NewExchangeRateRepository(db *sql.DB) *ExchangeRateRepository {
+	return &ExchangeRateRepository{db: db}
+}
+
+// ExchangeRateRow is one validated rate to store: Rate units of QuoteCurrency per unit of Currency.
+type ExchangeRateRow struct {
+	Currency      string
+	QuoteCurrency string
+	Rate          float64
+	Date          string // YYYY-MM-DD
+}
+
+// queryRower is satisfied by *sql.DB and *sql.Tx.
+type queryRower interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// BaseCurrency returns the currency the user's reports are in.
+func (r *ExchangeRateRepository) BaseCurrency(userID uuid.UUID) (string, error) {
+	return baseCurrency(r.db, userID)
+}
+
+func baseCurrency(q queryRower, userID uuid.UUID) (string, error) {
+	var code string
+	if err := q.QueryRow(`SELECT base_currency FROM users WHERE id = ?`, userID.String()).Scan(&code); err != nil {
+		return "", fmt.Errorf("base currency: %w", err)
+	}
+	return code, nil
+}
+
+// SetBaseCurrency changes the user's base currency and converts every transaction into it. It fails with
+// ErrNoExchangeRate when an account's currency has no rate into the new base.
+func (r *ExchangeRateRepository) SetBaseCurrency(userID uuid.UUID, code string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(`UPDATE users SET base_currency = ?, updated_at = datetime('now') WHERE id = ?`,
+		code, userID.String()); err != nil {
+		return fmt.Errorf("set base currency: %w", err)
+	}
+	if err := ensureRatesTx(tx, userID); err != nil {
+		return err
+	}
+	if err := rebaseTx(tx, userID, ""); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// List returns the user's rates, newest first, optionally only those involving currency.
+func (r *ExchangeRateRepository) List(userID uuid.UUID, currency string) ([]models.ExchangeRateAPI, error) {
+	rows, err := r.db.Query(`
+		SELECT id, currency, quote_currency, rate, rate_date, source, created_at, updated_at
+		FROM exchange_rates
+		WHERE user_id = ? AND (? = '' OR currency = ? OR quote_currency = ?)
+		ORDER BY rate_date DESC, currency, quote_currency
+	`, userID.String(), currency, currency, currency)
+	if err != nil {
+		return nil, fmt.Errorf("list exchange rates: %w", err)
+	}
+	defer rows.Close()
+	out := []models.ExchangeRateAPI{}
+	for rows.Next() {
+		var e models.ExchangeRateAPI
+		if err := rows.Scan(&e.ID, &e.Currency, &e.QuoteCurrency, &e.Rate, &e.RateDate, &e.Source,
+			&e.CreatedAt, &e.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan exchange rate: %w", err)
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// Save inserts the rates, replacing any the user already has for the same pair and date, and
+// re-converts the transactions the new rates apply to. It returns how many rows were stored.
+func (r *ExchangeRateRepository) Save(userID uuid.UUID, rates []ExchangeRateRow, source string) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, e := range rates {
+		// A rate entered the other way round replaces the stored inverse instead of competing with it.
+		if _, err := tx.Exec(`
+			DELETE FROM exchange_rates
+			WHERE user_id = ? AND currency = ? AND quote_currency = ? AND rate_date = ?
+		`, userID.String(), e.QuoteCurrency, e.Currency, e.Date); err != nil {
+			return 0, fmt.Errorf("replace inverse exchange rate: %w", err)
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO exchange_rates (id, user_id, currency, quote_currency, rate, rate_date, source, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, datetime('now'), datetime('now'))
+			ON CONFLICT (user_id, currency, quote_currency, rate_date) DO UPDATE SET
+				rate = excluded.rate, source = excluded.source, updated_at = datetime('now')
+		`, uuid.New().String(), userID.String(), e.Currency, e.QuoteCurrency, e.Rate, e.Date, source); err != nil {
+			return 0, fmt.Errorf("save exchange rate: %w", err)
+		}
+	}
+	if err := rebaseTx(tx, userID, ""); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit: %w", err)
+	}
+	return len(rates), nil
+}
+
+// Delete removes a rate and re-converts the transactions. It fails with ErrNoExchangeRate when an
+// account's currency would be left without any rate.
+func (r *ExchangeRateRepository) Delete(userID, id uuid.UUID) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec(`DELETE FROM exchange_rates WHERE id = ? AND user_id = ?`, id.String(), userID.String())
+	if err != nil {
+		return fmt.Errorf("delete exchange rate: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("delete exchange rate: %w", err)
+	} else if n == 0 {
+		return ErrExchangeRateNotFound
+	}
+	if err := ensureRatesTx(tx, userID); err != nil {
+		return err
+	}
+	if err := rebaseTx(tx, userID, ""); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// Convert converts cents from one currency into another at the rate effective on date.
+func (r *ExchangeRateRepository) Convert(userID uuid.UUID, cents int64, from, to, date string) (int64, error) {
+	base, err := baseCurrency(r.db, userID)
+	if err != nil {
+		return 0, err
+	}
+	rate, err := rateBetween(r.db, userID, from, to, base, date)
+	if err != nil {
+		return 0, err
+	}
+	return utils.ConvertCents(cents, rate, to), nil
+}
+
+// Rate returns units of to per unit of from effective on date.
+func (r *ExchangeRateRepository) Rate(userID uuid.UUID, from, to, date string) (float64, error) {
+	base, err := baseCurrency(r.db, userID)
+	if err != nil {
+		return 0, err
+	}
+	return rateBetween(r.db, userID, from, to, base, date)
+}
+
+// rateBetween finds the rate from -> to on date: a stored rate for the pair (either way round), or
+// failing that one through the base currency. The rate effective on date is the latest one on or
+// before it; a date before the first rate uses the first.
+func rateBetween(q queryRower, userID uuid.UUID, from, to, base, date string) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	rate, ok, err := pairRate(q, userID, from, to, date)
+	if err != nil || ok {
+		return rate, err
+	}
+	if from != base && to != base {
+		toBase, ok1, err := pairRate(q, userID, from, base, date)
+		if err != nil {
+			return 0, err
+		}
+		fromBase, ok2, err := pairRate(q, userID, base, to, date)
+		if err != nil {
+			return 0, err
+		}
+		if ok1 && ok2 {
+			return toBase * fromBase, nil
+		}
+	}
+	return 0, fmt.Errorf("%w from %s to %s", ErrNoExchangeRate, from, to)
+}
+
+func pairRate(q queryRower, userID uuid.UUID, from, to, date string) (float64, bool, error) {
+	var rate float64
+	err := q.QueryRow(`
+		SELECT CASE WHEN currency = ? THEN rate ELSE 1.0 / rate END
+		FROM exchange_rates
+		WHERE user_id = ?
+		  AND ((currency = ? AND quote_currency = ?) OR (currency = ? AND quote_currency = ?))
+		ORDER BY rate_date <= ? DESC,
+			CASE WHEN rate_date <= ? THEN rate_date END DESC,
+			rate_date,
+			currency = ? DESC
+		LIMIT 1
+	`, from, userID.String(), from, to, to, from, date, date, from).Scan(&rate)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("exchange rate: %w", err)
+	}
+	return rate, true, nil
+}
+
+// ensureRatesTx checks that every currency the user's accounts hold can be converted into the base
+// currency on some date.
+func ensureRatesTx(tx *sql.Tx, userID uuid.UUID) error {
+	base, err := baseCurrency(tx, userID)
+	if err != nil {
+		return err
+	}
+	rows, err := tx.Query(`SELECT DISTINCT currency FROM accounts WHERE user_id = ? AND currency != ?`,
+		userID.String(), base)
+	if err != nil {
+		return fmt.Errorf("account currencies: %w", err)
+	}
+	var codes []string
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan account currency: %w", err)
+		}
+		codes = append(codes, code)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return err
+	}
+	rows.Close()
+	for _, code := range codes {
+		// Any date will do: the earliest rate stands in for dates before it.
+		if _, err := rateBetween(tx, userID, code, base, base, "0000-01-01"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rebaseTx recomputes base_amount for the user's transactions matching cond (on alias t; empty for
+// all): NULL for accounts in the base currency, the converted amount otherwise. Only rows whose value
+// changes are written, so the budget triggers see just the difference.
+func rebaseTx(tx *sql.Tx, userID uuid.UUID, cond string, args ...any) error {
+	base, err := baseCurrency(tx, userID)
+	if err != nil {
+		return err
+	}
+	q := `
+		SELECT t.id, t.amount, t.transaction_date, a.currency, t.base_amount
+		FROM transactions t
+		JOIN accounts a ON a.id = t.account_id
+		WHERE t.user_id = ? AND (a.currency != ? OR t.base_amount IS NOT NULL)`
+	if cond != "" {
+		q += " AND (" + cond + ")"
+	}
+	rows, err := tx.Query(q, append([]any{userID.String(), base}, args...)...)
+	if err != nil {
+		return fmt.Errorf("load transactions to convert: %w", err)
+	}
+	type pending struct {
+		id, date, currency string
+		amount             int64
+		current            sql.NullInt64
+	}
+	var list []pending
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.id, &p.amount, &p.date, &p.currency, &p.current); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan transaction to convert: %w", err)
+		}
+		list = append(list, p)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return err
+	}
+	rows.Close()
+
+	rates := make(map[string]float64) // currency + date -> rate into base
+	for _, p := range list {
+		var next sql.NullInt64
+		if p.currency != base {
+			key := p.currency + p.date
+			rate, ok := rates[key]
+			if !ok {
+				if rate, err = rateBetween(tx, userID, p.currency, base, base, p.date); err != nil {
+					return err
+				}
+				rates[key] = rate
+			}
+			next = sql.NullInt64{Int64: utils.ConvertCents(p.amount, rate, base), Valid: true}
+		}
+		if next == p.current {
+			continue
+		}
+		if _, err := tx.Exec(`UPDATE transactions SET base_amount = ? WHERE id = ?`, next, p.id); err != nil {
+			return fmt.Errorf("convert transaction: %w", err)
+		}
+	}
+	return nil
+}
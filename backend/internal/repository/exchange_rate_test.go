@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/google/uuid"
+	_ "modernc.org/sqlite"
+)
+
+// rateTestDB opens an in-memory database holding only the exchange_rates table.
+func rateTestDB(t *testing.T, userID uuid.UUID, rates [][4]any) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec(`
+		CREATE TABLE exchange_rates (
+			id TEXT PRIMARY KEY NOT NULL,
+			user_id TEXT NOT NULL,
+			currency TEXT NOT NULL,
+			quote_currency TEXT NOT NULL,
+			rate REAL NOT NULL,
+			rate_date TEXT NOT NULL
+		)`); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range rates {
+		if _, err := db.Exec(`INSERT INTO exchange_rates (id, user_id, currency, quote_currency, rate, rate_date) VALUES (?, ?, ?, ?, ?, ?)`,
+			uuid.New().String(), userID.String(), r[0], r[1], r[2], r[3]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+func TestRateBetween(t *testing.T) {
+	user := uuid.New()
+	db := rateTestDB(t, user, [][4]any{
+		// currency, quote_currency, rate, rate_date
+		{"USD", "IDR", 16000.0, "2026-01-10"},
+		{"USD", "IDR", 16200.0, "2026-02-01"},
+		{"IDR", "USD", 1.0 / 16500, "2026-02-01"}, // the inverse pair on the same date loses to USD->IDR
+		{"IDR", "USD", 1.0 / 16400, "2026-03-01"},
+		{"SGD", "IDR", 12000.0, "2026-01-01"},
+		{"EUR", "IDR", 17500.0, "2026-01-01"},
+	})
+
+	tests := []struct {
+		name     string
+		from, to string
+		date     string
+		want     float64
+	}{
+		{"same currency", "IDR", "IDR", "2026-01-15", 1},
+		{"latest on or before the date", "USD", "IDR", "2026-01-31", 16000},
+		{"exact date", "USD", "IDR", "2026-01-10", 16000},
+		{"direct pair wins over its inverse on the same date", "USD", "IDR", "2026-02-15", 16200},
+		{"inverse pair on a later date", "USD", "IDR", "2026-03-05", 16400},
+		{"inverted lookup", "IDR", "USD", "2026-01-20", 1.0 / 16000},
+		{"date before the first rate uses the first", "USD", "IDR", "2025-12-01", 16000},
+		{"cross rate through the base currency", "SGD", "EUR", "2026-02-01", 12000.0 / 17500},
+		{"cross rate through the base currency, other way", "EUR", "USD", "2026-01-20", 17500.0 / 16000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rateBetween(db, user, tt.from, tt.to, "IDR", tt.date)
+			if err != nil {
+				t.Fatalf("rateBetween: %v", err)
+			}
+			if math.Abs(got-tt.want) > 1e-9*math.Max(1, tt.want) {
+				t.Errorf("rateBetween(%s, %s, %s) = %v, want %v", tt.from, tt.to, tt.date, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateBetweenMissing(t *testing.T) {
+	user := uuid.New()
+	db := rateTestDB(t, user, [][4]any{
+		{"USD", "IDR", 16000.0, "2026-01-10"},
+	})
+	tests := []struct {
+		name     string
+		from, to string
+		user     uuid.UUID
+	}{
+		{"no pair and no route through the base", "USD", "JPY", user},
+		{"base to a currency without rates", "IDR", "SGD", user},
+		{"another user's rates are not used", "USD", "IDR", uuid.New()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := rateBetween(db, tt.user, tt.from, tt.to, "IDR", "2026-02-01")
+			if !errors.Is(err, ErrNoExchangeRate) {
+				t.Errorf("err = %v, want ErrNoExchangeRate", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// ExportRepository reads whole tables of a user's data for GET /api/export.
+type ExportRepository struct {
+	db *sql.DB
+}
+
+func NewExportRepository(db *sql.DB) *ExportRepository {
+	return &ExportRepository{db: db}
+}
+
+// ExportRows is a query result with SQLite's own value types: int64, string or nil.
+type ExportRows struct {
+	Columns []string
+	Rows    [][]any
+}
+
+// BaseCurrency returns the currency the user's totals are converted into.
+func (r *ExportRepository) BaseCurrency(userID uuid.UUID) (string, error) {
+	return baseCurrency(r.db, userID)
+}
+
+// Transactions returns every transaction dated from..to (YYYY-MM-DD, inclusive; empty is open-ended),
+// oldest first, with its account, category, budget line and transfer id.
+func (r *ExportRepository) Transactions(userID uuid.UUID, from, to string) (*ExportRows, error) {
+	q := `
+		SELECT t.id, t.transaction_date AS date, t.transaction_type AS type, t.description, t.amount,
+			a.currency, t.base_amount AS base_amount_cents, t.original_amount AS original_amount_cents, t.original_currency,
+			t.account_id, a.name AS account, t.category_id, c.name AS category,
+			t.notes, t.location_name, bt.budget_id, bt.item, bt.store,
+			COALESCE(tf.id, tt.id) AS transfer_id, t.import_batch_id, t.external_id,
+			t.cleared, t.reconciliation_id, t.created_at, t.updated_at
+		FROM transactions t
+		INNER JOIN accounts a ON a.id = t.account_id
+		LEFT JOIN categories c ON c.id = t.category_id
+		LEFT JOIN budget_transactions bt ON bt.transaction_id = t.id
+		LEFT JOIN transfer_transactions tf ON tf.from_transaction_id = t.id
+		LEFT JOIN transfer_transactions tt ON tt.to_transaction_id = t.id
+		WHERE t.user_id = ?`
+	q, args := exportDateRange(q, []any{userID.String()}, "t.transaction_date", from, to)
+	return r.query("transactions", q+` ORDER BY t.transaction_date, t.created_at, t.id`, args...)
+}
+
+// Accounts returns all of the user's accounts, including inactive ones.
+func (r *ExportRepository) Accounts(userID uuid.UUID) (*ExportRows, error) {
+	return r.query("accounts", `
+		SELECT id, name, account_type, currency, bank_name, account_number, balance, credit_limit,
+			is_default, is_active, color, created_at, updated_at
+		FROM accounts
+		WHERE user_id = ?
+		ORDER BY created_at, id
+	`, userID.String())
+}
+
+// Categories returns the system categories and the user's own.
+func (r *ExportRepository) Categories(userID uuid.UUID) (*ExportRows, error) {
+	return r.query("categories", `
+		SELECT c.id, c.name, c.category_type, c.parent_category_id, p.name AS parent_category,
+			c.icon, c.color, c.is_system, c.is_active, c.created_at, c.updated_at
+		FROM categories c
+		LEFT JOIN categories p ON p.id = c.parent_category_id
+		WHERE c.user_id = ? OR c.user_id IS NULL
+		ORDER BY c.category_type, c.name COLLATE NOCASE, c.id
+	`, userID.String())
+}
+
+// Budgets returns the user's budgets whose current period overlaps from..to.
+func (r *ExportRepository) Budgets(userID uuid.UUID, from, to string) (*ExportRows, error) {
+	q := `
+		SELECT b.id, b.name, b.category_id, c.name AS category, b.budget_period,
+			b.period_start_date, b.period_end_date, b.allocated_amount, b.spent_amount,
+			b.carry_over, b.carryover_amount, b.alert_percentage, b.responsible_person,
+			b.auto_reset, b.is_active, b.sort_order, b.created_at, b.updated_at
+		FROM budgets b
+		LEFT JOIN categories c ON c.id = b.category_id
+		WHERE b.user_id = ?`
+	args := []any{userID.String()}
+	if from != "" {
+		q += " AND b.period_end_date >= ?"
+		args = append(args, from)
+	}
+	if to != "" {
+		q += " AND b.period_start_date <= ?"
+		args = append(args, to)
+	}
+	return r.query("budgets", q+` ORDER BY b.sort_order, b.name COLLATE NOCASE`, args...)
+}
+
+// CommonPurchases returns the presets of all the user's budgets with how often each was logged.
+func (r *ExportRepository) CommonPurchases(userID uuid.UUID) (*ExportRows, error) {
+	return r.query("common purchases", `
+		SELECT cp.id, cp.budget_id, b.name AS budget, cp.item, cp.quantity, cp.store,
+			cp.estimated_amount, cp.is_frequently_used, cp.sort_order,
+			(SELECT COUNT(*) FROM budget_transactions bt WHERE bt.common_purchase_id = cp.id) AS times_used,
+			cp.created_at, cp.updated_at
+		FROM budget_common_purchases cp
+		INNER JOIN budgets b ON b.id = cp.budget_id
+		WHERE b.user_id = ?
+		ORDER BY b.sort_order, b.name COLLATE NOCASE, cp.sort_order, cp.id
+	`, userID.String())
+}
+
+// BudgetLineItems returns the budget lines of transactions dated from..to, oldest first, with the
+// currency of the transaction's account.
+func (r *ExportRepository) BudgetLineItems(userID uuid.UUID, from, to string) (*ExportRows, error) {
+	q := `
+		SELECT bt.id, bt.transaction_id, t.transaction_date AS date, bt.budget_id, b.name AS budget,
+			bt.item, bt.quantity, bt.store, bt.unit_price, t.amount, a.currency, bt.common_purchase_id, bt.created_at
+		FROM budget_transactions bt
+		INNER JOIN transactions t ON t.id = bt.transaction_id
+		INNER JOIN accounts a ON a.id = t.account_id
+		INNER JOIN budgets b ON b.id = bt.budget_id
+		WHERE bt.user_id = ?`
+	q, args := exportDateRange(q, []any{userID.String()}, "t.transaction_date", from, to)
+	return r.query("budget line items", q+` ORDER BY t.transaction_date, bt.created_at, bt.id`, args...)
+}
+
+func exportDateRange(q string, args []any, column, from, to string) (string, []any) {
+	if from != "" {
+		q += " AND " + column + " >= ?"
+		args = append(args, from)
+	}
+	if to != "" {
+		q += " AND " + column + " <= ?"
+		args = append(args, to)
+	}
+	return q, args
+}
+
+func (r *ExportRepository) query(what, q string, args ...any) (*ExportRows, error) {
+	rows, err := r.db.Query(q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("export %s: %w", what, err)
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("export %s: %w", what, err)
+	}
+	out := &ExportRows{Columns: cols, Rows: [][]any{}}
+	for rows.Next() {
+		vals := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, fmt.Errorf("scan %s: %w", what, err)
+		}
+		for i, v := range vals {
+			if b, ok := v.([]byte); ok {
+				vals[i] = string(b)
+			}
+		}
+		out.Rows = append(out.Rows, vals)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,386 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"monman-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrImportProfileNotFound indicates the saved profile id does not exist for this user.
+	ErrImportProfileNotFound = errors.New("import profile not found")
+	// ErrImportProfileExists indicates the user already saved a profile with that name.
+	ErrImportProfileExists = errors.New("import profile with this name already exists")
+	// ErrImportBatchNotFound indicates the batch id does not exist for this user.
+	ErrImportBatchNotFound = errors.New("import batch not found")
+	// ErrImportBatchReverted indicates the batch was already reverted.
+	ErrImportBatchReverted = errors.New("import batch already reverted")
+)
+
+// ImportRepository stores saved import profiles and import batches.
+type ImportRepository struct {
+	db *sql.DB
+}
+
+func NewImportRepository(db *sql.DB) *ImportRepository {
+	return &ImportRepository{db: db}
+}
+
+// ImportBatch is one file to commit: every row becomes an income or expense on AccountID.
+type ImportBatch struct {
+	UserID            uuid.UUID
+	AccountID         uuid.UUID
+	Source            string
+	Filename          string
+	ProfileName       string
+	ExpenseCategoryID uuid.UUID
+	IncomeCategoryID  uuid.UUID
+	Rows              []ImportBatchRow
+}
+
+// ImportBatchRow is one validated statement line (amount in signed cents). CategoryID overrides the
+// batch default for its direction; Budget and Tags come from a matching categorization rule.
+type ImportBatchRow struct {
+	Date        string
+	Description string
+	Amount      int64
+	ExternalID  string
+	CategoryID  *uuid.UUID
+	Budget      *BudgetLinkParams
+	Tags        []string
+}
+
+// ListProfiles returns the user's saved profiles by name.
+func (r *ImportRepository) ListProfiles(userID uuid.UUID) ([]models.ImportProfile, error) {
+	rows, err := r.db.Query(`
+		SELECT id, mapping FROM import_profiles
+		WHERE user_id = ?
+		ORDER BY name COLLATE NOCASE
+	`, userID.String())
+	if err != nil {
+		return nil, fmt.Errorf("list import profiles: %w", err)
+	}
+	defer rows.Close()
+
+	list := []models.ImportProfile{}
+	for rows.Next() {
+		p, err := scanImportProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *p)
+	}
+	return list, rows.Err()
+}
+
+// GetProfile loads one saved profile.
+func (r *ImportRepository) GetProfile(id, userID uuid.UUID) (*models.ImportProfile, error) {
+	p, err := scanImportProfile(r.db.QueryRow(`
+		SELECT id, mapping FROM import_profiles WHERE id = ? AND user_id = ?
+	`, id.String(), userID.String()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrImportProfileNotFound
+	}
+	return p, err
+}
+
+// CreateProfile saves p (already validated) under its name.
+func (r *ImportRepository) CreateProfile(userID uuid.UUID, p models.ImportProfile) (uuid.UUID, error) {
+	id := uuid.New()
+	p.ID, p.BuiltIn = "", false
+	mapping, err := json.Marshal(p)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("encode import profile: %w", err)
+	}
+	_, err = r.db.Exec(`
+		INSERT INTO import_profiles (id, user_id, name, mapping, created_at, updated_at)
+		VALUES (?, ?, ?, ?, datetime('now'), datetime('now'))
+	`, id.String(), userID.String(), p.Name, string(mapping))
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return uuid.Nil, ErrImportProfileExists
+		}
+		return uuid.Nil, fmt.Errorf("insert import profile: %w", err)
+	}
+	return id, nil
+}
+
+// DeleteProfile removes a saved profile; past batches keep the profile name they recorded.
+func (r *ImportRepository) DeleteProfile(id, userID uuid.UUID) error {
+	res, err := r.db.Exec(`DELETE FROM import_profiles WHERE id = ? AND user_id = ?`, id.String(), userID.String())
+	if err != nil {
+		return fmt.Errorf("delete import profile: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("delete import profile: %w", err)
+	} else if n == 0 {
+		return ErrImportProfileNotFound
+	}
+	return nil
+}
+
+func scanImportProfile(s rowScanner) (*models.ImportProfile, error) {
+	var id, mapping string
+	if err := s.Scan(&id, &mapping); err != nil {
+		return nil, err
+	}
+	var p models.ImportProfile
+	if err := json.Unmarshal([]byte(mapping), &p); err != nil {
+		return nil, fmt.Errorf("decode import profile %s: %w", id, err)
+	}
+	p.ID = id
+	return &p, nil
+}
+
+// CreateBatch records the batch and inserts every row through the same path as Create, all in one DB
+// transaction: either the whole file lands or nothing does. Caller must enforce account/category ownership.
+func (r *ImportRepository) CreateBatch(b ImportBatch) (uuid.UUID, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var income, expense int64
+	for _, row := range b.Rows {
+		if row.Amount > 0 {
+			income += row.Amount
+		} else {
+			expense -= row.Amount
+		}
+	}
+	id := uuid.New()
+	if _, err := tx.Exec(`
+		INSERT INTO import_batches (
+			id, user_id, account_id, source, filename, profile_name,
+			row_count, income_total, expense_total, status, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, 'committed', datetime('now'))
+	`, id.String(), b.UserID.String(), b.AccountID.String(), b.Source,
+		emptyAsNull(b.Filename), emptyAsNull(b.ProfileName),
+		len(b.Rows), income, expense,
+	); err != nil {
+		return uuid.Nil, fmt.Errorf("insert import batch: %w", err)
+	}
+
+	for _, row := range b.Rows {
+		categoryID, txnType := b.ExpenseCategoryID, "expense"
+		if row.Amount > 0 {
+			categoryID, txnType = b.IncomeCategoryID, "income"
+		}
+		if row.CategoryID != nil {
+			categoryID = *row.CategoryID
+		}
+		if _, err := insertTransactionTx(tx, newTransactionRow{
+			UserID:        b.UserID,
+			AccountID:     b.AccountID,
+			CategoryID:    categoryID,
+			Amount:        row.Amount,
+			Description:   row.Description,
+			Type:          txnType,
+			Date:          row.Date,
+			ImportBatchID: &id,
+			ExternalID:    row.ExternalID,
+			Tags:          row.Tags,
+		}, row.Budget); err != nil {
+			return uuid.Nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return uuid.Nil, fmt.Errorf("commit: %w", err)
+	}
+	return id, nil
+}
+
+// KnownExternalIDs returns which of ids already exist on the account, either as the external_id of
+// an earlier import or as a MonMan transaction id (files exported by MonMan use those as FITID).
+func (r *ImportRepository) KnownExternalIDs(accountID uuid.UUID, ids []string) (map[string]bool, error) {
+	known := make(map[string]bool)
+	// Chunked to stay under SQLite's bound-parameter limit.
+	for start := 0; start < len(ids); start += 400 {
+		chunk := ids[start:min(start+400, len(ids))]
+		args := []any{accountID.String()}
+		for _, id := range chunk {
+			args = append(args, id)
+		}
+		args = append(args, args...)
+		in := placeholders(len(chunk))
+		rows, err := r.db.Query(`
+			SELECT external_id FROM transactions WHERE account_id = ? AND external_id IN (`+in+`)
+			UNION
+			SELECT id FROM transactions WHERE account_id = ? AND id IN (`+in+`)
+		`, args...)
+		if err != nil {
+			return nil, fmt.Errorf("load external ids: %w", err)
+		}
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("scan external id: %w", err)
+			}
+			known[id] = true
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return known, nil
+}
+
+// ExistingTransaction is a saved income/expense row considered when matching imported lines.
+type ExistingTransaction struct {
+	ID          string
+	Date        string
+	Description string
+	Store       string // budget line store, else location
+	Amount      int64
+	HasExternal bool
+}
+
+// TransactionsBetween returns the account's income/expense rows dated from..to (YYYY-MM-DD, inclusive),
+// oldest first, for fuzzy duplicate matching of an import.
+func (r *ImportRepository) TransactionsBetween(accountID uuid.UUID, from, to string) ([]ExistingTransaction, error) {
+	rows, err := r.db.Query(`
+		SELECT t.id, t.transaction_date, t.description, COALESCE(bt.store, t.location_name, ''), t.amount,
+			t.external_id IS NOT NULL
+		FROM transactions t
+		LEFT JOIN budget_transactions bt ON bt.transaction_id = t.id
+		WHERE t.account_id = ? AND t.transaction_type != 'transfer'
+			AND t.transaction_date BETWEEN ? AND ?
+		ORDER BY t.transaction_date, t.created_at
+	`, accountID.String(), from, to)
+	if err != nil {
+		return nil, fmt.Errorf("load account transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var out []ExistingTransaction
+	for rows.Next() {
+		var t ExistingTransaction
+		if err := rows.Scan(&t.ID, &t.Date, &t.Description, &t.Store, &t.Amount, &t.HasExternal); err != nil {
+			return nil, fmt.Errorf("scan account transaction: %w", err)
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+const importBatchSelect = `
+	SELECT b.id, b.account_id, a.name, b.source, COALESCE(b.filename, ''), COALESCE(b.profile_name, ''),
+		b.row_count, b.income_total, b.expense_total, b.status, b.created_at, COALESCE(b.reverted_at, '')
+	FROM import_batches b
+	INNER JOIN accounts a ON a.id = b.account_id`
+
+// ListBatches returns the user's imports, newest first.
+func (r *ImportRepository) ListBatches(userID uuid.UUID, limit, offset int) ([]models.ImportBatchAPI, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	rows, err := r.db.Query(importBatchSelect+`
+		WHERE b.user_id = ?
+		ORDER BY b.created_at DESC, b.rowid DESC
+		LIMIT ? OFFSET ?
+	`, userID.String(), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("list import batches: %w", err)
+	}
+	defer rows.Close()
+
+	list := []models.ImportBatchAPI{}
+	for rows.Next() {
+		b, err := scanImportBatch(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *b)
+	}
+	return list, rows.Err()
+}
+
+// GetBatch loads one import batch.
+func (r *ImportRepository) GetBatch(id, userID uuid.UUID) (*models.ImportBatchAPI, error) {
+	b, err := scanImportBatch(r.db.QueryRow(importBatchSelect+`
+		WHERE b.id = ? AND b.user_id = ?
+	`, id.String(), userID.String()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrImportBatchNotFound
+	}
+	return b, err
+}
+
+func scanImportBatch(s rowScanner) (*models.ImportBatchAPI, error) {
+	var b models.ImportBatchAPI
+	if err := s.Scan(
+		&b.ID, &b.AccountID, &b.Account, &b.Source, &b.Filename, &b.ProfileName,
+		&b.RowCount, &b.IncomeCents, &b.ExpenseCents, &b.Status, &b.CreatedAt, &b.RevertedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// RevertBatch deletes every transaction the batch created (including any budget links added since) and
+// marks it reverted; the DELETE triggers reverse balances / budget spent. It returns the number of
+// transactions removed, which is lower than row_count when some were already deleted by hand.
+func (r *ImportRepository) RevertBatch(id, userID uuid.UUID) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var status string
+	err = tx.QueryRow(`SELECT status FROM import_batches WHERE id = ? AND user_id = ?`,
+		id.String(), userID.String()).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrImportBatchNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("load import batch: %w", err)
+	}
+	if status == "reverted" {
+		return 0, ErrImportBatchReverted
+	}
+	if err := ensureUnreconciled(tx, "import_batch_id = ? AND user_id = ?", id.String(), userID.String()); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(`
+		DELETE FROM budget_transactions
+		WHERE transaction_id IN (SELECT id FROM transactions WHERE import_batch_id = ? AND user_id = ?)
+	`, id.String(), userID.String()); err != nil {
+		return 0, fmt.Errorf("delete budget_transactions: %w", err)
+	}
+	res, err := tx.Exec(`DELETE FROM transactions WHERE import_batch_id = ? AND user_id = ?`,
+		id.String(), userID.String())
+	if err != nil {
+		return 0, fmt.Errorf("delete imported transactions: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("delete imported transactions: %w", err)
+	}
+	if _, err := tx.Exec(`
+		UPDATE import_batches SET status = 'reverted', reverted_at = datetime('now')
+		WHERE id = ?
+	`, id.String()); err != nil {
+		return 0, fmt.Errorf("mark import batch reverted: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"monman-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ErrIncomeSourceNotFound indicates the income source id does not exist for this user.
+var ErrIncomeSourceNotFound = errors.New("income source not found")
+
+// IncomeReceipt is one income transaction, its amount in the user's base currency.
+type IncomeReceipt struct {
+	ID     string
+	Date   string // YYYY-MM-DD
+	Amount int64
+}
+
+// IncomeSourceRepository stores income_sources rows and reads matching income transactions.
+type IncomeSourceRepository struct {
+	db *sql.DB
+}
+
+func NewIncomeSourceRepository(db *sql.DB) *IncomeSourceRepository {
+	return &IncomeSourceRepository{db: db}
+}
+
+// Create inserts an active income source.
+func (r *IncomeSourceRepository) Create(src *models.IncomeSource) (uuid.UUID, error) {
+	id := uuid.New()
+	q := `
+		INSERT INTO income_sources (
+			id, user_id, category_id, name, amount, frequency, source_type,
+			employer_name, account_id, is_active, next_expected_date,
+			created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, 1, ?, datetime('now'), datetime('now'))
+	`
+	_, err := r.db.Exec(q,
+		id.String(), src.UserID.String(), src.CategoryID.String(), src.Name, src.Amount,
+		src.Frequency, src.SourceType,
+		nullableString(src.EmployerName), nullableUUID(src.AccountID), nullableDate(src.NextExpectedDate),
+	)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("insert income source: %w", err)
+	}
+	return id, nil
+}
+
+// ListForUser returns income sources with category and account names, active first.
+func (r *IncomeSourceRepository) ListForUser(userID uuid.UUID) ([]models.IncomeSourceAPI, error) {
+	q := `
+		SELECT
+			s.id, s.category_id, COALESCE(c.name, '—'), s.name, s.amount,
+			s.frequency, s.source_type, s.employer_name,
+			s.account_id, a.name, s.next_expected_date, s.is_active
+		FROM income_sources s
+		LEFT JOIN categories c ON c.id = s.category_id
+		LEFT JOIN accounts a ON a.id = s.account_id
+		WHERE s.user_id = ?
+		ORDER BY s.is_active DESC, s.name ASC
+	`
+	rows, err := r.db.Query(q, userID.String())
+	if err != nil {
+		return nil, fmt.Errorf("list income sources: %w", err)
+	}
+	defer rows.Close()
+
+	out := []models.IncomeSourceAPI{}
+	for rows.Next() {
+		var (
+			s                        models.IncomeSourceAPI
+			employer, accID, accName sql.NullString
+			nextDate                 sql.NullString
+			active                   int
+		)
+		if err := rows.Scan(
+			&s.ID, &s.CategoryID, &s.Category, &s.Name, &s.Amount,
+			&s.Frequency, &s.SourceType, &employer,
+			&accID, &accName, &nextDate, &active,
+		); err != nil {
+			return nil, fmt.Errorf("scan income source: %w", err)
+		}
+		if _, err := uuid.Parse(s.ID); err != nil {
+			continue
+		}
+		s.EmployerName = employer.String
+		s.AccountID = accID.String
+		s.Account = accName.String
+		s.NextExpectedDate = nextDate.String
+		s.IsActive = active == 1
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// GetForUser loads one income source owned by the user.
+func (r *IncomeSourceRepository) GetForUser(id, userID uuid.UUID) (*models.IncomeSource, error) {
+	q := `
+		SELECT category_id, name, amount, frequency, source_type,
+			employer_name, account_id, is_active, next_expected_date
+		FROM income_sources WHERE id = ? AND user_id = ?
+	`
+	var (
+		src                      models.IncomeSource
+		catStr                   string
+		employer, accID, nextStr sql.NullString
+		active                   int
+	)
+	err := r.db.QueryRow(q, id.String(), userID.String()).Scan(
+		&catStr, &src.Name, &src.Amount, &src.Frequency, &src.SourceType,
+		&employer, &accID, &active, &nextStr,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrIncomeSourceNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get income source: %w", err)
+	}
+	src.ID = id
+	src.UserID = userID
+	src.IsActive = active == 1
+	if src.CategoryID, err = uuid.Parse(catStr); err != nil {
+		return nil, fmt.Errorf("parse category id: %w", err)
+	}
+	if employer.Valid {
+		src.EmployerName = &employer.String
+	}
+	if accID.Valid {
+		aid, err := uuid.Parse(accID.String)
+		if err != nil {
+			return nil, fmt.Errorf("parse account id: %w", err)
+		}
+		src.AccountID = &aid
+	}
+	if nextStr.Valid && nextStr.String != "" {
+		t, err := parseSQLiteTime(nextStr.String)
+		if err != nil {
+			return nil, fmt.Errorf("parse next_expected_date: %w", err)
+		}
+		src.NextExpectedDate = &t
+	}
+	return &src, nil
+}
+
+// Update rewrites the editable columns of one owned income source.
+func (r *IncomeSourceRepository) Update(src *models.IncomeSource) error {
+	res, err := r.db.Exec(`
+		UPDATE income_sources SET
+			category_id = ?, name = ?, amount = ?, frequency = ?, source_type = ?,
+			employer_name = ?, account_id = ?, is_active = ?, next_expected_date = ?,
+			updated_at = datetime('now')
+		WHERE id = ? AND user_id = ?
+	`,
+		src.CategoryID.String(), src.Name, src.Amount, src.Frequency, src.SourceType,
+		nullableString(src.EmployerName), nullableUUID(src.AccountID), sqlBool(src.IsActive),
+		nullableDate(src.NextExpectedDate),
+		src.ID.String(), src.UserID.String(),
+	)
+	if err != nil {
+		return fmt.Errorf("update income source: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("update income source: %w", err)
+	} else if n == 0 {
+		return ErrIncomeSourceNotFound
+	}
+	return nil
+}
+
+// Delete removes an income source; matched transactions are untouched.
+func (r *IncomeSourceRepository) Delete(id, userID uuid.UUID) error {
+	res, err := r.db.Exec(`DELETE FROM income_sources WHERE id = ? AND user_id = ?`, id.String(), userID.String())
+	if err != nil {
+		return fmt.Errorf("delete income source: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("delete income source: %w", err)
+	} else if n == 0 {
+		return ErrIncomeSourceNotFound
+	}
+	return nil
+}
+
+// MatchingIncome lists income transactions in the source's category or its subcategories (and receiving
+// account, when set) between from and to (YYYY-MM-DD, inclusive), oldest first.
+func (r *IncomeSourceRepository) MatchingIncome(userID, categoryID uuid.UUID, accountID *uuid.UUID, from, to string) ([]IncomeReceipt, error) {
+	q := `
+		SELECT id, transaction_date, COALESCE(base_amount, amount)
+		FROM transactions
+		WHERE user_id = ?
+		  AND transaction_type = 'income'
+		  AND category_id IN ` + categorySubtree + `
+		  AND (? IS NULL OR account_id = ?)
+		  AND transaction_date >= ? AND transaction_date <= ?
+		ORDER BY transaction_date, created_at, id
+	`
+	acc := nullableUUID(accountID)
+	rows, err := r.db.Query(q, userID.String(), categoryID.String(), categoryID.String(), acc, acc, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("income actuals: %w", err)
+	}
+	defer rows.Close()
+
+	var out []IncomeReceipt
+	for rows.Next() {
+		var rc IncomeReceipt
+		if err := rows.Scan(&rc.ID, &rc.Date, &rc.Amount); err != nil {
+			return nil, fmt.Errorf("scan income actual: %w", err)
+		}
+		out = append(out, rc)
+	}
+	return out, rows.Err()
+}
+
+func nullableUUID(id *uuid.UUID) interface{} {
+	if id == nil {
+		return nil
+	}
+	return id.String()
+}
+
+func nullableDate(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.Format("2006-01-02")
+}
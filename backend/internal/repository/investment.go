@@ -0,0 +1,569 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"monman-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInstrumentNotFound indicates the instrument id does not exist for this user.
+	ErrInstrumentNotFound = errors.New("instrument not found")
+	// ErrInstrumentExists indicates the user already tracks an instrument with that symbol.
+	ErrInstrumentExists = errors.New("instrument with this symbol already exists")
+	// ErrInstrumentInUse indicates an instrument still has trades.
+	ErrInstrumentInUse = errors.New("instrument has trades")
+	// ErrInstrumentPriceNotFound indicates the price id does not exist for the instrument.
+	ErrInstrumentPriceNotFound = errors.New("instrument price not found")
+	// ErrTradeNotFound indicates the trade id does not exist for this user.
+	ErrTradeNotFound = errors.New("trade not found")
+	// ErrOversold indicates a sale of more units than the account held at the time.
+	ErrOversold = errors.New("sale exceeds the units held")
+	// ErrTradeTransaction indicates a change to the cash row of a trade outside the trade itself.
+	ErrTradeTransaction = errors.New("transaction belongs to an investment trade")
+)
+
+// unitsEpsilon absorbs float rounding when a sale empties a holding.
+const unitsEpsilon = 1e-9
+
+// InvestmentRepository stores instruments, their prices, and the trades and holdings of investment
+// accounts.
+type InvestmentRepository struct {
+	db *sql.DB
+}
+
+func NewInvestmentRepository(db *sql.DB) *InvestmentRepository {
+	return &InvestmentRepository{db: db}
+}
+
+// InstrumentParams is a validated instrument to create.
+type InstrumentParams struct {
+	Symbol         string
+	Name           string
+	InstrumentType string
+	Currency       string
+	UnitLabel      string
+}
+
+// TradeParams is a validated trade. The service picks the cash row's description and, for dividends,
+// its category.
+type TradeParams struct {
+	UserID       uuid.UUID
+	AccountID    uuid.UUID
+	InstrumentID uuid.UUID
+	TradeType    string
+	Date         string
+	Units        float64
+	Amount       int64
+	Fee          int64
+	CategoryID   uuid.UUID // dividends only
+	Description  string
+	Notes        *string
+}
+
+// Trade is one stored trade, as replayed into a Position.
+type Trade struct {
+	ID           string
+	AccountID    string
+	InstrumentID string
+	Type         string
+	Date         string
+	Units        float64
+	Amount       int64
+	Fee          int64
+}
+
+// Position is a holding replayed from its trades at average cost.
+type Position struct {
+	Units     float64
+	CostBasis int64
+	Realized  int64
+	Dividends int64
+}
+
+// Apply adds t to p and returns the gain a sale realizes: net proceeds less the average cost of the
+// units sold. It fails with ErrOversold when a sale exceeds the units held.
+func (p *Position) Apply(t Trade) (int64, error) {
+	switch t.Type {
+	case "buy":
+		p.Units += t.Units
+		p.CostBasis += t.Amount + t.Fee
+	case "sell":
+		if t.Units > p.Units+unitsEpsilon {
+			return 0, fmt.Errorf("%w on %s", ErrOversold, t.Date)
+		}
+		cost := p.CostBasis
+		if t.Units < p.Units-unitsEpsilon {
+			cost = int64(math.Round(float64(p.CostBasis) * t.Units / p.Units))
+			p.Units -= t.Units
+		} else {
+			p.Units = 0
+		}
+		p.CostBasis -= cost
+		gain := t.Amount - t.Fee - cost
+		p.Realized += gain
+		return gain, nil
+	case "dividend":
+		p.Dividends += t.Amount - t.Fee
+	}
+	return 0, nil
+}
+
+const instrumentSelect = `
+	SELECT i.id, i.symbol, i.name, i.instrument_type, i.currency, i.unit_label, i.created_at,
+		p.price, COALESCE(p.price_date, '')
+	FROM instruments i
+	LEFT JOIN instrument_prices p ON p.id = (
+		SELECT id FROM instrument_prices WHERE instrument_id = i.id ORDER BY price_date DESC LIMIT 1)`
+
+// CreateInstrument stores a new instrument for the user.
+func (r *InvestmentRepository) CreateInstrument(userID uuid.UUID, p InstrumentParams) (uuid.UUID, error) {
+	id := uuid.New()
+	_, err := r.db.Exec(`
+		INSERT INTO instruments (id, user_id, symbol, name, instrument_type, currency, unit_label)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, id.String(), userID.String(), p.Symbol, p.Name, p.InstrumentType, p.Currency, p.UnitLabel)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return uuid.Nil, ErrInstrumentExists
+		}
+		return uuid.Nil, fmt.Errorf("insert instrument: %w", err)
+	}
+	return id, nil
+}
+
+// ListInstruments returns the user's instruments by symbol.
+func (r *InvestmentRepository) ListInstruments(userID uuid.UUID) ([]models.InstrumentAPI, error) {
+	return r.queryInstruments(instrumentSelect+` WHERE i.user_id = ? ORDER BY i.symbol`, userID.String())
+}
+
+// GetInstrument returns one of the user's instruments.
+func (r *InvestmentRepository) GetInstrument(userID, id uuid.UUID) (*models.InstrumentAPI, error) {
+	list, err := r.queryInstruments(instrumentSelect+` WHERE i.id = ? AND i.user_id = ?`, id.String(), userID.String())
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, ErrInstrumentNotFound
+	}
+	return &list[0], nil
+}
+
+func (r *InvestmentRepository) queryInstruments(q string, args ...any) ([]models.InstrumentAPI, error) {
+	rows, err := r.db.Query(q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list instruments: %w", err)
+	}
+	defer rows.Close()
+	out := []models.InstrumentAPI{}
+	for rows.Next() {
+		var (
+			in    models.InstrumentAPI
+			price sql.NullInt64
+		)
+		if err := rows.Scan(&in.ID, &in.Symbol, &in.Name, &in.InstrumentType, &in.Currency, &in.UnitLabel,
+			&in.CreatedAt, &price, &in.LatestPriceDate); err != nil {
+			return nil, fmt.Errorf("scan instrument: %w", err)
+		}
+		if price.Valid {
+			in.LatestPrice = &price.Int64
+		}
+		out = append(out, in)
+	}
+	return out, rows.Err()
+}
+
+// DeleteInstrument removes an instrument and its prices. It fails with ErrInstrumentInUse while any
+// trade refers to it.
+func (r *InvestmentRepository) DeleteInstrument(userID, id uuid.UUID) error {
+	var trades int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM investment_trades WHERE instrument_id = ?`, id.String()).Scan(&trades); err != nil {
+		return fmt.Errorf("count instrument trades: %w", err)
+	}
+	if trades > 0 {
+		return ErrInstrumentInUse
+	}
+	res, err := r.db.Exec(`DELETE FROM instruments WHERE id = ? AND user_id = ?`, id.String(), userID.String())
+	if err != nil {
+		return fmt.Errorf("delete instrument: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("delete instrument: %w", err)
+	} else if n == 0 {
+		return ErrInstrumentNotFound
+	}
+	return nil
+}
+
+// SavePrice stores the instrument's price for date, replacing one already there.
+func (r *InvestmentRepository) SavePrice(instrumentID uuid.UUID, date string, price int64) error {
+	_, err := r.db.Exec(`
+		INSERT INTO instrument_prices (id, instrument_id, price_date, price, created_at)
+		VALUES (?, ?, ?, ?, datetime('now'))
+		ON CONFLICT (instrument_id, price_date) DO UPDATE SET price = excluded.price, created_at = excluded.created_at
+	`, uuid.New().String(), instrumentID.String(), date, price)
+	if err != nil {
+		return fmt.Errorf("save instrument price: %w", err)
+	}
+	return nil
+}
+
+// ListPrices returns the instrument's prices, newest first.
+func (r *InvestmentRepository) ListPrices(instrumentID uuid.UUID) ([]models.InstrumentPriceAPI, error) {
+	rows, err := r.db.Query(`
+		SELECT id, price_date, price FROM instrument_prices
+		WHERE instrument_id = ?
+		ORDER BY price_date DESC`, instrumentID.String())
+	if err != nil {
+		return nil, fmt.Errorf("list instrument prices: %w", err)
+	}
+	defer rows.Close()
+	out := []models.InstrumentPriceAPI{}
+	for rows.Next() {
+		var p models.InstrumentPriceAPI
+		if err := rows.Scan(&p.ID, &p.PriceDate, &p.Price); err != nil {
+			return nil, fmt.Errorf("scan instrument price: %w", err)
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// DeletePrice removes one price of the instrument.
+func (r *InvestmentRepository) DeletePrice(instrumentID, priceID uuid.UUID) error {
+	res, err := r.db.Exec(`DELETE FROM instrument_prices WHERE id = ? AND instrument_id = ?`,
+		priceID.String(), instrumentID.String())
+	if err != nil {
+		return fmt.Errorf("delete instrument price: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("delete instrument price: %w", err)
+	} else if n == 0 {
+		return ErrInstrumentPriceNotFound
+	}
+	return nil
+}
+
+// PricePoint is one price of an instrument.
+type PricePoint struct {
+	Date  string
+	Price int64
+}
+
+// Prices returns every price of the user's instruments by instrument id, oldest first.
+func (r *InvestmentRepository) Prices(userID uuid.UUID) (map[string][]PricePoint, error) {
+	rows, err := r.db.Query(`
+		SELECT p.instrument_id, p.price_date, p.price
+		FROM instrument_prices p
+		JOIN instruments i ON i.id = p.instrument_id
+		WHERE i.user_id = ?
+		ORDER BY p.instrument_id, p.price_date`, userID.String())
+	if err != nil {
+		return nil, fmt.Errorf("instrument prices: %w", err)
+	}
+	defer rows.Close()
+	out := make(map[string][]PricePoint)
+	for rows.Next() {
+		var (
+			id string
+			p  PricePoint
+		)
+		if err := rows.Scan(&id, &p.Date, &p.Price); err != nil {
+			return nil, fmt.Errorf("scan instrument price: %w", err)
+		}
+		out[id] = append(out[id], p)
+	}
+	return out, rows.Err()
+}
+
+// PriceAt returns the latest of prices (oldest first) on or before date.
+func PriceAt(prices []PricePoint, date string) (PricePoint, bool) {
+	var (
+		best PricePoint
+		ok   bool
+	)
+	for _, p := range prices {
+		if p.Date > date {
+			break
+		}
+		best, ok = p, true
+	}
+	return best, ok
+}
+
+// CreateTrade posts the trade's cash movement on the account, stores the trade and replays the
+// holding. Buys and sells move cash as 'transfer'-type rows without category (they are not income or
+// spending); a dividend is income in p.CategoryID.
+func (r *InvestmentRepository) CreateTrade(p TradeParams) (uuid.UUID, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var txID uuid.UUID
+	switch p.TradeType {
+	case "dividend":
+		txID, err = insertTransactionTx(tx, newTransactionRow{
+			UserID:      p.UserID,
+			AccountID:   p.AccountID,
+			CategoryID:  p.CategoryID,
+			Amount:      p.Amount - p.Fee,
+			Description: p.Description,
+			Type:        "income",
+			Date:        p.Date,
+		}, nil)
+		if err != nil {
+			return uuid.Nil, err
+		}
+	default:
+		cash := p.Amount - p.Fee
+		if p.TradeType == "buy" {
+			cash = -(p.Amount + p.Fee)
+		}
+		txID = uuid.New()
+		if _, err := tx.Exec(`
+			INSERT INTO transactions (
+				id, user_id, account_id, category_id, amount, description, notes,
+				transaction_type, transaction_date, created_at, updated_at
+			) VALUES (?, ?, ?, NULL, ?, ?, ?, 'transfer', ?, datetime('now'), datetime('now'))
+		`, txID.String(), p.UserID.String(), p.AccountID.String(), cash, p.Description, nullableString(p.Notes),
+			p.Date); err != nil {
+			return uuid.Nil, fmt.Errorf("insert trade transaction: %w", err)
+		}
+		if err := rebaseTx(tx, p.UserID, "t.id = ?", txID.String()); err != nil {
+			return uuid.Nil, err
+		}
+	}
+
+	id := uuid.New()
+	if _, err := tx.Exec(`
+		INSERT INTO investment_trades (
+			id, user_id, account_id, instrument_id, trade_type, trade_date, units, amount, fee,
+			transaction_id, notes, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'))
+	`, id.String(), p.UserID.String(), p.AccountID.String(), p.InstrumentID.String(), p.TradeType, p.Date,
+		p.Units, p.Amount, p.Fee, txID.String(), nullableString(p.Notes)); err != nil {
+		return uuid.Nil, fmt.Errorf("insert trade: %w", err)
+	}
+	if err := replayHoldingTx(tx, p.AccountID.String(), p.InstrumentID.String()); err != nil {
+		return uuid.Nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return uuid.Nil, fmt.Errorf("commit: %w", err)
+	}
+	return id, nil
+}
+
+// DeleteTrade removes a trade with its cash row and replays the holding; a sale that would then
+// exceed the units held fails with ErrOversold.
+func (r *InvestmentRepository) DeleteTrade(userID, id uuid.UUID) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var accountID, instrumentID string
+	var txID sql.NullString
+	err = tx.QueryRow(`
+		SELECT account_id, instrument_id, transaction_id FROM investment_trades
+		WHERE id = ? AND user_id = ?`, id.String(), userID.String()).Scan(&accountID, &instrumentID, &txID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrTradeNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("load trade: %w", err)
+	}
+	if txID.Valid {
+		if err := ensureUnreconciled(tx, "id = ?", txID.String); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM investment_trades WHERE id = ?`, id.String()); err != nil {
+		return fmt.Errorf("delete trade: %w", err)
+	}
+	if txID.Valid {
+		if _, err := tx.Exec(`DELETE FROM transactions WHERE id = ? AND user_id = ?`, txID.String, userID.String()); err != nil {
+			return fmt.Errorf("delete trade transaction: %w", err)
+		}
+	}
+	if err := replayHoldingTx(tx, accountID, instrumentID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// ListTrades returns the account's trades, newest first.
+func (r *InvestmentRepository) ListTrades(userID, accountID uuid.UUID) ([]models.InvestmentTradeAPI, error) {
+	rows, err := r.db.Query(`
+		SELECT t.id, t.account_id, t.instrument_id, i.symbol, t.trade_type, t.trade_date, t.units, t.amount,
+			t.fee, t.realized_gain, COALESCE(t.transaction_id, ''), COALESCE(t.notes, ''), t.created_at
+		FROM investment_trades t
+		JOIN instruments i ON i.id = t.instrument_id
+		WHERE t.user_id = ? AND t.account_id = ?
+		ORDER BY t.trade_date DESC, t.rowid DESC`, userID.String(), accountID.String())
+	if err != nil {
+		return nil, fmt.Errorf("list trades: %w", err)
+	}
+	defer rows.Close()
+	out := []models.InvestmentTradeAPI{}
+	for rows.Next() {
+		var (
+			t    models.InvestmentTradeAPI
+			gain sql.NullInt64
+		)
+		if err := rows.Scan(&t.ID, &t.AccountID, &t.InstrumentID, &t.Symbol, &t.TradeType, &t.TradeDate, &t.Units,
+			&t.Amount, &t.Fee, &gain, &t.TransactionID, &t.Notes, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan trade: %w", err)
+		}
+		if gain.Valid {
+			t.RealizedGain = &gain.Int64
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// Trades returns the user's trades dated up to to (YYYY-MM-DD), in replay order.
+func (r *InvestmentRepository) Trades(userID uuid.UUID, to string) ([]Trade, error) {
+	rows, err := r.db.Query(`
+		SELECT id, account_id, instrument_id, trade_type, trade_date, units, amount, fee
+		FROM investment_trades
+		WHERE user_id = ? AND trade_date <= ?
+		ORDER BY trade_date, rowid`, userID.String(), to)
+	if err != nil {
+		return nil, fmt.Errorf("load trades: %w", err)
+	}
+	defer rows.Close()
+	return scanTrades(rows)
+}
+
+func scanTrades(rows *sql.Rows) ([]Trade, error) {
+	var out []Trade
+	for rows.Next() {
+		var t Trade
+		if err := rows.Scan(&t.ID, &t.AccountID, &t.InstrumentID, &t.Type, &t.Date, &t.Units, &t.Amount, &t.Fee); err != nil {
+			return nil, fmt.Errorf("scan trade: %w", err)
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// replayHoldingTx recomputes one holding from its trades in date order, storing each sale's realized
+// gain and the resulting position.
+func replayHoldingTx(tx *sql.Tx, accountID, instrumentID string) error {
+	rows, err := tx.Query(`
+		SELECT id, account_id, instrument_id, trade_type, trade_date, units, amount, fee
+		FROM investment_trades
+		WHERE account_id = ? AND instrument_id = ?
+		ORDER BY trade_date, rowid`, accountID, instrumentID)
+	if err != nil {
+		return fmt.Errorf("load holding trades: %w", err)
+	}
+	trades, err := scanTrades(rows)
+	rows.Close()
+	if err != nil {
+		return err
+	}
+
+	var pos Position
+	for _, t := range trades {
+		gain, err := pos.Apply(t)
+		if err != nil {
+			return err
+		}
+		if t.Type == "sell" {
+			if _, err := tx.Exec(`UPDATE investment_trades SET realized_gain = ? WHERE id = ?`, gain, t.ID); err != nil {
+				return fmt.Errorf("store realized gain: %w", err)
+			}
+		}
+	}
+	if len(trades) == 0 {
+		if _, err := tx.Exec(`DELETE FROM investment_holdings WHERE account_id = ? AND instrument_id = ?`,
+			accountID, instrumentID); err != nil {
+			return fmt.Errorf("delete holding: %w", err)
+		}
+		return nil
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO investment_holdings (account_id, instrument_id, units, cost_basis, realized_gain, dividends, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, datetime('now'))
+		ON CONFLICT (account_id, instrument_id) DO UPDATE SET
+			units = excluded.units, cost_basis = excluded.cost_basis, realized_gain = excluded.realized_gain,
+			dividends = excluded.dividends, updated_at = excluded.updated_at
+	`, accountID, instrumentID, pos.Units, pos.CostBasis, pos.Realized, pos.Dividends); err != nil {
+		return fmt.Errorf("store holding: %w", err)
+	}
+	return nil
+}
+
+// Holdings returns the user's current holdings with the latest price on or before date, in account
+// then symbol order; positions sold out are kept while they carry realized gains or dividends.
+func (r *InvestmentRepository) Holdings(userID uuid.UUID, date string) ([]models.HoldingAPI, error) {
+	rows, err := r.db.Query(`
+		SELECT h.account_id, a.name, h.instrument_id, i.symbol, i.name, i.instrument_type, i.unit_label,
+			a.currency, h.units, h.cost_basis, h.realized_gain, h.dividends, p.price, COALESCE(p.price_date, '')
+		FROM investment_holdings h
+		JOIN accounts a ON a.id = h.account_id
+		JOIN instruments i ON i.id = h.instrument_id
+		LEFT JOIN instrument_prices p ON p.id = (
+			SELECT id FROM instrument_prices
+			WHERE instrument_id = h.instrument_id AND price_date <= ?
+			ORDER BY price_date DESC LIMIT 1)
+		WHERE a.user_id = ?
+		ORDER BY a.name, i.symbol`, date, userID.String())
+	if err != nil {
+		return nil, fmt.Errorf("list holdings: %w", err)
+	}
+	defer rows.Close()
+	out := []models.HoldingAPI{}
+	for rows.Next() {
+		var (
+			h     models.HoldingAPI
+			price sql.NullInt64
+		)
+		if err := rows.Scan(&h.AccountID, &h.AccountName, &h.InstrumentID, &h.Symbol, &h.Name, &h.InstrumentType,
+			&h.UnitLabel, &h.Currency, &h.Units, &h.CostBasis, &h.RealizedGain, &h.Dividends, &price, &h.PriceDate); err != nil {
+			return nil, fmt.Errorf("scan holding: %w", err)
+		}
+		if price.Valid {
+			h.Price = &price.Int64
+		}
+		out = append(out, h)
+	}
+	return out, rows.Err()
+}
+
+// InvestmentAccounts lists the user's active investment accounts and any other account holding
+// instruments, by name.
+func (r *InvestmentRepository) InvestmentAccounts(userID uuid.UUID) ([]models.InvestmentAccountAPI, error) {
+	rows, err := r.db.Query(`
+		SELECT id, name, currency, balance FROM accounts
+		WHERE user_id = ? AND ((account_type = 'investment' AND is_active = 1)
+			OR id IN (SELECT account_id FROM investment_holdings))
+		ORDER BY name`, userID.String())
+	if err != nil {
+		return nil, fmt.Errorf("investment accounts: %w", err)
+	}
+	defer rows.Close()
+	out := []models.InvestmentAccountAPI{}
+	for rows.Next() {
+		var a models.InvestmentAccountAPI
+		if err := rows.Scan(&a.AccountID, &a.Name, &a.Currency, &a.Cash); err != nil {
+			return nil, fmt.Errorf("scan investment account: %w", err)
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
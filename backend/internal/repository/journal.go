@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// JournalAccount is one money account as the plain-text accounting export needs it.
+type JournalAccount struct {
+	ID          string
+	Name        string
+	AccountType string
+	Balance     int64  // current balance in cents
+	CreatedOn   string // YYYY-MM-DD
+	Currency    string
+}
+
+// JournalCategory is one category with its parent, for the export's account tree.
+type JournalCategory struct {
+	ID           string
+	Name         string
+	CategoryType string
+	ParentID     string // empty for top-level categories
+	CreatedOn    string // YYYY-MM-DD
+}
+
+// JournalTransaction is one transactions row. TransferID is set on both legs of a transfer; Opening
+// marks an account's opening balance row and Adjustment a reconciliation adjustment.
+type JournalTransaction struct {
+	ID          string
+	Date        string
+	Type        string
+	Description string
+	Notes       string
+	Amount      int64
+	AccountID   string
+	CategoryID  string
+	TransferID  string
+	Opening     bool
+	Adjustment  bool
+}
+
+// JournalAccounts returns all of the user's accounts, including inactive ones.
+func (r *ExportRepository) JournalAccounts(userID uuid.UUID) ([]JournalAccount, error) {
+	rows, err := r.db.Query(`
+		SELECT id, name, account_type, balance, date(created_at), currency
+		FROM accounts
+		WHERE user_id = ?
+		ORDER BY created_at, id
+	`, userID.String())
+	if err != nil {
+		return nil, fmt.Errorf("journal accounts: %w", err)
+	}
+	defer rows.Close()
+
+	var out []JournalAccount
+	for rows.Next() {
+		var a JournalAccount
+		if err := rows.Scan(&a.ID, &a.Name, &a.AccountType, &a.Balance, &a.CreatedOn, &a.Currency); err != nil {
+			return nil, fmt.Errorf("scan journal account: %w", err)
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// JournalCategories returns the system categories and the user's own, including inactive ones.
+func (r *ExportRepository) JournalCategories(userID uuid.UUID) ([]JournalCategory, error) {
+	rows, err := r.db.Query(`
+		SELECT id, name, category_type, COALESCE(parent_category_id, ''), date(created_at)
+		FROM categories
+		WHERE user_id = ? OR user_id IS NULL
+		ORDER BY created_at, id
+	`, userID.String())
+	if err != nil {
+		return nil, fmt.Errorf("journal categories: %w", err)
+	}
+	defer rows.Close()
+
+	var out []JournalCategory
+	for rows.Next() {
+		var c JournalCategory
+		if err := rows.Scan(&c.ID, &c.Name, &c.CategoryType, &c.ParentID, &c.CreatedOn); err != nil {
+			return nil, fmt.Errorf("scan journal category: %w", err)
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// JournalTransactions returns the user's transactions dated from..to (YYYY-MM-DD, inclusive; empty is
+// open-ended) in a stable order: date, creation time, id.
+func (r *ExportRepository) JournalTransactions(userID uuid.UUID, from, to string) ([]JournalTransaction, error) {
+	q := `
+		SELECT t.id, t.transaction_date, t.transaction_type, t.description, COALESCE(t.notes, ''),
+			t.amount, t.account_id, COALESCE(t.category_id, ''), COALESCE(tf.id, tt.id, ''),
+			EXISTS (SELECT 1 FROM accounts oa WHERE oa.opening_transaction_id = t.id),
+			EXISTS (SELECT 1 FROM reconciliations rc WHERE rc.adjustment_transaction_id = t.id)
+		FROM transactions t
+		LEFT JOIN transfer_transactions tf ON tf.from_transaction_id = t.id
+		LEFT JOIN transfer_transactions tt ON tt.to_transaction_id = t.id
+		WHERE t.user_id = ?`
+	q, args := exportDateRange(q, []any{userID.String()}, "t.transaction_date", from, to)
+	rows, err := r.db.Query(q+` ORDER BY t.transaction_date, t.created_at, t.id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("journal transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var out []JournalTransaction
+	for rows.Next() {
+		var t JournalTransaction
+		if err := rows.Scan(&t.ID, &t.Date, &t.Type, &t.Description, &t.Notes,
+			&t.Amount, &t.AccountID, &t.CategoryID, &t.TransferID, &t.Opening, &t.Adjustment); err != nil {
+			return nil, fmt.Errorf("scan journal transaction: %w", err)
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// AmountsSince sums each account's transactions dated on or after from (all of them when from is
+// empty), so the balance before from is the current balance minus the sum.
+func (r *ExportRepository) AmountsSince(userID uuid.UUID, from string) (map[string]int64, error) {
+	q := `SELECT account_id, SUM(amount) FROM transactions WHERE user_id = ?`
+	args := []any{userID.String()}
+	if from != "" {
+		q += " AND transaction_date >= ?"
+		args = append(args, from)
+	}
+	rows, err := r.db.Query(q+` GROUP BY account_id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("account sums: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[string]int64)
+	for rows.Next() {
+		var id string
+		var sum int64
+		if err := rows.Scan(&id, &sum); err != nil {
+			return nil, fmt.Errorf("scan account sum: %w", err)
+		}
+		out[id] = sum
+	}
+	return out, rows.Err()
+}
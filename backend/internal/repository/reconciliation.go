@@ -0,0 +1,306 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"monman-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrReconciliationNotFound indicates the reconciliation id does not exist for this user.
+	ErrReconciliationNotFound = errors.New("reconciliation not found")
+	// ErrTransactionReconciled indicates a change would touch a row locked by a completed reconciliation.
+	ErrTransactionReconciled = errors.New("transaction is reconciled")
+)
+
+// ReconciliationAdjustmentDescription is the description of the row posted to close a reconciliation gap.
+const ReconciliationAdjustmentDescription = "Penyesuaian rekonsiliasi"
+
+type ReconciliationRepository struct {
+	db *sql.DB
+}
+
+func NewReconciliationRepository(db *sql.DB) *ReconciliationRepository {
+	return &ReconciliationRepository{db: db}
+}
+
+const reconciliationSelect = `
+	SELECT r.id, r.account_id, r.statement_date, r.statement_balance, COALESCE(r.cleared_balance, 0),
+		r.status, COALESCE(r.adjustment_transaction_id, ''), COALESCE(t.amount, 0),
+		r.created_at, COALESCE(r.completed_at, '')
+	FROM reconciliations r
+	LEFT JOIN transactions t ON t.id = r.adjustment_transaction_id`
+
+// Create opens a reconciliation for the account. Callers check no other one is open.
+func (r *ReconciliationRepository) Create(userID, accountID uuid.UUID, statementDate string, statementBalance int64) (uuid.UUID, error) {
+	id := uuid.New()
+	_, err := r.db.Exec(`
+		INSERT INTO reconciliations (id, user_id, account_id, statement_date, statement_balance)
+		VALUES (?, ?, ?, ?, ?)
+	`, id.String(), userID.String(), accountID.String(), statementDate, statementBalance)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("insert reconciliation: %w", err)
+	}
+	return id, nil
+}
+
+// Get returns one of the user's reconciliations; ClearedBalance is the stored value for completed ones.
+func (r *ReconciliationRepository) Get(userID, id uuid.UUID) (*models.ReconciliationAPI, error) {
+	list, err := r.query(reconciliationSelect+`
+		WHERE r.id = ? AND r.user_id = ?`, id.String(), userID.String())
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, ErrReconciliationNotFound
+	}
+	return &list[0], nil
+}
+
+// ListForAccount returns the account's reconciliations, newest statement first.
+func (r *ReconciliationRepository) ListForAccount(userID, accountID uuid.UUID) ([]models.ReconciliationAPI, error) {
+	return r.query(reconciliationSelect+`
+		WHERE r.account_id = ? AND r.user_id = ?
+		ORDER BY r.statement_date DESC, r.created_at DESC`, accountID.String(), userID.String())
+}
+
+// Latest returns the account's most recent reconciliation with the given status, or nil.
+func (r *ReconciliationRepository) Latest(accountID uuid.UUID, status string) (*models.ReconciliationAPI, error) {
+	list, err := r.query(reconciliationSelect+`
+		WHERE r.account_id = ? AND r.status = ?
+		ORDER BY r.statement_date DESC, r.completed_at DESC, r.created_at DESC
+		LIMIT 1`, accountID.String(), status)
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+	return &list[0], nil
+}
+
+// UpdateStatement changes an open reconciliation's statement date and balance.
+func (r *ReconciliationRepository) UpdateStatement(userID, id uuid.UUID, statementDate string, statementBalance int64) error {
+	res, err := r.db.Exec(`
+		UPDATE reconciliations
+		SET statement_date = ?, statement_balance = ?, updated_at = datetime('now')
+		WHERE id = ? AND user_id = ? AND status = 'open'
+	`, statementDate, statementBalance, id.String(), userID.String())
+	if err != nil {
+		return fmt.Errorf("update reconciliation: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("update reconciliation: %w", err)
+	} else if n == 0 {
+		return ErrReconciliationNotFound
+	}
+	return nil
+}
+
+// Transactions returns the account's rows dated up to statementDate that no reconciliation has locked,
+// oldest first.
+func (r *ReconciliationRepository) Transactions(accountID uuid.UUID, statementDate string) ([]models.ReconciliationTransactionAPI, error) {
+	rows, err := r.db.Query(`
+		SELECT id, transaction_date, description, amount, cleared
+		FROM transactions
+		WHERE account_id = ? AND reconciliation_id IS NULL AND transaction_date <= ?
+		ORDER BY transaction_date, created_at, id
+	`, accountID.String(), statementDate)
+	if err != nil {
+		return nil, fmt.Errorf("list reconciliation transactions: %w", err)
+	}
+	defer rows.Close()
+
+	out := []models.ReconciliationTransactionAPI{}
+	for rows.Next() {
+		var (
+			t       models.ReconciliationTransactionAPI
+			cleared int
+		)
+		if err := rows.Scan(&t.ID, &t.Date, &t.Description, &t.Amount, &cleared); err != nil {
+			return nil, fmt.Errorf("scan reconciliation transaction: %w", err)
+		}
+		t.Cleared = cleared == 1
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// ClearedBalance is the account balance counting only cleared or reconciled rows dated up to
+// statementDate. Balance not backed by any row (seeded before transactions were kept) counts as cleared.
+func (r *ReconciliationRepository) ClearedBalance(accountID uuid.UUID, statementDate string) (int64, error) {
+	var bal int64
+	err := r.db.QueryRow(`
+		SELECT a.balance
+			- COALESCE((SELECT SUM(amount) FROM transactions WHERE account_id = a.id), 0)
+			+ COALESCE((
+				SELECT SUM(amount) FROM transactions
+				WHERE account_id = a.id AND transaction_date <= ?
+					AND (cleared = 1 OR reconciliation_id IS NOT NULL)
+			), 0)
+		FROM accounts a WHERE a.id = ?
+	`, statementDate, accountID.String()).Scan(&bal)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrAccountNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("cleared balance: %w", err)
+	}
+	return bal, nil
+}
+
+// SetCleared marks the given (distinct) rows as cleared or not. They must all be unreconciled rows of
+// the account dated up to statementDate, else nothing changes and ErrTransactionNotFound is returned.
+func (r *ReconciliationRepository) SetCleared(accountID uuid.UUID, statementDate string, ids []uuid.UUID, cleared bool) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	args := []any{sqlBool(cleared), accountID.String(), statementDate}
+	for _, id := range ids {
+		args = append(args, id.String())
+	}
+	res, err := tx.Exec(`
+		UPDATE transactions SET cleared = ?
+		WHERE account_id = ? AND reconciliation_id IS NULL AND transaction_date <= ?
+			AND id IN (`+placeholders(len(ids))+`)
+	`, args...)
+	if err != nil {
+		return fmt.Errorf("set cleared: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("set cleared: %w", err)
+	} else if n != int64(len(ids)) {
+		return ErrTransactionNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// Complete closes an open reconciliation: a non-zero adjustment is posted as a cleared 'transfer'-type
+// row on the statement date, then every cleared row up to that date is stamped (locked) with the
+// reconciliation.
+func (r *ReconciliationRepository) Complete(userID uuid.UUID, rec *models.ReconciliationAPI, clearedBalance, adjustment int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var adjustmentID any
+	if adjustment != 0 {
+		id := uuid.New().String()
+		if _, err := tx.Exec(`
+			INSERT INTO transactions (
+				id, user_id, account_id, category_id, amount, description,
+				transaction_type, transaction_date, cleared, created_at, updated_at
+			) VALUES (?, ?, ?, NULL, ?, ?, 'transfer', ?, 1, datetime('now'), datetime('now'))
+		`, id, userID.String(), rec.AccountID, adjustment, ReconciliationAdjustmentDescription, rec.StatementDate); err != nil {
+			return fmt.Errorf("insert reconciliation adjustment: %w", err)
+		}
+		if err := rebaseTx(tx, userID, "t.id = ?", id); err != nil {
+			return err
+		}
+		adjustmentID = id
+	}
+	if _, err := tx.Exec(`
+		UPDATE transactions SET reconciliation_id = ?
+		WHERE account_id = ? AND reconciliation_id IS NULL AND cleared = 1 AND transaction_date <= ?
+	`, rec.ID, rec.AccountID, rec.StatementDate); err != nil {
+		return fmt.Errorf("lock reconciled transactions: %w", err)
+	}
+	res, err := tx.Exec(`
+		UPDATE reconciliations
+		SET status = 'completed', cleared_balance = ?, adjustment_transaction_id = ?,
+			completed_at = datetime('now'), updated_at = datetime('now')
+		WHERE id = ? AND user_id = ? AND status = 'open'
+	`, clearedBalance, adjustmentID, rec.ID, userID.String())
+	if err != nil {
+		return fmt.Errorf("complete reconciliation: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("complete reconciliation: %w", err)
+	} else if n == 0 {
+		return ErrReconciliationNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// Delete cancels an open reconciliation or undoes a completed one: its rows are unlocked (they stay
+// cleared) and its adjustment row is removed, the DELETE trigger reversing the balance.
+func (r *ReconciliationRepository) Delete(userID, id uuid.UUID) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var adjustmentID sql.NullString
+	err = tx.QueryRow(`SELECT adjustment_transaction_id FROM reconciliations WHERE id = ? AND user_id = ?`,
+		id.String(), userID.String()).Scan(&adjustmentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrReconciliationNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("load reconciliation: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE transactions SET reconciliation_id = NULL WHERE reconciliation_id = ?`, id.String()); err != nil {
+		return fmt.Errorf("unlock transactions: %w", err)
+	}
+	if adjustmentID.Valid {
+		if _, err := tx.Exec(`DELETE FROM transactions WHERE id = ? AND user_id = ?`, adjustmentID.String, userID.String()); err != nil {
+			return fmt.Errorf("delete reconciliation adjustment: %w", err)
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM reconciliations WHERE id = ?`, id.String()); err != nil {
+		return fmt.Errorf("delete reconciliation: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+func (r *ReconciliationRepository) query(q string, args ...any) ([]models.ReconciliationAPI, error) {
+	rows, err := r.db.Query(q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list reconciliations: %w", err)
+	}
+	defer rows.Close()
+
+	out := []models.ReconciliationAPI{}
+	for rows.Next() {
+		var rec models.ReconciliationAPI
+		if err := rows.Scan(&rec.ID, &rec.AccountID, &rec.StatementDate, &rec.StatementBalance, &rec.ClearedBalance,
+			&rec.Status, &rec.AdjustmentTransactionID, &rec.AdjustmentAmount,
+			&rec.CreatedAt, &rec.CompletedAt); err != nil {
+			return nil, fmt.Errorf("scan reconciliation: %w", err)
+		}
+		out = append(out, rec)
+	}
+	return out, rows.Err()
+}
+
+// ensureUnreconciled fails with ErrTransactionReconciled when any transactions row matching cond is
+// locked by a completed reconciliation. Write paths call it inside their DB transaction.
+func ensureUnreconciled(tx *sql.Tx, cond string, args ...any) error {
+	var n int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM transactions WHERE reconciliation_id IS NOT NULL AND `+cond, args...).Scan(&n); err != nil {
+		return fmt.Errorf("check reconciled: %w", err)
+	}
+	if n > 0 {
+		return ErrTransactionReconciled
+	}
+	return nil
+}
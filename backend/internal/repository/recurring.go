@@ -0,0 +1,321 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"monman-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ErrRecurringNotFound indicates the recurring rule id does not exist for this user.
+var ErrRecurringNotFound = errors.New("recurring transaction not found")
+
+// RecurringRule is one recurring_transactions row. Dates are YYYY-MM-DD; Amount is signed cents.
+type RecurringRule struct {
+	ID                 uuid.UUID
+	UserID             uuid.UUID
+	AccountID          uuid.UUID
+	CategoryID         uuid.UUID
+	Amount             int64
+	Description        string
+	TransactionType    string
+	Frequency          string
+	FrequencyInterval  int
+	StartDate          string
+	EndDate            *string
+	NextOccurrenceDate string
+	IsActive           bool
+}
+
+// RecurringRepository stores recurring rules; postings go through TransactionRepository.CreateRecurringOccurrence.
+type RecurringRepository struct {
+	db *sql.DB
+}
+
+func NewRecurringRepository(db *sql.DB) *RecurringRepository {
+	return &RecurringRepository{db: db}
+}
+
+// Create inserts a rule; the caller sets NextOccurrenceDate (normally StartDate).
+func (r *RecurringRepository) Create(rule RecurringRule) (uuid.UUID, error) {
+	id := uuid.New()
+	q := `
+		INSERT INTO recurring_transactions (
+			id, user_id, account_id, category_id, amount, description,
+			transaction_type, frequency, frequency_interval,
+			start_date, end_date, next_occurrence_date, is_active,
+			created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'), datetime('now'))
+	`
+	_, err := r.db.Exec(q,
+		id.String(), rule.UserID.String(), rule.AccountID.String(), rule.CategoryID.String(),
+		rule.Amount, rule.Description,
+		rule.TransactionType, rule.Frequency, rule.FrequencyInterval,
+		rule.StartDate, nullableString(rule.EndDate), rule.NextOccurrenceDate, sqlBool(rule.IsActive),
+	)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("insert recurring transaction: %w", err)
+	}
+	return id, nil
+}
+
+// ListForUser returns rules with account and category names, soonest occurrence first.
+func (r *RecurringRepository) ListForUser(userID uuid.UUID) ([]models.RecurringTransactionAPI, error) {
+	q := `
+		SELECT
+			rt.id, rt.account_id, a.name, rt.category_id, COALESCE(c.name, '—'),
+			rt.amount, rt.description, rt.transaction_type,
+			rt.frequency, COALESCE(rt.frequency_interval, 1),
+			rt.start_date, rt.end_date, rt.next_occurrence_date, rt.is_active
+		FROM recurring_transactions rt
+		INNER JOIN accounts a ON a.id = rt.account_id
+		LEFT JOIN categories c ON c.id = rt.category_id
+		WHERE rt.user_id = ?
+		ORDER BY rt.is_active DESC, rt.next_occurrence_date ASC, rt.description ASC
+	`
+	rows, err := r.db.Query(q, userID.String())
+	if err != nil {
+		return nil, fmt.Errorf("list recurring transactions: %w", err)
+	}
+	defer rows.Close()
+
+	out := []models.RecurringTransactionAPI{}
+	for rows.Next() {
+		var (
+			rt           models.RecurringTransactionAPI
+			catID, endAt sql.NullString
+			active       int
+		)
+		if err := rows.Scan(
+			&rt.ID, &rt.AccountID, &rt.Account, &catID, &rt.Category,
+			&rt.Amount, &rt.Description, &rt.TransactionType,
+			&rt.Frequency, &rt.FrequencyInterval,
+			&rt.StartDate, &endAt, &rt.NextOccurrenceDate, &active,
+		); err != nil {
+			return nil, fmt.Errorf("scan recurring transaction: %w", err)
+		}
+		rt.CategoryID = catID.String
+		rt.EndDate = endAt.String
+		rt.IsActive = active == 1
+		out = append(out, rt)
+	}
+	return out, rows.Err()
+}
+
+// GetForUser loads one rule owned by the user.
+func (r *RecurringRepository) GetForUser(id, userID uuid.UUID) (*RecurringRule, error) {
+	row := r.db.QueryRow(recurringSelect+` WHERE id = ? AND user_id = ?`, id.String(), userID.String())
+	rule, err := scanRecurringRule(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrRecurringNotFound
+	}
+	return rule, err
+}
+
+// Update rewrites the editable columns of one owned rule.
+func (r *RecurringRepository) Update(rule RecurringRule) error {
+	res, err := r.db.Exec(`
+		UPDATE recurring_transactions SET
+			account_id = ?, category_id = ?, amount = ?, description = ?,
+			transaction_type = ?, frequency = ?, frequency_interval = ?,
+			end_date = ?, next_occurrence_date = ?, is_active = ?,
+			updated_at = datetime('now')
+		WHERE id = ? AND user_id = ?
+	`,
+		rule.AccountID.String(), rule.CategoryID.String(), rule.Amount, rule.Description,
+		rule.TransactionType, rule.Frequency, rule.FrequencyInterval,
+		nullableString(rule.EndDate), rule.NextOccurrenceDate, sqlBool(rule.IsActive),
+		rule.ID.String(), rule.UserID.String(),
+	)
+	if err != nil {
+		return fmt.Errorf("update recurring transaction: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("update recurring transaction: %w", err)
+	} else if n == 0 {
+		return ErrRecurringNotFound
+	}
+	return nil
+}
+
+// Deactivate stops a rule, e.g. once its end_date falls before the next occurrence or its
+// account is no longer active.
+func (r *RecurringRepository) Deactivate(id uuid.UUID) error {
+	if _, err := r.db.Exec(`
+		UPDATE recurring_transactions SET is_active = 0, updated_at = datetime('now')
+		WHERE id = ? AND is_active = 1
+	`, id.String()); err != nil {
+		return fmt.Errorf("deactivate recurring transaction: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a rule and its occurrence log; already posted transactions are kept.
+func (r *RecurringRepository) Delete(id, userID uuid.UUID) error {
+	res, err := r.db.Exec(`DELETE FROM recurring_transactions WHERE id = ? AND user_id = ?`, id.String(), userID.String())
+	if err != nil {
+		return fmt.Errorf("delete recurring transaction: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("delete recurring transaction: %w", err)
+	} else if n == 0 {
+		return ErrRecurringNotFound
+	}
+	return nil
+}
+
+// ListDue returns active rules (all users) whose next occurrence is on or before today (YYYY-MM-DD).
+func (r *RecurringRepository) ListDue(today string) ([]RecurringRule, error) {
+	rows, err := r.db.Query(recurringSelect+`
+		WHERE is_active = 1 AND next_occurrence_date <= ?
+		ORDER BY next_occurrence_date, id`, today)
+	if err != nil {
+		return nil, fmt.Errorf("list due recurring transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var out []RecurringRule
+	for rows.Next() {
+		rule, err := scanRecurringRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *rule)
+	}
+	return out, rows.Err()
+}
+
+const recurringSelect = `
+	SELECT id, user_id, account_id, category_id, amount, description,
+		transaction_type, frequency, COALESCE(frequency_interval, 1),
+		start_date, end_date, next_occurrence_date, is_active
+	FROM recurring_transactions`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRecurringRule(s rowScanner) (*RecurringRule, error) {
+	var (
+		rule                   RecurringRule
+		idStr, userStr, accStr string
+		catStr, endAt          sql.NullString
+		active                 int
+	)
+	if err := s.Scan(
+		&idStr, &userStr, &accStr, &catStr, &rule.Amount, &rule.Description,
+		&rule.TransactionType, &rule.Frequency, &rule.FrequencyInterval,
+		&rule.StartDate, &endAt, &rule.NextOccurrenceDate, &active,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scan recurring transaction: %w", err)
+	}
+	var err error
+	if rule.ID, err = uuid.Parse(idStr); err != nil {
+		return nil, fmt.Errorf("parse recurring id: %w", err)
+	}
+	if rule.UserID, err = uuid.Parse(userStr); err != nil {
+		return nil, fmt.Errorf("parse user id: %w", err)
+	}
+	if rule.AccountID, err = uuid.Parse(accStr); err != nil {
+		return nil, fmt.Errorf("parse account id: %w", err)
+	}
+	if catStr.Valid {
+		if rule.CategoryID, err = uuid.Parse(catStr.String); err != nil {
+			return nil, fmt.Errorf("parse category id: %w", err)
+		}
+	}
+	if endAt.Valid && endAt.String != "" {
+		rule.EndDate = &endAt.String
+	}
+	rule.IsActive = active == 1
+	return &rule, nil
+}
+
+func nullableString(s *string) interface{} {
+	if s == nil {
+		return nil
+	}
+	return *s
+}
+
+func nullableInt64(n *int64) interface{} {
+	if n == nil {
+		return nil
+	}
+	return *n
+}
+
+func nullableInt(n *int) interface{} {
+	if n == nil {
+		return nil
+	}
+	return *n
+}
+
+// CreateRecurringOccurrence posts one occurrence of a rule through the same insert path as Create and
+// advances next_occurrence_date in one DB transaction. The (rule, date) pair is claimed in
+// recurring_occurrences first, so a repeated call for an already posted date only advances the rule.
+// finished deactivates the rule once next would pass its end_date.
+func (r *TransactionRepository) CreateRecurringOccurrence(rule RecurringRule, occurrenceDate, next string, finished bool) (posted bool, err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	occID := uuid.New()
+	res, err := tx.Exec(`
+		INSERT OR IGNORE INTO recurring_occurrences (id, recurring_id, occurrence_date, created_at)
+		VALUES (?, ?, ?, datetime('now'))
+	`, occID.String(), rule.ID.String(), occurrenceDate)
+	if err != nil {
+		return false, fmt.Errorf("claim occurrence: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("claim occurrence: %w", err)
+	}
+
+	if n > 0 {
+		pattern := rule.Frequency
+		if rule.FrequencyInterval > 1 {
+			pattern = fmt.Sprintf("%s/%d", rule.Frequency, rule.FrequencyInterval)
+		}
+		txID, err := insertTransactionTx(tx, newTransactionRow{
+			UserID:           rule.UserID,
+			AccountID:        rule.AccountID,
+			CategoryID:       rule.CategoryID,
+			Amount:           rule.Amount,
+			Description:      rule.Description,
+			Type:             rule.TransactionType,
+			Date:             occurrenceDate,
+			RecurringPattern: &pattern,
+		}, nil)
+		if err != nil {
+			return false, err
+		}
+		if _, err := tx.Exec(`UPDATE recurring_occurrences SET transaction_id = ? WHERE id = ?`,
+			txID.String(), occID.String()); err != nil {
+			return false, fmt.Errorf("link occurrence: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE recurring_transactions
+		SET next_occurrence_date = ?, is_active = ?, updated_at = datetime('now')
+		WHERE id = ? AND next_occurrence_date = ?
+	`, next, sqlBool(!finished), rule.ID.String(), occurrenceDate); err != nil {
+		return false, fmt.Errorf("advance recurring transaction: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("commit: %w", err)
+	}
+	return n > 0, nil
+}
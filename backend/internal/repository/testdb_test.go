@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+
+	"monman-backend/internal/db"
+
+	"github.com/google/uuid"
+	_ "modernc.org/sqlite"
+)
+
+// feeCategoryID is the system "Biaya Admin & Transfer" expense category (005_transfers.sql).
+var feeCategoryID = uuid.MustParse("14d1afbf-90e1-4df5-82c1-817bb1e6f105")
+
+// migratedTestDB opens an in-memory database with every migration applied, so the balance and budget
+// triggers under test are the real ones.
+func migratedTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	conn, err := sql.Open("sqlite", "file::memory:?_pragma=foreign_keys(1)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetMaxOpenConns(1)
+	t.Cleanup(func() { conn.Close() })
+	if err := db.ApplySQLiteMigrations(conn, "../..", false); err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+func testExec(t *testing.T, conn *sql.DB, q string, args ...any) {
+	t.Helper()
+	if _, err := conn.Exec(q, args...); err != nil {
+		t.Fatalf("%s: %v", q, err)
+	}
+}
+
+func testUser(t *testing.T, conn *sql.DB) uuid.UUID {
+	t.Helper()
+	id := uuid.New()
+	testExec(t, conn, `INSERT INTO users (id, username, password_hash, first_name, last_name) VALUES (?, ?, 'x', 'Test', 'User')`,
+		id.String(), "u"+id.String()[:8])
+	return id
+}
+
+func testAccount(t *testing.T, conn *sql.DB, userID uuid.UUID, name string) uuid.UUID {
+	t.Helper()
+	id := uuid.New()
+	testExec(t, conn, `INSERT INTO accounts (id, user_id, name, account_type) VALUES (?, ?, ?, 'bank')`,
+		id.String(), userID.String(), name)
+	return id
+}
+
+func testCategory(t *testing.T, conn *sql.DB, userID uuid.UUID, name string) uuid.UUID {
+	t.Helper()
+	id := uuid.New()
+	testExec(t, conn, `INSERT INTO categories (id, user_id, name, category_type) VALUES (?, ?, ?, 'expense')`,
+		id.String(), userID.String(), name)
+	return id
+}
+
+// testBudget adds an active monthly budget on categoryID for [start, end].
+func testBudget(t *testing.T, conn *sql.DB, userID, categoryID uuid.UUID, name string, allocated int64, start, end string) uuid.UUID {
+	t.Helper()
+	id := uuid.New()
+	testExec(t, conn, `
+		INSERT INTO budgets (id, user_id, category_id, name, allocated_amount, budget_period, period_start_date, period_end_date)
+		VALUES (?, ?, ?, ?, ?, 'monthly', ?, ?)`,
+		id.String(), userID.String(), categoryID.String(), name, allocated, start, end)
+	return id
+}
+
+func testExpense(t *testing.T, r *TransactionRepository, userID, accountID, categoryID uuid.UUID, cents int64, date string, link *BudgetLinkParams) uuid.UUID {
+	t.Helper()
+	id, err := r.Create(userID, accountID, categoryID, -cents, "Belanja", "expense", date, nil, nil, link, nil)
+	if err != nil {
+		t.Fatalf("create expense: %v", err)
+	}
+	return id
+}
+
+func testInt(t *testing.T, conn *sql.DB, q string, args ...any) int64 {
+	t.Helper()
+	var n int64
+	if err := conn.QueryRow(q, args...).Scan(&n); err != nil {
+		t.Fatalf("%s: %v", q, err)
+	}
+	return n
+}
+
+func budgetSpent(t *testing.T, conn *sql.DB, budgetID uuid.UUID) int64 {
+	t.Helper()
+	return testInt(t, conn, `SELECT spent_amount FROM budgets WHERE id = ?`, budgetID.String())
+}
+
+func accountBalance(t *testing.T, conn *sql.DB, accountID uuid.UUID) int64 {
+	t.Helper()
+	return testInt(t, conn, `SELECT balance FROM accounts WHERE id = ?`, accountID.String())
+}
@@ -2,9 +2,11 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 
 	"monman-backend/internal/models"
+	"monman-backend/internal/utils"
 
 	"github.com/google/uuid"
 )
@@ -17,8 +19,13 @@ type BudgetLinkParams struct {
 	Store     *string
 	UserID    uuid.UUID
 	UnitPrice *int64 // optional magnitude in cents
+	// CommonPurchaseID records the preset the expense was logged from (insert only).
+	CommonPurchaseID *uuid.UUID
 }
 
+// ErrTransactionNotFound indicates the transaction id does not exist for this user.
+var ErrTransactionNotFound = errors.New("transaction not found")
+
 // TransactionRepository reads transaction rows for finance views.
 type TransactionRepository struct {
 	db *sql.DB
@@ -28,28 +35,58 @@ func NewTransactionRepository(db *sql.DB) *TransactionRepository {
 	return &TransactionRepository{db: db}
 }
 
-// SumAccountBalance returns total balance across active accounts for a user (cents).
-func (r *TransactionRepository) SumAccountBalance(userID uuid.UUID) (int64, error) {
-	var sum sql.NullInt64
+// SumAccountBalance returns total balance across active accounts for a user (cents), each account
+// converted into the base currency at the rate effective on date (YYYY-MM-DD).
+func (r *TransactionRepository) SumAccountBalance(userID uuid.UUID, date string) (int64, error) {
 	q := `
-		SELECT COALESCE(SUM(balance), 0) FROM accounts
+		SELECT currency, COALESCE(SUM(balance), 0) FROM accounts
 		WHERE user_id = ? AND is_active = 1
+		GROUP BY currency
 	`
-	if err := r.db.QueryRow(q, userID.String()).Scan(&sum); err != nil {
+	rows, err := r.db.Query(q, userID.String())
+	if err != nil {
 		return 0, fmt.Errorf("sum account balance: %w", err)
 	}
-	if !sum.Valid {
-		return 0, nil
+	defer rows.Close()
+	sums := make(map[string]int64)
+	for rows.Next() {
+		var (
+			currency string
+			sum      int64
+		)
+		if err := rows.Scan(&currency, &sum); err != nil {
+			return 0, fmt.Errorf("scan account balance: %w", err)
+		}
+		sums[currency] = sum
 	}
-	return sum.Int64, nil
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	rows.Close()
+
+	base, err := baseCurrency(r.db, userID)
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for currency, sum := range sums {
+		rate, err := rateBetween(r.db, userID, currency, base, base, date)
+		if err != nil {
+			return 0, err
+		}
+		total += utils.ConvertCents(sum, rate, base)
+	}
+	return total, nil
 }
 
 // SumAmountForCalendarMonth returns sum(t.amount) for transactions in the same YYYY-MM as local "today".
+// Transfer legs are excluded; they only move money between the user's own accounts.
 func (r *TransactionRepository) SumAmountForCalendarMonth(userID uuid.UUID) (int64, error) {
 	var sum sql.NullInt64
 	q := `
-		SELECT COALESCE(SUM(amount), 0) FROM transactions
+		SELECT COALESCE(SUM(COALESCE(base_amount, amount)), 0) FROM transactions
 		WHERE user_id = ?
+		  AND transaction_type != 'transfer'
 		  AND strftime('%Y-%m', transaction_date) = strftime('%Y-%m', date('now','localtime'))
 	`
 	if err := r.db.QueryRow(q, userID.String()).Scan(&sum); err != nil {
@@ -62,13 +99,15 @@ func (r *TransactionRepository) SumAmountForCalendarMonth(userID uuid.UUID) (int
 }
 
 // MonthIncomeExpenseCents returns income (sum of positive amounts) and expense (sum of abs of negatives) for current calendar month.
+// Transfer legs are excluded; a transfer fee is a normal expense row and is counted.
 func (r *TransactionRepository) MonthIncomeExpenseCents(userID uuid.UUID) (income int64, expense int64, err error) {
 	q := `
 		SELECT
-			COALESCE(SUM(CASE WHEN amount > 0 THEN amount ELSE 0 END), 0),
-			COALESCE(SUM(CASE WHEN amount < 0 THEN -amount ELSE 0 END), 0)
+			COALESCE(SUM(CASE WHEN amount > 0 THEN COALESCE(base_amount, amount) ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN amount < 0 THEN -COALESCE(base_amount, amount) ELSE 0 END), 0)
 		FROM transactions
 		WHERE user_id = ?
+		  AND transaction_type != 'transfer'
 		  AND strftime('%Y-%m', transaction_date) = strftime('%Y-%m', date('now','localtime'))
 	`
 	var inc, exp sql.NullInt64
@@ -86,62 +125,14 @@ func (r *TransactionRepository) MonthIncomeExpenseCents(userID uuid.UUID) (incom
 
 // ListForUser returns transactions with account and category names, newest first.
 func (r *TransactionRepository) ListForUser(userID uuid.UUID, limit, offset int) ([]models.TransactionAPI, error) {
-	if limit <= 0 || limit > 500 {
-		limit = 100
-	}
-	if offset < 0 {
-		offset = 0
-	}
-	q := `
-		SELECT
-			t.id,
-			t.transaction_date,
-			t.description,
-			t.amount,
-			a.name,
-			COALESCE(c.name, '—') AS category_name
-		FROM transactions t
-		INNER JOIN accounts a ON a.id = t.account_id
-		LEFT JOIN categories c ON c.id = t.category_id
-		WHERE t.user_id = ?
-		ORDER BY t.transaction_date DESC, t.created_at DESC
-		LIMIT ? OFFSET ?
-	`
-	rows, err := r.db.Query(q, userID.String(), limit, offset)
+	page, err := r.list(userID, models.TransactionFilter{Limit: limit, Offset: offset})
 	if err != nil {
-		return nil, fmt.Errorf("list transactions: %w", err)
-	}
-	defer rows.Close()
-
-	var out []models.TransactionAPI
-	for rows.Next() {
-		var (
-			idStr       string
-			dateStr     string
-			description string
-			amount      int64
-			accountName string
-			category    string
-		)
-		if err := rows.Scan(&idStr, &dateStr, &description, &amount, &accountName, &category); err != nil {
-			return nil, fmt.Errorf("scan transaction: %w", err)
-		}
-		if _, err := uuid.Parse(idStr); err != nil {
-			continue
-		}
-		out = append(out, models.TransactionAPI{
-			ID:          idStr,
-			Date:        dateStr,
-			Description: description,
-			Category:    category,
-			Amount:      amount,
-			Account:     accountName,
-		})
+		return nil, err
 	}
-	return out, rows.Err()
+	return page.Transactions, nil
 }
 
-// Create inserts one transaction row and optionally a budget_transactions row inside a DB transaction.
+// Create inserts one transaction row, its tags and optionally a budget_transactions row inside a DB transaction.
 // Caller must enforce account/category ownership and triggers update balances / budget spent.
 func (r *TransactionRepository) Create(
 	userID uuid.UUID,
@@ -152,7 +143,9 @@ func (r *TransactionRepository) Create(
 	txnType string,
 	txnDate string,
 	location *string,
+	foreign *ForeignAmount,
 	budgetLink *BudgetLinkParams,
+	tags []string,
 ) (uuid.UUID, error) {
 	tx, err := r.db.Begin()
 	if err != nil {
@@ -160,69 +153,366 @@ func (r *TransactionRepository) Create(
 	}
 	defer func() { _ = tx.Rollback() }()
 
+	id, err := insertTransactionTx(tx, newTransactionRow{
+		UserID:      userID,
+		AccountID:   accountID,
+		CategoryID:  categoryID,
+		Amount:      amountSigned,
+		Description: description,
+		Type:        txnType,
+		Date:        txnDate,
+		Location:    location,
+		Foreign:     foreign,
+		Tags:        tags,
+	}, budgetLink)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return uuid.Nil, fmt.Errorf("commit: %w", err)
+	}
+	return id, nil
+}
+
+// newTransactionRow is one income/expense insert shared by Create and the batch paths (recurring postings, imports).
+type newTransactionRow struct {
+	UserID           uuid.UUID
+	AccountID        uuid.UUID
+	CategoryID       uuid.UUID
+	Amount           int64 // signed cents
+	Description      string
+	Type             string
+	Date             string
+	Location         *string
+	RecurringPattern *string    // set for rows materialized from recurring_transactions
+	ImportBatchID    *uuid.UUID // set for rows created by a statement import
+	ExternalID       string     // bank-assigned id from an import (OFX FITID), empty otherwise
+	Foreign          *ForeignAmount
+	Tags             []string // normalized by the caller
+}
+
+// ForeignAmount is what a row cost in another currency than its account's: signed units × 100.
+type ForeignAmount struct {
+	Currency string
+	Amount   int64
+}
+
+// args returns the original_amount and original_currency column values.
+func (f *ForeignAmount) args() (amount, currency any) {
+	if f == nil {
+		return nil, nil
+	}
+	return f.Amount, f.Currency
+}
+
+func insertTransactionTx(tx *sql.Tx, row newTransactionRow, budgetLink *BudgetLinkParams) (uuid.UUID, error) {
 	id := uuid.New()
 	insert := `
 		INSERT INTO transactions (
 			id, user_id, account_id, category_id, amount, description,
 			transaction_type, transaction_date, location_name,
+			is_recurring, recurring_pattern, import_batch_id, external_id,
+			original_amount, original_currency,
 			created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'), datetime('now'))
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'), datetime('now'))
 	`
-	var cat interface{} = categoryID.String()
 	var loc interface{}
-	if location != nil {
-		loc = *location
+	if row.Location != nil {
+		loc = *row.Location
+	}
+	var pattern interface{}
+	recurring := 0
+	if row.RecurringPattern != nil {
+		pattern = *row.RecurringPattern
+		recurring = 1
+	}
+	var batch interface{}
+	if row.ImportBatchID != nil {
+		batch = row.ImportBatchID.String()
 	}
+	origAmount, origCurrency := row.Foreign.args()
 
 	if _, err := tx.Exec(insert,
-		id.String(), userID.String(), accountID.String(), cat, amountSigned, description,
-		txnType, txnDate, loc,
+		id.String(), row.UserID.String(), row.AccountID.String(), row.CategoryID.String(), row.Amount, row.Description,
+		row.Type, row.Date, loc,
+		recurring, pattern, batch, emptyAsNull(row.ExternalID),
+		origAmount, origCurrency,
 	); err != nil {
 		return uuid.Nil, fmt.Errorf("insert transaction: %w", err)
 	}
+	if err := rebaseTx(tx, row.UserID, "t.id = ?", id.String()); err != nil {
+		return uuid.Nil, err
+	}
 
 	if budgetLink != nil {
-		btID := uuid.New()
-		bt := `
+		if err := insertBudgetLinkTx(tx, id, row.Description, budgetLink); err != nil {
+			return uuid.Nil, err
+		}
+	}
+	if err := insertTagsTx(tx, id, row.Tags); err != nil {
+		return uuid.Nil, err
+	}
+	return id, nil
+}
+
+// insertTagsTx adds tags to a transaction, ignoring ones it already has.
+func insertTagsTx(tx *sql.Tx, transactionID uuid.UUID, tags []string) error {
+	for _, tag := range tags {
+		if _, err := tx.Exec(`INSERT INTO transaction_tags (transaction_id, tag) VALUES (?, ?) ON CONFLICT DO NOTHING`,
+			transactionID.String(), tag); err != nil {
+			return fmt.Errorf("insert transaction tag: %w", err)
+		}
+	}
+	return nil
+}
+
+// budgetLinkArgs normalizes optional link columns; an empty item falls back to the transaction description.
+func budgetLinkArgs(description string, link *BudgetLinkParams) (item string, qty, store sql.NullString, unit sql.NullInt64) {
+	if link.Quantity != nil && *link.Quantity != "" {
+		qty = sql.NullString{String: *link.Quantity, Valid: true}
+	}
+	if link.Store != nil && *link.Store != "" {
+		store = sql.NullString{String: *link.Store, Valid: true}
+	}
+	if link.UnitPrice != nil {
+		unit = sql.NullInt64{Int64: *link.UnitPrice, Valid: true}
+	}
+	item = link.Item
+	if item == "" {
+		item = description
+	}
+	return item, qty, store, unit
+}
+
+func insertBudgetLinkTx(tx *sql.Tx, transactionID uuid.UUID, description string, link *BudgetLinkParams) error {
+	item, qty, store, unit := budgetLinkArgs(description, link)
+	q := `
+		INSERT INTO budget_transactions (
+			id, transaction_id, budget_id, user_id,
+			item, quantity, store, unit_price, common_purchase_id,
+			created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'))
+	`
+	var preset interface{}
+	if link.CommonPurchaseID != nil {
+		preset = link.CommonPurchaseID.String()
+	}
+	if _, err := tx.Exec(q,
+		uuid.New().String(),
+		transactionID.String(),
+		link.BudgetID.String(),
+		link.UserID.String(),
+		item, qty, store, unit, preset,
+	); err != nil {
+		return fmt.Errorf("insert budget_transactions: %w", err)
+	}
+	return nil
+}
+
+// GetForUser loads one transaction row and its optional budget_transactions link.
+// Returns ErrTransactionNotFound when the id does not belong to the user.
+func (r *TransactionRepository) GetForUser(id, userID uuid.UUID) (*models.Transaction, *models.BudgetTransaction, error) {
+	q := `
+		SELECT
+			t.account_id, t.category_id, t.amount, t.description,
+			t.transaction_type, t.transaction_date, t.location_name,
+			t.original_amount, t.original_currency,
+			bt.id, bt.budget_id, bt.item, bt.quantity, bt.store, bt.unit_price
+		FROM transactions t
+		LEFT JOIN budget_transactions bt ON bt.transaction_id = t.id
+		WHERE t.id = ? AND t.user_id = ?
+	`
+	var (
+		accStr, dateStr        string
+		catStr, location       sql.NullString
+		btID, btBudget, btItem sql.NullString
+		btQty, btStore         sql.NullString
+		btUnit, origAmount     sql.NullInt64
+		origCurrency           sql.NullString
+		t                      models.Transaction
+	)
+	err := r.db.QueryRow(q, id.String(), userID.String()).Scan(
+		&accStr, &catStr, &t.Amount, &t.Description,
+		&t.TransactionType, &dateStr, &location,
+		&origAmount, &origCurrency,
+		&btID, &btBudget, &btItem, &btQty, &btStore, &btUnit,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil, ErrTransactionNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("get transaction: %w", err)
+	}
+	t.ID = id
+	t.UserID = userID
+	if t.AccountID, err = uuid.Parse(accStr); err != nil {
+		return nil, nil, fmt.Errorf("parse account id: %w", err)
+	}
+	if catStr.Valid {
+		cid, err := uuid.Parse(catStr.String)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parse category id: %w", err)
+		}
+		t.CategoryID = &cid
+	}
+	if t.TransactionDate, err = parseSQLiteTime(dateStr); err != nil {
+		return nil, nil, fmt.Errorf("parse transaction date: %w", err)
+	}
+	if location.Valid {
+		t.LocationName = &location.String
+	}
+	if origAmount.Valid && origCurrency.Valid {
+		t.OriginalAmount = &origAmount.Int64
+		t.OriginalCurrency = &origCurrency.String
+	}
+
+	if !btID.Valid {
+		return &t, nil, nil
+	}
+	link := &models.BudgetTransaction{
+		TransactionID: id,
+		UserID:        userID,
+		Item:          btItem.String,
+	}
+	if link.ID, err = uuid.Parse(btID.String); err != nil {
+		return nil, nil, fmt.Errorf("parse budget link id: %w", err)
+	}
+	if link.BudgetID, err = uuid.Parse(btBudget.String); err != nil {
+		return nil, nil, fmt.Errorf("parse budget id: %w", err)
+	}
+	if btQty.Valid {
+		link.Quantity = &btQty.String
+	}
+	if btStore.Valid {
+		link.Store = &btStore.String
+	}
+	if btUnit.Valid {
+		link.UnitPrice = &btUnit.Int64
+	}
+	return &t, link, nil
+}
+
+// Update rewrites one transaction row and its budget_transactions link inside a DB transaction.
+// A nil budgetLink drops any existing link; otherwise the link row is moved/edited in place.
+// Caller must enforce ownership; the UPDATE triggers reverse and reapply balances / budget spent.
+func (r *TransactionRepository) Update(
+	id uuid.UUID,
+	userID uuid.UUID,
+	accountID uuid.UUID,
+	categoryID uuid.UUID,
+	amountSigned int64,
+	description string,
+	txnType string,
+	txnDate string,
+	location *string,
+	foreign *ForeignAmount,
+	budgetLink *BudgetLinkParams,
+) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := ensureUnreconciled(tx, "id = ? AND user_id = ?", id.String(), userID.String()); err != nil {
+		return err
+	}
+	var loc interface{}
+	if location != nil {
+		loc = *location
+	}
+	origAmount, origCurrency := foreign.args()
+	res, err := tx.Exec(`
+		UPDATE transactions SET
+			account_id = ?, category_id = ?, amount = ?, description = ?,
+			transaction_type = ?, transaction_date = ?, location_name = ?,
+			original_amount = ?, original_currency = ?,
+			updated_at = datetime('now')
+		WHERE id = ? AND user_id = ?
+	`,
+		accountID.String(), categoryID.String(), amountSigned, description,
+		txnType, txnDate, loc,
+		origAmount, origCurrency,
+		id.String(), userID.String(),
+	)
+	if err != nil {
+		return fmt.Errorf("update transaction: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("update transaction: %w", err)
+	} else if n == 0 {
+		return ErrTransactionNotFound
+	}
+	if err := rebaseTx(tx, userID, "t.id = ?", id.String()); err != nil {
+		return err
+	}
+
+	if budgetLink == nil {
+		if _, err := tx.Exec(`DELETE FROM budget_transactions WHERE transaction_id = ?`, id.String()); err != nil {
+			return fmt.Errorf("delete budget_transactions: %w", err)
+		}
+	} else {
+		item, qty, store, unit := budgetLinkArgs(description, budgetLink)
+		upsert := `
 			INSERT INTO budget_transactions (
 				id, transaction_id, budget_id, user_id,
 				item, quantity, store, unit_price,
 				created_at
 			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, datetime('now'))
+			ON CONFLICT (transaction_id) DO UPDATE SET
+				common_purchase_id = CASE WHEN budget_transactions.budget_id = excluded.budget_id
+					THEN budget_transactions.common_purchase_id END,
+				budget_id = excluded.budget_id,
+				item = excluded.item,
+				quantity = excluded.quantity,
+				store = excluded.store,
+				unit_price = excluded.unit_price
 		`
-		var q sql.NullString
-		if budgetLink.Quantity != nil && *budgetLink.Quantity != "" {
-			q.Valid = true
-			q.String = *budgetLink.Quantity
-		}
-		var shop sql.NullString
-		if budgetLink.Store != nil && *budgetLink.Store != "" {
-			shop.Valid = true
-			shop.String = *budgetLink.Store
-		}
-		var unit sql.NullInt64
-		if budgetLink.UnitPrice != nil {
-			unit.Valid = true
-			unit.Int64 = *budgetLink.UnitPrice
-		}
-		item := budgetLink.Item
-		if item == "" {
-			item = description
-		}
-
-		if _, err := tx.Exec(bt,
-			btID.String(),
-			id.String(),
-			budgetLink.BudgetID.String(),
-			budgetLink.UserID.String(),
-			item, q, shop, unit,
+		if _, err := tx.Exec(upsert,
+			uuid.New().String(), id.String(),
+			budgetLink.BudgetID.String(), budgetLink.UserID.String(),
+			item, qty, store, unit,
 		); err != nil {
-			return uuid.Nil, fmt.Errorf("insert budget_transactions: %w", err)
+			return fmt.Errorf("upsert budget_transactions: %w", err)
 		}
 	}
 
 	if err := tx.Commit(); err != nil {
-		return uuid.Nil, fmt.Errorf("commit: %w", err)
+		return fmt.Errorf("commit: %w", err)
 	}
-	return id, nil
+	return nil
+}
+
+// Delete removes one transaction and its budget_transactions link; the DELETE triggers reverse balances / budget spent.
+func (r *TransactionRepository) Delete(id, userID uuid.UUID) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := ensureUnreconciled(tx, "id = ? AND user_id = ?", id.String(), userID.String()); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		DELETE FROM budget_transactions
+		WHERE transaction_id = ? AND user_id = ?
+	`, id.String(), userID.String()); err != nil {
+		return fmt.Errorf("delete budget_transactions: %w", err)
+	}
+	res, err := tx.Exec(`DELETE FROM transactions WHERE id = ? AND user_id = ?`, id.String(), userID.String())
+	if err != nil {
+		return fmt.Errorf("delete transaction: %w", err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("delete transaction: %w", err)
+	} else if n == 0 {
+		return ErrTransactionNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
 }
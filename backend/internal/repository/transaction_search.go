@@ -0,0 +1,330 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"monman-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+const transactionListFrom = `
+	FROM transactions t
+	INNER JOIN accounts a ON a.id = t.account_id
+	LEFT JOIN categories c ON c.id = t.category_id
+	LEFT JOIN budget_transactions bt ON bt.transaction_id = t.id`
+
+// ErrInvalidCursor indicates a pagination cursor that is malformed or was issued for another sort.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// TransactionPage is one page of a transaction search. NextCursor/PrevCursor are empty when there
+// is nothing further in that direction.
+type TransactionPage struct {
+	Transactions []models.TransactionAPI
+	TotalCount   int
+	NextCursor   string
+	PrevCursor   string
+}
+
+// transactionCursor is the keyset position encoded (base64 JSON) in next_cursor/prev_cursor: the
+// sort key of the last (or, with Prev, first) row of the page it came from.
+type transactionCursor struct {
+	Sort      string `json:"s"`
+	Amount    int64  `json:"a,omitempty"`
+	Date      string `json:"d"`
+	CreatedAt string `json:"c"`
+	ID        string `json:"i"`
+	Prev      bool   `json:"p,omitempty"`
+}
+
+func (c transactionCursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeTransactionCursor(s, sort string) (*transactionCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c transactionCursor
+	if err := json.Unmarshal(b, &c); err != nil || c.ID == "" || c.Date == "" {
+		return nil, ErrInvalidCursor
+	}
+	if c.Sort != sort {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// keyset returns the row-value comparison selecting rows after c in its direction of travel.
+func (c *transactionCursor) keyset() (string, []any) {
+	ascending := c.Sort == models.TransactionSortDateAsc || c.Sort == models.TransactionSortAmountAsc
+	op := "<"
+	if ascending != c.Prev {
+		op = ">"
+	}
+	if c.Sort == models.TransactionSortAmountDesc || c.Sort == models.TransactionSortAmountAsc {
+		return "(ABS(t.amount), t.transaction_date, t.created_at, t.id) " + op + " (?, ?, ?, ?)",
+			[]any{c.Amount, c.Date, c.CreatedAt, c.ID}
+	}
+	return "(t.transaction_date, t.created_at, t.id) " + op + " (?, ?, ?)",
+		[]any{c.Date, c.CreatedAt, c.ID}
+}
+
+// Search returns one page of the user's transactions matching f, plus the number of matching rows.
+// Pages continue from f.Cursor when set; otherwise f.Offset (deprecated) skips rows.
+func (r *TransactionRepository) Search(userID uuid.UUID, f models.TransactionFilter) (*TransactionPage, error) {
+	page, err := r.list(userID, f)
+	if err != nil {
+		return nil, err
+	}
+	where, args := transactionFilterWhere(userID, f)
+	if err := r.db.QueryRow(`SELECT COUNT(*)`+transactionListFrom+where, args...).Scan(&page.TotalCount); err != nil {
+		return nil, fmt.Errorf("count transactions: %w", err)
+	}
+	return page, nil
+}
+
+func (r *TransactionRepository) list(userID uuid.UUID, f models.TransactionFilter) (*TransactionPage, error) {
+	if f.Limit <= 0 || f.Limit > 500 {
+		f.Limit = 100
+	}
+	if f.Offset < 0 {
+		f.Offset = 0
+	}
+	if f.Sort == "" {
+		f.Sort = models.TransactionSortDateDesc
+	}
+	var cur *transactionCursor
+	if f.Cursor != "" {
+		var err error
+		if cur, err = decodeTransactionCursor(f.Cursor, f.Sort); err != nil {
+			return nil, err
+		}
+		f.Offset = 0
+	}
+	backward := cur != nil && cur.Prev
+
+	where, args := transactionFilterWhere(userID, f)
+	if cur != nil {
+		cond, cargs := cur.keyset()
+		where += "\n\t\t\tAND " + cond
+		args = append(args, cargs...)
+	}
+	q := `
+		SELECT
+			t.id,
+			t.transaction_date,
+			t.description,
+			t.amount,
+			a.name,
+			COALESCE(c.name, CASE WHEN t.transaction_type = 'transfer' THEN 'Transfer' ELSE '—' END) AS category_name,
+			t.account_id,
+			COALESCE(t.category_id, ''),
+			t.transaction_type,
+			COALESCE(t.location_name, ''),
+			COALESCE(bt.budget_id, ''),
+			COALESCE(bt.item, ''),
+			COALESCE(bt.store, ''),
+			t.cleared,
+			t.reconciliation_id IS NOT NULL,
+			a.currency,
+			t.original_amount,
+			COALESCE(t.original_currency, ''),
+			(SELECT COALESCE(GROUP_CONCAT(tag, ','), '') FROM transaction_tags WHERE transaction_id = t.id),
+			t.created_at` + transactionListFrom + where + `
+		ORDER BY ` + transactionOrderBy(f.Sort, backward) + `
+		LIMIT ? OFFSET ?`
+	// One extra row tells whether another page follows.
+	args = append(args, f.Limit+1, f.Offset)
+
+	rows, err := r.db.Query(q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list transactions: %w", err)
+	}
+	defer rows.Close()
+
+	list := []models.TransactionAPI{}
+	var keys []transactionCursor
+	for rows.Next() {
+		var (
+			t                   models.TransactionAPI
+			cleared, reconciled int
+			createdAt, tags     string
+			originalAmount      sql.NullInt64
+		)
+		if err := rows.Scan(
+			&t.ID, &t.Date, &t.Description, &t.Amount, &t.Account, &t.Category,
+			&t.AccountID, &t.CategoryID, &t.TransactionType, &t.LocationName,
+			&t.BudgetID, &t.Item, &t.Store, &cleared, &reconciled,
+			&t.Currency, &originalAmount, &t.OriginalCurrency, &tags, &createdAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan transaction: %w", err)
+		}
+		if tags != "" {
+			t.Tags = strings.Split(tags, ",")
+		}
+		if originalAmount.Valid {
+			t.OriginalAmount = &originalAmount.Int64
+		}
+		t.Cleared = cleared == 1
+		t.Reconciled = reconciled == 1
+		if _, err := uuid.Parse(t.ID); err != nil {
+			continue
+		}
+		amount := t.Amount
+		if amount < 0 {
+			amount = -amount
+		}
+		list = append(list, t)
+		keys = append(keys, transactionCursor{Sort: f.Sort, Amount: amount, Date: t.Date, CreatedAt: createdAt, ID: t.ID})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	more := len(list) > f.Limit
+	if more {
+		list, keys = list[:f.Limit], keys[:f.Limit]
+	}
+	if backward {
+		for i, j := 0, len(list)-1; i < j; i, j = i+1, j-1 {
+			list[i], list[j] = list[j], list[i]
+			keys[i], keys[j] = keys[j], keys[i]
+		}
+	}
+
+	page := &TransactionPage{Transactions: list}
+	if len(keys) > 0 {
+		// Going forward there is a next page when the extra row came back; going backward we came
+		// from one. The previous page mirrors that.
+		if more || backward {
+			page.NextCursor = keys[len(keys)-1].encode()
+		}
+		if (backward && more) || (!backward && (cur != nil || f.Offset > 0)) {
+			first := keys[0]
+			first.Prev = true
+			page.PrevCursor = first.encode()
+		}
+	}
+	return page, nil
+}
+
+// transactionFilterWhere builds the WHERE clause for f and its arguments.
+func transactionFilterWhere(userID uuid.UUID, f models.TransactionFilter) (string, []any) {
+	conds := []string{"t.user_id = ?"}
+	args := []any{userID.String()}
+
+	if f.From != "" {
+		conds = append(conds, "t.transaction_date >= ?")
+		args = append(args, f.From)
+	}
+	if f.To != "" {
+		conds = append(conds, "t.transaction_date <= ?")
+		args = append(args, f.To)
+	}
+	if len(f.AccountIDs) > 0 {
+		conds = append(conds, "t.account_id IN ("+placeholders(len(f.AccountIDs))+")")
+		for _, id := range f.AccountIDs {
+			args = append(args, id.String())
+		}
+	}
+	if len(f.CategoryIDs) > 0 {
+		// A parent category matches its subcategories too.
+		conds = append(conds, "(t.category_id IN ("+placeholders(len(f.CategoryIDs))+
+			") OR c.parent_category_id IN ("+placeholders(len(f.CategoryIDs))+"))")
+		for _, id := range f.CategoryIDs {
+			args = append(args, id.String())
+		}
+		for _, id := range f.CategoryIDs {
+			args = append(args, id.String())
+		}
+	}
+	if len(f.Types) > 0 {
+		conds = append(conds, "t.transaction_type IN ("+placeholders(len(f.Types))+")")
+		for _, t := range f.Types {
+			args = append(args, t)
+		}
+	}
+	if f.MinAmount != nil {
+		conds = append(conds, "ABS(t.amount) >= ?")
+		args = append(args, *f.MinAmount)
+	}
+	if f.MaxAmount != nil {
+		conds = append(conds, "ABS(t.amount) <= ?")
+		args = append(args, *f.MaxAmount)
+	}
+	if f.BudgetID != nil {
+		conds = append(conds, "bt.budget_id = ?")
+		args = append(args, f.BudgetID.String())
+	}
+	if f.Store != "" {
+		like := "%" + escapeLike(f.Store) + "%"
+		conds = append(conds, `(bt.store LIKE ? ESCAPE '\' OR t.location_name LIKE ? ESCAPE '\')`)
+		args = append(args, like, like)
+	}
+	if f.Tag != "" {
+		conds = append(conds, "t.id IN (SELECT transaction_id FROM transaction_tags WHERE tag = ?)")
+		args = append(args, f.Tag)
+	}
+	if len(f.IDs) > 0 {
+		conds = append(conds, "t.id IN ("+placeholders(len(f.IDs))+")")
+		for _, id := range f.IDs {
+			args = append(args, id.String())
+		}
+	}
+	if q := ftsQuery(f.Query); q != "" {
+		conds = append(conds, `t.id IN (
+			SELECT s.transaction_id FROM transactions_fts
+			INNER JOIN transaction_search_ids s ON s.id = transactions_fts.rowid
+			WHERE transactions_fts MATCH ?)`)
+		args = append(args, q)
+	}
+	return "\n\t\tWHERE " + strings.Join(conds, "\n\t\t\tAND "), args
+}
+
+// transactionOrderBy orders by the sort key, ending in t.id so every row has a unique position for
+// keyset cursors. All columns share one direction; reverse flips it for walking a page backwards.
+func transactionOrderBy(sort string, reverse bool) string {
+	cols := "t.transaction_date, t.created_at, t.id"
+	if sort == models.TransactionSortAmountDesc || sort == models.TransactionSortAmountAsc {
+		cols = "ABS(t.amount), " + cols
+	}
+	desc := sort == models.TransactionSortDateDesc || sort == models.TransactionSortAmountDesc
+	dir := " ASC"
+	if desc != reverse {
+		dir = " DESC"
+	}
+	return strings.ReplaceAll(cols, ",", dir+",") + dir
+}
+
+// ftsQuery turns free text into an FTS5 query: every word must match, as a prefix.
+// Words are quoted so FTS5 operators and punctuation in user input are taken literally;
+// words without letters or digits are dropped since the tokenizer would ignore them anyway.
+func ftsQuery(text string) string {
+	var terms []string
+	for _, w := range strings.Fields(text) {
+		w = strings.ReplaceAll(w, `"`, "")
+		if strings.IndexFunc(w, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) < 0 {
+			continue
+		}
+		terms = append(terms, `"`+w+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
+
+func escapeLike(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s)
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
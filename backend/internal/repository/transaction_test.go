@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestTransactionUpdateDeleteMovesBudgetSpent(t *testing.T) {
+	conn := migratedTestDB(t)
+	txRepo := NewTransactionRepository(conn)
+	user := testUser(t, conn)
+	acc := testAccount(t, conn, user, "BCA")
+	food, transport := testCategory(t, conn, user, "Makan"), testCategory(t, conn, user, "Transport")
+	foodBudget := testBudget(t, conn, user, food, "Makan Oktober", 1000000, "2026-10-01", "2026-10-31")
+	transportBudget := testBudget(t, conn, user, transport, "Transport Oktober", 500000, "2026-10-01", "2026-10-31")
+
+	id := testExpense(t, txRepo, user, acc, food, 10000, "2026-10-05",
+		&BudgetLinkParams{BudgetID: foodBudget, UserID: user, Item: "Nasi"})
+	if got := budgetSpent(t, conn, foodBudget); got != 10000 {
+		t.Fatalf("food spent after create = %d, want 10000", got)
+	}
+
+	// Moving category, amount and budget line moves the spending with it.
+	err := txRepo.Update(id, user, acc, transport, -25000, "Ojek", "expense", "2026-10-06", nil, nil,
+		&BudgetLinkParams{BudgetID: transportBudget, UserID: user, Item: "Ojek"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := budgetSpent(t, conn, foodBudget); got != 0 {
+		t.Errorf("food spent after update = %d, want 0", got)
+	}
+	if got := budgetSpent(t, conn, transportBudget); got != 25000 {
+		t.Errorf("transport spent after update = %d, want 25000", got)
+	}
+	if got := testInt(t, conn, `SELECT COUNT(*) FROM budget_transactions WHERE transaction_id = ? AND budget_id = ?`,
+		id.String(), transportBudget.String()); got != 1 {
+		t.Errorf("budget line on the transport budget = %d, want 1", got)
+	}
+
+	// Out of the window and without a budget line.
+	if err := txRepo.Update(id, user, acc, transport, -25000, "Ojek", "expense", "2026-11-02", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := budgetSpent(t, conn, transportBudget); got != 0 {
+		t.Errorf("transport spent after moving out of the window = %d, want 0", got)
+	}
+	if got := testInt(t, conn, `SELECT COUNT(*) FROM budget_transactions WHERE transaction_id = ?`, id.String()); got != 0 {
+		t.Errorf("budget lines after unlinking = %d, want 0", got)
+	}
+	if got := accountBalance(t, conn, acc); got != -25000 {
+		t.Errorf("balance = %d, want -25000", got)
+	}
+
+	if err := txRepo.Delete(id, user); err != nil {
+		t.Fatal(err)
+	}
+	if got := accountBalance(t, conn, acc); got != 0 {
+		t.Errorf("balance after delete = %d, want 0", got)
+	}
+	if err := txRepo.Delete(id, user); !errors.Is(err, ErrTransactionNotFound) {
+		t.Errorf("second delete err = %v, want ErrTransactionNotFound", err)
+	}
+}
+
+func TestReconciledTransactionsAreLocked(t *testing.T) {
+	conn := migratedTestDB(t)
+	txRepo, reconRepo := NewTransactionRepository(conn), NewReconciliationRepository(conn)
+	user := testUser(t, conn)
+	acc := testAccount(t, conn, user, "BCA")
+	food := testCategory(t, conn, user, "Makan")
+
+	id := testExpense(t, txRepo, user, acc, food, 10000, "2026-10-05", nil)
+	recID, err := reconRepo.Create(user, acc, "2026-10-31", -10000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := reconRepo.SetCleared(acc, "2026-10-31", []uuid.UUID{id}, true); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := reconRepo.Get(user, recID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := reconRepo.Complete(user, rec, -10000, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	err = txRepo.Update(id, user, acc, food, -20000, "Belanja", "expense", "2026-10-05", nil, nil, nil)
+	if !errors.Is(err, ErrTransactionReconciled) {
+		t.Errorf("update err = %v, want ErrTransactionReconciled", err)
+	}
+	if err := txRepo.Delete(id, user); !errors.Is(err, ErrTransactionReconciled) {
+		t.Errorf("delete err = %v, want ErrTransactionReconciled", err)
+	}
+	if got := accountBalance(t, conn, acc); got != -10000 {
+		t.Errorf("balance = %d, want -10000", got)
+	}
+
+	// Undoing the reconciliation unlocks the row.
+	if err := reconRepo.Delete(user, recID); err != nil {
+		t.Fatal(err)
+	}
+	if err := txRepo.Delete(id, user); err != nil {
+		t.Errorf("delete after undo: %v", err)
+	}
+}
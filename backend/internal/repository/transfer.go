@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"monman-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrTransferNotFound indicates the transfer id does not exist for this user.
+	ErrTransferNotFound = errors.New("transfer not found")
+	// ErrTransferFeeTransaction indicates a change to a transfer's fee row outside the transfer itself.
+	ErrTransferFeeTransaction = errors.New("transaction is a transfer fee")
+)
+
+// TransferParams is one validated account-to-account transfer. Amounts are positive cents.
+type TransferParams struct {
+	UserID        uuid.UUID
+	FromAccountID uuid.UUID
+	ToAccountID   uuid.UUID
+	Amount        int64
+	ToAmount      int64 // credited to ToAccountID; differs from Amount only across currencies
+	Fee           int64
+	FeeCategoryID uuid.UUID // used only when Fee > 0
+	Description   string
+	Date          string
+	Notes         *string
+}
+
+// CreateTransfer inserts the debit/credit 'transfer' legs, an optional fee expense on the source account,
+// and the transfer_transactions row in one DB transaction. Triggers move both account balances.
+func (r *TransactionRepository) CreateTransfer(p TransferParams) (uuid.UUID, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	insert := `
+		INSERT INTO transactions (
+			id, user_id, account_id, category_id, amount, description,
+			transaction_type, transaction_date, notes,
+			created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'), datetime('now'))
+	`
+	var notes interface{}
+	if p.Notes != nil {
+		notes = *p.Notes
+	}
+
+	fromID, toID := uuid.New(), uuid.New()
+	if _, err := tx.Exec(insert,
+		fromID.String(), p.UserID.String(), p.FromAccountID.String(), nil, -p.Amount, p.Description,
+		"transfer", p.Date, notes,
+	); err != nil {
+		return uuid.Nil, fmt.Errorf("insert transfer debit: %w", err)
+	}
+	if _, err := tx.Exec(insert,
+		toID.String(), p.UserID.String(), p.ToAccountID.String(), nil, p.ToAmount, p.Description,
+		"transfer", p.Date, notes,
+	); err != nil {
+		return uuid.Nil, fmt.Errorf("insert transfer credit: %w", err)
+	}
+
+	legs := []any{fromID.String(), toID.String()}
+	var feeTx sql.NullString
+	if p.Fee > 0 {
+		feeID := uuid.New()
+		if _, err := tx.Exec(insert,
+			feeID.String(), p.UserID.String(), p.FromAccountID.String(), p.FeeCategoryID.String(), -p.Fee,
+			"Biaya transfer: "+p.Description, "expense", p.Date, nil,
+		); err != nil {
+			return uuid.Nil, fmt.Errorf("insert transfer fee: %w", err)
+		}
+		feeTx = sql.NullString{String: feeID.String(), Valid: true}
+		legs = append(legs, feeID.String())
+	}
+	if err := rebaseTx(tx, p.UserID, "t.id IN ("+placeholders(len(legs))+")", legs...); err != nil {
+		return uuid.Nil, err
+	}
+
+	id := uuid.New()
+	if _, err := tx.Exec(`
+		INSERT INTO transfer_transactions (
+			id, user_id, from_account_id, to_account_id,
+			from_transaction_id, to_transaction_id, fee_transaction_id,
+			amount, transfer_fee, notes, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'))
+	`,
+		id.String(), p.UserID.String(), p.FromAccountID.String(), p.ToAccountID.String(),
+		fromID.String(), toID.String(), feeTx,
+		p.Amount, p.Fee, notes,
+	); err != nil {
+		return uuid.Nil, fmt.Errorf("insert transfer_transactions: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return uuid.Nil, fmt.Errorf("commit: %w", err)
+	}
+	return id, nil
+}
+
+// ListTransfersForUser returns transfers with account names, newest first.
+func (r *TransactionRepository) ListTransfersForUser(userID uuid.UUID, limit, offset int) ([]models.TransferAPI, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	q := `
+		SELECT
+			tt.id, ft.transaction_date, ft.description,
+			tt.from_account_id, fa.name, tt.to_account_id, ta.name,
+			tt.amount, COALESCE(tt.transfer_fee, 0), tt.notes,
+			tt.from_transaction_id, tt.to_transaction_id, tt.fee_transaction_id
+		FROM transfer_transactions tt
+		INNER JOIN transactions ft ON ft.id = tt.from_transaction_id
+		INNER JOIN accounts fa ON fa.id = tt.from_account_id
+		INNER JOIN accounts ta ON ta.id = tt.to_account_id
+		WHERE tt.user_id = ?
+		ORDER BY ft.transaction_date DESC, tt.created_at DESC
+		LIMIT ? OFFSET ?
+	`
+	rows, err := r.db.Query(q, userID.String(), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("list transfers: %w", err)
+	}
+	defer rows.Close()
+
+	var out []models.TransferAPI
+	for rows.Next() {
+		var (
+			t            models.TransferAPI
+			notes, feeTx sql.NullString
+		)
+		if err := rows.Scan(
+			&t.ID, &t.Date, &t.Description,
+			&t.FromAccountID, &t.FromAccount, &t.ToAccountID, &t.ToAccount,
+			&t.AmountCents, &t.FeeCents, &notes,
+			&t.FromTransactionID, &t.ToTransactionID, &feeTx,
+		); err != nil {
+			return nil, fmt.Errorf("scan transfer: %w", err)
+		}
+		if notes.Valid {
+			t.Notes = notes.String
+		}
+		if feeTx.Valid {
+			t.FeeTransactionID = feeTx.String
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// TransferCounterAccounts maps each transfer leg on accountID to the name of the other account. The
+// account's opening balance row, also transfer-typed, maps to the account itself as QIF expects.
+func (r *TransactionRepository) TransferCounterAccounts(userID, accountID uuid.UUID) (map[string]string, error) {
+	rows, err := r.db.Query(`
+		SELECT tt.from_transaction_id, ta.name
+		FROM transfer_transactions tt
+		INNER JOIN accounts ta ON ta.id = tt.to_account_id
+		WHERE tt.user_id = ? AND tt.from_account_id = ?
+		UNION ALL
+		SELECT tt.to_transaction_id, fa.name
+		FROM transfer_transactions tt
+		INNER JOIN accounts fa ON fa.id = tt.from_account_id
+		WHERE tt.user_id = ? AND tt.to_account_id = ?
+		UNION ALL
+		SELECT opening_transaction_id, name
+		FROM accounts
+		WHERE user_id = ? AND id = ? AND opening_transaction_id IS NOT NULL
+	`, userID.String(), accountID.String(), userID.String(), accountID.String(), userID.String(), accountID.String())
+	if err != nil {
+		return nil, fmt.Errorf("transfer counter accounts: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[string]string)
+	for rows.Next() {
+		var txID, name string
+		if err := rows.Scan(&txID, &name); err != nil {
+			return nil, fmt.Errorf("scan transfer counter account: %w", err)
+		}
+		out[txID] = name
+	}
+	return out, rows.Err()
+}
+
+// IsTransferFee reports whether txID is the fee expense of a transfer.
+func (r *TransactionRepository) IsTransferFee(txID uuid.UUID) (bool, error) {
+	var n int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM transfer_transactions WHERE fee_transaction_id = ?`, txID.String()).Scan(&n); err != nil {
+		return false, fmt.Errorf("check transfer fee: %w", err)
+	}
+	return n > 0, nil
+}
+
+// DeleteTransfer removes both legs, the fee expense (if still present) and the transfer row together.
+// Delete triggers reverse the account balances and any budget spent from the fee.
+func (r *TransactionRepository) DeleteTransfer(id, userID uuid.UUID) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var fromTx, toTx string
+	var feeTx sql.NullString
+	err = tx.QueryRow(`
+		SELECT from_transaction_id, to_transaction_id, fee_transaction_id
+		FROM transfer_transactions WHERE id = ? AND user_id = ?
+	`, id.String(), userID.String()).Scan(&fromTx, &toTx, &feeTx)
+	if err == sql.ErrNoRows {
+		return ErrTransferNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("load transfer: %w", err)
+	}
+
+	ids := []string{fromTx, toTx}
+	if feeTx.Valid {
+		ids = append(ids, feeTx.String)
+	}
+	args := make([]any, len(ids))
+	for i, tid := range ids {
+		args[i] = tid
+	}
+	if err := ensureUnreconciled(tx, "id IN ("+placeholders(len(ids))+")", args...); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM transfer_transactions WHERE id = ?`, id.String()); err != nil {
+		return fmt.Errorf("delete transfer_transactions: %w", err)
+	}
+	for _, tid := range ids {
+		if _, err := tx.Exec(`DELETE FROM transactions WHERE id = ? AND user_id = ?`, tid, userID.String()); err != nil {
+			return fmt.Errorf("delete transfer leg: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
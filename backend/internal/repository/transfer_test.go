@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDeleteTransferReversesLegsAndFee(t *testing.T) {
+	conn := migratedTestDB(t)
+	txRepo := NewTransactionRepository(conn)
+	user := testUser(t, conn)
+	from, to := testAccount(t, conn, user, "BCA"), testAccount(t, conn, user, "GoPay")
+	feeBudget := testBudget(t, conn, user, feeCategoryID, "Biaya Admin", 100000, "2026-10-01", "2026-10-31")
+
+	id, err := txRepo.CreateTransfer(TransferParams{
+		UserID: user, FromAccountID: from, ToAccountID: to,
+		Amount: 100000, ToAmount: 100000, Fee: 2500, FeeCategoryID: feeCategoryID,
+		Description: "Top up", Date: "2026-10-10",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := accountBalance(t, conn, from); got != -102500 {
+		t.Errorf("from balance = %d, want -102500", got)
+	}
+	if got := accountBalance(t, conn, to); got != 100000 {
+		t.Errorf("to balance = %d, want 100000", got)
+	}
+	if got := budgetSpent(t, conn, feeBudget); got != 2500 {
+		t.Errorf("fee budget spent = %d, want 2500", got)
+	}
+
+	if err := txRepo.DeleteTransfer(id, user); err != nil {
+		t.Fatal(err)
+	}
+	if got := accountBalance(t, conn, from); got != 0 {
+		t.Errorf("from balance after delete = %d, want 0", got)
+	}
+	if got := accountBalance(t, conn, to); got != 0 {
+		t.Errorf("to balance after delete = %d, want 0", got)
+	}
+	if got := budgetSpent(t, conn, feeBudget); got != 0 {
+		t.Errorf("fee budget spent after delete = %d, want 0", got)
+	}
+	if got := testInt(t, conn, `SELECT COUNT(*) FROM transactions WHERE user_id = ?`, user.String()); got != 0 {
+		t.Errorf("transactions left = %d, want 0", got)
+	}
+	if err := txRepo.DeleteTransfer(id, user); !errors.Is(err, ErrTransferNotFound) {
+		t.Errorf("second delete err = %v, want ErrTransferNotFound", err)
+	}
+}
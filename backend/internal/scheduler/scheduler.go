@@ -0,0 +1,39 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Job is one periodic background task.
+type Job struct {
+	Name string
+	Run  func(now time.Time) error
+}
+
+// Start runs every job once right away and then on each interval tick until ctx is cancelled.
+// Jobs run sequentially in the order given; errors are logged and do not stop later jobs.
+func Start(ctx context.Context, interval time.Duration, jobs ...Job) {
+	go func() {
+		runAll(jobs)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				runAll(jobs)
+			}
+		}
+	}()
+}
+
+func runAll(jobs []Job) {
+	for _, j := range jobs {
+		if err := j.Run(time.Now()); err != nil {
+			log.Printf("job %s: %v", j.Name, err)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"monman-backend/internal/models"
+	"monman-backend/internal/repository"
+	"monman-backend/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+// UpdateAccount applies a partial edit to an owned account (active or archived) and returns it. A
+// changed opening_balance or opening_balance_date rewrites the opening balance row.
+func (s *FinanceService) UpdateAccount(userID, id uuid.UUID, req *models.UpdateAccountRequest, now time.Time) (*models.AccountSummary, error) {
+	a, err := s.getAccount(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		a.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.AccountType != nil {
+		a.AccountType = strings.ToLower(strings.TrimSpace(*req.AccountType))
+	}
+	if req.Color != nil {
+		a.Color = strings.TrimSpace(*req.Color)
+		if a.Color == "" {
+			return nil, validationError{"color cannot be empty"}
+		}
+	}
+	if req.BankName != nil {
+		a.BankName = trimmedOrNil(req.BankName)
+	}
+	if req.AccountNumber != nil {
+		a.AccountNumber = trimmedOrNil(req.AccountNumber)
+	}
+	switch {
+	case req.ClearCreditLimit:
+		a.CreditLimit = nil
+	case req.CreditLimit != nil:
+		a.CreditLimit = req.CreditLimit
+	case a.AccountType != "credit_card":
+		a.CreditLimit = nil // the limit only means something on a credit card
+	}
+	applyCardSettings(a, req.StatementClosingDay, req.PaymentDueDay, req.MinimumPaymentPercent, req.MinimumPaymentFloor)
+	if a.AccountType != "credit_card" && req.StatementClosingDay == nil && req.PaymentDueDay == nil {
+		a.StatementClosingDay, a.PaymentDueDay = nil, nil
+	}
+	if req.IsDefault != nil {
+		if *req.IsDefault && !a.IsActive {
+			return nil, validationError{"an archived account cannot be the default"}
+		}
+		a.IsDefault = *req.IsDefault
+	}
+	if err := validateAccount(a); err != nil {
+		return nil, err
+	}
+	if req.Currency != nil {
+		currency, err := s.accountCurrency(userID, *req.Currency, now)
+		if err != nil {
+			return nil, err
+		}
+		if currency != a.Currency {
+			used, err := s.accRepo.HasTransactions(id)
+			if err != nil {
+				return nil, err
+			}
+			if used {
+				return nil, validationError{"currency can only be changed while the account has no transactions"}
+			}
+			a.Currency = currency
+		}
+	}
+	if req.OpeningBalance != nil && !utils.ValidCurrencyAmount(*req.OpeningBalance, a.Currency) {
+		return nil, validationError{a.Currency + " has no minor unit; opening_balance must be a multiple of 100"}
+	}
+
+	var opening *repository.OpeningBalanceChange
+	if req.OpeningBalance != nil || req.OpeningBalanceDate != nil {
+		var openingDate string
+		if req.OpeningBalanceDate != nil {
+			if openingDate, err = parseOpeningDate(*req.OpeningBalanceDate); err != nil {
+				return nil, err
+			}
+		}
+		if opening, err = s.openingBalanceChange(userID, id, req.OpeningBalance, openingDate, now); err != nil {
+			return nil, err
+		}
+	}
+	// One DB transaction: a locked opening row must not leave the other edits half saved.
+	if err := s.accRepo.Update(a, opening); err != nil {
+		return nil, accountLookupError(err)
+	}
+	return s.accountSummary(userID, id)
+}
+
+// SetAccountActive archives (active=false) or restores an owned account. Archived accounts drop out of
+// GET /api/accounts and take no new transactions, but their history stays in lists and reports.
+func (s *FinanceService) SetAccountActive(userID, id uuid.UUID, active bool) (*models.AccountSummary, error) {
+	a, err := s.getAccount(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if a.IsActive == active {
+		return s.accountSummary(userID, id)
+	}
+	if !active {
+		n, err := s.accRepo.CountActive(userID)
+		if err != nil {
+			return nil, err
+		}
+		if n <= 1 {
+			return nil, validationError{"cannot archive the only active account"}
+		}
+		a.IsDefault = false
+	}
+	a.IsActive = active
+	if err := s.accRepo.Update(a, nil); err != nil {
+		return nil, accountLookupError(err)
+	}
+	return s.accountSummary(userID, id)
+}
+
+// ListArchivedAccounts returns the user's archived accounts.
+func (s *FinanceService) ListArchivedAccounts(userID uuid.UUID) ([]models.AccountSummary, error) {
+	list, err := s.accRepo.ListArchivedByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		list = []models.AccountSummary{}
+	}
+	return list, nil
+}
+
+// openingBalanceChange keeps whichever of amount/date is not given from the current opening row.
+func (s *FinanceService) openingBalanceChange(userID, id uuid.UUID, amount *int64, date string, now time.Time) (*repository.OpeningBalanceChange, error) {
+	value, curDate, err := s.accRepo.OpeningBalance(userID, id)
+	if err != nil {
+		return nil, accountLookupError(err)
+	}
+	if amount != nil {
+		value = *amount
+	}
+	if date == "" {
+		date = curDate
+	}
+	if date == "" {
+		date = now.Format(dateLayout)
+	}
+	return &repository.OpeningBalanceChange{Amount: value, Date: date}, nil
+}
+
+// accountCurrency validates the currency for an account; empty means the user's base currency. Any other
+// currency needs a rate into the base currency first, so the account can be counted in totals.
+func (s *FinanceService) accountCurrency(userID uuid.UUID, code string, now time.Time) (string, error) {
+	base, err := s.rateRepo.BaseCurrency(userID)
+	if err != nil {
+		return "", err
+	}
+	code = utils.NormalizeCurrency(code)
+	if code == "" || code == base {
+		return base, nil
+	}
+	if !utils.ValidCurrency(code) {
+		return "", validationError{"currency is not supported"}
+	}
+	if _, err := s.rateRepo.Rate(userID, code, base, now.Format(dateLayout)); err != nil {
+		return "", exchangeRateError(err)
+	}
+	return code, nil
+}
+
+func (s *FinanceService) getAccount(userID, id uuid.UUID) (*models.Account, error) {
+	a, err := s.accRepo.GetForUser(id, userID)
+	if err != nil {
+		return nil, accountLookupError(err)
+	}
+	return a, nil
+}
+
+func (s *FinanceService) accountSummary(userID, id uuid.UUID) (*models.AccountSummary, error) {
+	a, err := s.accRepo.GetSummary(userID, id)
+	if err != nil {
+		return nil, accountLookupError(err)
+	}
+	return a, nil
+}
+
+// validateAccount checks the fields shared by create and update.
+func validateAccount(a *models.Account) error {
+	if a.Name == "" {
+		return validationError{"name is required"}
+	}
+	if len(a.Name) > 120 {
+		return validationError{"name is too long"}
+	}
+	switch a.AccountType {
+	case "bank", "credit_card", "cash", "investment", "ewallet":
+	default:
+		return validationError{"account_type must be bank, credit_card, cash, investment, or ewallet"}
+	}
+	if len(a.Color) > 20 {
+		return validationError{"color must be at most 20 characters"}
+	}
+	if a.BankName != nil && len(*a.BankName) > 100 {
+		return validationError{"bank_name is too long"}
+	}
+	if a.AccountNumber != nil && len(*a.AccountNumber) > 50 {
+		return validationError{"account_number is too long"}
+	}
+	if a.CreditLimit != nil {
+		if a.AccountType != "credit_card" {
+			return validationError{"credit_limit is only for credit_card accounts"}
+		}
+		if *a.CreditLimit < 0 {
+			return validationError{"credit_limit cannot be negative"}
+		}
+	}
+	for _, day := range []*int{a.StatementClosingDay, a.PaymentDueDay} {
+		if day == nil {
+			continue
+		}
+		if a.AccountType != "credit_card" {
+			return validationError{"statement_closing_day and payment_due_day are only for credit_card accounts"}
+		}
+		if *day < 1 || *day > 31 {
+			return validationError{"statement_closing_day and payment_due_day must be between 1 and 31"}
+		}
+	}
+	if a.MinimumPaymentPercent < 0 || a.MinimumPaymentPercent > 100 {
+		return validationError{"minimum_payment_percent must be between 0 and 100"}
+	}
+	if a.MinimumPaymentFloor < 0 {
+		return validationError{"minimum_payment_floor cannot be negative"}
+	}
+	return nil
+}
+
+func parseOpeningDate(s string) (string, error) {
+	t, err := time.Parse(dateLayout, strings.TrimSpace(s))
+	if err != nil {
+		return "", validationError{"opening_balance_date must be YYYY-MM-DD"}
+	}
+	return t.Format(dateLayout), nil
+}
+
+// trimmedOrNil trims s; empty becomes nil so the column is cleared.
+func trimmedOrNil(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := strings.TrimSpace(*s)
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+func accountLookupError(err error) error {
+	if errors.Is(err, repository.ErrAccountNotFound) {
+		return validationError{"account not found"}
+	}
+	return reconciledError(err)
+}
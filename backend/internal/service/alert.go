@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"monman-backend/internal/models"
+	"monman-backend/internal/notify"
+	"monman-backend/internal/repository"
+	"monman-backend/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+// alertDeliveryBatch bounds how many pending alerts one delivery pass sends.
+const alertDeliveryBatch = 100
+
+// AlertService records budget threshold crossings and hands them to the configured notifiers.
+type AlertService struct {
+	alertRepo *repository.AlertRepository
+	budRepo   *repository.BudgetRepository
+	rateRepo  *repository.ExchangeRateRepository
+	notifiers []notify.Notifier
+}
+
+func NewAlertService(
+	alertRepo *repository.AlertRepository,
+	budRepo *repository.BudgetRepository,
+	rateRepo *repository.ExchangeRateRepository,
+	notifiers []notify.Notifier,
+) *AlertService {
+	return &AlertService{
+		alertRepo: alertRepo,
+		budRepo:   budRepo,
+		rateRepo:  rateRepo,
+		notifiers: notifiers,
+	}
+}
+
+// BudgetSpendChanged re-evaluates the user's budgets after a transaction write and delivers any new
+// alerts in the background. Failures are logged; they never fail the write that triggered them.
+// Safe to call on a nil *AlertService.
+func (s *AlertService) BudgetSpendChanged(userID uuid.UUID) {
+	if s == nil {
+		return
+	}
+	n, err := s.evaluate(userID)
+	if err != nil {
+		log.Printf("budget alerts for %s: %v", userID, err)
+		return
+	}
+	if n > 0 && len(s.notifiers) > 0 {
+		go func() {
+			if err := s.DeliverPending(); err != nil {
+				log.Printf("deliver budget alerts: %v", err)
+			}
+		}()
+	}
+}
+
+// EvaluateAll checks every active budget and delivers pending alerts. Run by the scheduler so that
+// writes outside FinanceService (recurring postings, rollovers) are covered too.
+func (s *AlertService) EvaluateAll(time.Time) (int, error) {
+	n, err := s.evaluate(uuid.Nil)
+	if err != nil {
+		return n, err
+	}
+	return n, s.DeliverPending()
+}
+
+// evaluate records an alert for each budget whose spending reached alert_percentage or 100% of the
+// available amount in its current period. uuid.Nil evaluates all users. Budgets are kept in the user's
+// base currency, which the messages use.
+func (s *AlertService) evaluate(userID uuid.UUID) (int, error) {
+	budgets, err := s.budRepo.ListActive(userID)
+	if err != nil {
+		return 0, err
+	}
+	bases := make(map[uuid.UUID]string)
+	created := 0
+	for i := range budgets {
+		b := &budgets[i]
+		thresholds := alertThresholds(b)
+		if len(thresholds) == 0 {
+			continue
+		}
+		base, ok := bases[b.UserID]
+		if !ok {
+			if base, err = s.rateRepo.BaseCurrency(b.UserID); err != nil {
+				return created, err
+			}
+			bases[b.UserID] = base
+		}
+		spent, available := utils.FormatMoney(b.SpentAmount, base), utils.FormatMoney(b.AvailableAmount(), base)
+		for _, threshold := range thresholds {
+			msg := fmt.Sprintf("%s has reached %d%% of its budget (%s of %s)", b.Name, threshold, spent, available)
+			if threshold >= 100 {
+				msg = fmt.Sprintf("%s is over budget: %s spent of %s", b.Name, spent, available)
+			}
+			_, ok, err := s.alertRepo.Record(*b, threshold, msg)
+			if err != nil {
+				return created, err
+			}
+			if ok {
+				created++
+			}
+		}
+	}
+	return created, nil
+}
+
+// alertThresholds lists the thresholds b has reached: its alert_percentage (via ShouldAlert) and 100.
+// An alert_percentage of 0 or >= 100 only uses the 100% threshold.
+func alertThresholds(b *models.Budget) []int {
+	var out []int
+	if b.SpentAmount <= 0 {
+		return out
+	}
+	if b.AlertPercentage > 0 && b.AlertPercentage < 100 && b.ShouldAlert() {
+		out = append(out, b.AlertPercentage)
+	}
+	if b.CalculateSpentPercentage() >= 100 {
+		out = append(out, 100)
+	}
+	return out
+}
+
+// DeliverPending sends every unclaimed alert through all notifiers. Each alert is claimed before
+// sending, so concurrent passes never deliver it twice; notifier errors are stored on the alert.
+func (s *AlertService) DeliverPending() error {
+	if len(s.notifiers) == 0 {
+		return nil
+	}
+	pending, err := s.alertRepo.ListUndelivered(alertDeliveryBatch)
+	if err != nil {
+		return err
+	}
+	for _, p := range pending {
+		ok, err := s.alertRepo.ClaimDelivery(p.ID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		a := notify.Alert{
+			ID:               p.ID,
+			UserID:           p.UserID,
+			Username:         p.Username,
+			Email:            p.Email,
+			BudgetID:         p.BudgetID,
+			BudgetName:       p.BudgetName,
+			ThresholdPercent: p.ThresholdPercent,
+			SpentAmount:      p.SpentAmount,
+			AvailableAmount:  p.AvailableAmount,
+			PeriodStartDate:  p.PeriodStartDate,
+			PeriodEndDate:    p.PeriodEndDate,
+			Message:          p.Message,
+			CreatedAt:        p.CreatedAt,
+		}
+		var failures []string
+		for _, n := range s.notifiers {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			if err := n.Notify(ctx, a); err != nil {
+				log.Printf("alert %s via %s: %v", p.ID, n.Name(), err)
+				failures = append(failures, n.Name()+": "+err.Error())
+			}
+			cancel()
+		}
+		if len(failures) > 0 {
+			if err := s.alertRepo.SetDeliveryError(p.ID, strings.Join(failures, "; ")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *AlertService) List(userID uuid.UUID, unreadOnly bool, limit, offset int) (*models.AlertsPayload, error) {
+	list, unread, err := s.alertRepo.ListForUser(userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AlertsPayload{Alerts: list, UnreadCount: unread}, nil
+}
+
+func (s *AlertService) SetRead(userID, id uuid.UUID, read bool) error {
+	err := s.alertRepo.SetRead(id, userID, read)
+	if errors.Is(err, repository.ErrAlertNotFound) {
+		return validationError{"alert not found"}
+	}
+	return err
+}
+
+func (s *AlertService) MarkAllRead(userID uuid.UUID) (int64, error) {
+	return s.alertRepo.MarkAllRead(userID)
+}
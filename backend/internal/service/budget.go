@@ -0,0 +1,177 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"monman-backend/internal/models"
+	"monman-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// UpdateBudget applies a partial edit to an owned budget (active or archived) and returns it.
+func (s *FinanceService) UpdateBudget(userID, id uuid.UUID, req *models.UpdateBudgetRequest) (*models.Budget, error) {
+	b, err := s.budRepo.GetForUser(id, userID)
+	if errors.Is(err, repository.ErrBudgetNotOwned) {
+		return nil, validationError{"budget not found"}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		b.Name = strings.TrimSpace(*req.Name)
+		if b.Name == "" {
+			return nil, validationError{"name is required"}
+		}
+		if len(b.Name) > 100 {
+			return nil, validationError{"name is too long"}
+		}
+	}
+	if req.CategoryID != nil && *req.CategoryID != b.CategoryID {
+		ctype, ok, err := s.catRepo.CategoryOwnedOrSystem(*req.CategoryID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, validationError{"category not found"}
+		}
+		if ctype != "expense" {
+			return nil, validationError{"budget category must be expense type"}
+		}
+		b.CategoryID = *req.CategoryID
+	}
+	if req.AllocatedAmount != nil {
+		if *req.AllocatedAmount <= 0 {
+			return nil, validationError{"allocated_amount must be positive"}
+		}
+		b.AllocatedAmount = *req.AllocatedAmount
+	}
+	if req.BudgetPeriod != nil {
+		switch *req.BudgetPeriod {
+		case models.BudgetPeriodWeekly, models.BudgetPeriodMonthly, models.BudgetPeriodYearly:
+		default:
+			return nil, validationError{"invalid budget_period"}
+		}
+		b.BudgetPeriod = *req.BudgetPeriod
+	}
+	if req.PeriodStartDate != nil {
+		t, err := time.Parse(dateLayout, strings.TrimSpace(*req.PeriodStartDate))
+		if err != nil {
+			return nil, validationError{"period_start_date must be YYYY-MM-DD"}
+		}
+		b.PeriodStartDate = t
+	}
+	if req.PeriodEndDate != nil {
+		t, err := time.Parse(dateLayout, strings.TrimSpace(*req.PeriodEndDate))
+		if err != nil {
+			return nil, validationError{"period_end_date must be YYYY-MM-DD"}
+		}
+		b.PeriodEndDate = t
+	}
+	if b.PeriodEndDate.Before(b.PeriodStartDate) {
+		return nil, validationError{"period_end_date cannot be before period_start_date"}
+	}
+	if req.ResponsiblePerson != nil {
+		b.ResponsiblePerson = nil
+		if rp := strings.TrimSpace(*req.ResponsiblePerson); rp != "" {
+			if len(rp) > 50 {
+				return nil, validationError{"responsible_person is too long"}
+			}
+			b.ResponsiblePerson = &rp
+		}
+	}
+	if req.AutoReset != nil {
+		b.AutoReset = *req.AutoReset
+	}
+	if req.CarryOver != nil {
+		mode := strings.ToLower(strings.TrimSpace(*req.CarryOver))
+		if !validCarryOver(mode) {
+			return nil, validationError{"carry_over must be none, unspent, overspent, or both"}
+		}
+		b.CarryOver = mode
+	}
+	if req.AlertPercentage != nil {
+		if *req.AlertPercentage < 0 || *req.AlertPercentage > 100 {
+			return nil, validationError{"alert_percentage must be between 0 and 100"}
+		}
+		b.AlertPercentage = *req.AlertPercentage
+	}
+	if req.IsActive != nil {
+		b.IsActive = *req.IsActive
+	}
+	if req.Icon != nil {
+		icon := strings.TrimSpace(*req.Icon)
+		if icon == "" || len(icon) > 10 {
+			return nil, validationError{"icon must be 1-10 characters"}
+		}
+		b.Icon = icon
+	}
+	if req.Color != nil {
+		color := strings.TrimSpace(*req.Color)
+		if color == "" || len(color) > 20 {
+			return nil, validationError{"color must be 1-20 characters"}
+		}
+		b.Color = color
+	}
+	if req.SortOrder != nil {
+		b.SortOrder = *req.SortOrder
+	}
+
+	return s.saveBudget(b)
+}
+
+// SetBudgetActive archives (active=false) or restores an owned budget. Archived budgets drop out of
+// GET /api/budgets and stop tracking spending; restoring recomputes spent_amount for the window.
+func (s *FinanceService) SetBudgetActive(userID, id uuid.UUID, active bool) (*models.Budget, error) {
+	b, err := s.budRepo.GetForUser(id, userID)
+	if errors.Is(err, repository.ErrBudgetNotOwned) {
+		return nil, validationError{"budget not found"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	b.IsActive = active
+	return s.saveBudget(b)
+}
+
+// ReorderBudgets sets sort_order from the position of each id in the list.
+func (s *FinanceService) ReorderBudgets(userID uuid.UUID, req *models.ReorderBudgetsRequest) error {
+	if len(req.BudgetIDs) == 0 {
+		return validationError{"budget_ids required"}
+	}
+	if len(req.BudgetIDs) > 200 {
+		return validationError{"at most 200 budget_ids allowed"}
+	}
+	seen := make(map[uuid.UUID]bool, len(req.BudgetIDs))
+	for _, id := range req.BudgetIDs {
+		if seen[id] {
+			return validationError{"budget_ids must not repeat"}
+		}
+		seen[id] = true
+	}
+	err := s.budRepo.Reorder(userID, req.BudgetIDs)
+	if errors.Is(err, repository.ErrBudgetNotOwned) {
+		return validationError{"budget not found"}
+	}
+	return err
+}
+
+func (s *FinanceService) saveBudget(b *models.Budget) (*models.Budget, error) {
+	err := s.budRepo.Update(b)
+	if errors.Is(err, repository.ErrBudgetNotOwned) {
+		return nil, validationError{"budget not found"}
+	}
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") || strings.Contains(err.Error(), "unique constraint") {
+			return nil, validationError{"a budget with this name already exists"}
+		}
+		return nil, err
+	}
+	if b.IsActive {
+		s.alerts.BudgetSpendChanged(b.UserID)
+	}
+	return s.budRepo.GetForUser(b.ID, b.UserID)
+}
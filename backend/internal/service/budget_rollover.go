@@ -0,0 +1,135 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"monman-backend/internal/models"
+	"monman-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// maxRolloverPeriods bounds how many missed windows one budget may close per run (10 years of weeks).
+const maxRolloverPeriods = 520
+
+func validCarryOver(mode string) bool {
+	switch mode {
+	case "none", "unspent", "overspent", "both":
+		return true
+	}
+	return false
+}
+
+// RolloverBudget closes every ended window of one budget up to today, regardless of auto_reset.
+func (s *FinanceService) RolloverBudget(userID, id uuid.UUID, now time.Time) (*models.BudgetRolloverPayload, error) {
+	w, err := s.budRepo.GetWindow(id, userID)
+	if errors.Is(err, repository.ErrBudgetNotOwned) {
+		return nil, validationError{"budget not found"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	today := now.Format(dateLayout)
+	if w.PeriodEndDate >= today {
+		return nil, validationError{fmt.Sprintf("current period runs until %s; nothing to roll over", w.PeriodEndDate)}
+	}
+	closed, err := s.rollBudget(*w, today)
+	if err != nil {
+		return nil, err
+	}
+	s.alerts.BudgetSpendChanged(userID)
+	b, err := s.budRepo.GetForUser(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.BudgetRolloverPayload{Budget: b, ClosedPeriods: closed}, nil
+}
+
+// RolloverDue advances every auto_reset budget whose period has ended, catching up missed windows.
+func (s *FinanceService) RolloverDue(now time.Time) (int, error) {
+	today := now.Format(dateLayout)
+	due, err := s.budRepo.ListRolloverDue(today)
+	if err != nil {
+		return 0, err
+	}
+	rolled := 0
+	var firstErr error
+	for _, w := range due {
+		closed, err := s.rollBudget(w, today)
+		rolled += len(closed)
+		if err != nil {
+			log.Printf("budget rollover %s: %v", w.ID, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return rolled, firstErr
+}
+
+func (s *FinanceService) rollBudget(w repository.BudgetWindow, today string) ([]models.BudgetPeriodHistoryAPI, error) {
+	closed := []models.BudgetPeriodHistoryAPI{}
+	carry := carryoverFunc(w.CarryOver)
+	for i := 0; i < maxRolloverPeriods && w.PeriodEndDate < today; i++ {
+		nextStart, nextEnd, err := nextBudgetWindow(w.BudgetPeriod, w.PeriodEndDate, w.AnchorDay)
+		if err != nil {
+			return closed, err
+		}
+		h, err := s.budRepo.ClosePeriod(w, nextStart, nextEnd, carry)
+		if err != nil {
+			return closed, err
+		}
+		if h == nil {
+			// Rolled concurrently; the next run picks up from the stored window.
+			break
+		}
+		closed = append(closed, *h)
+		w.PeriodStartDate, w.PeriodEndDate, w.CarryoverAmount = nextStart, nextEnd, h.CarryoverOut
+	}
+	return closed, nil
+}
+
+// nextBudgetWindow starts the day after end and spans one budget period. Monthly and yearly
+// windows end the day before the next anchorDay, clamped to short months, so a budget anchored on
+// the 31st runs Jan 31 – Feb 27, Feb 28 – Mar 30, Mar 31 – Apr 29.
+func nextBudgetWindow(period, end string, anchorDay int) (string, string, error) {
+	e, err := time.Parse(dateLayout, end)
+	if err != nil {
+		return "", "", fmt.Errorf("parse period_end_date: %w", err)
+	}
+	start := e.AddDate(0, 0, 1)
+	switch period {
+	case "weekly", "monthly", "yearly":
+	default:
+		return "", "", fmt.Errorf("unknown budget_period %q", period)
+	}
+	if anchorDay < 1 {
+		anchorDay = start.Day()
+	}
+	next := nextOccurrence(start, anchorDay, period, 1)
+	return start.Format(dateLayout), next.AddDate(0, 0, -1).Format(dateLayout), nil
+}
+
+// carryoverFunc returns how much of the closing window's remainder moves into the next one:
+// unspent carries only a surplus, overspent only a deficit (as a negative amount), both carries either.
+func carryoverFunc(mode string) func(available, spent int64) int64 {
+	return func(available, spent int64) int64 {
+		remaining := available - spent
+		switch mode {
+		case "unspent":
+			if remaining > 0 {
+				return remaining
+			}
+		case "overspent":
+			if remaining < 0 {
+				return remaining
+			}
+		case "both":
+			return remaining
+		}
+		return 0
+	}
+}
@@ -0,0 +1,572 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"monman-backend/internal/models"
+	"monman-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// maxRuleChangesListed bounds how many changed transactions a history re-apply lists; the counts cover all.
+const maxRuleChangesListed = 200
+
+// CategorizationService manages the user's auto-categorization rules, tests them against a sample and
+// re-applies them to past transactions. Matching on new and imported rows happens in FinanceService and
+// ImportService through ruleSet.
+type CategorizationService struct {
+	ruleRepo *repository.CategorizationRuleRepository
+	accRepo  *repository.AccountRepository
+	catRepo  *repository.CategoryRepository
+	budRepo  *repository.BudgetRepository
+	alerts   *AlertService
+}
+
+// NewCategorizationService wires the repositories; alerts may be nil to skip budget threshold checks.
+func NewCategorizationService(
+	ruleRepo *repository.CategorizationRuleRepository,
+	accRepo *repository.AccountRepository,
+	catRepo *repository.CategoryRepository,
+	budRepo *repository.BudgetRepository,
+	alerts *AlertService,
+) *CategorizationService {
+	return &CategorizationService{
+		ruleRepo: ruleRepo,
+		accRepo:  accRepo,
+		catRepo:  catRepo,
+		budRepo:  budRepo,
+		alerts:   alerts,
+	}
+}
+
+// List returns the user's rules in the order they are tried.
+func (s *CategorizationService) List(userID uuid.UUID) (*models.CategorizationRulesPayload, error) {
+	rules, err := s.ruleRepo.ListForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	out := &models.CategorizationRulesPayload{Rules: make([]models.CategorizationRuleAPI, 0, len(rules))}
+	for i := range rules {
+		out.Rules = append(out.Rules, categorizationRuleAPI(&rules[i]))
+	}
+	return out, nil
+}
+
+// Create stores a new rule; it is active unless is_active is false.
+func (s *CategorizationService) Create(userID uuid.UUID, req *models.CategorizationRuleRequest) (*models.CategorizationRuleAPI, error) {
+	rule := repository.CategorizationRule{UserID: userID, Priority: 100, MatchField: "description", MatchType: "contains", IsActive: true}
+	if err := s.applyRequest(userID, &rule, req); err != nil {
+		return nil, err
+	}
+	id, err := s.ruleRepo.Create(rule)
+	if err != nil {
+		return nil, err
+	}
+	return s.get(userID, id)
+}
+
+// Update applies a partial edit; the merged rule is validated like Create.
+func (s *CategorizationService) Update(userID, id uuid.UUID, req *models.CategorizationRuleRequest) (*models.CategorizationRuleAPI, error) {
+	rule, err := s.ruleRepo.GetForUser(id, userID)
+	if err != nil {
+		return nil, ruleLookupError(err)
+	}
+	if err := s.applyRequest(userID, rule, req); err != nil {
+		return nil, err
+	}
+	if err := s.ruleRepo.Update(*rule); err != nil {
+		return nil, ruleLookupError(err)
+	}
+	return s.get(userID, id)
+}
+
+// Delete removes a rule; transactions it already categorized are left as they are.
+func (s *CategorizationService) Delete(userID, id uuid.UUID) error {
+	return ruleLookupError(s.ruleRepo.Delete(id, userID))
+}
+
+func (s *CategorizationService) get(userID, id uuid.UUID) (*models.CategorizationRuleAPI, error) {
+	rule, err := s.ruleRepo.GetForUser(id, userID)
+	if err != nil {
+		return nil, ruleLookupError(err)
+	}
+	out := categorizationRuleAPI(rule)
+	return &out, nil
+}
+
+// applyRequest merges the fields set in req into rule and validates the result.
+func (s *CategorizationService) applyRequest(userID uuid.UUID, rule *repository.CategorizationRule, req *models.CategorizationRuleRequest) error {
+	if req.Name != nil {
+		rule.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Priority != nil {
+		rule.Priority = *req.Priority
+	}
+	if req.MatchField != nil {
+		rule.MatchField = strings.ToLower(strings.TrimSpace(*req.MatchField))
+	}
+	if req.MatchType != nil {
+		rule.MatchType = strings.ToLower(strings.TrimSpace(*req.MatchType))
+	}
+	if req.Pattern != nil {
+		rule.Pattern = strings.TrimSpace(*req.Pattern)
+	}
+	if req.MinAmount != nil {
+		rule.MinAmount = req.MinAmount
+	}
+	if req.MaxAmount != nil {
+		rule.MaxAmount = req.MaxAmount
+	}
+	if req.ClearAmounts {
+		rule.MinAmount, rule.MaxAmount = nil, nil
+	}
+	if req.AccountID != nil {
+		rule.AccountID = req.AccountID
+	}
+	if req.ClearAccount {
+		rule.AccountID = nil
+	}
+	if req.CategoryID != nil {
+		rule.CategoryID = *req.CategoryID
+	}
+	if req.BudgetID != nil {
+		rule.BudgetID = req.BudgetID
+	}
+	if req.ClearBudget {
+		rule.BudgetID, rule.Item = nil, nil
+	}
+	if req.Item != nil {
+		rule.Item = nil
+		if item := strings.TrimSpace(*req.Item); item != "" {
+			rule.Item = &item
+		}
+	}
+	if req.Tags != nil {
+		tags, err := normalizeTags(*req.Tags)
+		if err != nil {
+			return err
+		}
+		rule.Tags = tags
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if rule.Name == "" {
+		return validationError{"name is required"}
+	}
+	if len(rule.Name) > 100 {
+		return validationError{"name must be at most 100 characters"}
+	}
+	switch rule.MatchField {
+	case "description", "store", "location", "any":
+	default:
+		return validationError{"match_field must be description, store, location or any"}
+	}
+	switch rule.MatchType {
+	case "contains":
+	case "regex":
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return validationError{"pattern is not a valid regular expression: " + err.Error()}
+		}
+	default:
+		return validationError{"match_type must be contains or regex"}
+	}
+	if len(rule.Pattern) > 200 {
+		return validationError{"pattern must be at most 200 characters"}
+	}
+	if (rule.MinAmount != nil && *rule.MinAmount < 0) || (rule.MaxAmount != nil && *rule.MaxAmount < 0) {
+		return validationError{"min_amount and max_amount cannot be negative"}
+	}
+	if rule.MinAmount != nil && rule.MaxAmount != nil && *rule.MinAmount > *rule.MaxAmount {
+		return validationError{"min_amount must not be above max_amount"}
+	}
+	if rule.Pattern == "" && rule.MinAmount == nil && rule.MaxAmount == nil && rule.AccountID == nil {
+		return validationError{"a rule needs a pattern, an amount range or an account to match on"}
+	}
+	if rule.AccountID != nil {
+		ok, err := s.accRepo.AccountBelongs(*rule.AccountID, userID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return validationError{"account not found"}
+		}
+	}
+
+	// A budget implies its category; category_id may then be omitted.
+	if rule.BudgetID != nil {
+		bcat, ok, err := s.budRepo.BudgetBelongs(*rule.BudgetID, userID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return validationError{"budget not found"}
+		}
+		if req.CategoryID == nil && req.BudgetID != nil {
+			rule.CategoryID = bcat
+		}
+		if rule.CategoryID != bcat {
+			return validationError{"category_id must match the budget category"}
+		}
+	}
+	if rule.CategoryID == uuid.Nil {
+		return validationError{"category_id or budget_id is required"}
+	}
+	ctype, ok, err := s.catRepo.CategoryOwnedOrSystem(rule.CategoryID, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return validationError{"category not found"}
+	}
+	if rule.BudgetID != nil && ctype != "expense" {
+		return validationError{"budget_id can only be set for expense categories"}
+	}
+	if rule.Item != nil && rule.BudgetID == nil {
+		return validationError{"item requires budget_id"}
+	}
+	return nil
+}
+
+// Test returns the rule that would categorize the sample and every other active rule it matches, in the
+// order they are tried.
+func (s *CategorizationService) Test(userID uuid.UUID, req *models.CategorizationRuleTestRequest) (*models.CategorizationRuleTestPayload, error) {
+	txType := strings.ToLower(strings.TrimSpace(req.TransactionType))
+	if txType == "" {
+		txType = "expense"
+	}
+	if txType != "income" && txType != "expense" {
+		return nil, validationError{"transaction_type must be income or expense"}
+	}
+	if req.MagnitudeAmountCents < 0 {
+		return nil, validationError{"magnitude_amount_cents cannot be negative"}
+	}
+	sample := ruleSample{
+		Type:        txType,
+		Amount:      req.MagnitudeAmountCents,
+		Description: req.Description,
+		Store:       req.Store,
+		Location:    req.LocationName,
+	}
+	if req.AccountID != nil {
+		sample.AccountID = *req.AccountID
+	}
+	rules, err := loadRuleSet(s.ruleRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	out := &models.CategorizationRuleTestPayload{Matches: []models.CategorizationRuleAPI{}}
+	for _, rule := range rules.matchAll(sample) {
+		out.Matches = append(out.Matches, categorizationRuleAPI(rule))
+	}
+	if len(out.Matches) > 0 {
+		out.Rule = &out.Matches[0]
+	}
+	return out, nil
+}
+
+// Apply re-runs the active rules over past income and expense transactions in the request's range. By
+// default only rows in an uncategorized or import fallback category are changed; overwrite lets a rule
+// replace any category. Rows logged against a budget or posted by an investment trade are left alone. A
+// matching rule's budget link and tags are added too. With dry_run nothing is saved.
+func (s *CategorizationService) Apply(userID uuid.UUID, req *models.ApplyCategorizationRulesRequest) (*models.ApplyCategorizationRulesPayload, error) {
+	for _, d := range []struct{ name, value string }{{"from", req.From}, {"to", req.To}} {
+		if d.value == "" {
+			continue
+		}
+		if _, err := time.Parse(dateLayout, d.value); err != nil {
+			return nil, validationError{d.name + " must be YYYY-MM-DD"}
+		}
+	}
+	if req.From != "" && req.To != "" && req.From > req.To {
+		return nil, validationError{"from must not be after to"}
+	}
+	if req.AccountID != nil {
+		ok, err := s.accRepo.AccountBelongs(*req.AccountID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, validationError{"account not found"}
+		}
+	}
+	rules, err := loadRuleSet(s.ruleRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	var only []string
+	if !req.Overwrite {
+		only = []string{defaultImportExpenseCategoryID.String(), defaultImportIncomeCategoryID.String()}
+	}
+	candidates, err := s.ruleRepo.Candidates(userID, req.From, req.To, req.AccountID, only)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &models.ApplyCategorizationRulesPayload{
+		DryRun:  req.DryRun,
+		Scanned: len(candidates),
+		ByRule:  []models.CategorizationRuleCountAPI{},
+		Changes: []models.CategorizationChangeAPI{},
+	}
+	byRule := make(map[uuid.UUID]int) // index into out.ByRule
+	var changes []repository.RuleChange
+	for _, c := range candidates {
+		rule := rules.match(ruleSample{
+			AccountID:   c.AccountID,
+			Type:        c.Type,
+			Amount:      c.Amount,
+			Description: c.Description,
+			Location:    c.Location,
+		})
+		if rule == nil {
+			continue
+		}
+		change := repository.RuleChange{
+			TransactionID: c.ID,
+			Description:   c.Description,
+			Amount:        c.Amount,
+			FromCategory:  c.CategoryID,
+			CategoryID:    rule.CategoryID,
+			Tags:          missingTags(c.Tags, rule.Tags),
+		}
+		if rule.BudgetID != nil && c.Type == "expense" {
+			change.Budget = ruleBudgetLink(userID, rule, c.Amount)
+		}
+		if change.FromCategory == rule.CategoryID.String() && change.Budget == nil && len(change.Tags) == 0 {
+			continue
+		}
+		i, ok := byRule[rule.ID]
+		if !ok {
+			i = len(out.ByRule)
+			byRule[rule.ID] = i
+			out.ByRule = append(out.ByRule, models.CategorizationRuleCountAPI{RuleID: rule.ID.String(), Name: rule.Name})
+		}
+		out.ByRule[i].Count++
+		changes = append(changes, change)
+		if len(out.Changes) < maxRuleChangesListed {
+			out.Changes = append(out.Changes, models.CategorizationChangeAPI{
+				TransactionID: c.ID.String(),
+				Date:          c.Date,
+				Description:   c.Description,
+				FromCategory:  c.Category,
+				ToCategory:    rule.Category,
+				Budget:        rule.Budget,
+				Tags:          change.Tags,
+				RuleID:        rule.ID.String(),
+			})
+		}
+	}
+	out.Changed = len(changes)
+	if req.DryRun || len(changes) == 0 {
+		return out, nil
+	}
+	if err := s.ruleRepo.ApplyChanges(userID, changes); err != nil {
+		return nil, err
+	}
+	s.alerts.BudgetSpendChanged(userID)
+	return out, nil
+}
+
+func ruleLookupError(err error) error {
+	if errors.Is(err, repository.ErrCategorizationRuleNotFound) {
+		return validationError{"categorization rule not found"}
+	}
+	return err
+}
+
+func categorizationRuleAPI(rule *repository.CategorizationRule) models.CategorizationRuleAPI {
+	out := models.CategorizationRuleAPI{
+		ID:         rule.ID.String(),
+		Name:       rule.Name,
+		Priority:   rule.Priority,
+		MatchField: rule.MatchField,
+		MatchType:  rule.MatchType,
+		Pattern:    rule.Pattern,
+		MinAmount:  rule.MinAmount,
+		MaxAmount:  rule.MaxAmount,
+		Account:    rule.Account,
+		CategoryID: rule.CategoryID.String(),
+		Category:   rule.Category,
+		Budget:     rule.Budget,
+		Tags:       rule.Tags,
+		IsActive:   rule.IsActive,
+	}
+	if rule.AccountID != nil {
+		out.AccountID = rule.AccountID.String()
+	}
+	if rule.BudgetID != nil {
+		out.BudgetID = rule.BudgetID.String()
+	}
+	if rule.Item != nil {
+		out.Item = *rule.Item
+	}
+	if out.Tags == nil {
+		out.Tags = []string{}
+	}
+	return out
+}
+
+// normalizeTags lower-cases, trims and de-duplicates tags, dropping empty ones.
+func normalizeTags(in []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var out []string
+	for _, t := range in {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+		if strings.Contains(t, ",") {
+			return nil, validationError{"tags cannot contain commas"}
+		}
+		if len(t) > 50 {
+			return nil, validationError{"tags must be at most 50 characters"}
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	if len(out) > 10 {
+		return nil, validationError{"a rule can set at most 10 tags"}
+	}
+	return out, nil
+}
+
+// missingTags returns the tags in want that are not in have.
+func missingTags(have, want []string) []string {
+	var out []string
+	for _, t := range want {
+		if !slices.Contains(have, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// ruleBudgetLink is the budget line a matching rule adds to an expense of magnitude cents.
+func ruleBudgetLink(userID uuid.UUID, rule *repository.CategorizationRule, magnitude int64) *repository.BudgetLinkParams {
+	link := &repository.BudgetLinkParams{BudgetID: *rule.BudgetID, UserID: userID, UnitPrice: &magnitude}
+	if rule.Item != nil {
+		link.Item = *rule.Item
+	}
+	return link
+}
+
+// ruleSample is what rules are matched against: a new, imported or past transaction. Amount is a
+// magnitude in cents; AccountID is uuid.Nil when unknown.
+type ruleSample struct {
+	AccountID   uuid.UUID
+	Type        string // income | expense
+	Amount      int64
+	Description string
+	Store       string
+	Location    string
+}
+
+// ruleSet is a user's active rules in the order they are tried, with regex patterns compiled.
+type ruleSet []compiledRule
+
+type compiledRule struct {
+	rule    repository.CategorizationRule
+	pattern *regexp.Regexp // regex rules only
+}
+
+// loadRuleSet reads the user's active rules. A stored regex that no longer compiles is skipped.
+func loadRuleSet(ruleRepo *repository.CategorizationRuleRepository, userID uuid.UUID) (ruleSet, error) {
+	rules, err := ruleRepo.ListForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	var out ruleSet
+	for _, rule := range rules {
+		if !rule.IsActive {
+			continue
+		}
+		c := compiledRule{rule: rule}
+		if rule.MatchType == "regex" {
+			if c.pattern, err = compileRulePattern(rule.Pattern); err != nil {
+				log.Printf("categorization rule %s: %v", rule.ID, err)
+				continue
+			}
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// compileRulePattern compiles a regex rule pattern; matching ignores case like contains does.
+func compileRulePattern(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return nil, fmt.Errorf("compile pattern: %w", err)
+	}
+	return re, nil
+}
+
+// match returns the first rule matching the sample, or nil.
+func (rs ruleSet) match(sample ruleSample) *repository.CategorizationRule {
+	for i := range rs {
+		if rs[i].matches(sample) {
+			return &rs[i].rule
+		}
+	}
+	return nil
+}
+
+// matchAll returns every rule matching the sample, first match first.
+func (rs ruleSet) matchAll(sample ruleSample) []*repository.CategorizationRule {
+	var out []*repository.CategorizationRule
+	for i := range rs {
+		if rs[i].matches(sample) {
+			out = append(out, &rs[i].rule)
+		}
+	}
+	return out
+}
+
+func (c *compiledRule) matches(sample ruleSample) bool {
+	r := &c.rule
+	if r.CategoryType != sample.Type {
+		return false
+	}
+	if r.AccountID != nil && *r.AccountID != sample.AccountID {
+		return false
+	}
+	if (r.MinAmount != nil && sample.Amount < *r.MinAmount) || (r.MaxAmount != nil && sample.Amount > *r.MaxAmount) {
+		return false
+	}
+	if r.Pattern == "" {
+		return true
+	}
+	var fields []string
+	switch r.MatchField {
+	case "description":
+		fields = []string{sample.Description}
+	case "store":
+		fields = []string{sample.Store}
+	case "location":
+		fields = []string{sample.Location}
+	default:
+		fields = []string{sample.Description, sample.Store, sample.Location}
+	}
+	for _, f := range fields {
+		if f == "" {
+			continue
+		}
+		if c.pattern != nil {
+			if c.pattern.MatchString(f) {
+				return true
+			}
+		} else if strings.Contains(strings.ToLower(f), strings.ToLower(r.Pattern)) {
+			return true
+		}
+	}
+	return false
+}
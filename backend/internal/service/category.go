@@ -0,0 +1,448 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"monman-backend/internal/models"
+	"monman-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// CategoryTree returns the active categories visible to the user as top-level categories with their
+// subcategories, optionally only income or expense ones.
+func (s *FinanceService) CategoryTree(userID uuid.UUID, typeFilter string) ([]models.Category, error) {
+	all, err := s.catRepo.ListForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	children := make(map[uuid.UUID][]models.Category)
+	var top []models.Category
+	for _, c := range all {
+		if !c.IsActive || (typeFilter == "income" || typeFilter == "expense") && c.CategoryType != typeFilter {
+			continue
+		}
+		if c.ParentCategoryID != nil {
+			children[*c.ParentCategoryID] = append(children[*c.ParentCategoryID], c)
+			continue
+		}
+		top = append(top, c)
+	}
+	out := make([]models.Category, 0, len(top))
+	for _, c := range top {
+		c.SubCategories = children[c.ID]
+		delete(children, c.ID)
+		out = append(out, c)
+	}
+	// Subcategories of an archived or hidden parent stay reachable at the top level.
+	for _, c := range all {
+		if c.ParentCategoryID != nil && children[*c.ParentCategoryID] != nil {
+			out = append(out, children[*c.ParentCategoryID]...)
+			delete(children, *c.ParentCategoryID)
+		}
+	}
+	return out, nil
+}
+
+// ListArchivedCategories returns the user's archived categories and the system categories they hid.
+func (s *FinanceService) ListArchivedCategories(userID uuid.UUID) ([]models.CategorySummary, error) {
+	all, err := s.catRepo.ListForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	out := []models.CategorySummary{}
+	for _, c := range all {
+		if !c.IsActive {
+			out = append(out, categorySummary(&c))
+		}
+	}
+	return out, nil
+}
+
+func categorySummary(c *models.Category) models.CategorySummary {
+	out := models.CategorySummary{
+		ID:           c.ID.String(),
+		Name:         c.Name,
+		CategoryType: c.CategoryType,
+		Icon:         c.Icon,
+		IsSystem:     c.IsSystem,
+		Color:        c.Color,
+	}
+	if c.ParentCategoryID != nil {
+		out.ParentCategoryID = c.ParentCategoryID.String()
+	}
+	return out
+}
+
+// CreateCategory adds a user category, at the top level or under a parent whose type it takes.
+func (s *FinanceService) CreateCategory(userID uuid.UUID, req *models.CreateCategoryRequest) (*models.Category, error) {
+	c := &models.Category{
+		Name:             strings.TrimSpace(req.Name),
+		CategoryType:     strings.ToLower(strings.TrimSpace(req.CategoryType)),
+		ParentCategoryID: req.ParentCategoryID,
+		Icon:             strings.TrimSpace(req.Icon),
+		Color:            strings.TrimSpace(req.Color),
+	}
+	if c.Icon == "" {
+		c.Icon = "dollar-sign"
+	}
+	if c.Color == "" {
+		c.Color = "#6B7280"
+	}
+	if c.ParentCategoryID != nil {
+		parent, err := s.parentCategory(userID, *c.ParentCategoryID, uuid.Nil)
+		if err != nil {
+			return nil, err
+		}
+		if c.CategoryType != "" && c.CategoryType != parent.CategoryType {
+			return nil, validationError{"category_type must match the parent category"}
+		}
+		c.CategoryType = parent.CategoryType
+	}
+	if c.CategoryType != "income" && c.CategoryType != "expense" {
+		return nil, validationError{"category_type must be income or expense"}
+	}
+	if err := s.validateCategory(userID, c); err != nil {
+		return nil, err
+	}
+	id, err := s.catRepo.Create(userID, c)
+	if err != nil {
+		return nil, err
+	}
+	return s.catRepo.Get(userID, id)
+}
+
+// UpdateCategory renames, recolors or moves one of the user's own categories. System categories can
+// only be hidden.
+func (s *FinanceService) UpdateCategory(userID, id uuid.UUID, req *models.UpdateCategoryRequest) (*models.Category, error) {
+	c, err := s.ownCategory(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		c.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Icon != nil {
+		c.Icon = strings.TrimSpace(*req.Icon)
+	}
+	if req.Color != nil {
+		c.Color = strings.TrimSpace(*req.Color)
+		if c.Color == "" {
+			return nil, validationError{"color cannot be empty"}
+		}
+	}
+	switch {
+	case req.ClearParent && req.ParentCategoryID != nil:
+		return nil, validationError{"parent_category_id and clear_parent cannot both be set"}
+	case req.ClearParent:
+		c.ParentCategoryID = nil
+	case req.ParentCategoryID != nil:
+		parent, err := s.parentCategory(userID, *req.ParentCategoryID, id)
+		if err != nil {
+			return nil, err
+		}
+		if parent.CategoryType != c.CategoryType {
+			return nil, validationError{"category_type must match the parent category"}
+		}
+		children, err := s.catRepo.CountActiveChildren(userID, id)
+		if err != nil {
+			return nil, err
+		}
+		if children > 0 {
+			return nil, validationError{"a category with subcategories cannot become a subcategory"}
+		}
+		c.ParentCategoryID = req.ParentCategoryID
+	}
+	if err := s.validateCategory(userID, c); err != nil {
+		return nil, err
+	}
+	if err := s.catRepo.Update(userID, c); err != nil {
+		return nil, categoryLookupError(err)
+	}
+	return s.catRepo.Get(userID, id)
+}
+
+// SetCategoryActive archives (active=false) or restores one of the user's categories; for a system
+// category it hides it from, or shows it again in, the user's lists. Archived categories take no new
+// transactions, while hidden system categories can still be set explicitly (e.g. by import defaults).
+func (s *FinanceService) SetCategoryActive(userID, id uuid.UUID, active bool) (*models.Category, error) {
+	c, err := s.catRepo.Get(userID, id)
+	if err != nil {
+		return nil, categoryLookupError(err)
+	}
+	if !active {
+		children, err := s.catRepo.CountActiveChildren(userID, id)
+		if err != nil {
+			return nil, err
+		}
+		if children > 0 {
+			return nil, validationError{"archive or move its subcategories first"}
+		}
+	} else if c.ParentCategoryID != nil {
+		parent, err := s.catRepo.Get(userID, *c.ParentCategoryID)
+		if err != nil {
+			return nil, categoryLookupError(err)
+		}
+		if !parent.IsActive {
+			return nil, validationError{"restore the parent category first"}
+		}
+	}
+	if c.IsSystem {
+		err = s.catRepo.SetHidden(userID, id, !active)
+	} else {
+		err = s.catRepo.SetActive(userID, id, active)
+	}
+	if err != nil {
+		return nil, categoryLookupError(err)
+	}
+	return s.catRepo.Get(userID, id)
+}
+
+// DeleteCategory removes one of the user's categories that nothing refers to.
+func (s *FinanceService) DeleteCategory(userID, id uuid.UUID) error {
+	if _, err := s.ownCategory(userID, id); err != nil {
+		return err
+	}
+	err := s.catRepo.Delete(userID, id)
+	if errors.Is(err, repository.ErrCategoryInUse) {
+		return validationError{"category has transactions, budgets, recurring rules, income sources, categorization rules or subcategories; archive it instead"}
+	}
+	return categoryLookupError(err)
+}
+
+// MergeCategory moves everything filed under category id to the target category of the same type: the
+// user's transactions, budgets (with their linked transactions), recurring rules, income sources,
+// categorization rules and subcategories. Budget spent amounts are recomputed, then a user category is
+// deleted and a system one hidden. With dryRun nothing is saved and the payload reports what would change.
+func (s *FinanceService) MergeCategory(userID, id uuid.UUID, req *models.MergeCategoryRequest) (*models.CategoryMergePayload, error) {
+	if req.TargetCategoryID == uuid.Nil {
+		return nil, validationError{"target_category_id is required"}
+	}
+	if req.TargetCategoryID == id {
+		return nil, validationError{"a category cannot be merged into itself"}
+	}
+	source, err := s.catRepo.Get(userID, id)
+	if err != nil {
+		return nil, categoryLookupError(err)
+	}
+	target, err := s.catRepo.Get(userID, req.TargetCategoryID)
+	if errors.Is(err, repository.ErrCategoryNotFound) {
+		return nil, validationError{"target category not found"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !target.IsActive {
+		return nil, validationError{"target category is archived or hidden"}
+	}
+	if target.CategoryType != source.CategoryType {
+		return nil, validationError{"target category must have the same category_type"}
+	}
+	if target.ParentCategoryID != nil {
+		if *target.ParentCategoryID == id {
+			return nil, validationError{"a category cannot be merged into its own subcategory"}
+		}
+		hasChildren, err := s.catRepo.HasOwnChildren(userID, id)
+		if err != nil {
+			return nil, err
+		}
+		if hasChildren {
+			return nil, validationError{"categories nest only one level deep; merge into a top-level category"}
+		}
+	}
+	removeSource := !source.IsSystem && source.UserID != nil
+	res, err := s.catRepo.Merge(userID, id, target.ID, removeSource, req.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	out := &models.CategoryMergePayload{
+		Source:              categorySummary(source),
+		Target:              categorySummary(target),
+		DryRun:              req.DryRun,
+		SourceAction:        "hidden",
+		Transactions:        res.Transactions,
+		Budgets:             res.Budgets,
+		RecurringRules:      res.RecurringRules,
+		IncomeSources:       res.IncomeSources,
+		CategorizationRules: res.CategorizationRules,
+		Subcategories:       res.Subcategories,
+		BudgetChanges:       res.BudgetChanges,
+	}
+	if removeSource {
+		out.SourceAction = "deleted"
+	}
+	if out.BudgetChanges == nil {
+		out.BudgetChanges = []models.BudgetSpentChangeAPI{}
+	}
+	return out, nil
+}
+
+// CategoryReport totals income or expense transactions (default expense) by category between from and
+// to (YYYY-MM-DD, inclusive; default the current month up to today). Subcategories are listed under
+// their parent, whose total includes them.
+func (s *FinanceService) CategoryReport(userID uuid.UUID, ctype, from, to string, now time.Time) (*models.CategoryReportPayload, error) {
+	ctype = strings.ToLower(strings.TrimSpace(ctype))
+	if ctype == "" {
+		ctype = "expense"
+	}
+	if ctype != "income" && ctype != "expense" {
+		return nil, validationError{"type must be income or expense"}
+	}
+	end := startOfDay(now)
+	if to = strings.TrimSpace(to); to != "" {
+		t, err := time.Parse(dateLayout, to)
+		if err != nil {
+			return nil, validationError{"to must be YYYY-MM-DD"}
+		}
+		end = t
+	}
+	start := time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if from = strings.TrimSpace(from); from != "" {
+		f, err := time.Parse(dateLayout, from)
+		if err != nil {
+			return nil, validationError{"from must be YYYY-MM-DD"}
+		}
+		start = f
+	}
+	if start.After(end) {
+		return nil, validationError{"from must not be after to"}
+	}
+
+	base, err := s.rateRepo.BaseCurrency(userID)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := s.catRepo.Totals(userID, ctype, start.Format(dateLayout), end.Format(dateLayout))
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.catRepo.ListForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &models.CategoryReportPayload{
+		From:          start.Format(dateLayout),
+		To:            end.Format(dateLayout),
+		Type:          ctype,
+		Currency:      base,
+		Uncategorized: totals[""].Amount,
+		Categories:    []models.CategoryReportRowAPI{},
+	}
+	out.Total = out.Uncategorized
+	rows := make(map[uuid.UUID]*models.CategoryReportRowAPI)
+	parents := make(map[uuid.UUID]uuid.UUID)
+	for _, c := range categories {
+		t, ok := totals[c.ID.String()]
+		if c.ParentCategoryID == nil || ok {
+			rows[c.ID] = &models.CategoryReportRowAPI{
+				CategoryID: c.ID.String(),
+				Name:       c.Name,
+				Icon:       c.Icon,
+				Color:      c.Color,
+				Amount:     t.Amount,
+				Total:      t.Amount,
+				Count:      t.Count,
+			}
+		}
+		if c.ParentCategoryID != nil {
+			parents[c.ID] = *c.ParentCategoryID
+		}
+		out.Total += t.Amount
+	}
+	for id, parentID := range parents {
+		row, parent := rows[id], rows[parentID]
+		if row == nil || parent == nil {
+			continue
+		}
+		parent.Total += row.Total
+		parent.Count += row.Count
+		parent.Subcategories = append(parent.Subcategories, *row)
+		delete(rows, id)
+	}
+	for _, row := range rows {
+		if row.Total == 0 {
+			continue
+		}
+		sortCategoryRows(row.Subcategories)
+		out.Categories = append(out.Categories, *row)
+	}
+	sortCategoryRows(out.Categories)
+	return out, nil
+}
+
+func sortCategoryRows(rows []models.CategoryReportRowAPI) {
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Total != rows[j].Total {
+			return rows[i].Total > rows[j].Total
+		}
+		return rows[i].Name < rows[j].Name
+	})
+}
+
+// parentCategory loads a category that selfID (uuid.Nil for a new one) may be nested under: an active,
+// top-level category visible to the user.
+func (s *FinanceService) parentCategory(userID, parentID, selfID uuid.UUID) (*models.Category, error) {
+	if parentID == selfID {
+		return nil, validationError{"a category cannot be its own parent"}
+	}
+	parent, err := s.catRepo.Get(userID, parentID)
+	if errors.Is(err, repository.ErrCategoryNotFound) {
+		return nil, validationError{"parent category not found"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !parent.IsActive {
+		return nil, validationError{"parent category is archived or hidden"}
+	}
+	if parent.ParentCategoryID != nil {
+		return nil, validationError{"categories nest only one level deep; pick a top-level parent"}
+	}
+	return parent, nil
+}
+
+// ownCategory loads one of the user's own categories, refusing system ones.
+func (s *FinanceService) ownCategory(userID, id uuid.UUID) (*models.Category, error) {
+	c, err := s.catRepo.Get(userID, id)
+	if err != nil {
+		return nil, categoryLookupError(err)
+	}
+	if c.IsSystem || c.UserID == nil {
+		return nil, validationError{"system categories cannot be changed; archive hides them"}
+	}
+	return c, nil
+}
+
+func (s *FinanceService) validateCategory(userID uuid.UUID, c *models.Category) error {
+	if c.Name == "" {
+		return validationError{"name is required"}
+	}
+	if len(c.Name) > 100 {
+		return validationError{"name must be at most 100 characters"}
+	}
+	if len(c.Icon) > 50 {
+		return validationError{"icon must be at most 50 characters"}
+	}
+	if len(c.Color) > 20 {
+		return validationError{"color must be at most 20 characters"}
+	}
+	taken, err := s.catRepo.NameTaken(userID, c.Name, c.CategoryType, c.ParentCategoryID, c.ID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return validationError{"a category with this name already exists here"}
+	}
+	return nil
+}
+
+func categoryLookupError(err error) error {
+	if errors.Is(err, repository.ErrCategoryNotFound) {
+		return validationError{"category not found"}
+	}
+	return err
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"monman-backend/internal/models"
+	"monman-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// ListCommonPurchases returns the pembelian umum presets of an owned active budget, in sort_order or,
+// with byUsage, most-logged first.
+func (s *FinanceService) ListCommonPurchases(userID, budgetID uuid.UUID, byUsage bool) (*models.CommonPurchasesPayload, error) {
+	list, err := s.budRepo.ListCommonPurchases(budgetID, userID, byUsage)
+	if err != nil {
+		return nil, commonPurchaseError(err)
+	}
+	return &models.CommonPurchasesPayload{Purchases: list}, nil
+}
+
+// UpdateCommonPurchase applies a partial edit to one preset and returns it.
+func (s *FinanceService) UpdateCommonPurchase(userID, budgetID, id uuid.UUID, req *models.UpdateCommonPurchaseRequest) (*models.BudgetCommonPurchaseAPI, error) {
+	cp, err := s.budRepo.GetCommonPurchase(budgetID, userID, id)
+	if err != nil {
+		return nil, commonPurchaseError(err)
+	}
+	if req.Item != nil {
+		cp.Item = strings.TrimSpace(*req.Item)
+		if cp.Item == "" {
+			return nil, validationError{"item is required"}
+		}
+	}
+	if req.Quantity != nil {
+		cp.Quantity = strings.TrimSpace(*req.Quantity)
+	}
+	if req.EstimatedAmount != nil {
+		if *req.EstimatedAmount <= 0 {
+			return nil, validationError{"estimated_amount must be positive"}
+		}
+		cp.EstimatedAmount = *req.EstimatedAmount
+	}
+	if req.Store != nil {
+		cp.Store = strings.TrimSpace(*req.Store)
+	}
+	if req.IsFrequentlyUsed != nil {
+		cp.IsFrequentlyUsed = *req.IsFrequentlyUsed
+	}
+	if req.SortOrder != nil {
+		cp.SortOrder = *req.SortOrder
+	}
+	if err := s.budRepo.UpdateCommonPurchase(budgetID, userID, cp); err != nil {
+		return nil, commonPurchaseError(err)
+	}
+	return cp, nil
+}
+
+func (s *FinanceService) DeleteCommonPurchase(userID, budgetID, id uuid.UUID) error {
+	return commonPurchaseError(s.budRepo.DeleteCommonPurchase(budgetID, userID, id))
+}
+
+// ReplaceCommonPurchases swaps the budget's whole preset list for req.Purchases (empty clears it).
+// Presets matching an existing item+store keep their id.
+func (s *FinanceService) ReplaceCommonPurchases(userID, budgetID uuid.UUID, req *models.AppendCommonPurchasesRequest) (*models.CommonPurchasesPayload, error) {
+	if req == nil {
+		return nil, validationError{"purchases required"}
+	}
+	for _, cp := range req.Purchases {
+		if strings.TrimSpace(cp.Item) == "" {
+			return nil, validationError{"each purchase needs item"}
+		}
+		if cp.EstimatedAmount <= 0 {
+			return nil, validationError{"estimated_amount must be positive"}
+		}
+	}
+	if err := s.budRepo.ReplaceCommonPurchases(budgetID, userID, req.Purchases); err != nil {
+		return nil, commonPurchaseError(err)
+	}
+	return s.ListCommonPurchases(userID, budgetID, false)
+}
+
+// ReorderCommonPurchases sets sort_order from the position of each preset id in the list.
+func (s *FinanceService) ReorderCommonPurchases(userID, budgetID uuid.UUID, req *models.ReorderCommonPurchasesRequest) error {
+	if len(req.PurchaseIDs) == 0 {
+		return validationError{"purchase_ids required"}
+	}
+	seen := make(map[uuid.UUID]bool, len(req.PurchaseIDs))
+	for _, id := range req.PurchaseIDs {
+		if seen[id] {
+			return validationError{"purchase_ids must not repeat"}
+		}
+		seen[id] = true
+	}
+	return commonPurchaseError(s.budRepo.ReorderCommonPurchases(budgetID, userID, req.PurchaseIDs))
+}
+
+// LogCommonPurchase records buying a preset as an expense on its budget through CreateTransaction.
+// The preset supplies item, quantity, store and amount; req may override amount, account, date and
+// description. The expense is linked to the preset so it counts towards use_count.
+func (s *FinanceService) LogCommonPurchase(userID, budgetID, id uuid.UUID, req *models.LogCommonPurchaseRequest, now time.Time) (*models.LogCommonPurchasePayload, error) {
+	cp, err := s.budRepo.GetCommonPurchase(budgetID, userID, id)
+	if err != nil {
+		return nil, commonPurchaseError(err)
+	}
+
+	accountID := req.AccountID
+	if accountID == nil {
+		accountID, err = s.budRepo.CommonPurchaseLastAccount(id, userID)
+		if err != nil {
+			return nil, err
+		}
+		if accountID == nil {
+			return nil, validationError{"account_id is required the first time a preset is logged"}
+		}
+	}
+	amount := cp.EstimatedAmount
+	if req.MagnitudeAmountCents != nil {
+		amount = *req.MagnitudeAmountCents
+	}
+	date := now.Format(dateLayout)
+	if req.TransactionDate != nil && strings.TrimSpace(*req.TransactionDate) != "" {
+		date = strings.TrimSpace(*req.TransactionDate)
+		if _, err := time.Parse(dateLayout, date); err != nil {
+			return nil, validationError{"transaction_date must be YYYY-MM-DD"}
+		}
+	}
+	description := cp.Item
+	if req.Description != nil && strings.TrimSpace(*req.Description) != "" {
+		description = strings.TrimSpace(*req.Description)
+	}
+
+	txReq := models.CreateTransactionRequest{
+		AccountID:            *accountID,
+		MagnitudeAmountCents: amount,
+		Description:          description,
+		TransactionType:      "expense",
+		TransactionDate:      date,
+		BudgetID:             &budgetID,
+		Item:                 &cp.Item,
+		CommonPurchaseID:     &id,
+		AllowDuplicate:       req.AllowDuplicate,
+	}
+	if cp.Quantity != "" {
+		txReq.Quantity = &cp.Quantity
+	}
+	if cp.Store != "" {
+		txReq.Store = &cp.Store
+	}
+	txID, err := s.CreateTransaction(userID, &txReq)
+	if err != nil {
+		return nil, err
+	}
+
+	cp, err = s.budRepo.GetCommonPurchase(budgetID, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return &models.LogCommonPurchasePayload{TransactionID: txID.String(), Purchase: *cp}, nil
+}
+
+// commonPurchaseError maps preset repository errors to validation errors; others pass through.
+func commonPurchaseError(err error) error {
+	var dup *repository.DuplicateCommonPurchaseError
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, repository.ErrBudgetNotOwned):
+		return validationError{"budget not found"}
+	case errors.Is(err, repository.ErrCommonPurchaseNotFound):
+		return validationError{"common purchase not found"}
+	case errors.Is(err, repository.ErrTooManyCommonPurchases):
+		return validationError{"a budget can have at most 80 common purchases"}
+	case errors.As(err, &dup):
+		return validationError{err.Error()}
+	}
+	return err
+}
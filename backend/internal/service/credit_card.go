@@ -0,0 +1,310 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"monman-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultMinimumPaymentPercent = 5
+	defaultMinimumPaymentFloor   = 5_000_000 // Rp 50.000
+	cardReminderDays             = 7         // dashboard shows statements due within this many days
+)
+
+// applyCardSettings copies the credit card fields given in an account create or update request; a day
+// of 0 unsets it.
+func applyCardSettings(a *models.Account, closingDay, dueDay, percent *int, floor *int64) {
+	if closingDay != nil {
+		a.StatementClosingDay = dayOrNil(*closingDay)
+	}
+	if dueDay != nil {
+		a.PaymentDueDay = dayOrNil(*dueDay)
+	}
+	if percent != nil {
+		a.MinimumPaymentPercent = *percent
+	}
+	if floor != nil {
+		a.MinimumPaymentFloor = *floor
+	}
+}
+
+func dayOrNil(day int) *int {
+	if day == 0 {
+		return nil
+	}
+	return &day
+}
+
+// ListCreditCards returns every active credit card with its latest closed statement as of now.
+func (s *FinanceService) ListCreditCards(userID uuid.UUID, now time.Time) (*models.CreditCardsPayload, error) {
+	cards, err := s.accRepo.ListCreditCards(userID)
+	if err != nil {
+		return nil, err
+	}
+	today := startOfDay(now)
+	out := &models.CreditCardsPayload{CreditCards: []models.CreditCardAPI{}}
+	for i := range cards {
+		c, err := s.creditCard(&cards[i], today, today)
+		if err != nil {
+			return nil, err
+		}
+		out.CreditCards = append(out.CreditCards, *c)
+		out.TotalOwed += c.CurrentOwed
+	}
+	return out, nil
+}
+
+// CreditCardStatement returns the statement closing on or before on (YYYY-MM-DD, default today) with
+// the card's transactions in that cycle.
+func (s *FinanceService) CreditCardStatement(userID, id uuid.UUID, on string, now time.Time) (*models.CreditCardStatementPayload, error) {
+	a, err := s.creditCardAccount(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	today := startOfDay(now)
+	asOf := today
+	if on = strings.TrimSpace(on); on != "" {
+		if asOf, err = time.Parse(dateLayout, on); err != nil {
+			return nil, validationError{"on must be YYYY-MM-DD"}
+		}
+	}
+	c, err := s.creditCard(a, asOf, today)
+	if err != nil {
+		return nil, err
+	}
+	if c.Statement == nil {
+		return nil, validationError{"set statement_closing_day and payment_due_day on the card first"}
+	}
+	page, err := s.txRepo.Search(userID, models.TransactionFilter{
+		AccountIDs: []uuid.UUID{id},
+		From:       c.Statement.PeriodStart,
+		To:         c.Statement.ClosingDate,
+		Sort:       models.TransactionSortDateAsc,
+		Limit:      500,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.CreditCardStatementPayload{CreditCard: *c, Transactions: page.Transactions}, nil
+}
+
+// PayCreditCard transfers a payment into the card from another of the user's accounts. The amount is in
+// the card's currency; a source account in another currency is debited the converted amount.
+func (s *FinanceService) PayCreditCard(userID, id uuid.UUID, req *models.PayCreditCardRequest, now time.Time) (*models.CreditCardPaymentPayload, error) {
+	a, err := s.creditCardAccount(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !a.IsActive {
+		return nil, validationError{"credit card is archived"}
+	}
+	from, err := s.accRepo.GetForUser(req.FromAccountID, userID)
+	if err != nil {
+		return nil, validationError{"from_account_id not found"}
+	}
+	if from.AccountType == "credit_card" {
+		return nil, validationError{"pay from a bank, e-wallet or cash account, not another credit card"}
+	}
+	today := startOfDay(now)
+	c, err := s.creditCard(a, today, today)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := req.AmountCents
+	if amount == 0 {
+		switch strings.ToLower(strings.TrimSpace(req.Pay)) {
+		case "", "statement":
+			if c.Statement == nil {
+				return nil, validationError{"card has no statement cycle; pay full or give amount_cents"}
+			}
+			amount = c.Statement.RemainingDue
+		case "minimum":
+			if c.Statement == nil {
+				return nil, validationError{"card has no statement cycle; pay full or give amount_cents"}
+			}
+			amount = c.Statement.MinimumRemaining
+		case "full":
+			amount = c.CurrentOwed
+		default:
+			return nil, validationError{"pay must be statement, minimum or full"}
+		}
+		if amount <= 0 {
+			return nil, validationError{"nothing to pay on this card"}
+		}
+	}
+	date := today.Format(dateLayout)
+	if req.TransactionDate != "" {
+		t, err := time.Parse(dateLayout, strings.TrimSpace(req.TransactionDate))
+		if err != nil {
+			return nil, validationError{"transaction_date must be YYYY-MM-DD"}
+		}
+		date = t.Format(dateLayout)
+	}
+
+	transfer := &models.CreateTransferRequest{
+		FromAccountID:        req.FromAccountID,
+		ToAccountID:          id,
+		MagnitudeAmountCents: amount,
+		TransferFeeCents:     req.TransferFeeCents,
+		Description:          "Pembayaran " + a.Name,
+		TransactionDate:      date,
+		Notes:                req.Notes,
+	}
+	if from.Currency != a.Currency {
+		paid := amount
+		if transfer.MagnitudeAmountCents, err = s.rateRepo.Convert(userID, amount, a.Currency, from.Currency, date); err != nil {
+			return nil, exchangeRateError(err)
+		}
+		transfer.ToAmountCents = &paid
+	}
+	transferID, err := s.CreateTransfer(userID, transfer)
+	if err != nil {
+		return nil, err
+	}
+	if a, err = s.accRepo.GetForUser(id, userID); err != nil {
+		return nil, err
+	}
+	if c, err = s.creditCard(a, today, today); err != nil {
+		return nil, err
+	}
+	return &models.CreditCardPaymentPayload{TransferID: transferID.String(), AmountPaid: amount, CreditCard: *c}, nil
+}
+
+// cardPaymentReminders lists the cards whose statement is unpaid and due within cardReminderDays (or
+// overdue), soonest first.
+func (s *FinanceService) cardPaymentReminders(userID uuid.UUID, now time.Time) ([]models.CardPaymentReminderAPI, error) {
+	cards, err := s.ListCreditCards(userID, now)
+	if err != nil {
+		return nil, err
+	}
+	out := []models.CardPaymentReminderAPI{}
+	for _, c := range cards.CreditCards {
+		st := c.Statement
+		if st == nil || st.RemainingDue <= 0 || st.DaysUntilDue > cardReminderDays {
+			continue
+		}
+		out = append(out, models.CardPaymentReminderAPI{
+			AccountID:        c.AccountID,
+			Name:             c.Name,
+			DueDate:          st.DueDate,
+			DaysUntilDue:     st.DaysUntilDue,
+			RemainingDue:     st.RemainingDue,
+			MinimumRemaining: st.MinimumRemaining,
+			Status:           st.Status,
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].DueDate < out[j].DueDate })
+	return out, nil
+}
+
+func (s *FinanceService) creditCardAccount(userID, id uuid.UUID) (*models.Account, error) {
+	a, err := s.getAccount(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if a.AccountType != "credit_card" {
+		return nil, validationError{"account is not a credit card"}
+	}
+	return a, nil
+}
+
+// creditCard describes a card with the statement closing on or before asOf; days until due and the
+// status are relative to today.
+func (s *FinanceService) creditCard(a *models.Account, asOf, today time.Time) (*models.CreditCardAPI, error) {
+	c := &models.CreditCardAPI{
+		AccountID:           a.ID.String(),
+		Name:                a.Name,
+		Balance:             a.Balance,
+		CurrentOwed:         owed(a.Balance),
+		CreditLimit:         a.CreditLimit,
+		StatementClosingDay: a.StatementClosingDay,
+		PaymentDueDay:       a.PaymentDueDay,
+	}
+	if a.BankName != nil {
+		c.BankName = *a.BankName
+	}
+	if a.CreditLimit != nil {
+		available := *a.CreditLimit + a.Balance
+		c.AvailableCredit = &available
+		if *a.CreditLimit > 0 {
+			pct := int(c.CurrentOwed * 100 / *a.CreditLimit)
+			c.UtilizationPercent = &pct
+		}
+	}
+	if a.StatementClosingDay == nil || a.PaymentDueDay == nil {
+		return c, nil
+	}
+
+	closing := statementClosing(asOf, *a.StatementClosingDay)
+	prev := statementClosing(closing.AddDate(0, 0, -1), *a.StatementClosingDay)
+	due := paymentDueDate(closing, *a.PaymentDueDay)
+	next := clampDay(closing.Year(), closing.Month()+1, *a.StatementClosingDay)
+	net, credits, err := s.accRepo.ActivityAfter(a.ID, closing.Format(dateLayout))
+	if err != nil {
+		return nil, fmt.Errorf("credit card activity: %w", err)
+	}
+
+	st := &models.CreditCardStatementAPI{
+		PeriodStart:      prev.AddDate(0, 0, 1).Format(dateLayout),
+		ClosingDate:      closing.Format(dateLayout),
+		DueDate:          due.Format(dateLayout),
+		NextClosingDate:  next.Format(dateLayout),
+		StatementBalance: owed(a.Balance - net),
+		PaidSinceClosing: credits,
+		DaysUntilDue:     int(due.Sub(today).Hours() / 24),
+	}
+	st.MinimumPayment = minimumPayment(st.StatementBalance, a.MinimumPaymentPercent, a.MinimumPaymentFloor)
+	st.RemainingDue = max(st.StatementBalance-credits, 0)
+	st.MinimumRemaining = max(st.MinimumPayment-credits, 0)
+	switch {
+	case st.RemainingDue == 0:
+		st.Status = "paid"
+	case today.After(due):
+		st.Status = "overdue"
+	default:
+		st.Status = "due"
+	}
+	c.Statement = st
+	return c, nil
+}
+
+// statementClosing is the latest closing date on or before on.
+func statementClosing(on time.Time, closingDay int) time.Time {
+	d := clampDay(on.Year(), on.Month(), closingDay)
+	if d.After(on) {
+		d = clampDay(on.Year(), on.Month()-1, closingDay)
+	}
+	return d
+}
+
+// paymentDueDate is the first dueDay after the closing date.
+func paymentDueDate(closing time.Time, dueDay int) time.Time {
+	d := clampDay(closing.Year(), closing.Month(), dueDay)
+	if !d.After(closing) {
+		d = clampDay(closing.Year(), closing.Month()+1, dueDay)
+	}
+	return d
+}
+
+// minimumPayment is percent of the balance but at least floor, and never more than the balance.
+func minimumPayment(balance int64, percent int, floor int64) int64 {
+	if balance <= 0 {
+		return 0
+	}
+	return min(max(balance*int64(percent)/100, floor), balance)
+}
+
+func owed(balance int64) int64 {
+	return max(-balance, 0)
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
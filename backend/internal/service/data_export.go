@@ -0,0 +1,133 @@
+package service
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"monman-backend/internal/exporter"
+	"monman-backend/internal/repository"
+	"monman-backend/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+// DataExportService assembles the full data export (GET /api/export).
+type DataExportService struct {
+	expRepo *repository.ExportRepository
+}
+
+func NewDataExportService(expRepo *repository.ExportRepository) *DataExportService {
+	return &DataExportService{expRepo: expRepo}
+}
+
+// exportTable names one exported entity: money columns are written twice, as <column>_cents and
+// formatted in <column>_formatted, and flag columns (0/1 in SQLite) as booleans. currency names the
+// column holding each row's currency; tables without one are in the base currency.
+type exportTable struct {
+	name     string
+	money    []string
+	flags    []string
+	currency string
+	load     func() (*repository.ExportRows, error)
+}
+
+// Export collects every entity for the user. from/to (YYYY-MM-DD, either may be empty) restrict
+// transactions and budget line items by date and budgets by overlapping period; accounts, categories
+// and common purchases are always exported whole since the dated rows refer to them.
+func (s *DataExportService) Export(userID uuid.UUID, from, to string, now time.Time) (*exporter.DataExport, error) {
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+	if err := validateExportRange(from, to); err != nil {
+		return nil, err
+	}
+
+	tables := []exportTable{
+		{"transactions", []string{"amount"}, []string{"cleared"}, "currency",
+			func() (*repository.ExportRows, error) { return s.expRepo.Transactions(userID, from, to) }},
+		{"accounts", []string{"balance", "credit_limit"}, []string{"is_default", "is_active"}, "currency",
+			func() (*repository.ExportRows, error) { return s.expRepo.Accounts(userID) }},
+		{"categories", nil, []string{"is_system", "is_active"}, "",
+			func() (*repository.ExportRows, error) { return s.expRepo.Categories(userID) }},
+		{"budgets", []string{"allocated_amount", "spent_amount", "carryover_amount"}, []string{"auto_reset", "is_active"}, "",
+			func() (*repository.ExportRows, error) { return s.expRepo.Budgets(userID, from, to) }},
+		{"common_purchases", []string{"estimated_amount"}, []string{"is_frequently_used"}, "",
+			func() (*repository.ExportRows, error) { return s.expRepo.CommonPurchases(userID) }},
+		{"budget_line_items", []string{"unit_price", "amount"}, nil, "currency",
+			func() (*repository.ExportRows, error) { return s.expRepo.BudgetLineItems(userID, from, to) }},
+	}
+	base, err := s.expRepo.BaseCurrency(userID)
+	if err != nil {
+		return nil, err
+	}
+	out := &exporter.DataExport{From: from, To: to, Currency: base, GeneratedAt: now}
+	for _, t := range tables {
+		rows, err := t.load()
+		if err != nil {
+			return nil, err
+		}
+		out.Tables = append(out.Tables, t.table(rows, base))
+	}
+	return out, nil
+}
+
+// validateExportRange checks optional YYYY-MM-DD bounds.
+func validateExportRange(from, to string) error {
+	for _, d := range []string{from, to} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.Parse(dateLayout, d); err != nil {
+			return validationError{"from and to must be YYYY-MM-DD"}
+		}
+	}
+	if from != "" && to != "" && from > to {
+		return validationError{"from must not be after to"}
+	}
+	return nil
+}
+
+func (t exportTable) table(rows *repository.ExportRows, base string) exporter.Table {
+	isMoney := make(map[int]bool)
+	isFlag := make(map[int]bool)
+	currencyCol := -1
+	tbl := exporter.Table{Name: t.name, Rows: make([][]any, 0, len(rows.Rows))}
+	for i, c := range rows.Columns {
+		if t.currency != "" && c == t.currency {
+			currencyCol = i
+		}
+		switch {
+		case slices.Contains(t.money, c):
+			isMoney[i] = true
+			tbl.Columns = append(tbl.Columns, c+"_cents", c+"_formatted")
+		case slices.Contains(t.flags, c):
+			isFlag[i] = true
+			tbl.Columns = append(tbl.Columns, c)
+		default:
+			tbl.Columns = append(tbl.Columns, c)
+		}
+	}
+	for _, src := range rows.Rows {
+		currency := base
+		if currencyCol >= 0 {
+			if code, ok := src[currencyCol].(string); ok && code != "" {
+				currency = code
+			}
+		}
+		row := make([]any, 0, len(tbl.Columns))
+		for i, v := range src {
+			cents, isInt := v.(int64)
+			switch {
+			case isMoney[i] && isInt:
+				row = append(row, cents, utils.FormatMoney(cents, currency))
+			case isMoney[i]:
+				row = append(row, nil, nil)
+			case isFlag[i] && isInt:
+				row = append(row, cents != 0)
+			default:
+				row = append(row, v)
+			}
+		}
+		tbl.Rows = append(tbl.Rows, row)
+	}
+	return tbl
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"monman-backend/internal/models"
+	"monman-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// maxDuplicatePairs bounds GET /api/transactions/duplicates.
+const maxDuplicatePairs = 200
+
+// DuplicateTransactionError is returned by CreateTransaction when the new row looks like one already
+// saved; the client can resend with allow_duplicate to save it anyway.
+type DuplicateTransactionError struct {
+	Matches []models.TransactionAPI
+}
+
+func (e *DuplicateTransactionError) Error() string {
+	return fmt.Sprintf("looks like a duplicate of %d existing transaction(s); set allow_duplicate to save anyway", len(e.Matches))
+}
+
+// textTokens lowercases s and splits it into letter/digit words.
+func textTokens(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// similarText reports whether two descriptions plausibly name the same purchase: equal after
+// normalizing, one containing the other, or sharing at least half of their words.
+func similarText(a, b string) bool {
+	ta, tb := textTokens(a), textTokens(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return false
+	}
+	na, nb := strings.Join(ta, " "), strings.Join(tb, " ")
+	if na == nb || strings.Contains(na, nb) || strings.Contains(nb, na) {
+		return true
+	}
+	seen := make(map[string]bool, len(ta))
+	for _, t := range ta {
+		seen[t] = true
+	}
+	union := len(seen)
+	shared := 0
+	for _, t := range tb {
+		if seen[t] {
+			shared++
+			seen[t] = false // count each word once
+		} else if _, ok := seen[t]; !ok {
+			union++
+		}
+	}
+	return shared*2 >= union
+}
+
+// sharesKeyword reports whether a and b have a word of four or more characters in common that is not
+// just digits; bank statement lines bury the merchant among codes ("TRSF ... INDOMARET").
+func sharesKeyword(a, b string) bool {
+	words := make(map[string]bool)
+	for _, t := range textTokens(a) {
+		if len([]rune(t)) >= 4 && strings.IndexFunc(t, unicode.IsLetter) >= 0 {
+			words[t] = true
+		}
+	}
+	for _, t := range textTokens(b) {
+		if words[t] {
+			return true
+		}
+	}
+	return false
+}
+
+// looksDuplicate compares the text of two rows that already share account, amount and (±1 day) date.
+func looksDuplicate(descA, storeA, descB, storeB string) bool {
+	if similarText(descA, descB) {
+		return true
+	}
+	return strings.TrimSpace(storeA) != "" && strings.EqualFold(strings.TrimSpace(storeA), strings.TrimSpace(storeB))
+}
+
+// transactionStore is the store of the budget line, else the location.
+func transactionStore(t models.TransactionAPI) string {
+	if t.Store != "" {
+		return t.Store
+	}
+	return t.LocationName
+}
+
+// dayAround returns the dates one day before and after date (YYYY-MM-DD).
+func dayAround(date string) (string, string, error) {
+	d, err := time.Parse(dateLayout, date)
+	if err != nil {
+		return "", "", err
+	}
+	return d.AddDate(0, 0, -1).Format(dateLayout), d.AddDate(0, 0, 1).Format(dateLayout), nil
+}
+
+// findDuplicates returns saved rows on the account with the same signed amount and type, dated within
+// a day of date, whose description or store resembles the new one.
+func (s *FinanceService) findDuplicates(userID, accountID uuid.UUID, txType string, magnitude int64, date, description, store string) ([]models.TransactionAPI, error) {
+	from, to, err := dayAround(date)
+	if err != nil {
+		return nil, validationError{"transaction_date must be YYYY-MM-DD"}
+	}
+	page, err := s.txRepo.Search(userID, models.TransactionFilter{
+		From:       from,
+		To:         to,
+		AccountIDs: []uuid.UUID{accountID},
+		Types:      []string{txType},
+		MinAmount:  &magnitude,
+		MaxAmount:  &magnitude,
+		Limit:      50,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var out []models.TransactionAPI
+	for _, t := range page.Transactions {
+		if looksDuplicate(description, store, t.Description, transactionStore(t)) {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+// DuplicatePairs lists suspected duplicate pairs among saved transactions, newest first.
+func (s *FinanceService) DuplicatePairs(userID uuid.UUID, from, to string) (*models.DuplicatePairsPayload, error) {
+	for _, d := range []string{from, to} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.Parse(dateLayout, d); err != nil {
+			return nil, validationError{"from and to must be YYYY-MM-DD"}
+		}
+	}
+	candidates, err := s.txRepo.DuplicateCandidatePairs(userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		pairs [][2]uuid.UUID
+		ids   []uuid.UUID
+	)
+	for _, c := range candidates {
+		if len(pairs) == maxDuplicatePairs {
+			break
+		}
+		if !looksDuplicate(c[0].Description, c[0].Store, c[1].Description, c[1].Store) {
+			continue
+		}
+		pairs = append(pairs, [2]uuid.UUID{c[0].ID, c[1].ID})
+		ids = append(ids, c[0].ID, c[1].ID)
+	}
+
+	payload := &models.DuplicatePairsPayload{Pairs: []models.DuplicatePairAPI{}}
+	if len(pairs) == 0 {
+		return payload, nil
+	}
+	byID := make(map[string]models.TransactionAPI, len(ids))
+	for start := 0; start < len(ids); start += 400 {
+		page, err := s.txRepo.Search(userID, models.TransactionFilter{IDs: ids[start:min(start+400, len(ids))], Limit: 500})
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range page.Transactions {
+			byID[t.ID] = t
+		}
+	}
+	for _, p := range pairs {
+		a, okA := byID[p[0].String()]
+		b, okB := byID[p[1].String()]
+		if okA && okB {
+			payload.Pairs = append(payload.Pairs, models.DuplicatePairAPI{First: a, Second: b})
+		}
+	}
+	return payload, nil
+}
+
+// MergeDuplicates keeps one of two rows describing the same money movement and deletes the other.
+func (s *FinanceService) MergeDuplicates(userID uuid.UUID, req *models.MergeDuplicateTransactionsRequest) error {
+	if req.KeepID == req.RemoveID {
+		return validationError{"keep_id and remove_id must differ"}
+	}
+	keep, _, err := s.txRepo.GetForUser(req.KeepID, userID)
+	if err != nil {
+		return duplicateLookupError(err)
+	}
+	remove, _, err := s.txRepo.GetForUser(req.RemoveID, userID)
+	if err != nil {
+		return duplicateLookupError(err)
+	}
+	if keep.TransactionType == "transfer" || remove.TransactionType == "transfer" {
+		return validationError{"transfer legs cannot be merged; delete the transfer instead"}
+	}
+	if keep.AccountID != remove.AccountID || keep.Amount != remove.Amount || keep.TransactionType != remove.TransactionType {
+		return validationError{"only transactions with the same account, type and amount can be merged"}
+	}
+	if err := s.ensureNotTrade(req.RemoveID); err != nil {
+		return err
+	}
+	if err := s.ensureNotTransferFee(req.RemoveID); err != nil {
+		return err
+	}
+	if err := s.txRepo.MergeDuplicate(userID, req.KeepID, req.RemoveID); err != nil {
+		return duplicateLookupError(err)
+	}
+	s.alerts.BudgetSpendChanged(userID)
+	return nil
+}
+
+// DismissDuplicates marks two transactions as separate so they are no longer suggested as a pair.
+func (s *FinanceService) DismissDuplicates(userID uuid.UUID, req *models.DismissDuplicateTransactionsRequest) error {
+	if len(req.TransactionIDs) != 2 || req.TransactionIDs[0] == req.TransactionIDs[1] {
+		return validationError{"transaction_ids must hold two different ids"}
+	}
+	return duplicateLookupError(s.txRepo.DismissDuplicatePair(userID, req.TransactionIDs[0], req.TransactionIDs[1]))
+}
+
+func duplicateLookupError(err error) error {
+	if errors.Is(err, repository.ErrTransactionNotFound) {
+		return validationError{"transaction not found"}
+	}
+	if errors.Is(err, repository.ErrTradeTransaction) {
+		return validationError{tradeRowMessage}
+	}
+	if errors.Is(err, repository.ErrTransferFeeTransaction) {
+		return validationError{transferFeeRowMessage}
+	}
+	return reconciledError(err)
+}
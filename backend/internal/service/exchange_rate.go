@@ -0,0 +1,156 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"monman-backend/internal/importer"
+	"monman-backend/internal/models"
+	"monman-backend/internal/repository"
+	"monman-backend/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+// ExchangeRateService manages the user's base currency and the rates used to convert into it.
+type ExchangeRateService struct {
+	rateRepo *repository.ExchangeRateRepository
+}
+
+func NewExchangeRateService(rateRepo *repository.ExchangeRateRepository) *ExchangeRateService {
+	return &ExchangeRateService{rateRepo: rateRepo}
+}
+
+// List returns the base currency, the supported currencies and the stored rates, optionally only those
+// involving currency.
+func (s *ExchangeRateService) List(userID uuid.UUID, currency string) (*models.ExchangeRatesPayload, error) {
+	code := utils.NormalizeCurrency(currency)
+	if code != "" && !utils.ValidCurrency(code) {
+		return nil, validationError{"currency is not supported"}
+	}
+	base, err := s.rateRepo.BaseCurrency(userID)
+	if err != nil {
+		return nil, err
+	}
+	rates, err := s.rateRepo.List(userID, code)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ExchangeRatesPayload{BaseCurrency: base, Currencies: utils.Currencies(), Rates: rates}, nil
+}
+
+// Create stores one manually entered rate, replacing any for the same pair and date, and returns the
+// rates involving its currency.
+func (s *ExchangeRateService) Create(userID uuid.UUID, req *models.CreateExchangeRateRequest, now time.Time) (*models.ExchangeRatesPayload, error) {
+	base, err := s.rateRepo.BaseCurrency(userID)
+	if err != nil {
+		return nil, err
+	}
+	row, err := exchangeRateRow(req.Currency, req.QuoteCurrency, req.Rate, req.RateDate, base, now)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.rateRepo.Save(userID, []repository.ExchangeRateRow{row}, "manual"); err != nil {
+		return nil, exchangeRateError(err)
+	}
+	return s.List(userID, row.Currency)
+}
+
+// Import stores every valid line of a rates CSV (see importer.ParseRates) in one go; lines with errors
+// are reported and skipped.
+func (s *ExchangeRateService) Import(userID uuid.UUID, r io.Reader, now time.Time) (*models.ExchangeRateImportPayload, error) {
+	parsed, err := importer.ParseRates(r)
+	if err != nil {
+		return nil, validationError{err.Error()}
+	}
+	base, err := s.rateRepo.BaseCurrency(userID)
+	if err != nil {
+		return nil, err
+	}
+	out := &models.ExchangeRateImportPayload{Errors: []models.ExchangeRateImportRowAPI{}}
+	var rows []repository.ExchangeRateRow
+	for _, p := range parsed {
+		if p.Error != "" {
+			out.Errors = append(out.Errors, models.ExchangeRateImportRowAPI{Line: p.Line, Error: p.Error})
+			continue
+		}
+		row, err := exchangeRateRow(p.Currency, p.QuoteCurrency, p.Rate, p.Date, base, now)
+		if err != nil {
+			out.Errors = append(out.Errors, models.ExchangeRateImportRowAPI{Line: p.Line, Error: err.Error()})
+			continue
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) > 0 {
+		n, err := s.rateRepo.Save(userID, rows, "import")
+		if err != nil {
+			return nil, exchangeRateError(err)
+		}
+		out.Imported = n
+	}
+	return out, nil
+}
+
+// Delete removes one of the user's rates.
+func (s *ExchangeRateService) Delete(userID, id uuid.UUID) error {
+	err := s.rateRepo.Delete(userID, id)
+	if errors.Is(err, repository.ErrExchangeRateNotFound) {
+		return validationError{"exchange rate not found"}
+	}
+	return exchangeRateError(err)
+}
+
+// SetBaseCurrency switches the currency reports are in and re-converts the user's transactions.
+func (s *ExchangeRateService) SetBaseCurrency(userID uuid.UUID, req *models.SetBaseCurrencyRequest) (*models.ExchangeRatesPayload, error) {
+	code := utils.NormalizeCurrency(req.BaseCurrency)
+	if !utils.ValidCurrency(code) {
+		return nil, validationError{"base_currency is not supported"}
+	}
+	if err := s.rateRepo.SetBaseCurrency(userID, code); err != nil {
+		return nil, exchangeRateError(err)
+	}
+	return s.List(userID, "")
+}
+
+// exchangeRateRow validates one rate; the quote currency defaults to base and the date to today.
+func exchangeRateRow(currency, quote string, rate float64, date, base string, now time.Time) (repository.ExchangeRateRow, error) {
+	row := repository.ExchangeRateRow{
+		Currency:      utils.NormalizeCurrency(currency),
+		QuoteCurrency: utils.NormalizeCurrency(quote),
+		Rate:          rate,
+		Date:          strings.TrimSpace(date),
+	}
+	if row.QuoteCurrency == "" {
+		row.QuoteCurrency = base
+	}
+	if row.Date == "" {
+		row.Date = now.Format(dateLayout)
+	}
+	if !utils.ValidCurrency(row.Currency) {
+		return row, validationError{"currency is not supported"}
+	}
+	if !utils.ValidCurrency(row.QuoteCurrency) {
+		return row, validationError{"quote_currency is not supported"}
+	}
+	if row.Currency == row.QuoteCurrency {
+		return row, validationError{"currency and quote_currency must differ"}
+	}
+	if !(rate > 0) || rate > 1e12 {
+		return row, validationError{"rate must be a positive number"}
+	}
+	if _, err := time.Parse(dateLayout, row.Date); err != nil {
+		return row, validationError{"rate_date must be YYYY-MM-DD"}
+	}
+	return row, nil
+}
+
+// exchangeRateError turns a missing rate into a 400 that says where to add one.
+func exchangeRateError(err error) error {
+	if errors.Is(err, repository.ErrNoExchangeRate) {
+		return validationError{fmt.Sprintf("%s; add one under /api/exchange-rates", err)}
+	}
+	return err
+}
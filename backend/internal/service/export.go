@@ -0,0 +1,112 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"monman-backend/internal/exporter"
+	"monman-backend/internal/models"
+	"monman-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// maxStatementTransactions bounds one account export.
+const maxStatementTransactions = 50000
+
+// AccountStatement collects one account's transactions between from and to (YYYY-MM-DD, inclusive,
+// either may be empty) for the OFX/QIF exporters.
+func (s *FinanceService) AccountStatement(userID, accountID uuid.UUID, from, to string, now time.Time) (*exporter.Statement, error) {
+	acc, err := s.accRepo.GetForUser(accountID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrAccountNotFound) {
+			return nil, validationError{err.Error()}
+		}
+		return nil, err
+	}
+	f := models.TransactionFilter{
+		From:       strings.TrimSpace(from),
+		To:         strings.TrimSpace(to),
+		AccountIDs: []uuid.UUID{accountID},
+		Sort:       models.TransactionSortDateAsc,
+		Limit:      500,
+	}
+	if err := validateTransactionFilter(&f); err != nil {
+		return nil, err
+	}
+
+	st := &exporter.Statement{
+		AccountID:    acc.ID.String(),
+		AccountName:  acc.Name,
+		AccountType:  acc.AccountType,
+		Currency:     acc.Currency,
+		From:         f.From,
+		To:           f.To,
+		Balance:      acc.Balance,
+		GeneratedAt:  now,
+		Transactions: []exporter.Transaction{},
+	}
+	if acc.BankName != nil {
+		st.BankName = *acc.BankName
+	}
+	if acc.AccountNumber != nil {
+		st.AccountNumber = *acc.AccountNumber
+	}
+	parents, err := s.catRepo.ParentCategoryNames(userID)
+	if err != nil {
+		return nil, err
+	}
+	counterparts, err := s.txRepo.TransferCounterAccounts(userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		page, err := s.txRepo.Search(userID, f)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range page.Transactions {
+			st.Transactions = append(st.Transactions, statementTransaction(t, parents, counterparts))
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		if len(st.Transactions) >= maxStatementTransactions {
+			return nil, validationError{"too many transactions; narrow the date range"}
+		}
+		f.Cursor = page.NextCursor
+	}
+	return st, nil
+}
+
+// statementTransaction converts a transaction row; parents maps subcategory ids to their parent's
+// name and counterparts maps transfer legs to the other account's name.
+func statementTransaction(t models.TransactionAPI, parents, counterparts map[string]string) exporter.Transaction {
+	var memo []string
+	if t.Item != "" && t.Item != t.Description {
+		memo = append(memo, t.Item)
+	}
+	if t.Store != "" {
+		memo = append(memo, t.Store)
+	}
+	if t.LocationName != "" && t.LocationName != t.Store {
+		memo = append(memo, t.LocationName)
+	}
+	category := t.Category
+	if t.CategoryID == "" {
+		category = ""
+	}
+	return exporter.Transaction{
+		ID:              t.ID,
+		Date:            t.Date,
+		Type:            t.TransactionType,
+		Description:     t.Description,
+		Memo:            strings.Join(memo, ", "),
+		Category:        category,
+		ParentCategory:  parents[t.CategoryID],
+		TransferAccount: counterparts[t.ID],
+		Amount:          t.Amount,
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"monman-backend/internal/models"
 	"monman-backend/internal/repository"
+	"monman-backend/internal/utils"
 
 	"github.com/google/uuid"
 )
@@ -23,34 +25,48 @@ func IsValidation(err error) bool {
 
 // FinanceService aggregates dashboard, lists, account/category/budget payloads, and creates.
 type FinanceService struct {
-	txRepo  *repository.TransactionRepository
-	accRepo *repository.AccountRepository
-	catRepo *repository.CategoryRepository
-	budRepo *repository.BudgetRepository
+	txRepo   *repository.TransactionRepository
+	accRepo  *repository.AccountRepository
+	catRepo  *repository.CategoryRepository
+	budRepo  *repository.BudgetRepository
+	rateRepo *repository.ExchangeRateRepository
+	ruleRepo *repository.CategorizationRuleRepository
+	alerts   *AlertService
 }
 
+// NewFinanceService wires the repositories; alerts may be nil to skip budget threshold checks.
 func NewFinanceService(
 	txRepo *repository.TransactionRepository,
 	accRepo *repository.AccountRepository,
 	catRepo *repository.CategoryRepository,
 	budRepo *repository.BudgetRepository,
+	rateRepo *repository.ExchangeRateRepository,
+	ruleRepo *repository.CategorizationRuleRepository,
+	alerts *AlertService,
 ) *FinanceService {
 	return &FinanceService{
-		txRepo:  txRepo,
-		accRepo: accRepo,
-		catRepo: catRepo,
-		budRepo: budRepo,
+		txRepo:   txRepo,
+		accRepo:  accRepo,
+		catRepo:  catRepo,
+		budRepo:  budRepo,
+		rateRepo: rateRepo,
+		ruleRepo: ruleRepo,
+		alerts:   alerts,
 	}
 }
 
-// Dashboard returns balance, monthly net, and last N transactions.
-func (s *FinanceService) Dashboard(userID uuid.UUID, recentLimit int) (*models.DashboardPayload, error) {
+// Dashboard returns balance, monthly net, last N transactions and credit card payments coming due.
+func (s *FinanceService) Dashboard(userID uuid.UUID, recentLimit int, now time.Time) (*models.DashboardPayload, error) {
 	if recentLimit <= 0 || recentLimit > 50 {
 		recentLimit = 8
 	}
-	total, err := s.txRepo.SumAccountBalance(userID)
+	base, err := s.rateRepo.BaseCurrency(userID)
 	if err != nil {
-		return nil, fmt.Errorf("dashboard balance: %w", err)
+		return nil, err
+	}
+	total, err := s.txRepo.SumAccountBalance(userID, now.Format(dateLayout))
+	if err != nil {
+		return nil, exchangeRateError(fmt.Errorf("dashboard balance: %w", err))
 	}
 	monthlyNet, err := s.txRepo.SumAmountForCalendarMonth(userID)
 	if err != nil {
@@ -60,16 +76,30 @@ func (s *FinanceService) Dashboard(userID uuid.UUID, recentLimit int) (*models.D
 	if err != nil {
 		return nil, fmt.Errorf("dashboard recent: %w", err)
 	}
+	reminders, err := s.cardPaymentReminders(userID, now)
+	if err != nil {
+		return nil, fmt.Errorf("dashboard card reminders: %w", err)
+	}
 	return &models.DashboardPayload{
 		TotalBalanceCents:  total,
 		MonthlyNetCents:    monthlyNet,
 		RecentTransactions: recent,
+		BaseCurrency:       base,
+
+		CardPaymentReminders: reminders,
 	}, nil
 }
 
-// ListTransactions returns transactions with current-month income/expense totals.
-func (s *FinanceService) ListTransactions(userID uuid.UUID, limit, offset int) (*models.TransactionListPayload, error) {
-	list, err := s.txRepo.ListForUser(userID, limit, offset)
+// ListTransactions returns one page of transactions matching f, the total match count, and
+// current-month income/expense totals.
+func (s *FinanceService) ListTransactions(userID uuid.UUID, f models.TransactionFilter) (*models.TransactionListPayload, error) {
+	if err := validateTransactionFilter(&f); err != nil {
+		return nil, err
+	}
+	page, err := s.txRepo.Search(userID, f)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return nil, validationError{"invalid cursor for this sort"}
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -78,7 +108,12 @@ func (s *FinanceService) ListTransactions(userID uuid.UUID, limit, offset int) (
 		return nil, err
 	}
 	return &models.TransactionListPayload{
-		Transactions:      list,
+		Transactions:      page.Transactions,
+		TotalCount:        page.TotalCount,
+		Limit:             f.Limit,
+		Offset:            f.Offset,
+		NextCursor:        page.NextCursor,
+		PrevCursor:        page.PrevCursor,
 		MonthIncomeCents:  inc,
 		MonthExpenseCents: exp,
 	}, nil
@@ -105,33 +140,71 @@ func (s *FinanceService) ListCategories(userID uuid.UUID, typeFilter string) ([]
 	return s.catRepo.ListActiveForUser(userID, typeFilter)
 }
 
-// CreateAccount inserts an additional wallet/account row for the user.
-func (s *FinanceService) CreateAccount(userID uuid.UUID, req *models.CreateAccountRequest) (uuid.UUID, error) {
-	name := strings.TrimSpace(req.Name)
-	if name == "" {
-		return uuid.Nil, validationError{"name is required"}
+// CreateAccount inserts an additional wallet/account row for the user, posting its opening balance
+// when one is given.
+func (s *FinanceService) CreateAccount(userID uuid.UUID, req *models.CreateAccountRequest, now time.Time) (uuid.UUID, error) {
+	a := models.Account{
+		Name:          strings.TrimSpace(req.Name),
+		AccountType:   strings.ToLower(strings.TrimSpace(req.AccountType)),
+		Color:         strings.TrimSpace(req.Color),
+		BankName:      trimmedOrNil(req.BankName),
+		AccountNumber: trimmedOrNil(req.AccountNumber),
+		CreditLimit:   req.CreditLimit,
+		IsDefault:     req.IsDefault,
+
+		MinimumPaymentPercent: defaultMinimumPaymentPercent,
+		MinimumPaymentFloor:   defaultMinimumPaymentFloor,
 	}
-	if len(name) > 120 {
-		return uuid.Nil, validationError{"name is too long"}
+	applyCardSettings(&a, req.StatementClosingDay, req.PaymentDueDay, req.MinimumPaymentPercent, req.MinimumPaymentFloor)
+	if a.AccountType == "" {
+		a.AccountType = "cash"
 	}
-	at := strings.ToLower(strings.TrimSpace(req.AccountType))
-	if at == "" {
-		at = "cash"
+	if a.Color == "" {
+		a.Color = "#3B82F6"
 	}
-	switch at {
-	case "bank", "credit_card", "cash", "investment", "ewallet":
-	default:
-		return uuid.Nil, validationError{"account_type must be bank, credit_card, cash, investment, or ewallet"}
+	if err := validateAccount(&a); err != nil {
+		return uuid.Nil, err
+	}
+	currency, err := s.accountCurrency(userID, req.Currency, now)
+	if err != nil {
+		return uuid.Nil, err
 	}
-	col := strings.TrimSpace(req.Color)
-	if col == "" {
-		col = "#3B82F6"
+	a.Currency = currency
+	if req.MinimumPaymentFloor == nil && a.Currency != "IDR" {
+		// defaultMinimumPaymentFloor is a Rupiah amount; other cards default to the percentage alone.
+		a.MinimumPaymentFloor = 0
+	}
+	var opening int64
+	openingDate := now.Format(dateLayout)
+	if req.OpeningBalance != nil {
+		opening = *req.OpeningBalance
+		if !utils.ValidCurrencyAmount(opening, a.Currency) {
+			return uuid.Nil, validationError{a.Currency + " has no minor unit; opening_balance must be a multiple of 100"}
+		}
+	}
+	if req.OpeningBalanceDate != nil {
+		d, err := parseOpeningDate(*req.OpeningBalanceDate)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		openingDate = d
 	}
-	return s.accRepo.Create(userID, name, at, col)
+
+	id, err := s.accRepo.Create(userID, &a)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if opening != 0 {
+		if err := s.accRepo.SetOpeningBalance(userID, id, opening, openingDate); err != nil {
+			return uuid.Nil, err
+		}
+	}
+	return id, nil
 }
 
-func (s *FinanceService) ListBudgets(userID uuid.UUID) (*models.BudgetsPayload, error) {
-	cards, err := s.budRepo.ListBudgetCardsPayload(userID)
+// ListBudgets returns active budgets, or only archived ones when archived is set.
+func (s *FinanceService) ListBudgets(userID uuid.UUID, archived bool) (*models.BudgetsPayload, error) {
+	cards, err := s.budRepo.ListBudgetCardsPayload(userID, archived)
 	if err != nil {
 		return nil, err
 	}
@@ -175,6 +248,17 @@ func (s *FinanceService) CreateBudget(userID uuid.UUID, req *models.CreateBudget
 	if req.SortOrder != nil {
 		sort = *req.SortOrder
 	}
+	autoReset := true
+	if req.AutoReset != nil {
+		autoReset = *req.AutoReset
+	}
+	carryOver := "none"
+	if req.CarryOver != nil && strings.TrimSpace(*req.CarryOver) != "" {
+		carryOver = strings.ToLower(strings.TrimSpace(*req.CarryOver))
+	}
+	if !validCarryOver(carryOver) {
+		return uuid.Nil, validationError{"carry_over must be none, unspent, overspent, or both"}
+	}
 	alert := 80
 	if req.AlertPercentage != nil && *req.AlertPercentage > 0 && *req.AlertPercentage <= 100 {
 		alert = *req.AlertPercentage
@@ -191,14 +275,15 @@ func (s *FinanceService) CreateBudget(userID uuid.UUID, req *models.CreateBudget
 		}
 	}
 	id, err := s.budRepo.Create(userID, req.CategoryID, strings.TrimSpace(req.Name), req.AllocatedAmount,
-		req.BudgetPeriod, req.PeriodStartDate, req.PeriodEndDate, req.ResponsiblePerson, alert,
+		req.BudgetPeriod, req.PeriodStartDate, req.PeriodEndDate, req.ResponsiblePerson, autoReset, carryOver, alert,
 		icon, color, sort, req.CommonPurchases)
 	if err != nil {
 		if strings.Contains(err.Error(), "UNIQUE constraint failed") || strings.Contains(err.Error(), "unique constraint") {
 			return uuid.Nil, validationError{"a budget with this name already exists"}
 		}
-		return uuid.Nil, err
+		return uuid.Nil, commonPurchaseError(err)
 	}
+	s.alerts.BudgetSpendChanged(userID)
 	return id, nil
 }
 
@@ -218,81 +303,364 @@ func (s *FinanceService) AppendCommonPurchases(userID uuid.UUID, budgetID uuid.U
 			return validationError{"estimated_amount must be positive"}
 		}
 	}
-	err := s.budRepo.AppendCommonPurchases(budgetID, userID, req.Purchases)
-	if errors.Is(err, repository.ErrBudgetNotOwned) {
-		return validationError{"budget not found"}
-	}
-	return err
+	return commonPurchaseError(s.budRepo.AppendCommonPurchases(budgetID, userID, req.Purchases))
 }
 
 // CreateTransaction creates an income/expense posting and optionally links an expense line to one budget bucket.
+// Without category_id and budget_id the first matching categorization rule picks them, with its tags.
 func (s *FinanceService) CreateTransaction(userID uuid.UUID, req *models.CreateTransactionRequest) (uuid.UUID, error) {
+	var tags []string
+	if req.CategoryID == nil && req.BudgetID == nil {
+		var err error
+		if tags, err = s.categorize(userID, req); err != nil {
+			return uuid.Nil, err
+		}
+	}
+	in, err := s.resolveTransaction(userID, req)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !req.AllowDuplicate {
+		var store string
+		if req.LocationName != nil {
+			store = *req.LocationName
+		}
+		if in.link != nil && in.link.Store != nil {
+			store = *in.link.Store
+		}
+		matches, err := s.findDuplicates(userID, req.AccountID, in.txType, in.magnitude,
+			req.TransactionDate, in.description, store)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		if len(matches) > 0 {
+			return uuid.Nil, &DuplicateTransactionError{Matches: matches}
+		}
+	}
+	id, err := s.txRepo.Create(userID, req.AccountID, in.categoryID, in.signed, in.description,
+		in.txType, req.TransactionDate, req.LocationName, in.foreign, in.link, tags)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	s.alerts.BudgetSpendChanged(userID)
+	return id, nil
+}
+
+// categorize fills in req's category, and budget and item when the rule has them, from the user's first
+// matching rule and returns the rule's tags.
+func (s *FinanceService) categorize(userID uuid.UUID, req *models.CreateTransactionRequest) ([]string, error) {
+	rules, err := loadRuleSet(s.ruleRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	sample := ruleSample{
+		AccountID:   req.AccountID,
+		Type:        strings.ToLower(strings.TrimSpace(req.TransactionType)),
+		Amount:      req.MagnitudeAmountCents,
+		Description: req.Description,
+	}
+	if req.Store != nil {
+		sample.Store = *req.Store
+	}
+	if req.LocationName != nil {
+		sample.Location = *req.LocationName
+	}
+	rule := rules.match(sample)
+	if rule == nil {
+		return nil, validationError{"category_id is required; no categorization rule matched"}
+	}
+	req.CategoryID = &rule.CategoryID
+	if rule.BudgetID != nil && sample.Type == "expense" {
+		req.BudgetID = rule.BudgetID
+		if req.Item == nil {
+			req.Item = rule.Item
+		}
+	}
+	return rule.Tags, nil
+}
+
+// UpdateTransaction applies a partial edit to an owned income/expense row. The merged values go through
+// the same checks as CreateTransaction; the budget link is kept, moved, edited or dropped to match.
+func (s *FinanceService) UpdateTransaction(userID, id uuid.UUID, req *models.UpdateTransactionRequest) error {
+	cur, link, err := s.txRepo.GetForUser(id, userID)
+	if errors.Is(err, repository.ErrTransactionNotFound) {
+		return validationError{"transaction not found"}
+	}
+	if err != nil {
+		return err
+	}
+	if cur.TransactionType == "transfer" {
+		return s.transferLegError(id, "transfer legs cannot be edited; delete the transfer and create it again")
+	}
+	if err := s.ensureNotTrade(id); err != nil {
+		return err
+	}
+	if err := s.ensureNotTransferFee(id); err != nil {
+		return err
+	}
+
+	merged := models.CreateTransactionRequest{
+		AccountID:            cur.AccountID,
+		CategoryID:           cur.CategoryID,
+		MagnitudeAmountCents: cur.GetAbsoluteAmount(),
+		Description:          cur.Description,
+		TransactionType:      cur.TransactionType,
+		TransactionDate:      cur.TransactionDate.Format("2006-01-02"),
+		LocationName:         cur.LocationName,
+	}
+	if link != nil {
+		merged.BudgetID = &link.BudgetID
+		merged.Item = &link.Item
+		merged.Quantity = link.Quantity
+		merged.Store = link.Store
+	}
+	if cur.OriginalCurrency != nil && cur.OriginalAmount != nil {
+		// Keep the foreign price and what the account was charged for it.
+		charged := cur.GetAbsoluteAmount()
+		merged.Currency = *cur.OriginalCurrency
+		merged.MagnitudeAmountCents = *cur.OriginalAmount
+		if merged.MagnitudeAmountCents < 0 {
+			merged.MagnitudeAmountCents = -merged.MagnitudeAmountCents
+		}
+		merged.AccountAmountCents = &charged
+	}
+
+	if req.AccountID != nil {
+		merged.AccountID = *req.AccountID
+	}
+	if req.CategoryID != nil {
+		merged.CategoryID = req.CategoryID
+	}
+	if req.MagnitudeAmountCents != nil {
+		merged.MagnitudeAmountCents = *req.MagnitudeAmountCents
+	}
+	if req.Description != nil {
+		merged.Description = *req.Description
+	}
+	if req.TransactionType != nil {
+		merged.TransactionType = *req.TransactionType
+	}
+	if req.TransactionDate != nil {
+		merged.TransactionDate = *req.TransactionDate
+	}
+	if req.LocationName != nil {
+		loc := strings.TrimSpace(*req.LocationName)
+		merged.LocationName = nil
+		if loc != "" {
+			merged.LocationName = &loc
+		}
+	}
+	if req.RemoveBudget {
+		if req.BudgetID != nil {
+			return validationError{"budget_id and remove_budget cannot both be set"}
+		}
+		merged.BudgetID = nil
+		merged.Item, merged.Quantity, merged.Store = nil, nil, nil
+	}
+	if req.BudgetID != nil {
+		merged.BudgetID = req.BudgetID
+	}
+	if req.Item != nil {
+		merged.Item = req.Item
+	}
+	if req.Quantity != nil {
+		merged.Quantity = req.Quantity
+	}
+	if req.Store != nil {
+		merged.Store = req.Store
+	}
+	// A new price, currency or account is converted again unless the charged amount is given too.
+	if req.MagnitudeAmountCents != nil || req.Currency != nil ||
+		(req.AccountID != nil && *req.AccountID != cur.AccountID) {
+		merged.AccountAmountCents = nil
+	}
+	if req.Currency != nil {
+		if strings.TrimSpace(*req.Currency) == "" && merged.Currency != "" && req.MagnitudeAmountCents == nil {
+			merged.MagnitudeAmountCents = cur.GetAbsoluteAmount()
+		}
+		merged.Currency = *req.Currency
+	}
+	if req.AccountAmountCents != nil {
+		merged.AccountAmountCents = req.AccountAmountCents
+	}
+
+	in, err := s.resolveTransaction(userID, &merged)
+	if err != nil {
+		return err
+	}
+	err = s.txRepo.Update(id, userID, merged.AccountID, in.categoryID, in.signed, in.description,
+		in.txType, merged.TransactionDate, merged.LocationName, in.foreign, in.link)
+	if errors.Is(err, repository.ErrTransactionNotFound) {
+		return validationError{"transaction not found"}
+	}
+	if err != nil {
+		return reconciledError(err)
+	}
+	s.alerts.BudgetSpendChanged(userID)
+	return nil
+}
+
+// DeleteTransaction removes an owned income/expense row together with its budget link.
+func (s *FinanceService) DeleteTransaction(userID, id uuid.UUID) error {
+	cur, _, err := s.txRepo.GetForUser(id, userID)
+	if errors.Is(err, repository.ErrTransactionNotFound) {
+		return validationError{"transaction not found"}
+	}
+	if err != nil {
+		return err
+	}
+	if cur.TransactionType == "transfer" {
+		return s.transferLegError(id, "transfer legs must be deleted via DELETE /api/transfers/{id}")
+	}
+	if err := s.ensureNotTrade(id); err != nil {
+		return err
+	}
+	if err := s.ensureNotTransferFee(id); err != nil {
+		return err
+	}
+	err = s.txRepo.Delete(id, userID)
+	if errors.Is(err, repository.ErrTransactionNotFound) {
+		return validationError{"transaction not found"}
+	}
+	return reconciledError(err)
+}
+
+// transferLegError explains why a transfer-type row cannot be changed directly; an account's opening
+// balance row is also transfer-typed but is managed through the account.
+func (s *FinanceService) transferLegError(id uuid.UUID, msg string) error {
+	opening, err := s.accRepo.IsOpeningTransaction(id)
+	if err != nil {
+		return err
+	}
+	if opening {
+		return validationError{"opening balance rows are changed via PATCH /api/accounts/{id}"}
+	}
+	if err := s.ensureNotTrade(id); err != nil {
+		return err
+	}
+	return validationError{msg}
+}
+
+const tradeRowMessage = "investment trade rows are changed via DELETE /api/trades/{id}"
+
+// ensureNotTrade rejects changes to the cash row of an investment trade, which would leave the trade
+// and its holding out of step.
+func (s *FinanceService) ensureNotTrade(id uuid.UUID) error {
+	trade, err := s.accRepo.IsTradeTransaction(id)
+	if err != nil {
+		return err
+	}
+	if trade {
+		return validationError{tradeRowMessage}
+	}
+	return nil
+}
+
+const transferFeeRowMessage = "transfer fee rows are changed via DELETE /api/transfers/{id}"
+
+// ensureNotTransferFee rejects changes to a transfer's fee expense, which would leave the transfer
+// reporting a fee with no row behind it.
+func (s *FinanceService) ensureNotTransferFee(id uuid.UUID) error {
+	fee, err := s.txRepo.IsTransferFee(id)
+	if err != nil {
+		return err
+	}
+	if fee {
+		return validationError{transferFeeRowMessage}
+	}
+	return nil
+}
+
+// resolvedTransaction is a validated create/update body ready for the repository.
+type resolvedTransaction struct {
+	categoryID  uuid.UUID
+	signed      int64
+	magnitude   int64 // in the account's currency
+	description string
+	txType      string
+	foreign     *repository.ForeignAmount
+	link        *repository.BudgetLinkParams
+}
+
+// resolveTransaction validates amounts, ownership and category/budget consistency shared by create and update.
+func (s *FinanceService) resolveTransaction(userID uuid.UUID, req *models.CreateTransactionRequest) (*resolvedTransaction, error) {
 	if req.MagnitudeAmountCents <= 0 {
-		return uuid.Nil, validationError{"magnitude_amount_cents must be positive"}
+		return nil, validationError{"magnitude_amount_cents must be positive"}
 	}
 	if strings.TrimSpace(req.Description) == "" {
-		return uuid.Nil, validationError{"description is required"}
+		return nil, validationError{"description is required"}
 	}
 	if req.TransactionDate == "" {
-		return uuid.Nil, validationError{"transaction_date is required"}
+		return nil, validationError{"transaction_date is required"}
 	}
 	txType := strings.ToLower(strings.TrimSpace(req.TransactionType))
 	if txType != "income" && txType != "expense" {
-		return uuid.Nil, validationError{"transaction_type must be income or expense"}
+		return nil, validationError{"transaction_type must be income or expense"}
 	}
 
 	ok, err := s.accRepo.AccountBelongs(req.AccountID, userID)
 	if err != nil {
-		return uuid.Nil, err
+		return nil, err
 	}
 	if !ok {
-		return uuid.Nil, validationError{"account not found"}
+		return nil, validationError{"account not found"}
+	}
+	acc, err := s.getAccount(userID, req.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	magnitude, foreign, err := s.accountAmount(userID, acc.Currency, req)
+	if err != nil {
+		return nil, err
 	}
 
 	var effectiveCategory uuid.UUID
 	if req.BudgetID != nil {
 		if txType != "expense" {
-			return uuid.Nil, validationError{"budget_id can only be set for expense"}
+			return nil, validationError{"budget_id can only be set for expense"}
 		}
 		bcat, okb, err := s.budRepo.BudgetBelongs(*req.BudgetID, userID)
 		if err != nil {
-			return uuid.Nil, err
+			return nil, err
 		}
 		if !okb {
-			return uuid.Nil, validationError{"budget not found"}
+			return nil, validationError{"budget not found"}
 		}
 		effectiveCategory = bcat
 		if req.CategoryID != nil && *req.CategoryID != effectiveCategory {
-			return uuid.Nil, validationError{"category_id must match the budget category"}
+			return nil, validationError{"category_id must match the budget category"}
 		}
 	} else {
 		if req.CategoryID == nil {
-			return uuid.Nil, validationError{"category_id is required"}
+			return nil, validationError{"category_id is required"}
 		}
 		effectiveCategory = *req.CategoryID
 	}
 
 	ctype, ok, err := s.catRepo.CategoryOwnedOrSystem(effectiveCategory, userID)
 	if err != nil {
-		return uuid.Nil, err
+		return nil, err
 	}
 	if !ok {
-		return uuid.Nil, validationError{"category not found"}
+		return nil, validationError{"category not found"}
 	}
 	want := "expense"
 	if txType == "income" {
 		want = "income"
 	}
 	if ctype != want {
-		return uuid.Nil, validationError{"category type does not match transaction_type"}
+		return nil, validationError{"category type does not match transaction_type"}
 	}
 
 	var signed int64
 	switch txType {
 	case "income":
-		signed = req.MagnitudeAmountCents
+		signed = magnitude
 	case "expense":
-		signed = -req.MagnitudeAmountCents
+		signed = -magnitude
+		if foreign != nil {
+			foreign.Amount = -foreign.Amount
+		}
 	}
 
 	var link *repository.BudgetLinkParams
@@ -318,15 +686,65 @@ func (s *FinanceService) CreateTransaction(userID uuid.UUID, req *models.CreateT
 				link.Store = &st
 			}
 		}
-		mag := req.MagnitudeAmountCents
-		link.UnitPrice = &mag
+		link.UnitPrice = &magnitude
+		if req.CommonPurchaseID != nil {
+			if _, err := s.budRepo.GetCommonPurchase(*req.BudgetID, userID, *req.CommonPurchaseID); err != nil {
+				return nil, commonPurchaseError(err)
+			}
+			link.CommonPurchaseID = req.CommonPurchaseID
+		}
+	} else if req.CommonPurchaseID != nil {
+		return nil, validationError{"common_purchase_id requires budget_id"}
 	}
 
-	desc := strings.TrimSpace(req.Description)
-	id, err := s.txRepo.Create(userID, req.AccountID, effectiveCategory, signed, desc,
-		txType, req.TransactionDate, req.LocationName, link)
-	if err != nil {
-		return uuid.Nil, err
+	return &resolvedTransaction{
+		categoryID:  effectiveCategory,
+		signed:      signed,
+		magnitude:   magnitude,
+		description: strings.TrimSpace(req.Description),
+		txType:      txType,
+		foreign:     foreign,
+		link:        link,
+	}, nil
+}
+
+// accountAmount returns the magnitude to post in the account's currency and, when req.Currency names
+// another currency, the foreign amount paid (positive; the caller applies the sign).
+func (s *FinanceService) accountAmount(userID uuid.UUID, accountCurrency string, req *models.CreateTransactionRequest) (int64, *repository.ForeignAmount, error) {
+	code := utils.NormalizeCurrency(req.Currency)
+	if code == "" || code == accountCurrency {
+		if req.AccountAmountCents != nil {
+			return 0, nil, validationError{"account_amount_cents is only for a currency other than the account's"}
+		}
+		if !utils.ValidCurrencyAmount(req.MagnitudeAmountCents, accountCurrency) {
+			return 0, nil, validationError{accountCurrency + " has no minor unit; magnitude_amount_cents must be a multiple of 100"}
+		}
+		return req.MagnitudeAmountCents, nil, nil
 	}
-	return id, nil
+	if !utils.ValidCurrency(code) {
+		return 0, nil, validationError{"currency is not supported"}
+	}
+	if !utils.ValidCurrencyAmount(req.MagnitudeAmountCents, code) {
+		return 0, nil, validationError{code + " has no minor unit; magnitude_amount_cents must be a multiple of 100"}
+	}
+	var amount int64
+	if req.AccountAmountCents != nil {
+		amount = *req.AccountAmountCents
+		if amount <= 0 {
+			return 0, nil, validationError{"account_amount_cents must be positive"}
+		}
+		if !utils.ValidCurrencyAmount(amount, accountCurrency) {
+			return 0, nil, validationError{accountCurrency + " has no minor unit; account_amount_cents must be a multiple of 100"}
+		}
+	} else {
+		var err error
+		amount, err = s.rateRepo.Convert(userID, req.MagnitudeAmountCents, code, accountCurrency, req.TransactionDate)
+		if err != nil {
+			return 0, nil, exchangeRateError(err)
+		}
+		if amount <= 0 {
+			return 0, nil, validationError{"the amount converts to zero in " + accountCurrency + "; set account_amount_cents"}
+		}
+	}
+	return amount, &repository.ForeignAmount{Currency: code, Amount: req.MagnitudeAmountCents}, nil
 }
@@ -0,0 +1,460 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"monman-backend/internal/importer"
+	"monman-backend/internal/models"
+	"monman-backend/internal/repository"
+	"monman-backend/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// defaultImportExpenseCategoryID is the system "Lain-lain" expense category (002_seed_categories.sql).
+	defaultImportExpenseCategoryID = uuid.MustParse("aae95140-25c8-4cb8-808b-59aee555b24c")
+	// defaultImportIncomeCategoryID is the system "Pendapatan Lainnya" income category.
+	defaultImportIncomeCategoryID = uuid.MustParse("3d320c86-88bc-43bf-9daa-6e0fcf5ab5f5")
+)
+
+// ImportService parses bank statement files and commits them as reversible import batches.
+type ImportService struct {
+	impRepo  *repository.ImportRepository
+	accRepo  *repository.AccountRepository
+	catRepo  *repository.CategoryRepository
+	ruleRepo *repository.CategorizationRuleRepository
+	alerts   *AlertService
+}
+
+// NewImportService wires the repositories; alerts may be nil to skip budget threshold checks.
+func NewImportService(
+	impRepo *repository.ImportRepository,
+	accRepo *repository.AccountRepository,
+	catRepo *repository.CategoryRepository,
+	ruleRepo *repository.CategorizationRuleRepository,
+	alerts *AlertService,
+) *ImportService {
+	return &ImportService{
+		impRepo:  impRepo,
+		accRepo:  accRepo,
+		catRepo:  catRepo,
+		ruleRepo: ruleRepo,
+		alerts:   alerts,
+	}
+}
+
+// ListProfiles returns the built-in bank profiles followed by the user's saved ones.
+func (s *ImportService) ListProfiles(userID uuid.UUID) (*models.ImportProfilesPayload, error) {
+	saved, err := s.impRepo.ListProfiles(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ImportProfilesPayload{Profiles: append(importer.BuiltInProfiles(), saved...)}, nil
+}
+
+// SaveProfile validates and stores a custom column mapping.
+func (s *ImportService) SaveProfile(userID uuid.UUID, p *models.ImportProfile) (*models.ImportProfile, error) {
+	if err := importer.ValidateProfile(p); err != nil {
+		return nil, validationError{err.Error()}
+	}
+	if p.Name == "" {
+		return nil, validationError{"name is required"}
+	}
+	if len(p.Name) > 100 {
+		return nil, validationError{"name is too long"}
+	}
+	if _, ok := importer.BuiltInProfile(p.Name); ok {
+		return nil, validationError{"name is reserved for a built-in profile"}
+	}
+	id, err := s.impRepo.CreateProfile(userID, *p)
+	if err != nil {
+		if errors.Is(err, repository.ErrImportProfileExists) {
+			return nil, validationError{err.Error()}
+		}
+		return nil, err
+	}
+	saved := *p
+	saved.ID, saved.BuiltIn = id.String(), false
+	return &saved, nil
+}
+
+func (s *ImportService) DeleteProfile(userID, id uuid.UUID) error {
+	err := s.impRepo.DeleteProfile(id, userID)
+	if errors.Is(err, repository.ErrImportProfileNotFound) {
+		return validationError{err.Error()}
+	}
+	return err
+}
+
+// resolveProfile picks the ad-hoc mapping, a built-in profile or a saved one.
+func (s *ImportService) resolveProfile(userID uuid.UUID, in *models.ImportInput) (models.ImportProfile, error) {
+	if in.Mapping != nil {
+		p := *in.Mapping
+		if err := importer.ValidateProfile(&p); err != nil {
+			return p, validationError{err.Error()}
+		}
+		return p, nil
+	}
+	if in.ProfileID == "" {
+		return models.ImportProfile{}, validationError{"profile_id or mapping is required"}
+	}
+	if p, ok := importer.BuiltInProfile(in.ProfileID); ok {
+		return p, nil
+	}
+	id, err := uuid.Parse(in.ProfileID)
+	if err != nil {
+		return models.ImportProfile{}, validationError{"import profile not found"}
+	}
+	p, err := s.impRepo.GetProfile(id, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrImportProfileNotFound) {
+			return models.ImportProfile{}, validationError{err.Error()}
+		}
+		return models.ImportProfile{}, err
+	}
+	return *p, nil
+}
+
+// importCategory checks an optional category override, falling back to def.
+func (s *ImportService) importCategory(userID uuid.UUID, id *uuid.UUID, def uuid.UUID, want string) (uuid.UUID, error) {
+	if id == nil {
+		return def, nil
+	}
+	ctype, ok, err := s.catRepo.CategoryOwnedOrSystem(*id, userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !ok {
+		return uuid.Nil, validationError{want + " category not found"}
+	}
+	if ctype != want {
+		return uuid.Nil, validationError{fmt.Sprintf("%s category must be %s type", want, want)}
+	}
+	return *id, nil
+}
+
+// Import parses a statement file in in.Format and returns the preview. With in.Commit the valid rows
+// are posted to the account as one batch; rows with errors block the commit unless in.SkipInvalid is
+// set, and rows whose external id the account already has are skipped as duplicates.
+func (s *ImportService) Import(userID uuid.UUID, in *models.ImportInput, file io.Reader) (*models.ImportPreviewAPI, error) {
+	acc, err := s.accRepo.GetForUser(in.AccountID, userID)
+	if errors.Is(err, repository.ErrAccountNotFound) || err == nil && !acc.IsActive {
+		return nil, validationError{"account not found"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	expenseCat, err := s.importCategory(userID, in.ExpenseCategoryID, defaultImportExpenseCategoryID, "expense")
+	if err != nil {
+		return nil, err
+	}
+	incomeCat, err := s.importCategory(userID, in.IncomeCategoryID, defaultImportIncomeCategoryID, "income")
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		parsed  []importer.Row
+		profile *models.ImportProfile
+	)
+	switch in.Format {
+	case "csv":
+		p, err := s.resolveProfile(userID, in)
+		if err != nil {
+			return nil, err
+		}
+		profile = &p
+		parsed, err = importer.ParseCSV(file, p)
+		if err != nil {
+			return nil, validationError{err.Error()}
+		}
+	case "ofx":
+		if parsed, err = importer.ParseOFX(file); err != nil {
+			return nil, validationError{err.Error()}
+		}
+	case "qif":
+		if parsed, err = importer.ParseQIF(file, in.DateFormat); err != nil {
+			return nil, validationError{err.Error()}
+		}
+	default:
+		return nil, validationError{"format must be csv, ofx or qif"}
+	}
+	for i := range parsed {
+		if parsed[i].Error == "" && !utils.ValidCurrencyAmount(parsed[i].Amount, acc.Currency) {
+			parsed[i].Error = acc.Currency + " has no minor unit; amount must be a whole number"
+		}
+	}
+
+	duplicates, err := s.duplicateRows(in.AccountID, parsed)
+	if err != nil {
+		return nil, err
+	}
+	duplicateOf, err := s.matchExisting(in.AccountID, parsed, duplicates)
+	if err != nil {
+		return nil, err
+	}
+	preview := previewRows(profile, parsed, duplicates, duplicateOf)
+
+	// A category named in the file wins; otherwise the first matching rule, then the batch default.
+	categories, err := s.categoriesByName(userID)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := loadRuleSet(s.ruleRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	fileCategories := make(map[int]uuid.UUID)
+	matched := make(map[int]*repository.CategorizationRule)
+	for i, row := range parsed {
+		if row.Error != "" || duplicates[i] {
+			continue
+		}
+		if id, ok := fileCategory(categories, row); ok {
+			fileCategories[i] = id
+			continue
+		}
+		sample := ruleSample{AccountID: in.AccountID, Type: "expense", Amount: -row.Amount, Description: row.Description}
+		if row.Amount > 0 {
+			sample.Type, sample.Amount = "income", row.Amount
+		}
+		if rule := rules.match(sample); rule != nil {
+			matched[i] = rule
+			preview.Rows[i].Rule = rule.Name
+		}
+	}
+	if !in.Commit {
+		return preview, nil
+	}
+
+	if preview.ErrorCount > 0 && !in.SkipInvalid {
+		return nil, validationError{fmt.Sprintf("%d rows have errors; fix them or set skip_invalid", preview.ErrorCount)}
+	}
+	if preview.ValidCount == 0 {
+		return nil, validationError{"no new valid rows to import"}
+	}
+	batch := repository.ImportBatch{
+		UserID:            userID,
+		AccountID:         in.AccountID,
+		Source:            in.Format,
+		Filename:          strings.TrimSpace(in.Filename),
+		ExpenseCategoryID: expenseCat,
+		IncomeCategoryID:  incomeCat,
+	}
+	if profile != nil {
+		batch.ProfileName = profile.Name
+	}
+	for i, row := range parsed {
+		if row.Error != "" || duplicates[i] {
+			continue
+		}
+		br := repository.ImportBatchRow{
+			Date:        row.Date,
+			Description: row.Description,
+			Amount:      row.Amount,
+			ExternalID:  row.ExternalID,
+		}
+		if id, ok := fileCategories[i]; ok {
+			br.CategoryID = &id
+		} else if rule := matched[i]; rule != nil {
+			br.CategoryID, br.Tags = &rule.CategoryID, rule.Tags
+			if rule.BudgetID != nil && row.Amount < 0 {
+				br.Budget = ruleBudgetLink(userID, rule, -row.Amount)
+			}
+		}
+		batch.Rows = append(batch.Rows, br)
+	}
+	id, err := s.impRepo.CreateBatch(batch)
+	if err != nil {
+		return nil, err
+	}
+	if preview.ExpenseCents > 0 {
+		s.alerts.BudgetSpendChanged(userID)
+	}
+	if preview.Batch, err = s.impRepo.GetBatch(id, userID); err != nil {
+		return nil, err
+	}
+	return preview, nil
+}
+
+// duplicateRows flags rows whose external id is already on the account or repeats earlier in the file.
+func (s *ImportService) duplicateRows(accountID uuid.UUID, parsed []importer.Row) (map[int]bool, error) {
+	var ids []string
+	for _, row := range parsed {
+		if row.ExternalID != "" {
+			ids = append(ids, row.ExternalID)
+		}
+	}
+	dup := make(map[int]bool)
+	if len(ids) == 0 {
+		return dup, nil
+	}
+	known, err := s.impRepo.KnownExternalIDs(accountID, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i, row := range parsed {
+		if row.ExternalID == "" || row.Error != "" {
+			continue
+		}
+		if known[row.ExternalID] {
+			dup[i] = true
+		}
+		known[row.ExternalID] = true
+	}
+	return dup, nil
+}
+
+// matchExisting flags rows that look like a transaction already on the account (same signed amount,
+// dates at most a day apart, a similar description or a merchant word in common) and returns the matched
+// ids by row index. Each saved transaction absorbs one row, and two lines that both carry a bank id are
+// never matched to each other.
+func (s *ImportService) matchExisting(accountID uuid.UUID, parsed []importer.Row, duplicates map[int]bool) (map[int]string, error) {
+	var from, to string
+	for i, row := range parsed {
+		if row.Error != "" || duplicates[i] {
+			continue
+		}
+		if from == "" || row.Date < from {
+			from = row.Date
+		}
+		if row.Date > to {
+			to = row.Date
+		}
+	}
+	matched := make(map[int]string)
+	if from == "" {
+		return matched, nil
+	}
+	from, _, err := dayAround(from)
+	if err != nil {
+		return nil, err
+	}
+	_, to, err = dayAround(to)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.impRepo.TransactionsBetween(accountID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	used := make([]bool, len(existing))
+	for i, row := range parsed {
+		if row.Error != "" || duplicates[i] {
+			continue
+		}
+		lo, hi, err := dayAround(row.Date)
+		if err != nil {
+			continue
+		}
+		for j, t := range existing {
+			if used[j] || t.Amount != row.Amount || t.Date < lo || t.Date > hi {
+				continue
+			}
+			if row.ExternalID != "" && t.HasExternal {
+				continue
+			}
+			if !similarText(row.Description, t.Description) && !sharesKeyword(row.Description, t.Description) &&
+				!sharesKeyword(row.Description, t.Store) {
+				continue
+			}
+			used[j] = true
+			duplicates[i] = true
+			matched[i] = t.ID
+			break
+		}
+	}
+	return matched, nil
+}
+
+// fileCategory resolves the category a file row names (QIF), trying the most specific name first:
+// "Makanan:Snack" matches Snack, then Makanan.
+func fileCategory(categories map[string]uuid.UUID, row importer.Row) (uuid.UUID, bool) {
+	if row.Category == "" {
+		return uuid.Nil, false
+	}
+	ctype := "expense"
+	if row.Amount > 0 {
+		ctype = "income"
+	}
+	names := strings.Split(row.Category, ":")
+	for i := len(names) - 1; i >= 0; i-- {
+		if id, ok := categories[ctype+"\x00"+strings.ToLower(strings.TrimSpace(names[i]))]; ok {
+			return id, true
+		}
+	}
+	return uuid.Nil, false
+}
+
+// categoriesByName maps "type\x00lower(name)" to the user's usable categories, for QIF category names.
+func (s *ImportService) categoriesByName(userID uuid.UUID) (map[string]uuid.UUID, error) {
+	list, err := s.catRepo.ListActiveForUser(userID, "")
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]uuid.UUID, len(list))
+	for _, c := range list {
+		id, err := uuid.Parse(c.ID)
+		if err != nil {
+			continue
+		}
+		key := c.CategoryType + "\x00" + strings.ToLower(c.Name)
+		// System categories are listed first; a user's own category of the same name wins.
+		out[key] = id
+	}
+	return out, nil
+}
+
+func previewRows(profile *models.ImportProfile, parsed []importer.Row, duplicates map[int]bool, duplicateOf map[int]string) *models.ImportPreviewAPI {
+	preview := &models.ImportPreviewAPI{Profile: profile, Rows: make([]models.ImportRowAPI, 0, len(parsed))}
+	for i, row := range parsed {
+		preview.Rows = append(preview.Rows, models.ImportRowAPI{
+			Line:        row.Line,
+			Date:        row.Date,
+			Description: row.Description,
+			Amount:      row.Amount,
+			ExternalID:  row.ExternalID,
+			Category:    row.Category,
+			Duplicate:   duplicates[i],
+			DuplicateOf: duplicateOf[i],
+			Error:       row.Error,
+		})
+		switch {
+		case row.Error != "":
+			preview.ErrorCount++
+		case duplicates[i]:
+			preview.DuplicateCount++
+		case row.Amount > 0:
+			preview.ValidCount++
+			preview.IncomeCents += row.Amount
+		default:
+			preview.ValidCount++
+			preview.ExpenseCents -= row.Amount
+		}
+	}
+	return preview
+}
+
+func (s *ImportService) ListBatches(userID uuid.UUID, limit, offset int) (*models.ImportBatchesPayload, error) {
+	list, err := s.impRepo.ListBatches(userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ImportBatchesPayload{Batches: list}, nil
+}
+
+// RevertBatch removes every transaction a committed import created.
+func (s *ImportService) RevertBatch(userID, id uuid.UUID) (*models.ImportBatchAPI, error) {
+	if _, err := s.impRepo.RevertBatch(id, userID); err != nil {
+		if errors.Is(err, repository.ErrImportBatchNotFound) || errors.Is(err, repository.ErrImportBatchReverted) {
+			return nil, validationError{err.Error()}
+		}
+		return nil, reconciledError(err)
+	}
+	s.alerts.BudgetSpendChanged(userID)
+	return s.impRepo.GetBatch(id, userID)
+}
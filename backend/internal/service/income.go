@@ -0,0 +1,446 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"monman-backend/internal/models"
+	"monman-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// maxIncomeReportMonths bounds GET /api/income-sources/report.
+const maxIncomeReportMonths = 24
+
+// IncomeService manages income sources (Gapok, Tukin, freelance) and compares them with received income.
+type IncomeService struct {
+	incRepo  *repository.IncomeSourceRepository
+	accRepo  *repository.AccountRepository
+	catRepo  *repository.CategoryRepository
+	rateRepo *repository.ExchangeRateRepository
+}
+
+func NewIncomeService(
+	incRepo *repository.IncomeSourceRepository,
+	accRepo *repository.AccountRepository,
+	catRepo *repository.CategoryRepository,
+	rateRepo *repository.ExchangeRateRepository,
+) *IncomeService {
+	return &IncomeService{
+		incRepo:  incRepo,
+		accRepo:  accRepo,
+		catRepo:  catRepo,
+		rateRepo: rateRepo,
+	}
+}
+
+func (s *IncomeService) List(userID uuid.UUID) (*models.IncomeSourcesPayload, error) {
+	list, err := s.incRepo.ListForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.IncomeSourcesPayload{IncomeSources: list}, nil
+}
+
+func (s *IncomeService) Create(userID uuid.UUID, req *models.CreateIncomeSourceRequest) (uuid.UUID, error) {
+	src := &models.IncomeSource{
+		UserID:       userID,
+		CategoryID:   req.CategoryID,
+		Name:         req.Name,
+		Amount:       req.Amount,
+		Frequency:    req.Frequency,
+		SourceType:   req.SourceType,
+		EmployerName: req.EmployerName,
+		AccountID:    req.AccountID,
+		IsActive:     true,
+	}
+	if req.NextExpectedDate != nil && strings.TrimSpace(*req.NextExpectedDate) != "" {
+		t, err := time.Parse(dateLayout, strings.TrimSpace(*req.NextExpectedDate))
+		if err != nil {
+			return uuid.Nil, validationError{"next_expected_date must be YYYY-MM-DD"}
+		}
+		src.NextExpectedDate = &t
+	}
+	if err := s.validate(src); err != nil {
+		return uuid.Nil, err
+	}
+	return s.incRepo.Create(src)
+}
+
+func (s *IncomeService) Update(userID, id uuid.UUID, req *models.UpdateIncomeSourceRequest) error {
+	src, err := s.incRepo.GetForUser(id, userID)
+	if errors.Is(err, repository.ErrIncomeSourceNotFound) {
+		return validationError{"income source not found"}
+	}
+	if err != nil {
+		return err
+	}
+	if req.CategoryID != nil {
+		src.CategoryID = *req.CategoryID
+	}
+	if req.Name != nil {
+		src.Name = *req.Name
+	}
+	if req.Amount != nil {
+		src.Amount = *req.Amount
+	}
+	if req.Frequency != nil {
+		src.Frequency = *req.Frequency
+	}
+	if req.SourceType != nil {
+		src.SourceType = *req.SourceType
+	}
+	if req.EmployerName != nil {
+		src.EmployerName = req.EmployerName
+	}
+	if req.ClearAccount {
+		if req.AccountID != nil {
+			return validationError{"account_id and clear_account cannot both be set"}
+		}
+		src.AccountID = nil
+	}
+	if req.AccountID != nil {
+		src.AccountID = req.AccountID
+	}
+	if req.NextExpectedDate != nil {
+		src.NextExpectedDate = nil
+		if d := strings.TrimSpace(*req.NextExpectedDate); d != "" {
+			t, err := time.Parse(dateLayout, d)
+			if err != nil {
+				return validationError{"next_expected_date must be YYYY-MM-DD"}
+			}
+			src.NextExpectedDate = &t
+		}
+	}
+	if req.IsActive != nil {
+		src.IsActive = *req.IsActive
+	}
+	if err := s.validate(src); err != nil {
+		return err
+	}
+	err = s.incRepo.Update(src)
+	if errors.Is(err, repository.ErrIncomeSourceNotFound) {
+		return validationError{"income source not found"}
+	}
+	return err
+}
+
+func (s *IncomeService) Delete(userID, id uuid.UUID) error {
+	err := s.incRepo.Delete(id, userID)
+	if errors.Is(err, repository.ErrIncomeSourceNotFound) {
+		return validationError{"income source not found"}
+	}
+	return err
+}
+
+// validate normalizes a merged source and checks category/account ownership.
+func (s *IncomeService) validate(src *models.IncomeSource) error {
+	src.Name = strings.TrimSpace(src.Name)
+	if src.Name == "" {
+		return validationError{"name is required"}
+	}
+	if len(src.Name) > 120 {
+		return validationError{"name is too long"}
+	}
+	if src.Amount <= 0 {
+		return validationError{"amount must be positive"}
+	}
+	src.Frequency = strings.ToLower(strings.TrimSpace(src.Frequency))
+	switch src.Frequency {
+	case "daily", "weekly", "monthly", "yearly", "irregular":
+	default:
+		return validationError{"frequency must be daily, weekly, monthly, yearly, or irregular"}
+	}
+	src.SourceType = strings.ToLower(strings.TrimSpace(src.SourceType))
+	if src.SourceType == "" {
+		src.SourceType = "salary"
+	}
+	switch src.SourceType {
+	case "salary", "freelance", "investment", "business", "other":
+	default:
+		return validationError{"source_type must be salary, freelance, investment, business, or other"}
+	}
+	if src.EmployerName != nil {
+		e := strings.TrimSpace(*src.EmployerName)
+		src.EmployerName = nil
+		if e != "" {
+			src.EmployerName = &e
+		}
+	}
+
+	ctype, ok, err := s.catRepo.CategoryOwnedOrSystem(src.CategoryID, src.UserID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return validationError{"category not found"}
+	}
+	if ctype != "income" {
+		return validationError{"income source category must be income type"}
+	}
+	if src.AccountID != nil {
+		ok, err := s.accRepo.AccountBelongs(*src.AccountID, src.UserID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return validationError{"account not found"}
+		}
+	}
+	return nil
+}
+
+// Report compares expected and received income per active source for each month in [from, to] (YYYY-MM).
+// Income is matched by the source's category and, when set, its receiving account; each transaction
+// counts toward one source only (see assignIncome).
+// Empty bounds default to the last six months including the current one.
+func (s *IncomeService) Report(userID uuid.UUID, from, to string, now time.Time) (*models.IncomeReportPayload, error) {
+	toMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if to != "" {
+		t, err := time.Parse("2006-01", to)
+		if err != nil {
+			return nil, validationError{"to must be YYYY-MM"}
+		}
+		toMonth = t
+	}
+	fromMonth := toMonth.AddDate(0, -5, 0)
+	if from != "" {
+		f, err := time.Parse("2006-01", from)
+		if err != nil {
+			return nil, validationError{"from must be YYYY-MM"}
+		}
+		fromMonth = f
+	}
+	if fromMonth.After(toMonth) {
+		return nil, validationError{"from must not be after to"}
+	}
+	var months []time.Time
+	for m := fromMonth; !m.After(toMonth); m = m.AddDate(0, 1, 0) {
+		months = append(months, m)
+	}
+	if len(months) > maxIncomeReportMonths {
+		return nil, validationError{"report range is limited to 24 months"}
+	}
+
+	sources, err := s.incRepo.ListForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	base, err := s.rateRepo.BaseCurrency(userID)
+	if err != nil {
+		return nil, err
+	}
+	today := now.Format(dateLayout)
+	rangeFrom := fromMonth.Format(dateLayout)
+	rangeTo := toMonth.AddDate(0, 1, -1).Format(dateLayout)
+
+	out := &models.IncomeReportPayload{
+		From:     fromMonth.Format("2006-01"),
+		To:       toMonth.Format("2006-01"),
+		Currency: base,
+		Sources:  []models.IncomeSourceReportAPI{},
+	}
+	var (
+		active  []models.IncomeSourceAPI
+		anchors []*time.Time
+		matches [][]repository.IncomeReceipt
+	)
+	for _, src := range sources {
+		if !src.IsActive {
+			continue
+		}
+		catID, err := uuid.Parse(src.CategoryID)
+		if err != nil {
+			continue
+		}
+		var accID *uuid.UUID
+		if src.AccountID != "" {
+			if a, err := uuid.Parse(src.AccountID); err == nil {
+				accID = &a
+			}
+		}
+		receipts, err := s.incRepo.MatchingIncome(userID, catID, accID, rangeFrom, rangeTo)
+		if err != nil {
+			return nil, err
+		}
+
+		var anchor *time.Time
+		if src.NextExpectedDate != "" {
+			if t, err := time.Parse(dateLayout, src.NextExpectedDate); err == nil {
+				anchor = &t
+			}
+		}
+		active = append(active, src)
+		anchors = append(anchors, anchor)
+		matches = append(matches, receipts)
+	}
+
+	paydays := make([]map[string]int, len(active))
+	for i, src := range active {
+		paydays[i] = make(map[string]int, len(months))
+		for _, m := range months {
+			paydays[i][m.Format("2006-01")], _ = incomeSchedule(src, anchors[i], m)
+		}
+	}
+	actuals := assignIncome(active, paydays, matches)
+
+	for i, src := range active {
+		rep := models.IncomeSourceReportAPI{Source: src}
+		for _, m := range months {
+			row := incomeMonth(src, anchors[i], m, actuals[i][m.Format("2006-01")], today)
+			rep.TotalExpectedCents += row.ExpectedCents
+			rep.TotalActualCents += row.ActualCents
+			rep.Months = append(rep.Months, row)
+		}
+		out.Sources = append(out.Sources, rep)
+	}
+	return out, nil
+}
+
+// incomeActual is the received income assigned to a source for one month.
+type incomeActual struct {
+	TotalCents int64
+	Count      int
+	FirstDate  string
+}
+
+// assignIncome gives each income transaction to exactly one of the sources it matches, so sources that
+// share a category and account (Gapok and Tukin both paid into one account) do not each count the same
+// money. In each month a source first takes the transactions closest to its expected amount, up to its
+// number of paydays; transactions left over go to the matching source with the closest amount.
+// The result holds one map per source, keyed by YYYY-MM.
+func assignIncome(sources []models.IncomeSourceAPI, paydays []map[string]int, matches [][]repository.IncomeReceipt) []map[string]incomeActual {
+	type claim struct {
+		source  int
+		receipt repository.IncomeReceipt
+		gap     int64
+	}
+	var claims []claim
+	for i, receipts := range matches {
+		for _, rc := range receipts {
+			gap := rc.Amount - sources[i].Amount
+			if gap < 0 {
+				gap = -gap
+			}
+			claims = append(claims, claim{source: i, receipt: rc, gap: gap})
+		}
+	}
+	sort.SliceStable(claims, func(a, b int) bool {
+		if claims[a].gap != claims[b].gap {
+			return claims[a].gap < claims[b].gap
+		}
+		return claims[a].receipt.Date < claims[b].receipt.Date
+	})
+
+	out := make([]map[string]incomeActual, len(sources))
+	for i := range out {
+		out[i] = make(map[string]incomeActual)
+	}
+	taken := make(map[string]bool)
+	filled := make([]map[string]int, len(sources))
+	for i := range filled {
+		filled[i] = make(map[string]int)
+	}
+	give := func(c claim) {
+		month := c.receipt.Date[:7]
+		a := out[c.source][month]
+		a.TotalCents += c.receipt.Amount
+		a.Count++
+		if a.FirstDate == "" || c.receipt.Date < a.FirstDate {
+			a.FirstDate = c.receipt.Date
+		}
+		out[c.source][month] = a
+		filled[c.source][month]++
+		taken[c.receipt.ID] = true
+	}
+	for _, c := range claims {
+		month := c.receipt.Date[:7]
+		if !taken[c.receipt.ID] && filled[c.source][month] < paydays[c.source][month] {
+			give(c)
+		}
+	}
+	for _, c := range claims {
+		if !taken[c.receipt.ID] {
+			give(c)
+		}
+	}
+	return out
+}
+
+// incomeMonth builds one comparison row. The expected date is the last scheduled payday in the month,
+// so a payment counts as late only when it arrives (or is still missing) after that day.
+func incomeMonth(src models.IncomeSourceAPI, anchor *time.Time, month time.Time, actual incomeActual, today string) models.IncomeMonthAPI {
+	row := models.IncomeMonthAPI{
+		Month:             month.Format("2006-01"),
+		ActualCents:       actual.TotalCents,
+		TransactionCount:  actual.Count,
+		FirstReceivedDate: actual.FirstDate,
+	}
+	occurrences, expected := incomeSchedule(src, anchor, month)
+	if occurrences == 0 {
+		row.Status = "unscheduled"
+		if actual.Count > 0 {
+			row.Status = "received"
+		}
+		row.DifferenceCents = row.ActualCents
+		return row
+	}
+
+	row.ExpectedCents = src.Amount * int64(occurrences)
+	row.ExpectedDate = expected.Format(dateLayout)
+	row.DifferenceCents = row.ActualCents - row.ExpectedCents
+	due := row.ExpectedDate <= today
+
+	switch {
+	case actual.Count == 0 && !due:
+		row.Status = "pending"
+	case actual.Count == 0:
+		row.Status = "missing"
+		row.IsLate = true
+	case row.ActualCents < row.ExpectedCents && !due:
+		row.Status = "pending"
+	case row.ActualCents < row.ExpectedCents:
+		row.Status = "short"
+		row.IsShort = true
+		row.IsLate = actual.FirstDate > row.ExpectedDate
+	case actual.FirstDate > row.ExpectedDate:
+		row.Status = "late"
+		row.IsLate = true
+	default:
+		row.Status = "received"
+	}
+	return row
+}
+
+// incomeSchedule counts a source's paydays in month and returns the last of them.
+func incomeSchedule(src models.IncomeSourceAPI, anchor *time.Time, month time.Time) (occurrences int, expected time.Time) {
+	lastDay := month.AddDate(0, 1, -1)
+	switch src.Frequency {
+	case "daily":
+		occurrences, expected = lastDay.Day(), lastDay
+	case "weekly":
+		weekday := lastDay.Weekday()
+		if anchor != nil {
+			weekday = anchor.Weekday()
+		}
+		for d := month; !d.After(lastDay); d = d.AddDate(0, 0, 1) {
+			if d.Weekday() == weekday {
+				occurrences++
+				expected = d
+			}
+		}
+	case "monthly":
+		occurrences, expected = 1, lastDay
+		if anchor != nil {
+			expected = clampDay(month.Year(), month.Month(), anchor.Day())
+		}
+	case "yearly":
+		if anchor != nil && anchor.Month() == month.Month() {
+			occurrences, expected = 1, clampDay(month.Year(), month.Month(), anchor.Day())
+		}
+	}
+	return occurrences, expected
+}
@@ -0,0 +1,509 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"monman-backend/internal/models"
+	"monman-backend/internal/repository"
+	"monman-backend/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+// defaultDividendCategoryID is the system "Dividen Saham" income category.
+var defaultDividendCategoryID = uuid.MustParse("60e931cd-772a-4df5-a902-b9ad0a372080")
+
+var instrumentTypes = map[string]bool{
+	"mutual_fund": true, "stock": true, "gold": true, "bond": true, "other": true,
+}
+
+// InvestmentService manages instruments and their prices, the trades of investment accounts, and the
+// holdings and gain reports replayed from them.
+type InvestmentService struct {
+	invRepo  *repository.InvestmentRepository
+	accRepo  *repository.AccountRepository
+	catRepo  *repository.CategoryRepository
+	rateRepo *repository.ExchangeRateRepository
+}
+
+func NewInvestmentService(
+	invRepo *repository.InvestmentRepository,
+	accRepo *repository.AccountRepository,
+	catRepo *repository.CategoryRepository,
+	rateRepo *repository.ExchangeRateRepository,
+) *InvestmentService {
+	return &InvestmentService{
+		invRepo:  invRepo,
+		accRepo:  accRepo,
+		catRepo:  catRepo,
+		rateRepo: rateRepo,
+	}
+}
+
+// ListInstruments returns the user's instruments with their latest prices.
+func (s *InvestmentService) ListInstruments(userID uuid.UUID) (*models.InstrumentsPayload, error) {
+	list, err := s.invRepo.ListInstruments(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.InstrumentsPayload{Instruments: list}, nil
+}
+
+// CreateInstrument validates and stores a new instrument; its currency defaults to the base currency.
+func (s *InvestmentService) CreateInstrument(userID uuid.UUID, req *models.CreateInstrumentRequest) (*models.InstrumentAPI, error) {
+	p := repository.InstrumentParams{
+		Symbol:         strings.ToUpper(strings.TrimSpace(req.Symbol)),
+		Name:           strings.TrimSpace(req.Name),
+		InstrumentType: strings.ToLower(strings.TrimSpace(req.InstrumentType)),
+		Currency:       utils.NormalizeCurrency(req.Currency),
+		UnitLabel:      strings.TrimSpace(req.UnitLabel),
+	}
+	if p.Symbol == "" || len(p.Symbol) > 20 {
+		return nil, validationError{"symbol is required (max 20 characters)"}
+	}
+	if p.Name == "" {
+		p.Name = p.Symbol
+	}
+	if len(p.Name) > 100 {
+		return nil, validationError{"name must be at most 100 characters"}
+	}
+	if !instrumentTypes[p.InstrumentType] {
+		return nil, validationError{"instrument_type must be mutual_fund, stock, gold, bond or other"}
+	}
+	if p.Currency == "" {
+		base, err := s.rateRepo.BaseCurrency(userID)
+		if err != nil {
+			return nil, err
+		}
+		p.Currency = base
+	}
+	if !utils.ValidCurrency(p.Currency) {
+		return nil, validationError{"currency is not supported"}
+	}
+	if p.UnitLabel == "" {
+		p.UnitLabel = "unit"
+	}
+	if len(p.UnitLabel) > 20 {
+		return nil, validationError{"unit_label must be at most 20 characters"}
+	}
+	id, err := s.invRepo.CreateInstrument(userID, p)
+	if errors.Is(err, repository.ErrInstrumentExists) {
+		return nil, validationError{err.Error()}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.invRepo.GetInstrument(userID, id)
+}
+
+// DeleteInstrument removes an instrument without trades, with its prices.
+func (s *InvestmentService) DeleteInstrument(userID, id uuid.UUID) error {
+	err := s.invRepo.DeleteInstrument(userID, id)
+	switch {
+	case errors.Is(err, repository.ErrInstrumentNotFound):
+		return validationError{"instrument not found"}
+	case errors.Is(err, repository.ErrInstrumentInUse):
+		return validationError{"instrument has trades; delete them first"}
+	}
+	return err
+}
+
+// Prices returns the instrument's price table, newest first.
+func (s *InvestmentService) Prices(userID, instrumentID uuid.UUID) (*models.InstrumentPricesPayload, error) {
+	in, err := s.instrument(userID, instrumentID)
+	if err != nil {
+		return nil, err
+	}
+	prices, err := s.invRepo.ListPrices(instrumentID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.InstrumentPricesPayload{Instrument: *in, Prices: prices}, nil
+}
+
+// SavePrice stores the instrument's price for a date (default today), replacing one already there.
+func (s *InvestmentService) SavePrice(userID, instrumentID uuid.UUID, req *models.SaveInstrumentPriceRequest, now time.Time) (*models.InstrumentPricesPayload, error) {
+	in, err := s.instrument(userID, instrumentID)
+	if err != nil {
+		return nil, err
+	}
+	date := strings.TrimSpace(req.PriceDate)
+	if date == "" {
+		date = now.Format(dateLayout)
+	}
+	if _, err := time.Parse(dateLayout, date); err != nil {
+		return nil, validationError{"price_date must be YYYY-MM-DD"}
+	}
+	if req.Price < 0 {
+		return nil, validationError{"price must not be negative"}
+	}
+	if !utils.ValidCurrencyAmount(req.Price, in.Currency) {
+		return nil, validationError{in.Currency + " has no minor unit; price must be a multiple of 100"}
+	}
+	if err := s.invRepo.SavePrice(instrumentID, date, req.Price); err != nil {
+		return nil, err
+	}
+	return s.Prices(userID, instrumentID)
+}
+
+// DeletePrice removes one price of the instrument.
+func (s *InvestmentService) DeletePrice(userID, instrumentID, priceID uuid.UUID) error {
+	if _, err := s.instrument(userID, instrumentID); err != nil {
+		return err
+	}
+	err := s.invRepo.DeletePrice(instrumentID, priceID)
+	if errors.Is(err, repository.ErrInstrumentPriceNotFound) {
+		return validationError{"price not found"}
+	}
+	return err
+}
+
+func (s *InvestmentService) instrument(userID, id uuid.UUID) (*models.InstrumentAPI, error) {
+	in, err := s.invRepo.GetInstrument(userID, id)
+	if errors.Is(err, repository.ErrInstrumentNotFound) {
+		return nil, validationError{"instrument not found"}
+	}
+	return in, err
+}
+
+// ListTrades returns the trades of one of the user's accounts, newest first.
+func (s *InvestmentService) ListTrades(userID, accountID uuid.UUID) (*models.InvestmentTradesPayload, error) {
+	if _, err := s.accRepo.GetForUser(accountID, userID); errors.Is(err, repository.ErrAccountNotFound) {
+		return nil, validationError{"account not found"}
+	} else if err != nil {
+		return nil, err
+	}
+	trades, err := s.invRepo.ListTrades(userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.InvestmentTradesPayload{Trades: trades}, nil
+}
+
+// CreateTrade validates a buy, sell or dividend on an active investment account and records it with
+// its cash movement. Trades are in the account's currency, so the instrument must be priced in it.
+func (s *InvestmentService) CreateTrade(userID, accountID uuid.UUID, req *models.CreateInvestmentTradeRequest, now time.Time) (uuid.UUID, error) {
+	acc, err := s.accRepo.GetForUser(accountID, userID)
+	if errors.Is(err, repository.ErrAccountNotFound) {
+		return uuid.Nil, validationError{"account not found"}
+	}
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !acc.IsActive {
+		return uuid.Nil, validationError{"account is archived"}
+	}
+	if acc.AccountType != "investment" {
+		return uuid.Nil, validationError{"trades can only be recorded on investment accounts"}
+	}
+	if req.InstrumentID == uuid.Nil {
+		return uuid.Nil, validationError{"instrument_id is required"}
+	}
+	in, err := s.instrument(userID, req.InstrumentID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if in.Currency != acc.Currency {
+		return uuid.Nil, validationError{"instrument is priced in " + in.Currency + " but the account holds " + acc.Currency}
+	}
+
+	p := repository.TradeParams{
+		UserID:       userID,
+		AccountID:    accountID,
+		InstrumentID: req.InstrumentID,
+		TradeType:    strings.ToLower(strings.TrimSpace(req.TradeType)),
+		Date:         strings.TrimSpace(req.TradeDate),
+		Units:        req.Units,
+		Amount:       req.Amount,
+		Fee:          req.Fee,
+		Notes:        req.Notes,
+	}
+	if p.Date == "" {
+		p.Date = now.Format(dateLayout)
+	}
+	if _, err := time.Parse(dateLayout, p.Date); err != nil {
+		return uuid.Nil, validationError{"trade_date must be YYYY-MM-DD"}
+	}
+	switch p.TradeType {
+	case "buy", "sell":
+		if !(p.Units > 0) || math.IsInf(p.Units, 1) {
+			return uuid.Nil, validationError{"units must be a positive number"}
+		}
+	case "dividend":
+		if p.Units != 0 {
+			return uuid.Nil, validationError{"dividends carry no units"}
+		}
+		p.CategoryID = defaultDividendCategoryID
+		if req.CategoryID != nil {
+			ctype, ok, err := s.catRepo.CategoryOwnedOrSystem(*req.CategoryID, userID)
+			if err != nil {
+				return uuid.Nil, err
+			}
+			if !ok || ctype != "income" {
+				return uuid.Nil, validationError{"category_id must be an income category"}
+			}
+			p.CategoryID = *req.CategoryID
+		}
+	default:
+		return uuid.Nil, validationError{"trade_type must be buy, sell or dividend"}
+	}
+	if p.Amount <= 0 {
+		return uuid.Nil, validationError{"amount must be positive"}
+	}
+	if p.Fee < 0 {
+		return uuid.Nil, validationError{"fee must not be negative"}
+	}
+	if p.TradeType != "buy" && p.Fee > p.Amount {
+		return uuid.Nil, validationError{"fee must not exceed amount"}
+	}
+	if !utils.ValidCurrencyAmount(p.Amount, acc.Currency) || !utils.ValidCurrencyAmount(p.Fee, acc.Currency) {
+		return uuid.Nil, validationError{acc.Currency + " has no minor unit; amount and fee must be multiples of 100"}
+	}
+	p.Description = map[string]string{"buy": "Beli ", "sell": "Jual ", "dividend": "Dividen "}[p.TradeType] + in.Symbol
+
+	id, err := s.invRepo.CreateTrade(p)
+	if errors.Is(err, repository.ErrOversold) {
+		return uuid.Nil, validationError{err.Error()}
+	}
+	return id, err
+}
+
+// DeleteTrade removes a trade and its cash movement, replaying the holding without it.
+func (s *InvestmentService) DeleteTrade(userID, id uuid.UUID) error {
+	err := s.invRepo.DeleteTrade(userID, id)
+	switch {
+	case errors.Is(err, repository.ErrTradeNotFound):
+		return validationError{"trade not found"}
+	case errors.Is(err, repository.ErrOversold):
+		return validationError{"without this trade, a " + err.Error()}
+	}
+	return reconciledError(err)
+}
+
+// Holdings returns every current holding valued at its latest price up to today (at cost without one),
+// with per-account totals in the account currency and overall totals in the base currency.
+func (s *InvestmentService) Holdings(userID uuid.UUID, now time.Time) (*models.InvestmentsPayload, error) {
+	date := now.Format(dateLayout)
+	base, err := s.rateRepo.BaseCurrency(userID)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := s.invRepo.InvestmentAccounts(userID)
+	if err != nil {
+		return nil, err
+	}
+	holdings, err := s.invRepo.Holdings(userID, date)
+	if err != nil {
+		return nil, err
+	}
+	out := &models.InvestmentsPayload{Date: date, Currency: base, Accounts: accounts, Holdings: holdings}
+	byAccount := make(map[string]*models.InvestmentAccountAPI, len(accounts))
+	for i := range out.Accounts {
+		byAccount[out.Accounts[i].AccountID] = &out.Accounts[i]
+	}
+	conv := newBaseConverter(s.rateRepo, userID, base)
+	for i := range out.Holdings {
+		h := &out.Holdings[i]
+		valueHolding(h, h.Price)
+		if a := byAccount[h.AccountID]; a != nil {
+			a.CostBasis += h.CostBasis
+			a.MarketValue += h.MarketValue
+		}
+		cost, err := conv.convert(h.CostBasis, h.Currency, date)
+		if err != nil {
+			return nil, err
+		}
+		value, err := conv.convert(h.MarketValue, h.Currency, date)
+		if err != nil {
+			return nil, err
+		}
+		out.TotalCostBasis += cost
+		out.TotalMarketValue += value
+	}
+	for i := range out.Accounts {
+		out.Accounts[i].Total = out.Accounts[i].Cash + out.Accounts[i].MarketValue
+	}
+	out.UnrealizedGain = out.TotalMarketValue - out.TotalCostBasis
+	return out, nil
+}
+
+// Gains replays every trade up to to and reports the gains realized and dividends received between from
+// and to (YYYY-MM-DD, inclusive; default the start of to's year up to today), and unrealized gains at to
+// using the price effective that day. Realized gains and dividends are converted into the base currency
+// at their trade date, unrealized gains at to.
+func (s *InvestmentService) Gains(userID uuid.UUID, from, to string, now time.Time) (*models.InvestmentGainsPayload, error) {
+	end := startOfDay(now)
+	if to = strings.TrimSpace(to); to != "" {
+		t, err := time.Parse(dateLayout, to)
+		if err != nil {
+			return nil, validationError{"to must be YYYY-MM-DD"}
+		}
+		end = t
+	}
+	start := time.Date(end.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	if from = strings.TrimSpace(from); from != "" {
+		f, err := time.Parse(dateLayout, from)
+		if err != nil {
+			return nil, validationError{"from must be YYYY-MM-DD"}
+		}
+		start = f
+	}
+	if start.After(end) {
+		return nil, validationError{"from must not be after to"}
+	}
+	from, to = start.Format(dateLayout), end.Format(dateLayout)
+
+	base, err := s.rateRepo.BaseCurrency(userID)
+	if err != nil {
+		return nil, err
+	}
+	instruments, err := s.invRepo.ListInstruments(userID)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := s.invRepo.InvestmentAccounts(userID)
+	if err != nil {
+		return nil, err
+	}
+	trades, err := s.invRepo.Trades(userID, to)
+	if err != nil {
+		return nil, err
+	}
+	prices, err := s.invRepo.Prices(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	accountByID := make(map[string]models.InvestmentAccountAPI, len(accounts))
+	for _, a := range accounts {
+		accountByID[a.AccountID] = a
+	}
+
+	out := &models.InvestmentGainsPayload{From: from, To: to, Currency: base, Holdings: []models.HoldingAPI{}}
+	conv := newBaseConverter(s.rateRepo, userID, base)
+	type key struct{ account, instrument string }
+	positions := make(map[key]*repository.Position)
+	inRange := make(map[key]*models.HoldingAPI)
+	for _, t := range trades {
+		k := key{t.AccountID, t.InstrumentID}
+		pos := positions[k]
+		if pos == nil {
+			pos = &repository.Position{}
+			positions[k] = pos
+			inRange[k] = &models.HoldingAPI{}
+		}
+		gain, err := pos.Apply(t)
+		if err != nil {
+			return nil, err
+		}
+		if t.Date < from {
+			continue
+		}
+		h := inRange[k]
+		var total *int64
+		switch t.Type {
+		case "sell":
+			h.RealizedGain += gain
+			total = &out.RealizedGain
+		case "dividend":
+			gain = t.Amount - t.Fee
+			h.Dividends += gain
+			total = &out.Dividends
+		default:
+			continue
+		}
+		converted, err := conv.convert(gain, accountByID[t.AccountID].Currency, t.Date)
+		if err != nil {
+			return nil, err
+		}
+		*total += converted
+	}
+
+	instrumentByID := make(map[string]models.InstrumentAPI, len(instruments))
+	for _, in := range instruments {
+		instrumentByID[in.ID] = in
+	}
+	for k, pos := range positions {
+		h := inRange[k]
+		if pos.Units <= 0 && h.RealizedGain == 0 && h.Dividends == 0 {
+			continue
+		}
+		in, acc := instrumentByID[k.instrument], accountByID[k.account]
+		h.AccountID, h.AccountName = k.account, acc.Name
+		h.InstrumentID, h.Symbol, h.Name = k.instrument, in.Symbol, in.Name
+		h.InstrumentType, h.UnitLabel, h.Currency = in.InstrumentType, in.UnitLabel, acc.Currency
+		h.Units, h.CostBasis = pos.Units, pos.CostBasis
+		var price *int64
+		if p, ok := repository.PriceAt(prices[k.instrument], to); ok {
+			price, h.PriceDate = &p.Price, p.Date
+		}
+		valueHolding(h, price)
+
+		unrealized, err := conv.convert(h.UnrealizedGain, h.Currency, to)
+		if err != nil {
+			return nil, err
+		}
+		out.UnrealizedGain += unrealized
+		out.Holdings = append(out.Holdings, *h)
+	}
+	sort.Slice(out.Holdings, func(i, j int) bool {
+		a, b := out.Holdings[i], out.Holdings[j]
+		if a.AccountName != b.AccountName {
+			return a.AccountName < b.AccountName
+		}
+		return a.Symbol < b.Symbol
+	})
+	return out, nil
+}
+
+// valueHolding fills the average cost and, at price or else at cost, the market value and unrealized
+// gain of h.
+func valueHolding(h *models.HoldingAPI, price *int64) {
+	h.Price = price
+	h.AverageCost = 0
+	if h.Units > 0 {
+		h.AverageCost = int64(math.Round(float64(h.CostBasis) / h.Units))
+	}
+	h.MarketValue = holdingValue(h.Units, h.CostBasis, price, h.Currency)
+	h.UnrealizedGain = h.MarketValue - h.CostBasis
+}
+
+// holdingValue is units at price in currency, or the cost basis when there is no price.
+func holdingValue(units float64, costBasis int64, price *int64, currency string) int64 {
+	if price == nil {
+		return costBasis
+	}
+	return utils.ConvertCents(*price, units, currency)
+}
+
+// baseConverter converts amounts into the base currency, caching the rate per currency and date.
+type baseConverter struct {
+	rateRepo *repository.ExchangeRateRepository
+	userID   uuid.UUID
+	base     string
+	rates    map[string]float64
+}
+
+func newBaseConverter(rateRepo *repository.ExchangeRateRepository, userID uuid.UUID, base string) *baseConverter {
+	return &baseConverter{rateRepo: rateRepo, userID: userID, base: base, rates: make(map[string]float64)}
+}
+
+func (c *baseConverter) convert(cents int64, currency, date string) (int64, error) {
+	if currency == c.base || cents == 0 {
+		return cents, nil
+	}
+	key := currency + date
+	rate, ok := c.rates[key]
+	if !ok {
+		var err error
+		if rate, err = c.rateRepo.Rate(c.userID, currency, c.base, date); err != nil {
+			return 0, exchangeRateError(err)
+		}
+		c.rates[key] = rate
+	}
+	return utils.ConvertCents(cents, rate, c.base), nil
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"sort"
+	"strings"
+
+	"monman-backend/internal/exporter"
+	"monman-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	journalOpeningAccount    = "Equity:Opening-Balances"
+	journalTransfersAccount  = "Equity:Transfers" // balances a transfer leg whose other side is outside the range
+	journalAdjustmentAccount = "Equity:Reconciliation-Adjustments"
+	journalMaxCategoryDepth  = 8
+)
+
+// journalAccountRoots maps account_type to its place in the account tree.
+var journalAccountRoots = map[string]string{
+	"bank":        "Assets:Bank",
+	"ewallet":     "Assets:E-Wallet",
+	"cash":        "Assets:Cash",
+	"investment":  "Assets:Investment",
+	"credit_card": "Liabilities:Credit-Card",
+}
+
+// Journal builds the double-entry journal for the ledger and beancount exports. from/to (YYYY-MM-DD,
+// either may be empty) limit the transactions; each account's balance before from becomes an opening
+// entry against Equity:Opening-Balances. Both legs of a transfer become one entry. Postings are in the
+// account's currency; a transfer between currencies is balanced per currency through Equity:Transfers.
+func (s *DataExportService) Journal(userID uuid.UUID, from, to string) (*exporter.Journal, error) {
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+	if err := validateExportRange(from, to); err != nil {
+		return nil, err
+	}
+	accounts, err := s.expRepo.JournalAccounts(userID)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.expRepo.JournalCategories(userID)
+	if err != nil {
+		return nil, err
+	}
+	txs, err := s.expRepo.JournalTransactions(userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	sums, err := s.expRepo.AmountsSince(userID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := s.expRepo.BaseCurrency(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	b := journalBuilder{
+		names:       make(map[string]string),
+		taken:       make(map[string]bool),
+		opened:      make(map[string]string),
+		ids:         make(map[string]string),
+		commodities: make(map[string]map[string]bool),
+	}
+	currency := make(map[string]string, len(accounts)) // account id -> currency
+	for _, a := range accounts {
+		currency[a.ID] = a.Currency
+		root, ok := journalAccountRoots[a.AccountType]
+		if !ok {
+			root = "Assets:Other"
+		}
+		b.declare(a.ID, root+":"+exporter.AccountSegment(a.Name), a.CreatedOn)
+		b.commodities[b.names[a.ID]] = map[string]bool{a.Currency: true}
+	}
+	byID := make(map[string]repository.JournalCategory, len(categories))
+	for _, c := range categories {
+		byID[c.ID] = c
+	}
+	for _, c := range categories {
+		b.declare(c.ID, categoryAccountName(c, byID), c.CreatedOn)
+	}
+
+	j := &exporter.Journal{From: from, To: to, Commodity: base}
+
+	firstDate := make(map[string]string)
+	for _, t := range txs {
+		if _, ok := firstDate[t.AccountID]; !ok {
+			firstDate[t.AccountID] = t.Date
+		}
+	}
+	for _, a := range accounts {
+		opening := a.Balance - sums[a.ID]
+		if opening == 0 {
+			continue
+		}
+		date := from
+		if date == "" {
+			date = a.CreatedOn
+			if d, ok := firstDate[a.ID]; ok && d < date {
+				date = d
+			}
+		}
+		j.Entries = append(j.Entries, b.entry(exporter.JournalEntry{
+			ID:          "opening-" + a.ID,
+			Date:        date,
+			Description: "Opening balance",
+			Postings: []exporter.JournalPosting{
+				{Account: b.names[a.ID], Amount: opening, Commodity: a.Currency},
+				{Account: journalOpeningAccount, Amount: -opening, Commodity: a.Currency},
+			},
+		}))
+	}
+
+	legs := make(map[string][]repository.JournalTransaction)
+	for _, t := range txs {
+		if t.TransferID != "" {
+			legs[t.TransferID] = append(legs[t.TransferID], t)
+		}
+	}
+	for _, t := range txs {
+		e := exporter.JournalEntry{ID: t.ID, Date: t.Date, Description: t.Description, Notes: t.Notes}
+		c := currency[t.AccountID]
+		switch {
+		case t.TransferID != "":
+			group := legs[t.TransferID]
+			if group[0].ID != t.ID {
+				continue // written with the first leg
+			}
+			e.ID = t.TransferID
+			sums := make(map[string]int64)
+			var order []string
+			for _, leg := range group {
+				c := currency[leg.AccountID]
+				e.Postings = append(e.Postings, exporter.JournalPosting{
+					Account: b.names[leg.AccountID], Amount: leg.Amount, Commodity: c})
+				if _, ok := sums[c]; !ok {
+					order = append(order, c)
+				}
+				sums[c] += leg.Amount
+			}
+			for _, c := range order {
+				if sums[c] != 0 {
+					e.Postings = append(e.Postings, exporter.JournalPosting{
+						Account: journalTransfersAccount, Amount: -sums[c], Commodity: c})
+				}
+			}
+		case t.Opening:
+			e.Postings = []exporter.JournalPosting{
+				{Account: b.names[t.AccountID], Amount: t.Amount, Commodity: c},
+				{Account: journalOpeningAccount, Amount: -t.Amount, Commodity: c},
+			}
+		case t.Adjustment:
+			e.Postings = []exporter.JournalPosting{
+				{Account: b.names[t.AccountID], Amount: t.Amount, Commodity: c},
+				{Account: journalAdjustmentAccount, Amount: -t.Amount, Commodity: c},
+			}
+		case t.Type == "transfer":
+			e.Postings = []exporter.JournalPosting{
+				{Account: b.names[t.AccountID], Amount: t.Amount, Commodity: c},
+				{Account: journalTransfersAccount, Amount: -t.Amount, Commodity: c},
+			}
+		default:
+			counter, ok := b.names[t.CategoryID]
+			if !ok {
+				counter = "Expenses:Uncategorized"
+				if t.Type == "income" {
+					counter = "Income:Uncategorized"
+				}
+			}
+			e.Postings = []exporter.JournalPosting{
+				{Account: counter, Amount: -t.Amount, Commodity: c},
+				{Account: b.names[t.AccountID], Amount: t.Amount, Commodity: c},
+			}
+		}
+		j.Entries = append(j.Entries, b.entry(e))
+	}
+	// Opening entries were added first, so on a shared date they stay ahead of that day's transactions.
+	sort.SliceStable(j.Entries, func(a, c int) bool { return j.Entries[a].Date < j.Entries[c].Date })
+
+	for name, opened := range b.opened {
+		a := exporter.JournalAccount{Name: name, Opened: opened, ID: b.ids[name], Commodity: base}
+		switch used := b.commodities[name]; len(used) {
+		case 0:
+		case 1:
+			for c := range used {
+				a.Commodity = c
+			}
+		default:
+			a.Commodity = "" // holds several currencies
+		}
+		j.Accounts = append(j.Accounts, a)
+	}
+	sort.Slice(j.Accounts, func(a, c int) bool { return j.Accounts[a].Name < j.Accounts[c].Name })
+	return j, nil
+}
+
+// journalBuilder assigns unique account names and tracks the earliest date each is used.
+type journalBuilder struct {
+	names       map[string]string // MonMan id -> account name
+	taken       map[string]bool
+	opened      map[string]string          // account name -> open date
+	ids         map[string]string          // account name -> MonMan id
+	commodities map[string]map[string]bool // account name -> commodities posted to it
+}
+
+// declare names the account for id; a clash with an earlier name gets the id's first block appended.
+func (b *journalBuilder) declare(id, name, created string) {
+	if b.taken[name] {
+		name += "-" + strings.SplitN(id, "-", 2)[0]
+	}
+	b.taken[name] = true
+	b.names[id] = name
+	b.ids[name] = id
+	b.opened[name] = created
+}
+
+// entry moves the open date of every account the entry posts to back to the entry's date if needed.
+func (b *journalBuilder) entry(e exporter.JournalEntry) exporter.JournalEntry {
+	for _, p := range e.Postings {
+		if d, ok := b.opened[p.Account]; !ok || e.Date < d {
+			b.opened[p.Account] = e.Date
+		}
+		if b.commodities[p.Account] == nil {
+			b.commodities[p.Account] = make(map[string]bool)
+		}
+		b.commodities[p.Account][p.Commodity] = true
+	}
+	return e
+}
+
+// categoryAccountName places a category under Expenses or Income below its parents.
+func categoryAccountName(c repository.JournalCategory, byID map[string]repository.JournalCategory) string {
+	segments := []string{exporter.AccountSegment(c.Name)}
+	for p, depth := c.ParentID, 0; p != "" && depth < journalMaxCategoryDepth; depth++ {
+		parent, ok := byID[p]
+		if !ok {
+			break
+		}
+		segments = append([]string{exporter.AccountSegment(parent.Name)}, segments...)
+		p = parent.ParentID
+	}
+	root := "Expenses"
+	if c.CategoryType == "income" {
+		root = "Income"
+	}
+	return root + ":" + strings.Join(segments, ":")
+}
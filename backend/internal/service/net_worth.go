@@ -0,0 +1,189 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"monman-backend/internal/models"
+	"monman-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// maxNetWorthPoints bounds GET /api/reports/net-worth (a bit over a year of days).
+const maxNetWorthPoints = 400
+
+// NetWorthService rebuilds account balances over time for the net-worth report.
+type NetWorthService struct {
+	histRepo *repository.BalanceHistoryRepository
+	invRepo  *repository.InvestmentRepository
+	rateRepo *repository.ExchangeRateRepository
+}
+
+func NewNetWorthService(
+	histRepo *repository.BalanceHistoryRepository,
+	invRepo *repository.InvestmentRepository,
+	rateRepo *repository.ExchangeRateRepository,
+) *NetWorthService {
+	return &NetWorthService{histRepo: histRepo, invRepo: invRepo, rateRepo: rateRepo}
+}
+
+// Report returns net worth at the end of each day, week (Monday to Sunday) or month between from and
+// to (YYYY-MM-DD, inclusive; default the last 12 months up to today), with every account's balance at
+// the same points. Credit cards count as liabilities, every other account as an asset. Balances in other
+// currencies are valued in the base currency at the rate effective on each point's date. An account's
+// investment holdings are added at their market value on each date (at cost before the first price).
+func (s *NetWorthService) Report(userID uuid.UUID, from, to, interval string, now time.Time) (*models.NetWorthPayload, error) {
+	interval = strings.ToLower(strings.TrimSpace(interval))
+	if interval == "" {
+		interval = "month"
+	}
+	if interval != "day" && interval != "week" && interval != "month" {
+		return nil, validationError{"interval must be day, week or month"}
+	}
+	end := startOfDay(now)
+	if to = strings.TrimSpace(to); to != "" {
+		t, err := time.Parse(dateLayout, to)
+		if err != nil {
+			return nil, validationError{"to must be YYYY-MM-DD"}
+		}
+		end = t
+	}
+	var start time.Time
+	switch interval {
+	case "day":
+		start = end.AddDate(0, 0, -29)
+	case "week":
+		start = end.AddDate(0, 0, -7*12+1)
+	default:
+		start = time.Date(end.Year(), end.Month()-11, 1, 0, 0, 0, 0, time.UTC)
+	}
+	if from = strings.TrimSpace(from); from != "" {
+		f, err := time.Parse(dateLayout, from)
+		if err != nil {
+			return nil, validationError{"from must be YYYY-MM-DD"}
+		}
+		start = f
+	}
+	if start.After(end) {
+		return nil, validationError{"from must not be after to"}
+	}
+	ends := intervalEnds(start, end, interval)
+	if len(ends) > maxNetWorthPoints {
+		return nil, validationError{"report range is limited to 400 points; use a longer interval"}
+	}
+
+	base, err := s.rateRepo.BaseCurrency(userID)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := s.histRepo.Accounts(userID)
+	if err != nil {
+		return nil, err
+	}
+	balances, err := s.histRepo.BalancesBefore(userID, start.Format(dateLayout))
+	if err != nil {
+		return nil, err
+	}
+	changes, err := s.histRepo.DailyChanges(userID, start.Format(dateLayout), end.Format(dateLayout))
+	if err != nil {
+		return nil, err
+	}
+	trades, err := s.invRepo.Trades(userID, end.Format(dateLayout))
+	if err != nil {
+		return nil, err
+	}
+	prices, err := s.invRepo.Prices(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &models.NetWorthPayload{
+		From:     start.Format(dateLayout),
+		To:       end.Format(dateLayout),
+		Interval: interval,
+		Currency: base,
+		Points:   make([]models.NetWorthPointAPI, 0, len(ends)),
+		Accounts: make([]models.AccountBalanceHistoryAPI, 0, len(accounts)),
+	}
+	for _, a := range accounts {
+		out.Accounts = append(out.Accounts, models.AccountBalanceHistoryAPI{
+			AccountID:   a.ID,
+			Name:        a.Name,
+			AccountType: a.AccountType,
+			Currency:    a.Currency,
+			IsLiability: a.AccountType == "credit_card",
+			IsActive:    a.IsActive,
+			Balances:    make([]int64, 0, len(ends)),
+		})
+	}
+	conv := newBaseConverter(s.rateRepo, userID, base)
+	holdings := make(map[string]map[string]*repository.Position) // account -> instrument
+	next, nextTrade := 0, 0
+	for _, e := range ends {
+		date := e.Format(dateLayout)
+		for ; next < len(changes) && changes[next].Date <= date; next++ {
+			balances[changes[next].AccountID] += changes[next].Amount
+		}
+		for ; nextTrade < len(trades) && trades[nextTrade].Date <= date; nextTrade++ {
+			t := trades[nextTrade]
+			if holdings[t.AccountID] == nil {
+				holdings[t.AccountID] = make(map[string]*repository.Position)
+			}
+			pos := holdings[t.AccountID][t.InstrumentID]
+			if pos == nil {
+				pos = &repository.Position{}
+				holdings[t.AccountID][t.InstrumentID] = pos
+			}
+			if _, err := pos.Apply(t); err != nil {
+				return nil, err
+			}
+		}
+		p := models.NetWorthPointAPI{Date: date}
+		for i := range out.Accounts {
+			a := &out.Accounts[i]
+			b := balances[a.AccountID]
+			for instrumentID, pos := range holdings[a.AccountID] {
+				var price *int64
+				if pp, ok := repository.PriceAt(prices[instrumentID], date); ok {
+					price = &pp.Price
+				}
+				b += holdingValue(pos.Units, pos.CostBasis, price, a.Currency)
+			}
+			if b, err = conv.convert(b, a.Currency, date); err != nil {
+				return nil, err
+			}
+			a.Balances = append(a.Balances, b)
+			if a.IsLiability {
+				p.Liabilities -= b
+			} else {
+				p.Assets += b
+			}
+		}
+		p.NetWorth = p.Assets - p.Liabilities
+		out.Points = append(out.Points, p)
+	}
+	return out, nil
+}
+
+// intervalEnds lists the last day of each interval touching [start, end], the final one cut at end.
+func intervalEnds(start, end time.Time, interval string) []time.Time {
+	var ends []time.Time
+	for d := start; !d.After(end) && len(ends) <= maxNetWorthPoints; d = d.AddDate(0, 0, 1) {
+		var e time.Time
+		switch interval {
+		case "day":
+			e = d
+		case "week":
+			e = d.AddDate(0, 0, (7-int(d.Weekday()))%7) // through Sunday
+		default:
+			e = time.Date(d.Year(), d.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+		}
+		if e.After(end) {
+			e = end
+		}
+		ends = append(ends, e)
+		d = e
+	}
+	return ends
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"monman-backend/internal/models"
+	"monman-backend/internal/repository"
+	"monman-backend/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+const maxReconciliationClearIDs = 500
+
+// ReconciliationService matches an account's transactions against bank statements.
+type ReconciliationService struct {
+	reconRepo *repository.ReconciliationRepository
+	accRepo   *repository.AccountRepository
+}
+
+func NewReconciliationService(reconRepo *repository.ReconciliationRepository, accRepo *repository.AccountRepository) *ReconciliationService {
+	return &ReconciliationService{reconRepo: reconRepo, accRepo: accRepo}
+}
+
+// Start opens a reconciliation for an active account. An account has at most one open reconciliation,
+// and a statement cannot end before the last completed one.
+func (s *ReconciliationService) Start(userID, accountID uuid.UUID, req *models.StartReconciliationRequest) (*models.ReconciliationPayload, error) {
+	ok, err := s.accRepo.AccountBelongs(accountID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, validationError{"account not found"}
+	}
+	date, err := s.statementDate(accountID, req.StatementDate)
+	if err != nil {
+		return nil, err
+	}
+	open, err := s.reconRepo.Latest(accountID, "open")
+	if err != nil {
+		return nil, err
+	}
+	if open != nil {
+		return nil, validationError{"account already has an open reconciliation (" + open.ID + ")"}
+	}
+	id, err := s.reconRepo.Create(userID, accountID, date, req.StatementBalance)
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(userID, id)
+}
+
+// Get returns a reconciliation with, while it is open, the rows still to be matched.
+func (s *ReconciliationService) Get(userID, id uuid.UUID) (*models.ReconciliationPayload, error) {
+	rec, err := s.reconRepo.Get(userID, id)
+	if err != nil {
+		return nil, reconciliationLookupError(err)
+	}
+	if err := s.fill(rec); err != nil {
+		return nil, err
+	}
+	out := &models.ReconciliationPayload{Reconciliation: *rec, Transactions: []models.ReconciliationTransactionAPI{}}
+	if rec.Status != "open" {
+		return out, nil
+	}
+	accountID, err := uuid.Parse(rec.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("parse account id: %w", err)
+	}
+	if out.Transactions, err = s.reconRepo.Transactions(accountID, rec.StatementDate); err != nil {
+		return nil, err
+	}
+	for _, t := range out.Transactions {
+		if t.Cleared {
+			out.ClearedCount++
+		} else {
+			out.UnclearedCount++
+		}
+	}
+	return out, nil
+}
+
+// List returns the account's reconciliations, newest statement first.
+func (s *ReconciliationService) List(userID, accountID uuid.UUID) (*models.ReconciliationsPayload, error) {
+	if _, err := s.accRepo.GetForUser(accountID, userID); err != nil {
+		return nil, accountLookupError(err)
+	}
+	list, err := s.reconRepo.ListForAccount(userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		if err := s.fill(&list[i]); err != nil {
+			return nil, err
+		}
+	}
+	return &models.ReconciliationsPayload{Reconciliations: list}, nil
+}
+
+// Update changes the statement date or balance of an open reconciliation.
+func (s *ReconciliationService) Update(userID, id uuid.UUID, req *models.UpdateReconciliationRequest) (*models.ReconciliationPayload, error) {
+	rec, err := s.openReconciliation(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	date, balance := rec.StatementDate, rec.StatementBalance
+	if req.StatementDate != nil {
+		accountID, err := uuid.Parse(rec.AccountID)
+		if err != nil {
+			return nil, fmt.Errorf("parse account id: %w", err)
+		}
+		if date, err = s.statementDate(accountID, *req.StatementDate); err != nil {
+			return nil, err
+		}
+	}
+	if req.StatementBalance != nil {
+		balance = *req.StatementBalance
+	}
+	if err := s.reconRepo.UpdateStatement(userID, id, date, balance); err != nil {
+		return nil, reconciliationLookupError(err)
+	}
+	return s.Get(userID, id)
+}
+
+// SetCleared marks rows of an open reconciliation as cleared (seen on the statement) or not. Every id
+// must be an unreconciled row of the account dated on or before the statement date.
+func (s *ReconciliationService) SetCleared(userID, id uuid.UUID, req *models.ClearReconciliationTransactionsRequest) (*models.ReconciliationPayload, error) {
+	rec, err := s.openReconciliation(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.TransactionIDs) == 0 {
+		return nil, validationError{"transaction_ids is required"}
+	}
+	if len(req.TransactionIDs) > maxReconciliationClearIDs {
+		return nil, validationError{fmt.Sprintf("at most %d transaction_ids per request", maxReconciliationClearIDs)}
+	}
+	seen := make(map[uuid.UUID]bool, len(req.TransactionIDs))
+	ids := make([]uuid.UUID, 0, len(req.TransactionIDs))
+	for _, tid := range req.TransactionIDs {
+		if !seen[tid] {
+			seen[tid] = true
+			ids = append(ids, tid)
+		}
+	}
+	accountID, err := uuid.Parse(rec.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("parse account id: %w", err)
+	}
+	err = s.reconRepo.SetCleared(accountID, rec.StatementDate, ids, req.Cleared)
+	if errors.Is(err, repository.ErrTransactionNotFound) {
+		return nil, validationError{"transaction_ids must be unreconciled transactions of this account dated on or before the statement date"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(userID, id)
+}
+
+// Complete closes an open reconciliation once the cleared balance matches the statement, locking the
+// cleared rows. With req.Adjust a remaining difference is posted as an adjustment row on the statement
+// date first.
+func (s *ReconciliationService) Complete(userID, id uuid.UUID, req *models.CompleteReconciliationRequest) (*models.ReconciliationPayload, error) {
+	rec, err := s.openReconciliation(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if rec.Difference != 0 && !req.Adjust {
+		acc, err := s.accRepo.GetForUser(uuid.MustParse(rec.AccountID), userID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, validationError{fmt.Sprintf(
+			"cleared balance differs from the statement by %s; clear the missing transactions or complete with adjust: true",
+			utils.FormatMoney(rec.Difference, acc.Currency))}
+	}
+	if err := s.reconRepo.Complete(userID, rec, rec.ClearedBalance, rec.Difference); err != nil {
+		return nil, reconciliationLookupError(err)
+	}
+	return s.Get(userID, id)
+}
+
+// Delete cancels an open reconciliation or undoes the account's latest completed one, unlocking its
+// rows and removing its adjustment.
+func (s *ReconciliationService) Delete(userID, id uuid.UUID) error {
+	rec, err := s.reconRepo.Get(userID, id)
+	if err != nil {
+		return reconciliationLookupError(err)
+	}
+	if rec.Status == "completed" {
+		accountID, err := uuid.Parse(rec.AccountID)
+		if err != nil {
+			return fmt.Errorf("parse account id: %w", err)
+		}
+		latest, err := s.reconRepo.Latest(accountID, "completed")
+		if err != nil {
+			return err
+		}
+		if latest == nil || latest.ID != rec.ID {
+			return validationError{"only the latest completed reconciliation of an account can be undone"}
+		}
+	}
+	return reconciliationLookupError(s.reconRepo.Delete(userID, id))
+}
+
+// fill sets ClearedBalance (live while open) and Difference.
+func (s *ReconciliationService) fill(rec *models.ReconciliationAPI) error {
+	if rec.Status == "open" {
+		accountID, err := uuid.Parse(rec.AccountID)
+		if err != nil {
+			return fmt.Errorf("parse account id: %w", err)
+		}
+		if rec.ClearedBalance, err = s.reconRepo.ClearedBalance(accountID, rec.StatementDate); err != nil {
+			return err
+		}
+	}
+	rec.Difference = rec.StatementBalance - rec.ClearedBalance - rec.AdjustmentAmount
+	return nil
+}
+
+func (s *ReconciliationService) openReconciliation(userID, id uuid.UUID) (*models.ReconciliationAPI, error) {
+	rec, err := s.reconRepo.Get(userID, id)
+	if err != nil {
+		return nil, reconciliationLookupError(err)
+	}
+	if rec.Status != "open" {
+		return nil, validationError{"reconciliation is already completed"}
+	}
+	if err := s.fill(rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// statementDate parses a statement end date, which may not precede the last completed statement.
+func (s *ReconciliationService) statementDate(accountID uuid.UUID, raw string) (string, error) {
+	t, err := time.Parse(dateLayout, strings.TrimSpace(raw))
+	if err != nil {
+		return "", validationError{"statement_date must be YYYY-MM-DD"}
+	}
+	date := t.Format(dateLayout)
+	last, err := s.reconRepo.Latest(accountID, "completed")
+	if err != nil {
+		return "", err
+	}
+	if last != nil && date < last.StatementDate {
+		return "", validationError{"statement_date must not be before the last reconciled statement (" + last.StatementDate + ")"}
+	}
+	return date, nil
+}
+
+func reconciliationLookupError(err error) error {
+	if errors.Is(err, repository.ErrReconciliationNotFound) {
+		return validationError{"reconciliation not found"}
+	}
+	return err
+}
+
+// reconciledError explains a change refused because it touches rows a completed reconciliation locked.
+func reconciledError(err error) error {
+	if errors.Is(err, repository.ErrTransactionReconciled) {
+		return validationError{"transaction is reconciled and locked; undo the reconciliation to change it"}
+	}
+	return err
+}
@@ -0,0 +1,312 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"monman-backend/internal/models"
+	"monman-backend/internal/repository"
+	"monman-backend/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+const dateLayout = "2006-01-02"
+
+// maxCatchUpOccurrences bounds how many missed occurrences one rule may post per run.
+const maxCatchUpOccurrences = 400
+
+// RecurringService manages recurring rules and materializes due occurrences into transactions.
+type RecurringService struct {
+	recRepo *repository.RecurringRepository
+	txRepo  *repository.TransactionRepository
+	accRepo *repository.AccountRepository
+	catRepo *repository.CategoryRepository
+}
+
+func NewRecurringService(
+	recRepo *repository.RecurringRepository,
+	txRepo *repository.TransactionRepository,
+	accRepo *repository.AccountRepository,
+	catRepo *repository.CategoryRepository,
+) *RecurringService {
+	return &RecurringService{
+		recRepo: recRepo,
+		txRepo:  txRepo,
+		accRepo: accRepo,
+		catRepo: catRepo,
+	}
+}
+
+func (s *RecurringService) List(userID uuid.UUID) (*models.RecurringTransactionsPayload, error) {
+	list, err := s.recRepo.ListForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.RecurringTransactionsPayload{RecurringTransactions: list}, nil
+}
+
+// Create stores a new rule; its first occurrence is start_date.
+func (s *RecurringService) Create(userID uuid.UUID, req *models.CreateRecurringTransactionRequest) (uuid.UUID, error) {
+	if _, err := time.Parse(dateLayout, req.StartDate); err != nil {
+		return uuid.Nil, validationError{"start_date must be YYYY-MM-DD"}
+	}
+	interval := req.FrequencyInterval
+	if interval == 0 {
+		interval = 1
+	}
+	rule := repository.RecurringRule{
+		UserID:             userID,
+		AccountID:          req.AccountID,
+		CategoryID:         req.CategoryID,
+		Amount:             req.MagnitudeAmountCents,
+		Description:        req.Description,
+		TransactionType:    req.TransactionType,
+		Frequency:          req.Frequency,
+		FrequencyInterval:  interval,
+		StartDate:          req.StartDate,
+		EndDate:            req.EndDate,
+		NextOccurrenceDate: req.StartDate,
+		IsActive:           true,
+	}
+	if err := s.validateRule(&rule, req.MagnitudeAmountCents); err != nil {
+		return uuid.Nil, err
+	}
+	return s.recRepo.Create(rule)
+}
+
+// Update applies a partial edit; the merged rule is validated like Create.
+func (s *RecurringService) Update(userID, id uuid.UUID, req *models.UpdateRecurringTransactionRequest) error {
+	rule, err := s.recRepo.GetForUser(id, userID)
+	if errors.Is(err, repository.ErrRecurringNotFound) {
+		return validationError{"recurring transaction not found"}
+	}
+	if err != nil {
+		return err
+	}
+
+	magnitude := rule.Amount
+	if magnitude < 0 {
+		magnitude = -magnitude
+	}
+	if req.AccountID != nil {
+		rule.AccountID = *req.AccountID
+	}
+	if req.CategoryID != nil {
+		rule.CategoryID = *req.CategoryID
+	}
+	if req.MagnitudeAmountCents != nil {
+		magnitude = *req.MagnitudeAmountCents
+	}
+	if req.Description != nil {
+		rule.Description = *req.Description
+	}
+	if req.TransactionType != nil {
+		rule.TransactionType = *req.TransactionType
+	}
+	if req.Frequency != nil {
+		rule.Frequency = *req.Frequency
+	}
+	if req.FrequencyInterval != nil {
+		rule.FrequencyInterval = *req.FrequencyInterval
+	}
+	if req.EndDate != nil {
+		rule.EndDate = nil
+		if strings.TrimSpace(*req.EndDate) != "" {
+			end := strings.TrimSpace(*req.EndDate)
+			rule.EndDate = &end
+		}
+	}
+	if req.NextOccurrenceDate != nil {
+		next := strings.TrimSpace(*req.NextOccurrenceDate)
+		if _, err := time.Parse(dateLayout, next); err != nil {
+			return validationError{"next_occurrence_date must be YYYY-MM-DD"}
+		}
+		if next < rule.StartDate {
+			return validationError{"next_occurrence_date cannot be before start_date"}
+		}
+		rule.NextOccurrenceDate = next
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if err := s.validateRule(rule, magnitude); err != nil {
+		return err
+	}
+	if rule.EndDate != nil && rule.NextOccurrenceDate > *rule.EndDate {
+		// Nothing is left to post before the end date, so the rule is finished.
+		rule.IsActive = false
+	}
+	err = s.recRepo.Update(*rule)
+	if errors.Is(err, repository.ErrRecurringNotFound) {
+		return validationError{"recurring transaction not found"}
+	}
+	return err
+}
+
+// Delete removes a rule; transactions it already posted stay.
+func (s *RecurringService) Delete(userID, id uuid.UUID) error {
+	err := s.recRepo.Delete(id, userID)
+	if errors.Is(err, repository.ErrRecurringNotFound) {
+		return validationError{"recurring transaction not found"}
+	}
+	return err
+}
+
+// validateRule normalizes and checks a merged rule, setting the signed Amount from magnitude.
+func (s *RecurringService) validateRule(rule *repository.RecurringRule, magnitude int64) error {
+	if magnitude <= 0 {
+		return validationError{"magnitude_amount_cents must be positive"}
+	}
+	rule.Description = strings.TrimSpace(rule.Description)
+	if rule.Description == "" {
+		return validationError{"description is required"}
+	}
+	rule.TransactionType = strings.ToLower(strings.TrimSpace(rule.TransactionType))
+	switch rule.TransactionType {
+	case "income":
+		rule.Amount = magnitude
+	case "expense":
+		rule.Amount = -magnitude
+	default:
+		return validationError{"transaction_type must be income or expense"}
+	}
+	rule.Frequency = strings.ToLower(strings.TrimSpace(rule.Frequency))
+	switch rule.Frequency {
+	case "daily", "weekly", "monthly", "yearly":
+	default:
+		return validationError{"frequency must be daily, weekly, monthly, or yearly"}
+	}
+	if rule.FrequencyInterval < 1 || rule.FrequencyInterval > 365 {
+		return validationError{"frequency_interval must be between 1 and 365"}
+	}
+	if rule.EndDate != nil {
+		if _, err := time.Parse(dateLayout, *rule.EndDate); err != nil {
+			return validationError{"end_date must be YYYY-MM-DD"}
+		}
+		if *rule.EndDate < rule.StartDate {
+			return validationError{"end_date cannot be before start_date"}
+		}
+	}
+
+	acc, err := s.accRepo.GetForUser(rule.AccountID, rule.UserID)
+	if errors.Is(err, repository.ErrAccountNotFound) || err == nil && !acc.IsActive {
+		return validationError{"account not found"}
+	}
+	if err != nil {
+		return err
+	}
+	if !utils.ValidCurrencyAmount(magnitude, acc.Currency) {
+		return validationError{acc.Currency + " has no minor unit; magnitude_amount_cents must be a multiple of 100"}
+	}
+	ctype, ok, err := s.catRepo.CategoryOwnedOrSystem(rule.CategoryID, rule.UserID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return validationError{"category not found"}
+	}
+	if ctype != rule.TransactionType {
+		return validationError{"category type does not match transaction_type"}
+	}
+	return nil
+}
+
+// ProcessDue posts every occurrence due on or before now's local date, catching up missed ones.
+// Safe to run repeatedly or concurrently with restarts: each (rule, date) is posted at most once.
+func (s *RecurringService) ProcessDue(now time.Time) (int, error) {
+	today := now.Format(dateLayout)
+	rules, err := s.recRepo.ListDue(today)
+	if err != nil {
+		return 0, err
+	}
+
+	posted := 0
+	var firstErr error
+	for _, rule := range rules {
+		n, err := s.processRule(rule, today)
+		posted += n
+		if err != nil {
+			log.Printf("recurring %s: %v", rule.ID, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return posted, firstErr
+}
+
+func (s *RecurringService) processRule(rule repository.RecurringRule, today string) (int, error) {
+	if rule.EndDate != nil && rule.NextOccurrenceDate > *rule.EndDate {
+		return 0, s.recRepo.Deactivate(rule.ID)
+	}
+	ok, err := s.accRepo.AccountBelongs(rule.AccountID, rule.UserID)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		// The account is inactive: stop the rule instead of failing on every tick.
+		// Re-activating it (PATCH is_active) resumes from next_occurrence_date.
+		log.Printf("recurring %s: account %s is inactive; deactivating rule", rule.ID, rule.AccountID)
+		return 0, s.recRepo.Deactivate(rule.ID)
+	}
+	start, err := time.Parse(dateLayout, rule.StartDate)
+	if err != nil {
+		return 0, fmt.Errorf("parse start_date: %w", err)
+	}
+
+	posted := 0
+	occurrence := rule.NextOccurrenceDate
+	for i := 0; i < maxCatchUpOccurrences && occurrence <= today; i++ {
+		if rule.EndDate != nil && occurrence > *rule.EndDate {
+			break
+		}
+		cur, err := time.Parse(dateLayout, occurrence)
+		if err != nil {
+			return posted, fmt.Errorf("parse next_occurrence_date: %w", err)
+		}
+		next := nextOccurrence(cur, start.Day(), rule.Frequency, rule.FrequencyInterval).Format(dateLayout)
+		finished := rule.EndDate != nil && next > *rule.EndDate
+
+		ok, err := s.txRepo.CreateRecurringOccurrence(rule, occurrence, next, finished)
+		if err != nil {
+			return posted, err
+		}
+		if ok {
+			posted++
+		}
+		occurrence = next
+	}
+	return posted, nil
+}
+
+// nextOccurrence advances cur by interval periods. Monthly and yearly rules keep anchorDay,
+// clamped to the month's last day (a rule starting on the 31st posts on Feb 28/29).
+func nextOccurrence(cur time.Time, anchorDay int, frequency string, interval int) time.Time {
+	if interval < 1 {
+		interval = 1
+	}
+	switch frequency {
+	case "daily":
+		return cur.AddDate(0, 0, interval)
+	case "weekly":
+		return cur.AddDate(0, 0, 7*interval)
+	case "yearly":
+		return clampDay(cur.Year()+interval, cur.Month(), anchorDay)
+	default:
+		firstOfMonth := time.Date(cur.Year(), cur.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, interval, 0)
+		return clampDay(firstOfMonth.Year(), firstOfMonth.Month(), anchorDay)
+	}
+}
+
+func clampDay(year int, month time.Month, day int) time.Time {
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if day > last {
+		day = last
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
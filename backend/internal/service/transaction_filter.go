@@ -0,0 +1,64 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"monman-backend/internal/models"
+)
+
+// validateTransactionFilter checks f and fills in the default page size and sort order.
+func validateTransactionFilter(f *models.TransactionFilter) error {
+	if f.Limit <= 0 {
+		f.Limit = 100
+	}
+	if f.Limit > 500 {
+		return validationError{"limit must be at most 500"}
+	}
+	if f.Offset < 0 {
+		return validationError{"offset must not be negative"}
+	}
+	if f.Cursor != "" {
+		f.Offset = 0
+	}
+	if f.From != "" {
+		if _, err := time.Parse(dateLayout, f.From); err != nil {
+			return validationError{"from must be YYYY-MM-DD"}
+		}
+	}
+	if f.To != "" {
+		if _, err := time.Parse(dateLayout, f.To); err != nil {
+			return validationError{"to must be YYYY-MM-DD"}
+		}
+	}
+	if f.From != "" && f.To != "" && f.To < f.From {
+		return validationError{"to cannot be before from"}
+	}
+	for i, t := range f.Types {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t != "income" && t != "expense" && t != "transfer" {
+			return validationError{"type must be income, expense or transfer"}
+		}
+		f.Types[i] = t
+	}
+	if (f.MinAmount != nil && *f.MinAmount < 0) || (f.MaxAmount != nil && *f.MaxAmount < 0) {
+		return validationError{"min_amount and max_amount must not be negative"}
+	}
+	if f.MinAmount != nil && f.MaxAmount != nil && *f.MaxAmount < *f.MinAmount {
+		return validationError{"max_amount cannot be less than min_amount"}
+	}
+	f.Store = strings.TrimSpace(f.Store)
+	f.Query = strings.TrimSpace(f.Query)
+	if len(f.Query) > 200 || len(f.Store) > 100 {
+		return validationError{"search text is too long"}
+	}
+	switch f.Sort {
+	case "":
+		f.Sort = models.TransactionSortDateDesc
+	case models.TransactionSortDateDesc, models.TransactionSortDateAsc,
+		models.TransactionSortAmountDesc, models.TransactionSortAmountAsc:
+	default:
+		return validationError{"sort must be date_desc, date_asc, amount_desc or amount_asc"}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"monman-backend/internal/models"
+	"monman-backend/internal/repository"
+	"monman-backend/internal/utils"
+
+	"github.com/google/uuid"
+)
+
+// defaultTransferFeeCategoryID is the system "Biaya Admin & Transfer" expense category (005_transfers.sql).
+var defaultTransferFeeCategoryID = uuid.MustParse("14d1afbf-90e1-4df5-82c1-817bb1e6f105")
+
+// CreateTransfer moves money between two owned accounts without touching income/expense totals.
+// A positive transfer_fee_cents is posted as an ordinary expense on the source account.
+func (s *FinanceService) CreateTransfer(userID uuid.UUID, req *models.CreateTransferRequest) (uuid.UUID, error) {
+	if req.MagnitudeAmountCents <= 0 {
+		return uuid.Nil, validationError{"magnitude_amount_cents must be positive"}
+	}
+	if req.TransferFeeCents < 0 {
+		return uuid.Nil, validationError{"transfer_fee_cents cannot be negative"}
+	}
+	if req.TransactionDate == "" {
+		return uuid.Nil, validationError{"transaction_date is required"}
+	}
+	if req.FromAccountID == req.ToAccountID {
+		return uuid.Nil, validationError{"from_account_id and to_account_id must differ"}
+	}
+	for _, acc := range []uuid.UUID{req.FromAccountID, req.ToAccountID} {
+		ok, err := s.accRepo.AccountBelongs(acc, userID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		if !ok {
+			return uuid.Nil, validationError{"account not found"}
+		}
+	}
+	toAmount, err := s.transferToAmount(userID, req)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	feeCategory := defaultTransferFeeCategoryID
+	if req.TransferFeeCents > 0 {
+		if req.FeeCategoryID != nil {
+			feeCategory = *req.FeeCategoryID
+		}
+		ctype, ok, err := s.catRepo.CategoryOwnedOrSystem(feeCategory, userID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		if !ok {
+			return uuid.Nil, validationError{"fee category not found"}
+		}
+		if ctype != "expense" {
+			return uuid.Nil, validationError{"fee category must be expense type"}
+		}
+	}
+
+	desc := strings.TrimSpace(req.Description)
+	if desc == "" {
+		desc = "Transfer"
+	}
+	var notes *string
+	if req.Notes != nil && strings.TrimSpace(*req.Notes) != "" {
+		n := strings.TrimSpace(*req.Notes)
+		notes = &n
+	}
+
+	id, err := s.txRepo.CreateTransfer(repository.TransferParams{
+		UserID:        userID,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.MagnitudeAmountCents,
+		ToAmount:      toAmount,
+		Fee:           req.TransferFeeCents,
+		FeeCategoryID: feeCategory,
+		Description:   desc,
+		Date:          req.TransactionDate,
+		Notes:         notes,
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if req.TransferFeeCents > 0 {
+		s.alerts.BudgetSpendChanged(userID)
+	}
+	return id, nil
+}
+
+// ListTransfers returns transfers newest first.
+func (s *FinanceService) ListTransfers(userID uuid.UUID, limit, offset int) (*models.TransfersPayload, error) {
+	list, err := s.txRepo.ListTransfersForUser(userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &models.TransfersPayload{Transfers: list}, nil
+}
+
+// DeleteTransfer removes a transfer with both legs and its fee expense.
+func (s *FinanceService) DeleteTransfer(userID, id uuid.UUID) error {
+	err := s.txRepo.DeleteTransfer(id, userID)
+	if errors.Is(err, repository.ErrTransferNotFound) {
+		return validationError{"transfer not found"}
+	}
+	return reconciledError(err)
+}
+
+// transferToAmount validates the transfer amounts against both accounts' currencies and returns the
+// amount credited to the destination: the same amount, or between currencies to_amount_cents or the
+// amount converted at the rate on the transfer date.
+func (s *FinanceService) transferToAmount(userID uuid.UUID, req *models.CreateTransferRequest) (int64, error) {
+	from, err := s.getAccount(userID, req.FromAccountID)
+	if err != nil {
+		return 0, err
+	}
+	to, err := s.getAccount(userID, req.ToAccountID)
+	if err != nil {
+		return 0, err
+	}
+	if !utils.ValidCurrencyAmount(req.MagnitudeAmountCents, from.Currency) ||
+		!utils.ValidCurrencyAmount(req.TransferFeeCents, from.Currency) {
+		return 0, validationError{from.Currency + " has no minor unit; amounts must be multiples of 100"}
+	}
+	if from.Currency == to.Currency {
+		if req.ToAmountCents != nil && *req.ToAmountCents != req.MagnitudeAmountCents {
+			return 0, validationError{"to_amount_cents only applies between accounts in different currencies"}
+		}
+		return req.MagnitudeAmountCents, nil
+	}
+	if req.ToAmountCents != nil {
+		if *req.ToAmountCents <= 0 {
+			return 0, validationError{"to_amount_cents must be positive"}
+		}
+		if !utils.ValidCurrencyAmount(*req.ToAmountCents, to.Currency) {
+			return 0, validationError{to.Currency + " has no minor unit; to_amount_cents must be a multiple of 100"}
+		}
+		return *req.ToAmountCents, nil
+	}
+	amount, err := s.rateRepo.Convert(userID, req.MagnitudeAmountCents, from.Currency, to.Currency, req.TransactionDate)
+	if err != nil {
+		return 0, exchangeRateError(err)
+	}
+	if amount <= 0 {
+		return 0, validationError{"the amount converts to zero in " + to.Currency + "; set to_amount_cents"}
+	}
+	return amount, nil
+}
@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// currencyDecimals lists the ISO 4217 currencies MonMan accepts with their minor-unit digits. Every
+// amount is still stored as units × 100 ("cents"), whatever the currency, so a zero-decimal currency
+// such as JPY always ends in 00. Three-decimal currencies (KWD, BHD, ...) do not fit that convention and
+// are left out.
+var currencyDecimals = map[string]int{
+	"IDR": 2, "USD": 2, "SGD": 2, "MYR": 2, "EUR": 2, "GBP": 2, "AUD": 2, "NZD": 2, "CAD": 2, "CHF": 2,
+	"CNY": 2, "HKD": 2, "TWD": 2, "THB": 2, "PHP": 2, "INR": 2, "SAR": 2, "AED": 2, "QAR": 2, "TRY": 2,
+	"BND": 2, "MOP": 2, "SEK": 2, "NOK": 2, "DKK": 2,
+	"JPY": 0, "KRW": 0, "VND": 0, "CLP": 0, "ISK": 0, "PYG": 0, "UGX": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+}
+
+// NormalizeCurrency upper-cases and trims a currency code.
+func NormalizeCurrency(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// ValidCurrency reports whether code (already normalized) is a supported currency.
+func ValidCurrency(code string) bool {
+	_, ok := currencyDecimals[code]
+	return ok
+}
+
+// Currencies returns the supported codes, sorted.
+func Currencies() []string {
+	out := make([]string, 0, len(currencyDecimals))
+	for code := range currencyDecimals {
+		out = append(out, code)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// CurrencyDecimals returns the minor-unit digits of code (2 for unknown codes).
+func CurrencyDecimals(code string) int {
+	if d, ok := currencyDecimals[code]; ok {
+		return d
+	}
+	return 2
+}
+
+// ValidCurrencyAmount reports whether cents can be expressed in code, i.e. is a whole number of units
+// for a zero-decimal currency.
+func ValidCurrencyAmount(cents int64, code string) bool {
+	return CurrencyDecimals(code) > 0 || cents%100 == 0
+}
+
+// ConvertCents converts cents at rate (units of the target per unit of the source) and rounds the
+// result half away from zero to what the target currency can express.
+func ConvertCents(cents int64, rate float64, to string) int64 {
+	step := 1.0
+	if CurrencyDecimals(to) == 0 {
+		step = 100
+	}
+	return int64(math.Round(float64(cents)*rate/step) * step)
+}
+
+// FormatMoney renders cents in code: Rupiah via FormatRupiah, anything else as "USD 1,234.56" (or
+// "JPY 1,235" for a zero-decimal currency). Negative amounts are prefixed with "-".
+func FormatMoney(cents int64, code string) string {
+	if code == "" || code == "IDR" {
+		return FormatRupiah(cents)
+	}
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	whole := strconv.FormatInt(cents/100, 10)
+	grouped := make([]byte, 0, len(whole)+len(whole)/3)
+	for i := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped = append(grouped, ',')
+		}
+		grouped = append(grouped, whole[i])
+	}
+	if CurrencyDecimals(code) == 0 {
+		return fmt.Sprintf("%s%s %s", sign, code, grouped)
+	}
+	return fmt.Sprintf("%s%s %s.%02d", sign, code, grouped, cents%100)
+}
@@ -0,0 +1,69 @@
+package utils
+
+import "testing"
+
+func TestConvertCents(t *testing.T) {
+	tests := []struct {
+		name  string
+		cents int64
+		rate  float64
+		to    string
+		want  int64
+	}{
+		{"same scale", 1000, 1, "IDR", 1000},
+		{"usd to idr", 1050, 16250.5, "IDR", 17063025},
+		{"rounds half away from zero", 1, 0.5, "USD", 1},
+		{"negative rounds half away from zero", -1, 0.5, "USD", -1},
+		{"rounds down below half", 10, 0.04, "USD", 0},
+		{"zero-decimal target keeps whole units", 10000, 1.234, "JPY", 12300},
+		{"zero-decimal target rounds to the unit", 10000, 1.235, "JPY", 12400},
+		{"zero-decimal negative", -10000, 1.235, "JPY", -12400},
+		{"idr to usd", 1625000000, 1.0 / 16250, "USD", 100000},
+		{"unknown code uses two decimals", 333, 0.5, "XXX", 167},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ConvertCents(tt.cents, tt.rate, tt.to); got != tt.want {
+				t.Errorf("ConvertCents(%d, %v, %q) = %d, want %d", tt.cents, tt.rate, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidCurrencyAmount(t *testing.T) {
+	tests := []struct {
+		cents int64
+		code  string
+		want  bool
+	}{
+		{150, "IDR", true},
+		{150, "JPY", false},
+		{1500, "JPY", true},
+		{-1500, "KRW", true},
+	}
+	for _, tt := range tests {
+		if got := ValidCurrencyAmount(tt.cents, tt.code); got != tt.want {
+			t.Errorf("ValidCurrencyAmount(%d, %q) = %v, want %v", tt.cents, tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestFormatMoney(t *testing.T) {
+	tests := []struct {
+		cents int64
+		code  string
+		want  string
+	}{
+		{123456750, "IDR", "Rp 1.234.567,50"},
+		{-7500000, "", "-Rp 75.000"},
+		{123456, "USD", "USD 1,234.56"},
+		{-5, "SGD", "-SGD 0.05"},
+		{123500, "JPY", "JPY 1,235"},
+		{100000000, "KRW", "KRW 1,000,000"},
+	}
+	for _, tt := range tests {
+		if got := FormatMoney(tt.cents, tt.code); got != tt.want {
+			t.Errorf("FormatMoney(%d, %q) = %q, want %q", tt.cents, tt.code, got, tt.want)
+		}
+	}
+}
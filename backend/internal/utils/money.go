@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// FormatRupiah renders cents in Indonesian style: "Rp 1.234.567", or "Rp 1.234.567,50" when
+// there are leftover cents. Negative amounts are prefixed with "-".
+func FormatRupiah(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	whole := strconv.FormatInt(cents/100, 10)
+	grouped := make([]byte, 0, len(whole)+len(whole)/3)
+	for i := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped = append(grouped, '.')
+		}
+		grouped = append(grouped, whole[i])
+	}
+	if frac := cents % 100; frac != 0 {
+		return fmt.Sprintf("%sRp %s,%02d", sign, grouped, frac)
+	}
+	return fmt.Sprintf("%sRp %s", sign, grouped)
+}
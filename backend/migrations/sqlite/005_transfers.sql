@@ -0,0 +1,16 @@
+-- Account-to-account transfers: paired 'transfer' rows plus an optional fee expense.
+-- The fee row is a normal expense so budgets and monthly totals still see it.
+
+ALTER TABLE transfer_transactions
+    ADD COLUMN fee_transaction_id TEXT REFERENCES transactions(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_transfer_transactions_user ON transfer_transactions(user_id);
+CREATE INDEX IF NOT EXISTS idx_transfer_transactions_from_tx ON transfer_transactions(from_transaction_id);
+CREATE INDEX IF NOT EXISTS idx_transfer_transactions_to_tx ON transfer_transactions(to_transaction_id);
+-- Edits and deletes of a transaction check whether it is a transfer's fee row.
+CREATE INDEX IF NOT EXISTS idx_transfer_transactions_fee_tx ON transfer_transactions(fee_transaction_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_user_type ON transactions(user_id, transaction_type);
+
+-- Default category for transfer fees (biaya admin antar bank, top-up e-wallet).
+INSERT OR REPLACE INTO categories (id, name, category_type, icon, color, is_system, is_active) VALUES
+('14d1afbf-90e1-4df5-82c1-817bb1e6f105', 'Biaya Admin & Transfer', 'expense', 'arrow-left-right', '#64748B', 1, 1);
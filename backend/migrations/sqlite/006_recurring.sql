@@ -0,0 +1,15 @@
+-- Recurring rules: one row per materialized occurrence so the scheduler never double-posts,
+-- even if it crashes between inserting the transaction and advancing next_occurrence_date.
+
+CREATE TABLE IF NOT EXISTS recurring_occurrences (
+    id TEXT PRIMARY KEY NOT NULL,
+    recurring_id TEXT NOT NULL REFERENCES recurring_transactions(id) ON DELETE CASCADE,
+    occurrence_date TEXT NOT NULL,
+    transaction_id TEXT REFERENCES transactions(id) ON DELETE SET NULL,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    UNIQUE (recurring_id, occurrence_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_recurring_transactions_user ON recurring_transactions(user_id);
+CREATE INDEX IF NOT EXISTS idx_recurring_transactions_due ON recurring_transactions(is_active, next_occurrence_date);
+CREATE INDEX IF NOT EXISTS idx_recurring_occurrences_transaction ON recurring_occurrences(transaction_id);
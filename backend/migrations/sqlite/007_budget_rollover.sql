@@ -0,0 +1,30 @@
+-- Budget period rollover: each closed window is kept in budget_period_history, and budgets can
+-- carry the remaining amount (unspent, overspent, or both) into the next window.
+-- carryover_amount is signed cents added to allocated_amount for the current window.
+
+ALTER TABLE budgets ADD COLUMN carry_over TEXT NOT NULL DEFAULT 'none'
+    CHECK (carry_over IN ('none', 'unspent', 'overspent', 'both'));
+ALTER TABLE budgets ADD COLUMN carryover_amount INTEGER NOT NULL DEFAULT 0;
+
+-- Monthly and yearly windows keep the day of month they started on; once a window is clamped to a short
+-- month (31st -> Feb 28) its start no longer shows that day, so rollover stores it here. NULL means the
+-- day of period_start_date (set on the first rollover, cleared when the start is edited).
+ALTER TABLE budgets ADD COLUMN period_anchor_day INTEGER CHECK (period_anchor_day BETWEEN 1 AND 31);
+
+CREATE TABLE IF NOT EXISTS budget_period_history (
+    id TEXT PRIMARY KEY NOT NULL,
+    budget_id TEXT NOT NULL REFERENCES budgets(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    budget_period TEXT NOT NULL,
+    period_start_date TEXT NOT NULL,
+    period_end_date TEXT NOT NULL,
+    allocated_amount INTEGER NOT NULL,
+    carryover_in INTEGER NOT NULL DEFAULT 0,
+    spent_amount INTEGER NOT NULL DEFAULT 0,
+    carryover_out INTEGER NOT NULL DEFAULT 0,
+    closed_at TEXT NOT NULL DEFAULT (datetime('now')),
+    UNIQUE (budget_id, period_start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_budget_period_history_budget ON budget_period_history(budget_id, period_start_date DESC);
+CREATE INDEX IF NOT EXISTS idx_budgets_rollover_due ON budgets(is_active, auto_reset, period_end_date);
@@ -0,0 +1,24 @@
+-- Budget threshold alerts: one row per (budget, period, threshold), so an alert fires once when
+-- spending crosses alert_percentage and once at 100%, not on every later expense in that period.
+-- notified_at is set when delivery to the configured notifiers is claimed (at most once).
+
+CREATE TABLE IF NOT EXISTS budget_alerts (
+    id TEXT PRIMARY KEY NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    budget_id TEXT NOT NULL REFERENCES budgets(id) ON DELETE CASCADE,
+    period_start_date TEXT NOT NULL,
+    period_end_date TEXT NOT NULL,
+    threshold_percent INTEGER NOT NULL CHECK (threshold_percent > 0),
+    spent_amount INTEGER NOT NULL,
+    available_amount INTEGER NOT NULL,
+    message TEXT NOT NULL,
+    is_read INTEGER NOT NULL DEFAULT 0 CHECK (is_read IN (0, 1)),
+    read_at TEXT,
+    notified_at TEXT,
+    delivery_error TEXT,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    UNIQUE (budget_id, period_start_date, threshold_percent)
+);
+
+CREATE INDEX IF NOT EXISTS idx_budget_alerts_user ON budget_alerts(user_id, is_read, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_budget_alerts_pending ON budget_alerts(notified_at);
@@ -0,0 +1,8 @@
+-- Logging a pembelian umum preset records which preset the budget_transactions row came from.
+-- Usage counts (and the account to default to next time) are derived from these links, so deleting
+-- a logged expense also drops it from the preset's count.
+
+ALTER TABLE budget_transactions ADD COLUMN common_purchase_id TEXT
+    REFERENCES budget_common_purchases(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_budget_transactions_common_purchase ON budget_transactions(common_purchase_id);
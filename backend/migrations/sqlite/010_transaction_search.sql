@@ -0,0 +1,85 @@
+-- Full-text search over transactions: one transactions_fts row per transaction holding its
+-- description, notes and location plus the budget_transactions item/store, kept in sync by triggers.
+-- The index is keyed by rowid so the triggers find their row directly. transactions' own rowid is not
+-- stable across VACUUM (its key is TEXT), so transaction_search_ids hands out an INTEGER PRIMARY KEY
+-- per transaction that the FTS rowid follows.
+
+CREATE TABLE IF NOT EXISTS transaction_search_ids (
+    id INTEGER PRIMARY KEY,
+    transaction_id TEXT NOT NULL UNIQUE
+);
+
+CREATE VIRTUAL TABLE IF NOT EXISTS transactions_fts USING fts5(
+    description,
+    notes,
+    location_name,
+    item,
+    store,
+    tokenize = 'unicode61 remove_diacritics 2'
+);
+
+INSERT INTO transaction_search_ids (transaction_id)
+SELECT id FROM transactions;
+
+INSERT INTO transactions_fts (rowid, description, notes, location_name, item, store)
+SELECT s.id, t.description, COALESCE(t.notes, ''), COALESCE(t.location_name, ''),
+       COALESCE(bt.item, ''), COALESCE(bt.store, '')
+FROM transaction_search_ids s
+INNER JOIN transactions t ON t.id = s.transaction_id
+LEFT JOIN budget_transactions bt ON bt.transaction_id = t.id;
+
+CREATE TRIGGER IF NOT EXISTS tr_transactions_fts_after_insert
+AFTER INSERT ON transactions
+FOR EACH ROW
+BEGIN
+    INSERT INTO transaction_search_ids (transaction_id) VALUES (NEW.id);
+    INSERT INTO transactions_fts (rowid, description, notes, location_name, item, store)
+    VALUES (
+        (SELECT id FROM transaction_search_ids WHERE transaction_id = NEW.id),
+        NEW.description, COALESCE(NEW.notes, ''), COALESCE(NEW.location_name, ''), '', ''
+    );
+END;
+
+CREATE TRIGGER IF NOT EXISTS tr_transactions_fts_after_update
+AFTER UPDATE OF description, notes, location_name ON transactions
+FOR EACH ROW
+BEGIN
+    UPDATE transactions_fts
+    SET description = NEW.description,
+        notes = COALESCE(NEW.notes, ''),
+        location_name = COALESCE(NEW.location_name, '')
+    WHERE rowid = (SELECT id FROM transaction_search_ids WHERE transaction_id = NEW.id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS tr_transactions_fts_after_delete
+AFTER DELETE ON transactions
+FOR EACH ROW
+BEGIN
+    DELETE FROM transactions_fts
+    WHERE rowid = (SELECT id FROM transaction_search_ids WHERE transaction_id = OLD.id);
+    DELETE FROM transaction_search_ids WHERE transaction_id = OLD.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS tr_budget_transactions_fts_after_insert
+AFTER INSERT ON budget_transactions
+FOR EACH ROW
+BEGIN
+    UPDATE transactions_fts SET item = NEW.item, store = COALESCE(NEW.store, '')
+    WHERE rowid = (SELECT id FROM transaction_search_ids WHERE transaction_id = NEW.transaction_id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS tr_budget_transactions_fts_after_update
+AFTER UPDATE OF item, store ON budget_transactions
+FOR EACH ROW
+BEGIN
+    UPDATE transactions_fts SET item = NEW.item, store = COALESCE(NEW.store, '')
+    WHERE rowid = (SELECT id FROM transaction_search_ids WHERE transaction_id = NEW.transaction_id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS tr_budget_transactions_fts_after_delete
+AFTER DELETE ON budget_transactions
+FOR EACH ROW
+BEGIN
+    UPDATE transactions_fts SET item = '', store = ''
+    WHERE rowid = (SELECT id FROM transaction_search_ids WHERE transaction_id = OLD.transaction_id);
+END;
@@ -0,0 +1,4 @@
+-- Keyset pagination for GET /api/transactions seeks on (transaction_date, created_at, id) per user;
+-- this index serves both directions of the default date sort.
+
+CREATE INDEX IF NOT EXISTS idx_transactions_user_keyset ON transactions(user_id, transaction_date, created_at, id);
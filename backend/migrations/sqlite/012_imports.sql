@@ -0,0 +1,33 @@
+-- Statement imports: saved CSV column mappings per user, and one import_batches row per committed
+-- file. Imported transactions point at their batch so the whole file can be reverted at once.
+
+CREATE TABLE IF NOT EXISTS import_profiles (
+    id TEXT PRIMARY KEY NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    mapping TEXT NOT NULL, -- JSON models.ImportProfile
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    updated_at TEXT NOT NULL DEFAULT (datetime('now')),
+    UNIQUE (user_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS import_batches (
+    id TEXT PRIMARY KEY NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    account_id TEXT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    source TEXT NOT NULL, -- csv
+    filename TEXT,
+    profile_name TEXT,
+    row_count INTEGER NOT NULL DEFAULT 0,
+    income_total INTEGER NOT NULL DEFAULT 0,
+    expense_total INTEGER NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'committed' CHECK (status IN ('committed', 'reverted')),
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    reverted_at TEXT
+);
+
+ALTER TABLE transactions ADD COLUMN import_batch_id TEXT
+    REFERENCES import_batches(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_import_batches_user ON import_batches(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_transactions_import_batch ON transactions(import_batch_id);
@@ -0,0 +1,7 @@
+-- Bank-assigned transaction ids (OFX FITID) recorded on import, so the same statement line is never
+-- imported twice into one account. Unique per account; rows entered by hand have none.
+
+ALTER TABLE transactions ADD COLUMN external_id TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_account_external_id
+    ON transactions(account_id, external_id) WHERE external_id IS NOT NULL;
@@ -0,0 +1,14 @@
+-- Duplicate detection: candidates share account and amount within a day, so index that lookup.
+-- Pairs the user confirmed are two real purchases are remembered (first_id < second_id) and no
+-- longer suggested.
+
+CREATE INDEX IF NOT EXISTS idx_transactions_account_amount_date
+    ON transactions(account_id, amount, transaction_date);
+
+CREATE TABLE IF NOT EXISTS transaction_duplicate_dismissals (
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    first_id TEXT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    second_id TEXT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    PRIMARY KEY (first_id, second_id)
+);
@@ -0,0 +1,5 @@
+-- Opening balance: a dated 'transfer'-type row with no category that brings the trigger-maintained
+-- balance to the real bank balance. Being a transfer it stays out of budgets and income/expense totals.
+
+ALTER TABLE accounts ADD COLUMN opening_transaction_id TEXT
+    REFERENCES transactions(id) ON DELETE SET NULL;
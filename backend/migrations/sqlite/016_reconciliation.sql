@@ -0,0 +1,30 @@
+-- Statement reconciliation: the user enters a statement's end date and ending balance, marks the
+-- account's transactions as cleared, and completes once the cleared balance matches (optionally
+-- posting an adjustment row for the gap). Completing stamps the cleared rows with the reconciliation,
+-- which locks them against edits and deletes until that reconciliation is undone.
+
+CREATE TABLE IF NOT EXISTS reconciliations (
+    id TEXT PRIMARY KEY NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    account_id TEXT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    statement_date TEXT NOT NULL,
+    statement_balance INTEGER NOT NULL,
+    cleared_balance INTEGER, -- set on completion, before any adjustment
+    adjustment_transaction_id TEXT REFERENCES transactions(id) ON DELETE SET NULL,
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'completed')),
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    updated_at TEXT NOT NULL DEFAULT (datetime('now')),
+    completed_at TEXT
+);
+
+-- At most one reconciliation in progress per account.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reconciliations_open
+    ON reconciliations(account_id) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS idx_reconciliations_account
+    ON reconciliations(account_id, statement_date);
+
+ALTER TABLE transactions ADD COLUMN cleared INTEGER NOT NULL DEFAULT 0 CHECK (cleared IN (0, 1));
+ALTER TABLE transactions ADD COLUMN reconciliation_id TEXT
+    REFERENCES reconciliations(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_reconciliation ON transactions(reconciliation_id);
@@ -0,0 +1,12 @@
+-- Credit card statement cycles: the day of the month the statement closes and the day payment is due
+-- (both clamped to short months), and the minimum payment rule, the larger of a percentage of the
+-- statement balance and a floor (default 5% / Rp 50.000), capped at the balance itself.
+
+ALTER TABLE accounts ADD COLUMN statement_closing_day INTEGER
+    CHECK (statement_closing_day BETWEEN 1 AND 31);
+ALTER TABLE accounts ADD COLUMN payment_due_day INTEGER
+    CHECK (payment_due_day BETWEEN 1 AND 31);
+ALTER TABLE accounts ADD COLUMN minimum_payment_percent INTEGER NOT NULL DEFAULT 5
+    CHECK (minimum_payment_percent BETWEEN 0 AND 100);
+ALTER TABLE accounts ADD COLUMN minimum_payment_floor INTEGER NOT NULL DEFAULT 5000000
+    CHECK (minimum_payment_floor >= 0);
@@ -0,0 +1,39 @@
+-- Month-end account balances so the net-worth report does not re-sum years of history. A snapshot is
+-- the balance after every row dated in or before its month; rows are filled lazily by the report and the
+-- triggers below drop the ones a transaction change makes stale.
+
+CREATE TABLE IF NOT EXISTS account_balance_snapshots (
+    account_id TEXT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    month TEXT NOT NULL, -- YYYY-MM
+    balance INTEGER NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    PRIMARY KEY (account_id, month)
+);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_account_date ON transactions(account_id, transaction_date);
+
+CREATE TRIGGER IF NOT EXISTS tr_balance_snapshots_after_insert_tx
+AFTER INSERT ON transactions
+FOR EACH ROW
+BEGIN
+    DELETE FROM account_balance_snapshots
+    WHERE account_id = NEW.account_id AND month >= substr(NEW.transaction_date, 1, 7);
+END;
+
+CREATE TRIGGER IF NOT EXISTS tr_balance_snapshots_after_update_tx
+AFTER UPDATE OF account_id, amount, transaction_date ON transactions
+FOR EACH ROW
+BEGIN
+    DELETE FROM account_balance_snapshots
+    WHERE account_id = OLD.account_id AND month >= substr(OLD.transaction_date, 1, 7);
+    DELETE FROM account_balance_snapshots
+    WHERE account_id = NEW.account_id AND month >= substr(NEW.transaction_date, 1, 7);
+END;
+
+CREATE TRIGGER IF NOT EXISTS tr_balance_snapshots_after_delete_tx
+AFTER DELETE ON transactions
+FOR EACH ROW
+BEGIN
+    DELETE FROM account_balance_snapshots
+    WHERE account_id = OLD.account_id AND month >= substr(OLD.transaction_date, 1, 7);
+END;
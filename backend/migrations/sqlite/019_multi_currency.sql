@@ -0,0 +1,81 @@
+-- Multi-currency: every account holds one currency and its transactions' amounts are in it (still
+-- units × 100). base_amount is the amount converted into the user's base currency at the rate
+-- effective on the transaction date; NULL means the account is already in the base currency. Reports
+-- and budgets sum COALESCE(base_amount, amount).
+
+ALTER TABLE users ADD COLUMN base_currency TEXT NOT NULL DEFAULT 'IDR';
+ALTER TABLE accounts ADD COLUMN currency TEXT NOT NULL DEFAULT 'IDR';
+
+ALTER TABLE transactions ADD COLUMN base_amount INTEGER;
+-- Set when the purchase was made in another currency than the account's, e.g. a USD subscription
+-- charged to a Rupiah card: the signed amount in that currency.
+ALTER TABLE transactions ADD COLUMN original_amount INTEGER;
+ALTER TABLE transactions ADD COLUMN original_currency TEXT;
+
+-- rate units of quote_currency buy one unit of currency, effective from rate_date until the next one.
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id TEXT PRIMARY KEY NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    currency TEXT NOT NULL,
+    quote_currency TEXT NOT NULL,
+    rate REAL NOT NULL CHECK (rate > 0),
+    rate_date TEXT NOT NULL,
+    source TEXT NOT NULL DEFAULT 'manual' CHECK (source IN ('manual', 'import')),
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    updated_at TEXT NOT NULL DEFAULT (datetime('now')),
+    CHECK (currency != quote_currency)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_exchange_rates_pair_date ON exchange_rates(user_id, currency, quote_currency, rate_date);
+
+-- Budget spent counts the converted amount.
+DROP TRIGGER IF EXISTS tr_budgets_after_insert_tx;
+DROP TRIGGER IF EXISTS tr_budgets_after_update_tx;
+DROP TRIGGER IF EXISTS tr_budgets_after_delete_tx;
+
+CREATE TRIGGER IF NOT EXISTS tr_budgets_after_insert_tx
+AFTER INSERT ON transactions
+FOR EACH ROW
+WHEN NEW.transaction_type = 'expense' AND NEW.category_id IS NOT NULL
+BEGIN
+    UPDATE budgets SET spent_amount = spent_amount + ABS(COALESCE(NEW.base_amount, NEW.amount))
+    WHERE user_id = NEW.user_id
+      AND category_id = NEW.category_id
+      AND period_start_date <= NEW.transaction_date
+      AND period_end_date >= NEW.transaction_date
+      AND is_active = 1;
+END;
+
+CREATE TRIGGER IF NOT EXISTS tr_budgets_after_update_tx
+AFTER UPDATE ON transactions
+FOR EACH ROW
+BEGIN
+    UPDATE budgets SET spent_amount = spent_amount - ABS(COALESCE(OLD.base_amount, OLD.amount))
+    WHERE OLD.transaction_type = 'expense' AND OLD.category_id IS NOT NULL
+      AND user_id = OLD.user_id
+      AND category_id = OLD.category_id
+      AND period_start_date <= OLD.transaction_date
+      AND period_end_date >= OLD.transaction_date
+      AND is_active = 1;
+
+    UPDATE budgets SET spent_amount = spent_amount + ABS(COALESCE(NEW.base_amount, NEW.amount))
+    WHERE NEW.transaction_type = 'expense' AND NEW.category_id IS NOT NULL
+      AND user_id = NEW.user_id
+      AND category_id = NEW.category_id
+      AND period_start_date <= NEW.transaction_date
+      AND period_end_date >= NEW.transaction_date
+      AND is_active = 1;
+END;
+
+CREATE TRIGGER IF NOT EXISTS tr_budgets_after_delete_tx
+AFTER DELETE ON transactions
+FOR EACH ROW
+WHEN OLD.transaction_type = 'expense' AND OLD.category_id IS NOT NULL
+BEGIN
+    UPDATE budgets SET spent_amount = spent_amount - ABS(COALESCE(OLD.base_amount, OLD.amount))
+    WHERE user_id = OLD.user_id
+      AND category_id = OLD.category_id
+      AND period_start_date <= OLD.transaction_date
+      AND period_end_date >= OLD.transaction_date
+      AND is_active = 1;
+END;
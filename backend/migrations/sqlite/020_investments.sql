@@ -0,0 +1,62 @@
+-- Investment holdings: instruments (reksadana, IDX stocks, gold, ...) the user tracks, a local price
+-- table, and buy/sell/dividend trades on investment accounts. The account balance stays the cash in the
+-- account (each trade posts its cash movement as a transaction); holdings are replayed from the trades
+-- with average cost, and the market value at the latest price is added to the account for net worth.
+
+CREATE TABLE IF NOT EXISTS instruments (
+    id TEXT PRIMARY KEY NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    symbol TEXT NOT NULL,
+    name TEXT NOT NULL,
+    instrument_type TEXT NOT NULL CHECK (instrument_type IN ('mutual_fund', 'stock', 'gold', 'bond', 'other')),
+    currency TEXT NOT NULL DEFAULT 'IDR',
+    unit_label TEXT NOT NULL DEFAULT 'unit', -- e.g. unit, lembar, gram
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_instruments_symbol ON instruments(user_id, symbol);
+
+-- price is cents per unit, effective from price_date until the instrument's next price.
+CREATE TABLE IF NOT EXISTS instrument_prices (
+    id TEXT PRIMARY KEY NOT NULL,
+    instrument_id TEXT NOT NULL REFERENCES instruments(id) ON DELETE CASCADE,
+    price_date TEXT NOT NULL,
+    price INTEGER NOT NULL CHECK (price >= 0),
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_instrument_prices_date ON instrument_prices(instrument_id, price_date);
+
+-- amount is the positive gross value of the trade (or the dividend) and fee what the broker charged on
+-- top of a buy or took from a sale. realized_gain is filled for sells by the holdings replay.
+CREATE TABLE IF NOT EXISTS investment_trades (
+    id TEXT PRIMARY KEY NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    account_id TEXT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    instrument_id TEXT NOT NULL REFERENCES instruments(id),
+    trade_type TEXT NOT NULL CHECK (trade_type IN ('buy', 'sell', 'dividend')),
+    trade_date TEXT NOT NULL,
+    units REAL NOT NULL DEFAULT 0 CHECK (units >= 0),
+    amount INTEGER NOT NULL CHECK (amount >= 0),
+    fee INTEGER NOT NULL DEFAULT 0 CHECK (fee >= 0),
+    realized_gain INTEGER,
+    transaction_id TEXT REFERENCES transactions(id) ON DELETE SET NULL,
+    notes TEXT,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_investment_trades_holding ON investment_trades(account_id, instrument_id, trade_date);
+CREATE INDEX IF NOT EXISTS idx_investment_trades_transaction ON investment_trades(transaction_id);
+
+-- Current position per account and instrument, rewritten by the replay after every trade change.
+CREATE TABLE IF NOT EXISTS investment_holdings (
+    account_id TEXT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    instrument_id TEXT NOT NULL REFERENCES instruments(id) ON DELETE CASCADE,
+    units REAL NOT NULL,
+    cost_basis INTEGER NOT NULL,
+    realized_gain INTEGER NOT NULL DEFAULT 0,
+    dividends INTEGER NOT NULL DEFAULT 0,
+    updated_at TEXT NOT NULL DEFAULT (datetime('now')),
+    PRIMARY KEY (account_id, instrument_id)
+);
@@ -0,0 +1,64 @@
+-- User-defined categories nest one level deep under a system or own category of the same type. A budget
+-- on a parent category counts the spending of its subcategories too. System categories cannot be edited
+-- but each user can hide them from their pickers.
+
+CREATE TABLE IF NOT EXISTS hidden_categories (
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    category_id TEXT NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    PRIMARY KEY (user_id, category_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_categories_parent ON categories(parent_category_id);
+
+-- Budget spent counts the transaction's category and its parent.
+DROP TRIGGER IF EXISTS tr_budgets_after_insert_tx;
+DROP TRIGGER IF EXISTS tr_budgets_after_update_tx;
+DROP TRIGGER IF EXISTS tr_budgets_after_delete_tx;
+
+CREATE TRIGGER IF NOT EXISTS tr_budgets_after_insert_tx
+AFTER INSERT ON transactions
+FOR EACH ROW
+WHEN NEW.transaction_type = 'expense' AND NEW.category_id IS NOT NULL
+BEGIN
+    UPDATE budgets SET spent_amount = spent_amount + ABS(COALESCE(NEW.base_amount, NEW.amount))
+    WHERE user_id = NEW.user_id
+      AND category_id IN (NEW.category_id, (SELECT parent_category_id FROM categories WHERE id = NEW.category_id))
+      AND period_start_date <= NEW.transaction_date
+      AND period_end_date >= NEW.transaction_date
+      AND is_active = 1;
+END;
+
+CREATE TRIGGER IF NOT EXISTS tr_budgets_after_update_tx
+AFTER UPDATE ON transactions
+FOR EACH ROW
+BEGIN
+    UPDATE budgets SET spent_amount = spent_amount - ABS(COALESCE(OLD.base_amount, OLD.amount))
+    WHERE OLD.transaction_type = 'expense' AND OLD.category_id IS NOT NULL
+      AND user_id = OLD.user_id
+      AND category_id IN (OLD.category_id, (SELECT parent_category_id FROM categories WHERE id = OLD.category_id))
+      AND period_start_date <= OLD.transaction_date
+      AND period_end_date >= OLD.transaction_date
+      AND is_active = 1;
+
+    UPDATE budgets SET spent_amount = spent_amount + ABS(COALESCE(NEW.base_amount, NEW.amount))
+    WHERE NEW.transaction_type = 'expense' AND NEW.category_id IS NOT NULL
+      AND user_id = NEW.user_id
+      AND category_id IN (NEW.category_id, (SELECT parent_category_id FROM categories WHERE id = NEW.category_id))
+      AND period_start_date <= NEW.transaction_date
+      AND period_end_date >= NEW.transaction_date
+      AND is_active = 1;
+END;
+
+CREATE TRIGGER IF NOT EXISTS tr_budgets_after_delete_tx
+AFTER DELETE ON transactions
+FOR EACH ROW
+WHEN OLD.transaction_type = 'expense' AND OLD.category_id IS NOT NULL
+BEGIN
+    UPDATE budgets SET spent_amount = spent_amount - ABS(COALESCE(OLD.base_amount, OLD.amount))
+    WHERE user_id = OLD.user_id
+      AND category_id IN (OLD.category_id, (SELECT parent_category_id FROM categories WHERE id = OLD.category_id))
+      AND period_start_date <= OLD.transaction_date
+      AND period_end_date >= OLD.transaction_date
+      AND is_active = 1;
+END;
@@ -0,0 +1,33 @@
+-- Auto-categorization: each user's rules are tried in priority order (lowest first) against a new or
+-- imported transaction's description, store or location, amount and account. The first match sets its
+-- category and optionally a budget (with line item name) and tags.
+
+CREATE TABLE IF NOT EXISTS categorization_rules (
+    id TEXT PRIMARY KEY NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    priority INTEGER NOT NULL DEFAULT 100,
+    match_field TEXT NOT NULL DEFAULT 'description' CHECK (match_field IN ('description', 'store', 'location', 'any')),
+    match_type TEXT NOT NULL DEFAULT 'contains' CHECK (match_type IN ('contains', 'regex')),
+    pattern TEXT NOT NULL DEFAULT '',
+    min_amount INTEGER CHECK (min_amount IS NULL OR min_amount >= 0),
+    max_amount INTEGER CHECK (max_amount IS NULL OR max_amount >= 0),
+    account_id TEXT REFERENCES accounts(id) ON DELETE CASCADE,
+    category_id TEXT NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    budget_id TEXT REFERENCES budgets(id) ON DELETE SET NULL,
+    item TEXT,
+    tags TEXT NOT NULL DEFAULT '', -- comma-separated, lower case
+    is_active INTEGER NOT NULL DEFAULT 1 CHECK (is_active IN (0, 1)),
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    updated_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_categorization_rules_user ON categorization_rules(user_id, priority);
+
+CREATE TABLE IF NOT EXISTS transaction_tags (
+    transaction_id TEXT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    PRIMARY KEY (transaction_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_tags_tag ON transaction_tags(tag);